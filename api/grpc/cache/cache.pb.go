@@ -21,6 +21,52 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type RateLimitAlgorithm int32
+
+const (
+	RateLimitAlgorithm_TOKEN_BUCKET   RateLimitAlgorithm = 0 // Допускает всплески до limit, затем limit запросов за window_ms
+	RateLimitAlgorithm_SLIDING_WINDOW RateLimitAlgorithm = 1 // Не больше limit запросов за любые window_ms
+)
+
+// Enum value maps for RateLimitAlgorithm.
+var (
+	RateLimitAlgorithm_name = map[int32]string{
+		0: "TOKEN_BUCKET",
+		1: "SLIDING_WINDOW",
+	}
+	RateLimitAlgorithm_value = map[string]int32{
+		"TOKEN_BUCKET":   0,
+		"SLIDING_WINDOW": 1,
+	}
+)
+
+func (x RateLimitAlgorithm) Enum() *RateLimitAlgorithm {
+	p := new(RateLimitAlgorithm)
+	*p = x
+	return p
+}
+
+func (x RateLimitAlgorithm) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RateLimitAlgorithm) Descriptor() protoreflect.EnumDescriptor {
+	return file_cache_cache_proto_enumTypes[0].Descriptor()
+}
+
+func (RateLimitAlgorithm) Type() protoreflect.EnumType {
+	return &file_cache_cache_proto_enumTypes[0]
+}
+
+func (x RateLimitAlgorithm) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RateLimitAlgorithm.Descriptor instead.
+func (RateLimitAlgorithm) EnumDescriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{0}
+}
+
 type HealthCheckResponse_Status int32
 
 const (
@@ -54,11 +100,11 @@ func (x HealthCheckResponse_Status) String() string {
 }
 
 func (HealthCheckResponse_Status) Descriptor() protoreflect.EnumDescriptor {
-	return file_cache_cache_proto_enumTypes[0].Descriptor()
+	return file_cache_cache_proto_enumTypes[1].Descriptor()
 }
 
 func (HealthCheckResponse_Status) Type() protoreflect.EnumType {
-	return &file_cache_cache_proto_enumTypes[0]
+	return &file_cache_cache_proto_enumTypes[1]
 }
 
 func (x HealthCheckResponse_Status) Number() protoreflect.EnumNumber {
@@ -67,14 +113,15 @@ func (x HealthCheckResponse_Status) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use HealthCheckResponse_Status.Descriptor instead.
 func (HealthCheckResponse_Status) EnumDescriptor() ([]byte, []int) {
-	return file_cache_cache_proto_rawDescGZIP(), []int{19, 0}
+	return file_cache_cache_proto_rawDescGZIP(), []int{44, 0}
 }
 
 type SetRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
-	Ttl           int32                  `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"` // Время жизни в секундах
+	Ttl           int32                  `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`  // Время жизни в секундах
+	Tags          []string               `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"` // Теги для группового удаления через DeleteByTag
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -130,6 +177,13 @@ func (x *SetRequest) GetTtl() int32 {
 	return 0
 }
 
+func (x *SetRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
 type SetResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -361,7 +415,8 @@ func (x *DeleteResponse) GetSuccess() bool {
 type IncrementRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	Value         int32                  `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"` // Значение для инкремента
+	Value         int32                  `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"` // Значение для инкремента, по умолчанию 1
+	Ttl           int32                  `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`     // Время жизни в секундах, задается при создании счетчика
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -410,6 +465,13 @@ func (x *IncrementRequest) GetValue() int32 {
 	return 0
 }
 
+func (x *IncrementRequest) GetTtl() int32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
 type IncrementResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	NewValue      int64                  `protobuf:"varint,1,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"`
@@ -457,7 +519,8 @@ func (x *IncrementResponse) GetNewValue() int64 {
 type DecrementRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	Value         int32                  `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"` // Значение для декремента
+	Value         int32                  `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"` // Значение для декремента, по умолчанию 1
+	Ttl           int32                  `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`     // Время жизни в секундах, задается при создании счетчика
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -506,6 +569,13 @@ func (x *DecrementRequest) GetValue() int32 {
 	return 0
 }
 
+func (x *DecrementRequest) GetTtl() int32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
 type DecrementResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	NewValue      int64                  `protobuf:"varint,1,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"`
@@ -554,8 +624,10 @@ type AddToListRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
-	Ttl           int32                  `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`      // Время жизни в секундах
-	Score         float64                `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"` // Для сортированных наборов
+	Ttl           int32                  `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`                              // Время жизни в секундах
+	Score         float64                `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"`                         // Для сортированных наборов
+	MaxLength     int32                  `protobuf:"varint,5,opt,name=max_length,json=maxLength,proto3" json:"max_length,omitempty"` // Максимальная длина, лишние элементы отбрасываются (по умолчанию 1000)
+	Sorted        bool                   `protobuf:"varint,6,opt,name=sorted,proto3" json:"sorted,omitempty"`                        // Сортированный набор по score вместо списка
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -618,6 +690,20 @@ func (x *AddToListRequest) GetScore() float64 {
 	return 0
 }
 
+func (x *AddToListRequest) GetMaxLength() int32 {
+	if x != nil {
+		return x.MaxLength
+	}
+	return 0
+}
+
+func (x *AddToListRequest) GetSorted() bool {
+	if x != nil {
+		return x.Sorted
+	}
+	return false
+}
+
 type AddToListResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -674,7 +760,7 @@ type GetListRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	Offset        int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
-	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"` // По умолчанию 20, не больше 1000
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -990,26 +1076,27 @@ func (x *HGetResponse) GetFields() map[string][]byte {
 	return nil
 }
 
-type HealthCheckRequest struct {
+type MGetRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"` // Не больше 1000 ключей
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthCheckRequest) Reset() {
-	*x = HealthCheckRequest{}
+func (x *MGetRequest) Reset() {
+	*x = MGetRequest{}
 	mi := &file_cache_cache_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthCheckRequest) String() string {
+func (x *MGetRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheckRequest) ProtoMessage() {}
+func (*MGetRequest) ProtoMessage() {}
 
-func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
+func (x *MGetRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_cache_cache_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1021,32 +1108,41 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
-func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use MGetRequest.ProtoReflect.Descriptor instead.
+func (*MGetRequest) Descriptor() ([]byte, []int) {
 	return file_cache_cache_proto_rawDescGZIP(), []int{18}
 }
 
-type HealthCheckResponse struct {
-	state         protoimpl.MessageState     `protogen:"open.v1"`
-	Status        HealthCheckResponse_Status `protobuf:"varint,1,opt,name=status,proto3,enum=cache.HealthCheckResponse_Status" json:"status,omitempty"`
+func (x *MGetRequest) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type MGetResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Exists        bool                   `protobuf:"varint,2,opt,name=exists,proto3" json:"exists,omitempty"`
+	Value         []byte                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthCheckResponse) Reset() {
-	*x = HealthCheckResponse{}
+func (x *MGetResult) Reset() {
+	*x = MGetResult{}
 	mi := &file_cache_cache_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthCheckResponse) String() string {
+func (x *MGetResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheckResponse) ProtoMessage() {}
+func (*MGetResult) ProtoMessage() {}
 
-func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
+func (x *MGetResult) ProtoReflect() protoreflect.Message {
 	mi := &file_cache_cache_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1058,90 +1154,1660 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
-func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use MGetResult.ProtoReflect.Descriptor instead.
+func (*MGetResult) Descriptor() ([]byte, []int) {
 	return file_cache_cache_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *HealthCheckResponse) GetStatus() HealthCheckResponse_Status {
+func (x *MGetResult) GetKey() string {
 	if x != nil {
-		return x.Status
+		return x.Key
 	}
-	return HealthCheckResponse_UNKNOWN
+	return ""
 }
 
-var File_cache_cache_proto protoreflect.FileDescriptor
+func (x *MGetResult) GetExists() bool {
+	if x != nil {
+		return x.Exists
+	}
+	return false
+}
 
-const file_cache_cache_proto_rawDesc = "" +
-	"\n" +
-	"\x11cache/cache.proto\x12\x05cache\"F\n" +
-	"\n" +
-	"SetRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\fR\x05value\x12\x10\n" +
-	"\x03ttl\x18\x03 \x01(\x05R\x03ttl\"'\n" +
-	"\vSetResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x1e\n" +
-	"\n" +
-	"GetRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\";\n" +
-	"\vGetResponse\x12\x16\n" +
-	"\x06exists\x18\x01 \x01(\bR\x06exists\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\fR\x05value\"!\n" +
-	"\rDeleteRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\"*\n" +
-	"\x0eDeleteResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\":\n" +
-	"\x10IncrementRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x05R\x05value\"0\n" +
-	"\x11IncrementResponse\x12\x1b\n" +
-	"\tnew_value\x18\x01 \x01(\x03R\bnewValue\":\n" +
-	"\x10DecrementRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x05R\x05value\"0\n" +
-	"\x11DecrementResponse\x12\x1b\n" +
-	"\tnew_value\x18\x01 \x01(\x03R\bnewValue\"b\n" +
-	"\x10AddToListRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\fR\x05value\x12\x10\n" +
-	"\x03ttl\x18\x03 \x01(\x05R\x03ttl\x12\x14\n" +
-	"\x05score\x18\x04 \x01(\x01R\x05score\"J\n" +
-	"\x11AddToListResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1b\n" +
-	"\tlist_size\x18\x02 \x01(\x03R\blistSize\"P\n" +
-	"\x0eGetListRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x16\n" +
-	"\x06offset\x18\x02 \x01(\x05R\x06offset\x12\x14\n" +
-	"\x05limit\x18\x03 \x01(\x05R\x05limit\"?\n" +
-	"\x0fGetListResponse\x12\x16\n" +
-	"\x06values\x18\x01 \x03(\fR\x06values\x12\x14\n" +
-	"\x05total\x18\x02 \x01(\x03R\x05total\"\xa4\x01\n" +
-	"\vHSetRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x126\n" +
-	"\x06fields\x18\x02 \x03(\v2\x1e.cache.HSetRequest.FieldsEntryR\x06fields\x12\x10\n" +
-	"\x03ttl\x18\x03 \x01(\x05R\x03ttl\x1a9\n" +
-	"\vFieldsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\fR\x05value:\x028\x01\"(\n" +
-	"\fHSetResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"7\n" +
-	"\vHGetRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x16\n" +
-	"\x06fields\x18\x02 \x03(\tR\x06fields\"\x9a\x01\n" +
-	"\fHGetResponse\x12\x16\n" +
-	"\x06exists\x18\x01 \x01(\bR\x06exists\x127\n" +
-	"\x06fields\x18\x02 \x03(\v2\x1f.cache.HGetResponse.FieldsEntryR\x06fields\x1a9\n" +
-	"\vFieldsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\fR\x05value:\x028\x01\"\x14\n" +
+func (x *MGetResult) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type MGetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*MGetResult          `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // В порядке запрошенных ключей
+	Hits          int32                  `protobuf:"varint,2,opt,name=hits,proto3" json:"hits,omitempty"`
+	Misses        int32                  `protobuf:"varint,3,opt,name=misses,proto3" json:"misses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MGetResponse) Reset() {
+	*x = MGetResponse{}
+	mi := &file_cache_cache_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MGetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MGetResponse) ProtoMessage() {}
+
+func (x *MGetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MGetResponse.ProtoReflect.Descriptor instead.
+func (*MGetResponse) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *MGetResponse) GetResults() []*MGetResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *MGetResponse) GetHits() int32 {
+	if x != nil {
+		return x.Hits
+	}
+	return 0
+}
+
+func (x *MGetResponse) GetMisses() int32 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+type MSetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*SetRequest          `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"` // Не больше 1000 значений
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MSetRequest) Reset() {
+	*x = MSetRequest{}
+	mi := &file_cache_cache_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MSetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MSetRequest) ProtoMessage() {}
+
+func (x *MSetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MSetRequest.ProtoReflect.Descriptor instead.
+func (*MSetRequest) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *MSetRequest) GetItems() []*SetRequest {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type MSetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Stored        int32                  `protobuf:"varint,2,opt,name=stored,proto3" json:"stored,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MSetResponse) Reset() {
+	*x = MSetResponse{}
+	mi := &file_cache_cache_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MSetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MSetResponse) ProtoMessage() {}
+
+func (x *MSetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MSetResponse.ProtoReflect.Descriptor instead.
+func (*MSetResponse) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *MSetResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *MSetResponse) GetStored() int32 {
+	if x != nil {
+		return x.Stored
+	}
+	return 0
+}
+
+type MDeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"` // Не больше 1000 ключей
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MDeleteRequest) Reset() {
+	*x = MDeleteRequest{}
+	mi := &file_cache_cache_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MDeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MDeleteRequest) ProtoMessage() {}
+
+func (x *MDeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MDeleteRequest.ProtoReflect.Descriptor instead.
+func (*MDeleteRequest) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *MDeleteRequest) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type MDeleteResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Deleted       bool                   `protobuf:"varint,2,opt,name=deleted,proto3" json:"deleted,omitempty"` // false, если ключа не было
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MDeleteResult) Reset() {
+	*x = MDeleteResult{}
+	mi := &file_cache_cache_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MDeleteResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MDeleteResult) ProtoMessage() {}
+
+func (x *MDeleteResult) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MDeleteResult.ProtoReflect.Descriptor instead.
+func (*MDeleteResult) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *MDeleteResult) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *MDeleteResult) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+type MDeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*MDeleteResult       `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // В порядке запрошенных ключей
+	Deleted       int64                  `protobuf:"varint,2,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MDeleteResponse) Reset() {
+	*x = MDeleteResponse{}
+	mi := &file_cache_cache_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MDeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MDeleteResponse) ProtoMessage() {}
+
+func (x *MDeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MDeleteResponse.ProtoReflect.Descriptor instead.
+func (*MDeleteResponse) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *MDeleteResponse) GetResults() []*MDeleteResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *MDeleteResponse) GetDeleted() int64 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+type DeleteByPatternRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pattern       string                 `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"` // Glob-паттерн Redis, например "post:123:*"; не может начинаться с подстановки
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteByPatternRequest) Reset() {
+	*x = DeleteByPatternRequest{}
+	mi := &file_cache_cache_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteByPatternRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteByPatternRequest) ProtoMessage() {}
+
+func (x *DeleteByPatternRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteByPatternRequest.ProtoReflect.Descriptor instead.
+func (*DeleteByPatternRequest) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *DeleteByPatternRequest) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+type DeleteByPatternResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deleted       int64                  `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteByPatternResponse) Reset() {
+	*x = DeleteByPatternResponse{}
+	mi := &file_cache_cache_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteByPatternResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteByPatternResponse) ProtoMessage() {}
+
+func (x *DeleteByPatternResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteByPatternResponse.ProtoReflect.Descriptor instead.
+func (*DeleteByPatternResponse) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DeleteByPatternResponse) GetDeleted() int64 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+type DeleteByTagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          []string               `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteByTagRequest) Reset() {
+	*x = DeleteByTagRequest{}
+	mi := &file_cache_cache_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteByTagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteByTagRequest) ProtoMessage() {}
+
+func (x *DeleteByTagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteByTagRequest.ProtoReflect.Descriptor instead.
+func (*DeleteByTagRequest) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *DeleteByTagRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type DeleteByTagResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deleted       int64                  `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteByTagResponse) Reset() {
+	*x = DeleteByTagResponse{}
+	mi := &file_cache_cache_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteByTagResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteByTagResponse) ProtoMessage() {}
+
+func (x *DeleteByTagResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteByTagResponse.ProtoReflect.Descriptor instead.
+func (*DeleteByTagResponse) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *DeleteByTagResponse) GetDeleted() int64 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+type TryLockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                 // Имя блокировки, например "world-generation:<user_id>"
+	Owner         string                 `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`               // Уникальный идентификатор владельца (экземпляр + запрос)
+	TtlMs         int64                  `protobuf:"varint,3,opt,name=ttl_ms,json=ttlMs,proto3" json:"ttl_ms,omitempty"` // Время жизни блокировки в миллисекундах
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TryLockRequest) Reset() {
+	*x = TryLockRequest{}
+	mi := &file_cache_cache_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TryLockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TryLockRequest) ProtoMessage() {}
+
+func (x *TryLockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TryLockRequest.ProtoReflect.Descriptor instead.
+func (*TryLockRequest) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *TryLockRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TryLockRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *TryLockRequest) GetTtlMs() int64 {
+	if x != nil {
+		return x.TtlMs
+	}
+	return 0
+}
+
+type TryLockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acquired      bool                   `protobuf:"varint,1,opt,name=acquired,proto3" json:"acquired,omitempty"`
+	Token         int64                  `protobuf:"varint,2,opt,name=token,proto3" json:"token,omitempty"`              // Fencing-токен владельца блокировки, растет с каждым захватом
+	Owner         string                 `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`               // Текущий владелец блокировки
+	TtlMs         int64                  `protobuf:"varint,4,opt,name=ttl_ms,json=ttlMs,proto3" json:"ttl_ms,omitempty"` // Оставшееся время жизни блокировки
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TryLockResponse) Reset() {
+	*x = TryLockResponse{}
+	mi := &file_cache_cache_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TryLockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TryLockResponse) ProtoMessage() {}
+
+func (x *TryLockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TryLockResponse.ProtoReflect.Descriptor instead.
+func (*TryLockResponse) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *TryLockResponse) GetAcquired() bool {
+	if x != nil {
+		return x.Acquired
+	}
+	return false
+}
+
+func (x *TryLockResponse) GetToken() int64 {
+	if x != nil {
+		return x.Token
+	}
+	return 0
+}
+
+func (x *TryLockResponse) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *TryLockResponse) GetTtlMs() int64 {
+	if x != nil {
+		return x.TtlMs
+	}
+	return 0
+}
+
+type UnlockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Owner         string                 `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Token         int64                  `protobuf:"varint,3,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnlockRequest) Reset() {
+	*x = UnlockRequest{}
+	mi := &file_cache_cache_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockRequest) ProtoMessage() {}
+
+func (x *UnlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockRequest.ProtoReflect.Descriptor instead.
+func (*UnlockRequest) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *UnlockRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UnlockRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *UnlockRequest) GetToken() int64 {
+	if x != nil {
+		return x.Token
+	}
+	return 0
+}
+
+type UnlockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Released      bool                   `protobuf:"varint,1,opt,name=released,proto3" json:"released,omitempty"` // false, если блокировка истекла или захвачена другим владельцем
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnlockResponse) Reset() {
+	*x = UnlockResponse{}
+	mi := &file_cache_cache_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnlockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockResponse) ProtoMessage() {}
+
+func (x *UnlockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockResponse.ProtoReflect.Descriptor instead.
+func (*UnlockResponse) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *UnlockResponse) GetReleased() bool {
+	if x != nil {
+		return x.Released
+	}
+	return false
+}
+
+type ExtendRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Owner         string                 `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Token         int64                  `protobuf:"varint,3,opt,name=token,proto3" json:"token,omitempty"`
+	TtlMs         int64                  `protobuf:"varint,4,opt,name=ttl_ms,json=ttlMs,proto3" json:"ttl_ms,omitempty"` // Новое время жизни, отсчитывается от момента продления
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtendRequest) Reset() {
+	*x = ExtendRequest{}
+	mi := &file_cache_cache_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtendRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtendRequest) ProtoMessage() {}
+
+func (x *ExtendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtendRequest.ProtoReflect.Descriptor instead.
+func (*ExtendRequest) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ExtendRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ExtendRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *ExtendRequest) GetToken() int64 {
+	if x != nil {
+		return x.Token
+	}
+	return 0
+}
+
+func (x *ExtendRequest) GetTtlMs() int64 {
+	if x != nil {
+		return x.TtlMs
+	}
+	return 0
+}
+
+type ExtendResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Extended      bool                   `protobuf:"varint,1,opt,name=extended,proto3" json:"extended,omitempty"` // false, если блокировка истекла или захвачена другим владельцем
+	TtlMs         int64                  `protobuf:"varint,2,opt,name=ttl_ms,json=ttlMs,proto3" json:"ttl_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtendResponse) Reset() {
+	*x = ExtendResponse{}
+	mi := &file_cache_cache_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtendResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtendResponse) ProtoMessage() {}
+
+func (x *ExtendResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtendResponse.ProtoReflect.Descriptor instead.
+func (*ExtendResponse) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ExtendResponse) GetExtended() bool {
+	if x != nil {
+		return x.Extended
+	}
+	return false
+}
+
+func (x *ExtendResponse) GetTtlMs() int64 {
+	if x != nil {
+		return x.TtlMs
+	}
+	return 0
+}
+
+type RateLimitRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`      // Ключ лимита, например "like:<user_id>"
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // Не больше 10000
+	WindowMs      int64                  `protobuf:"varint,3,opt,name=window_ms,json=windowMs,proto3" json:"window_ms,omitempty"`
+	Cost          int32                  `protobuf:"varint,4,opt,name=cost,proto3" json:"cost,omitempty"` // Вес запроса, по умолчанию 1
+	Algorithm     RateLimitAlgorithm     `protobuf:"varint,5,opt,name=algorithm,proto3,enum=cache.RateLimitAlgorithm" json:"algorithm,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RateLimitRequest) Reset() {
+	*x = RateLimitRequest{}
+	mi := &file_cache_cache_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateLimitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateLimitRequest) ProtoMessage() {}
+
+func (x *RateLimitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateLimitRequest.ProtoReflect.Descriptor instead.
+func (*RateLimitRequest) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *RateLimitRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *RateLimitRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *RateLimitRequest) GetWindowMs() int64 {
+	if x != nil {
+		return x.WindowMs
+	}
+	return 0
+}
+
+func (x *RateLimitRequest) GetCost() int32 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *RateLimitRequest) GetAlgorithm() RateLimitAlgorithm {
+	if x != nil {
+		return x.Algorithm
+	}
+	return RateLimitAlgorithm_TOKEN_BUCKET
+}
+
+type RateLimitResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Allowed       bool                   `protobuf:"varint,1,opt,name=allowed,proto3" json:"allowed,omitempty"`
+	Remaining     int32                  `protobuf:"varint,2,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	RetryAfterMs  int64                  `protobuf:"varint,3,opt,name=retry_after_ms,json=retryAfterMs,proto3" json:"retry_after_ms,omitempty"` // Через сколько запрос с той же стоимостью будет разрешен, 0 если разрешен
+	ResetAfterMs  int64                  `protobuf:"varint,4,opt,name=reset_after_ms,json=resetAfterMs,proto3" json:"reset_after_ms,omitempty"` // Через сколько лимит полностью восстановится
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RateLimitResponse) Reset() {
+	*x = RateLimitResponse{}
+	mi := &file_cache_cache_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateLimitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateLimitResponse) ProtoMessage() {}
+
+func (x *RateLimitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateLimitResponse.ProtoReflect.Descriptor instead.
+func (*RateLimitResponse) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *RateLimitResponse) GetAllowed() bool {
+	if x != nil {
+		return x.Allowed
+	}
+	return false
+}
+
+func (x *RateLimitResponse) GetRemaining() int32 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *RateLimitResponse) GetRetryAfterMs() int64 {
+	if x != nil {
+		return x.RetryAfterMs
+	}
+	return 0
+}
+
+func (x *RateLimitResponse) GetResetAfterMs() int64 {
+	if x != nil {
+		return x.ResetAfterMs
+	}
+	return 0
+}
+
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`             // Только это пространство имен; пусто - все
+	TopKeys       int32                  `protobuf:"varint,2,opt,name=top_keys,json=topKeys,proto3" json:"top_keys,omitempty"` // Число горячих ключей в ответе, по умолчанию 20
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_cache_cache_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *GetStatsRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *GetStatsRequest) GetTopKeys() int32 {
+	if x != nil {
+		return x.TopKeys
+	}
+	return 0
+}
+
+type ValueSizeBucket struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LeBytes       int64                  `protobuf:"varint,1,opt,name=le_bytes,json=leBytes,proto3" json:"le_bytes,omitempty"` // Верхняя граница корзины, 0 - значения больше последней границы
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValueSizeBucket) Reset() {
+	*x = ValueSizeBucket{}
+	mi := &file_cache_cache_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValueSizeBucket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValueSizeBucket) ProtoMessage() {}
+
+func (x *ValueSizeBucket) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValueSizeBucket.ProtoReflect.Descriptor instead.
+func (*ValueSizeBucket) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ValueSizeBucket) GetLeBytes() int64 {
+	if x != nil {
+		return x.LeBytes
+	}
+	return 0
+}
+
+func (x *ValueSizeBucket) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type NamespaceStats struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Namespace       string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"` // Часть ключа до первого ":"
+	Hits            int64                  `protobuf:"varint,2,opt,name=hits,proto3" json:"hits,omitempty"`
+	Misses          int64                  `protobuf:"varint,3,opt,name=misses,proto3" json:"misses,omitempty"`
+	HitRatio        float64                `protobuf:"fixed64,4,opt,name=hit_ratio,json=hitRatio,proto3" json:"hit_ratio,omitempty"`
+	Writes          int64                  `protobuf:"varint,5,opt,name=writes,proto3" json:"writes,omitempty"`
+	Evictions       int64                  `protobuf:"varint,6,opt,name=evictions,proto3" json:"evictions,omitempty"`     // Вытеснены Redis по maxmemory
+	Expirations     int64                  `protobuf:"varint,7,opt,name=expirations,proto3" json:"expirations,omitempty"` // Удалены Redis по TTL
+	ValueBytesTotal int64                  `protobuf:"varint,8,opt,name=value_bytes_total,json=valueBytesTotal,proto3" json:"value_bytes_total,omitempty"`
+	MaxValueBytes   int64                  `protobuf:"varint,9,opt,name=max_value_bytes,json=maxValueBytes,proto3" json:"max_value_bytes,omitempty"`
+	ValueSizes      []*ValueSizeBucket     `protobuf:"bytes,10,rep,name=value_sizes,json=valueSizes,proto3" json:"value_sizes,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *NamespaceStats) Reset() {
+	*x = NamespaceStats{}
+	mi := &file_cache_cache_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NamespaceStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NamespaceStats) ProtoMessage() {}
+
+func (x *NamespaceStats) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NamespaceStats.ProtoReflect.Descriptor instead.
+func (*NamespaceStats) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *NamespaceStats) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *NamespaceStats) GetHits() int64 {
+	if x != nil {
+		return x.Hits
+	}
+	return 0
+}
+
+func (x *NamespaceStats) GetMisses() int64 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+func (x *NamespaceStats) GetHitRatio() float64 {
+	if x != nil {
+		return x.HitRatio
+	}
+	return 0
+}
+
+func (x *NamespaceStats) GetWrites() int64 {
+	if x != nil {
+		return x.Writes
+	}
+	return 0
+}
+
+func (x *NamespaceStats) GetEvictions() int64 {
+	if x != nil {
+		return x.Evictions
+	}
+	return 0
+}
+
+func (x *NamespaceStats) GetExpirations() int64 {
+	if x != nil {
+		return x.Expirations
+	}
+	return 0
+}
+
+func (x *NamespaceStats) GetValueBytesTotal() int64 {
+	if x != nil {
+		return x.ValueBytesTotal
+	}
+	return 0
+}
+
+func (x *NamespaceStats) GetMaxValueBytes() int64 {
+	if x != nil {
+		return x.MaxValueBytes
+	}
+	return 0
+}
+
+func (x *NamespaceStats) GetValueSizes() []*ValueSizeBucket {
+	if x != nil {
+		return x.ValueSizes
+	}
+	return nil
+}
+
+type HotKey struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Key               string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Namespace         string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	EstimatedAccesses int64                  `protobuf:"varint,3,opt,name=estimated_accesses,json=estimatedAccesses,proto3" json:"estimated_accesses,omitempty"` // Оценка числа обращений с учетом сэмплирования
+	MaxError          int64                  `protobuf:"varint,4,opt,name=max_error,json=maxError,proto3" json:"max_error,omitempty"`                            // Оценка может быть завышена не больше чем на это значение
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *HotKey) Reset() {
+	*x = HotKey{}
+	mi := &file_cache_cache_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HotKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HotKey) ProtoMessage() {}
+
+func (x *HotKey) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HotKey.ProtoReflect.Descriptor instead.
+func (*HotKey) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *HotKey) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *HotKey) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *HotKey) GetEstimatedAccesses() int64 {
+	if x != nil {
+		return x.EstimatedAccesses
+	}
+	return 0
+}
+
+func (x *HotKey) GetMaxError() int64 {
+	if x != nil {
+		return x.MaxError
+	}
+	return 0
+}
+
+type GetStatsResponse struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Namespaces           []*NamespaceStats      `protobuf:"bytes,1,rep,name=namespaces,proto3" json:"namespaces,omitempty"`
+	HotKeys              []*HotKey              `protobuf:"bytes,2,rep,name=hot_keys,json=hotKeys,proto3" json:"hot_keys,omitempty"`
+	SampleRate           int32                  `protobuf:"varint,3,opt,name=sample_rate,json=sampleRate,proto3" json:"sample_rate,omitempty"`                   // Учитывается одно из sample_rate обращений
+	Since                string                 `protobuf:"bytes,4,opt,name=since,proto3" json:"since,omitempty"`                                                // Начало сбора статистики экземпляром (RFC 3339)
+	EvictionsTracked     bool                   `protobuf:"varint,5,opt,name=evictions_tracked,json=evictionsTracked,proto3" json:"evictions_tracked,omitempty"` // true, если экземпляр считает вытеснения (CACHE_TRACK_KEY_EVENTS и аренда)
+	RedisKeys            int64                  `protobuf:"varint,6,opt,name=redis_keys,json=redisKeys,proto3" json:"redis_keys,omitempty"`
+	RedisUsedMemoryBytes int64                  `protobuf:"varint,7,opt,name=redis_used_memory_bytes,json=redisUsedMemoryBytes,proto3" json:"redis_used_memory_bytes,omitempty"`
+	RedisEvictedKeys     int64                  `protobuf:"varint,8,opt,name=redis_evicted_keys,json=redisEvictedKeys,proto3" json:"redis_evicted_keys,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *GetStatsResponse) Reset() {
+	*x = GetStatsResponse{}
+	mi := &file_cache_cache_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsResponse) ProtoMessage() {}
+
+func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetStatsResponse) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *GetStatsResponse) GetNamespaces() []*NamespaceStats {
+	if x != nil {
+		return x.Namespaces
+	}
+	return nil
+}
+
+func (x *GetStatsResponse) GetHotKeys() []*HotKey {
+	if x != nil {
+		return x.HotKeys
+	}
+	return nil
+}
+
+func (x *GetStatsResponse) GetSampleRate() int32 {
+	if x != nil {
+		return x.SampleRate
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetSince() string {
+	if x != nil {
+		return x.Since
+	}
+	return ""
+}
+
+func (x *GetStatsResponse) GetEvictionsTracked() bool {
+	if x != nil {
+		return x.EvictionsTracked
+	}
+	return false
+}
+
+func (x *GetStatsResponse) GetRedisKeys() int64 {
+	if x != nil {
+		return x.RedisKeys
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetRedisUsedMemoryBytes() int64 {
+	if x != nil {
+		return x.RedisUsedMemoryBytes
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetRedisEvictedKeys() int64 {
+	if x != nil {
+		return x.RedisEvictedKeys
+	}
+	return 0
+}
+
+type HealthCheckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthCheckRequest) Reset() {
+	*x = HealthCheckRequest{}
+	mi := &file_cache_cache_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckRequest) ProtoMessage() {}
+
+func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
+func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{43}
+}
+
+type HealthCheckResponse struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Status        HealthCheckResponse_Status `protobuf:"varint,1,opt,name=status,proto3,enum=cache.HealthCheckResponse_Status" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthCheckResponse) Reset() {
+	*x = HealthCheckResponse{}
+	mi := &file_cache_cache_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckResponse) ProtoMessage() {}
+
+func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
+func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *HealthCheckResponse) GetStatus() HealthCheckResponse_Status {
+	if x != nil {
+		return x.Status
+	}
+	return HealthCheckResponse_UNKNOWN
+}
+
+var File_cache_cache_proto protoreflect.FileDescriptor
+
+const file_cache_cache_proto_rawDesc = "" +
+	"\n" +
+	"\x11cache/cache.proto\x12\x05cache\"Z\n" +
+	"\n" +
+	"SetRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\x12\x10\n" +
+	"\x03ttl\x18\x03 \x01(\x05R\x03ttl\x12\x12\n" +
+	"\x04tags\x18\x04 \x03(\tR\x04tags\"'\n" +
+	"\vSetResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x1e\n" +
+	"\n" +
+	"GetRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\";\n" +
+	"\vGetResponse\x12\x16\n" +
+	"\x06exists\x18\x01 \x01(\bR\x06exists\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\"!\n" +
+	"\rDeleteRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"*\n" +
+	"\x0eDeleteResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"L\n" +
+	"\x10IncrementRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value\x12\x10\n" +
+	"\x03ttl\x18\x03 \x01(\x05R\x03ttl\"0\n" +
+	"\x11IncrementResponse\x12\x1b\n" +
+	"\tnew_value\x18\x01 \x01(\x03R\bnewValue\"L\n" +
+	"\x10DecrementRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value\x12\x10\n" +
+	"\x03ttl\x18\x03 \x01(\x05R\x03ttl\"0\n" +
+	"\x11DecrementResponse\x12\x1b\n" +
+	"\tnew_value\x18\x01 \x01(\x03R\bnewValue\"\x99\x01\n" +
+	"\x10AddToListRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\x12\x10\n" +
+	"\x03ttl\x18\x03 \x01(\x05R\x03ttl\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x01R\x05score\x12\x1d\n" +
+	"\n" +
+	"max_length\x18\x05 \x01(\x05R\tmaxLength\x12\x16\n" +
+	"\x06sorted\x18\x06 \x01(\bR\x06sorted\"J\n" +
+	"\x11AddToListResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1b\n" +
+	"\tlist_size\x18\x02 \x01(\x03R\blistSize\"P\n" +
+	"\x0eGetListRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x05R\x06offset\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"?\n" +
+	"\x0fGetListResponse\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\fR\x06values\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\"\xa4\x01\n" +
+	"\vHSetRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x126\n" +
+	"\x06fields\x18\x02 \x03(\v2\x1e.cache.HSetRequest.FieldsEntryR\x06fields\x12\x10\n" +
+	"\x03ttl\x18\x03 \x01(\x05R\x03ttl\x1a9\n" +
+	"\vFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value:\x028\x01\"(\n" +
+	"\fHSetResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"7\n" +
+	"\vHGetRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x16\n" +
+	"\x06fields\x18\x02 \x03(\tR\x06fields\"\x9a\x01\n" +
+	"\fHGetResponse\x12\x16\n" +
+	"\x06exists\x18\x01 \x01(\bR\x06exists\x127\n" +
+	"\x06fields\x18\x02 \x03(\v2\x1f.cache.HGetResponse.FieldsEntryR\x06fields\x1a9\n" +
+	"\vFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value:\x028\x01\"!\n" +
+	"\vMGetRequest\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\"L\n" +
+	"\n" +
+	"MGetResult\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x16\n" +
+	"\x06exists\x18\x02 \x01(\bR\x06exists\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\fR\x05value\"g\n" +
+	"\fMGetResponse\x12+\n" +
+	"\aresults\x18\x01 \x03(\v2\x11.cache.MGetResultR\aresults\x12\x12\n" +
+	"\x04hits\x18\x02 \x01(\x05R\x04hits\x12\x16\n" +
+	"\x06misses\x18\x03 \x01(\x05R\x06misses\"6\n" +
+	"\vMSetRequest\x12'\n" +
+	"\x05items\x18\x01 \x03(\v2\x11.cache.SetRequestR\x05items\"@\n" +
+	"\fMSetResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x16\n" +
+	"\x06stored\x18\x02 \x01(\x05R\x06stored\"$\n" +
+	"\x0eMDeleteRequest\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\";\n" +
+	"\rMDeleteResult\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x18\n" +
+	"\adeleted\x18\x02 \x01(\bR\adeleted\"[\n" +
+	"\x0fMDeleteResponse\x12.\n" +
+	"\aresults\x18\x01 \x03(\v2\x14.cache.MDeleteResultR\aresults\x12\x18\n" +
+	"\adeleted\x18\x02 \x01(\x03R\adeleted\"2\n" +
+	"\x16DeleteByPatternRequest\x12\x18\n" +
+	"\apattern\x18\x01 \x01(\tR\apattern\"3\n" +
+	"\x17DeleteByPatternResponse\x12\x18\n" +
+	"\adeleted\x18\x01 \x01(\x03R\adeleted\"(\n" +
+	"\x12DeleteByTagRequest\x12\x12\n" +
+	"\x04tags\x18\x01 \x03(\tR\x04tags\"/\n" +
+	"\x13DeleteByTagResponse\x12\x18\n" +
+	"\adeleted\x18\x01 \x01(\x03R\adeleted\"Q\n" +
+	"\x0eTryLockRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05owner\x18\x02 \x01(\tR\x05owner\x12\x15\n" +
+	"\x06ttl_ms\x18\x03 \x01(\x03R\x05ttlMs\"p\n" +
+	"\x0fTryLockResponse\x12\x1a\n" +
+	"\bacquired\x18\x01 \x01(\bR\bacquired\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\x03R\x05token\x12\x14\n" +
+	"\x05owner\x18\x03 \x01(\tR\x05owner\x12\x15\n" +
+	"\x06ttl_ms\x18\x04 \x01(\x03R\x05ttlMs\"O\n" +
+	"\rUnlockRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05owner\x18\x02 \x01(\tR\x05owner\x12\x14\n" +
+	"\x05token\x18\x03 \x01(\x03R\x05token\",\n" +
+	"\x0eUnlockResponse\x12\x1a\n" +
+	"\breleased\x18\x01 \x01(\bR\breleased\"f\n" +
+	"\rExtendRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05owner\x18\x02 \x01(\tR\x05owner\x12\x14\n" +
+	"\x05token\x18\x03 \x01(\x03R\x05token\x12\x15\n" +
+	"\x06ttl_ms\x18\x04 \x01(\x03R\x05ttlMs\"C\n" +
+	"\x0eExtendResponse\x12\x1a\n" +
+	"\bextended\x18\x01 \x01(\bR\bextended\x12\x15\n" +
+	"\x06ttl_ms\x18\x02 \x01(\x03R\x05ttlMs\"\xa4\x01\n" +
+	"\x10RateLimitRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x1b\n" +
+	"\twindow_ms\x18\x03 \x01(\x03R\bwindowMs\x12\x12\n" +
+	"\x04cost\x18\x04 \x01(\x05R\x04cost\x127\n" +
+	"\talgorithm\x18\x05 \x01(\x0e2\x19.cache.RateLimitAlgorithmR\talgorithm\"\x97\x01\n" +
+	"\x11RateLimitResponse\x12\x18\n" +
+	"\aallowed\x18\x01 \x01(\bR\aallowed\x12\x1c\n" +
+	"\tremaining\x18\x02 \x01(\x05R\tremaining\x12$\n" +
+	"\x0eretry_after_ms\x18\x03 \x01(\x03R\fretryAfterMs\x12$\n" +
+	"\x0ereset_after_ms\x18\x04 \x01(\x03R\fresetAfterMs\"J\n" +
+	"\x0fGetStatsRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x19\n" +
+	"\btop_keys\x18\x02 \x01(\x05R\atopKeys\"B\n" +
+	"\x0fValueSizeBucket\x12\x19\n" +
+	"\ble_bytes\x18\x01 \x01(\x03R\aleBytes\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\"\xdc\x02\n" +
+	"\x0eNamespaceStats\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x12\n" +
+	"\x04hits\x18\x02 \x01(\x03R\x04hits\x12\x16\n" +
+	"\x06misses\x18\x03 \x01(\x03R\x06misses\x12\x1b\n" +
+	"\thit_ratio\x18\x04 \x01(\x01R\bhitRatio\x12\x16\n" +
+	"\x06writes\x18\x05 \x01(\x03R\x06writes\x12\x1c\n" +
+	"\tevictions\x18\x06 \x01(\x03R\tevictions\x12 \n" +
+	"\vexpirations\x18\a \x01(\x03R\vexpirations\x12*\n" +
+	"\x11value_bytes_total\x18\b \x01(\x03R\x0fvalueBytesTotal\x12&\n" +
+	"\x0fmax_value_bytes\x18\t \x01(\x03R\rmaxValueBytes\x127\n" +
+	"\vvalue_sizes\x18\n" +
+	" \x03(\v2\x16.cache.ValueSizeBucketR\n" +
+	"valueSizes\"\x84\x01\n" +
+	"\x06HotKey\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12-\n" +
+	"\x12estimated_accesses\x18\x03 \x01(\x03R\x11estimatedAccesses\x12\x1b\n" +
+	"\tmax_error\x18\x04 \x01(\x03R\bmaxError\"\xdb\x02\n" +
+	"\x10GetStatsResponse\x125\n" +
+	"\n" +
+	"namespaces\x18\x01 \x03(\v2\x15.cache.NamespaceStatsR\n" +
+	"namespaces\x12(\n" +
+	"\bhot_keys\x18\x02 \x03(\v2\r.cache.HotKeyR\ahotKeys\x12\x1f\n" +
+	"\vsample_rate\x18\x03 \x01(\x05R\n" +
+	"sampleRate\x12\x14\n" +
+	"\x05since\x18\x04 \x01(\tR\x05since\x12+\n" +
+	"\x11evictions_tracked\x18\x05 \x01(\bR\x10evictionsTracked\x12\x1d\n" +
+	"\n" +
+	"redis_keys\x18\x06 \x01(\x03R\tredisKeys\x125\n" +
+	"\x17redis_used_memory_bytes\x18\a \x01(\x03R\x14redisUsedMemoryBytes\x12,\n" +
+	"\x12redis_evicted_keys\x18\b \x01(\x03R\x10redisEvictedKeys\"\x14\n" +
 	"\x12HealthCheckRequest\"\x85\x01\n" +
 	"\x13HealthCheckResponse\x129\n" +
 	"\x06status\x18\x01 \x01(\x0e2!.cache.HealthCheckResponse.StatusR\x06status\"3\n" +
 	"\x06Status\x12\v\n" +
 	"\aUNKNOWN\x10\x00\x12\v\n" +
 	"\aSERVING\x10\x01\x12\x0f\n" +
-	"\vNOT_SERVING\x10\x022\xc3\x04\n" +
+	"\vNOT_SERVING\x10\x02*:\n" +
+	"\x12RateLimitAlgorithm\x12\x10\n" +
+	"\fTOKEN_BUCKET\x10\x00\x12\x12\n" +
+	"\x0eSLIDING_WINDOW\x10\x012\x9c\t\n" +
 	"\fCacheService\x12,\n" +
 	"\x03Set\x12\x11.cache.SetRequest\x1a\x12.cache.SetResponse\x12,\n" +
 	"\x03Get\x12\x11.cache.GetRequest\x1a\x12.cache.GetResponse\x125\n" +
@@ -1151,7 +2817,17 @@ const file_cache_cache_proto_rawDesc = "" +
 	"\tAddToList\x12\x17.cache.AddToListRequest\x1a\x18.cache.AddToListResponse\x128\n" +
 	"\aGetList\x12\x15.cache.GetListRequest\x1a\x16.cache.GetListResponse\x12/\n" +
 	"\x04HSet\x12\x12.cache.HSetRequest\x1a\x13.cache.HSetResponse\x12/\n" +
-	"\x04HGet\x12\x12.cache.HGetRequest\x1a\x13.cache.HGetResponse\x12D\n" +
+	"\x04HGet\x12\x12.cache.HGetRequest\x1a\x13.cache.HGetResponse\x12/\n" +
+	"\x04MGet\x12\x12.cache.MGetRequest\x1a\x13.cache.MGetResponse\x12/\n" +
+	"\x04MSet\x12\x12.cache.MSetRequest\x1a\x13.cache.MSetResponse\x128\n" +
+	"\aMDelete\x12\x15.cache.MDeleteRequest\x1a\x16.cache.MDeleteResponse\x12P\n" +
+	"\x0fDeleteByPattern\x12\x1d.cache.DeleteByPatternRequest\x1a\x1e.cache.DeleteByPatternResponse\x12D\n" +
+	"\vDeleteByTag\x12\x19.cache.DeleteByTagRequest\x1a\x1a.cache.DeleteByTagResponse\x128\n" +
+	"\aTryLock\x12\x15.cache.TryLockRequest\x1a\x16.cache.TryLockResponse\x125\n" +
+	"\x06Unlock\x12\x14.cache.UnlockRequest\x1a\x15.cache.UnlockResponse\x125\n" +
+	"\x06Extend\x12\x14.cache.ExtendRequest\x1a\x15.cache.ExtendResponse\x12>\n" +
+	"\tRateLimit\x12\x17.cache.RateLimitRequest\x1a\x18.cache.RateLimitResponse\x12;\n" +
+	"\bGetStats\x12\x16.cache.GetStatsRequest\x1a\x17.cache.GetStatsResponse\x12D\n" +
 	"\vHealthCheck\x12\x19.cache.HealthCheckRequest\x1a\x1a.cache.HealthCheckResponseB-Z+github.com/sdshorin/generia/api/proto/cacheb\x06proto3"
 
 var (
@@ -1166,62 +2842,115 @@ func file_cache_cache_proto_rawDescGZIP() []byte {
 	return file_cache_cache_proto_rawDescData
 }
 
-var file_cache_cache_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_cache_cache_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_cache_cache_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_cache_cache_proto_msgTypes = make([]protoimpl.MessageInfo, 47)
 var file_cache_cache_proto_goTypes = []any{
-	(HealthCheckResponse_Status)(0), // 0: cache.HealthCheckResponse.Status
-	(*SetRequest)(nil),              // 1: cache.SetRequest
-	(*SetResponse)(nil),             // 2: cache.SetResponse
-	(*GetRequest)(nil),              // 3: cache.GetRequest
-	(*GetResponse)(nil),             // 4: cache.GetResponse
-	(*DeleteRequest)(nil),           // 5: cache.DeleteRequest
-	(*DeleteResponse)(nil),          // 6: cache.DeleteResponse
-	(*IncrementRequest)(nil),        // 7: cache.IncrementRequest
-	(*IncrementResponse)(nil),       // 8: cache.IncrementResponse
-	(*DecrementRequest)(nil),        // 9: cache.DecrementRequest
-	(*DecrementResponse)(nil),       // 10: cache.DecrementResponse
-	(*AddToListRequest)(nil),        // 11: cache.AddToListRequest
-	(*AddToListResponse)(nil),       // 12: cache.AddToListResponse
-	(*GetListRequest)(nil),          // 13: cache.GetListRequest
-	(*GetListResponse)(nil),         // 14: cache.GetListResponse
-	(*HSetRequest)(nil),             // 15: cache.HSetRequest
-	(*HSetResponse)(nil),            // 16: cache.HSetResponse
-	(*HGetRequest)(nil),             // 17: cache.HGetRequest
-	(*HGetResponse)(nil),            // 18: cache.HGetResponse
-	(*HealthCheckRequest)(nil),      // 19: cache.HealthCheckRequest
-	(*HealthCheckResponse)(nil),     // 20: cache.HealthCheckResponse
-	nil,                             // 21: cache.HSetRequest.FieldsEntry
-	nil,                             // 22: cache.HGetResponse.FieldsEntry
+	(RateLimitAlgorithm)(0),         // 0: cache.RateLimitAlgorithm
+	(HealthCheckResponse_Status)(0), // 1: cache.HealthCheckResponse.Status
+	(*SetRequest)(nil),              // 2: cache.SetRequest
+	(*SetResponse)(nil),             // 3: cache.SetResponse
+	(*GetRequest)(nil),              // 4: cache.GetRequest
+	(*GetResponse)(nil),             // 5: cache.GetResponse
+	(*DeleteRequest)(nil),           // 6: cache.DeleteRequest
+	(*DeleteResponse)(nil),          // 7: cache.DeleteResponse
+	(*IncrementRequest)(nil),        // 8: cache.IncrementRequest
+	(*IncrementResponse)(nil),       // 9: cache.IncrementResponse
+	(*DecrementRequest)(nil),        // 10: cache.DecrementRequest
+	(*DecrementResponse)(nil),       // 11: cache.DecrementResponse
+	(*AddToListRequest)(nil),        // 12: cache.AddToListRequest
+	(*AddToListResponse)(nil),       // 13: cache.AddToListResponse
+	(*GetListRequest)(nil),          // 14: cache.GetListRequest
+	(*GetListResponse)(nil),         // 15: cache.GetListResponse
+	(*HSetRequest)(nil),             // 16: cache.HSetRequest
+	(*HSetResponse)(nil),            // 17: cache.HSetResponse
+	(*HGetRequest)(nil),             // 18: cache.HGetRequest
+	(*HGetResponse)(nil),            // 19: cache.HGetResponse
+	(*MGetRequest)(nil),             // 20: cache.MGetRequest
+	(*MGetResult)(nil),              // 21: cache.MGetResult
+	(*MGetResponse)(nil),            // 22: cache.MGetResponse
+	(*MSetRequest)(nil),             // 23: cache.MSetRequest
+	(*MSetResponse)(nil),            // 24: cache.MSetResponse
+	(*MDeleteRequest)(nil),          // 25: cache.MDeleteRequest
+	(*MDeleteResult)(nil),           // 26: cache.MDeleteResult
+	(*MDeleteResponse)(nil),         // 27: cache.MDeleteResponse
+	(*DeleteByPatternRequest)(nil),  // 28: cache.DeleteByPatternRequest
+	(*DeleteByPatternResponse)(nil), // 29: cache.DeleteByPatternResponse
+	(*DeleteByTagRequest)(nil),      // 30: cache.DeleteByTagRequest
+	(*DeleteByTagResponse)(nil),     // 31: cache.DeleteByTagResponse
+	(*TryLockRequest)(nil),          // 32: cache.TryLockRequest
+	(*TryLockResponse)(nil),         // 33: cache.TryLockResponse
+	(*UnlockRequest)(nil),           // 34: cache.UnlockRequest
+	(*UnlockResponse)(nil),          // 35: cache.UnlockResponse
+	(*ExtendRequest)(nil),           // 36: cache.ExtendRequest
+	(*ExtendResponse)(nil),          // 37: cache.ExtendResponse
+	(*RateLimitRequest)(nil),        // 38: cache.RateLimitRequest
+	(*RateLimitResponse)(nil),       // 39: cache.RateLimitResponse
+	(*GetStatsRequest)(nil),         // 40: cache.GetStatsRequest
+	(*ValueSizeBucket)(nil),         // 41: cache.ValueSizeBucket
+	(*NamespaceStats)(nil),          // 42: cache.NamespaceStats
+	(*HotKey)(nil),                  // 43: cache.HotKey
+	(*GetStatsResponse)(nil),        // 44: cache.GetStatsResponse
+	(*HealthCheckRequest)(nil),      // 45: cache.HealthCheckRequest
+	(*HealthCheckResponse)(nil),     // 46: cache.HealthCheckResponse
+	nil,                             // 47: cache.HSetRequest.FieldsEntry
+	nil,                             // 48: cache.HGetResponse.FieldsEntry
 }
 var file_cache_cache_proto_depIdxs = []int32{
-	21, // 0: cache.HSetRequest.fields:type_name -> cache.HSetRequest.FieldsEntry
-	22, // 1: cache.HGetResponse.fields:type_name -> cache.HGetResponse.FieldsEntry
-	0,  // 2: cache.HealthCheckResponse.status:type_name -> cache.HealthCheckResponse.Status
-	1,  // 3: cache.CacheService.Set:input_type -> cache.SetRequest
-	3,  // 4: cache.CacheService.Get:input_type -> cache.GetRequest
-	5,  // 5: cache.CacheService.Delete:input_type -> cache.DeleteRequest
-	7,  // 6: cache.CacheService.Increment:input_type -> cache.IncrementRequest
-	9,  // 7: cache.CacheService.Decrement:input_type -> cache.DecrementRequest
-	11, // 8: cache.CacheService.AddToList:input_type -> cache.AddToListRequest
-	13, // 9: cache.CacheService.GetList:input_type -> cache.GetListRequest
-	15, // 10: cache.CacheService.HSet:input_type -> cache.HSetRequest
-	17, // 11: cache.CacheService.HGet:input_type -> cache.HGetRequest
-	19, // 12: cache.CacheService.HealthCheck:input_type -> cache.HealthCheckRequest
-	2,  // 13: cache.CacheService.Set:output_type -> cache.SetResponse
-	4,  // 14: cache.CacheService.Get:output_type -> cache.GetResponse
-	6,  // 15: cache.CacheService.Delete:output_type -> cache.DeleteResponse
-	8,  // 16: cache.CacheService.Increment:output_type -> cache.IncrementResponse
-	10, // 17: cache.CacheService.Decrement:output_type -> cache.DecrementResponse
-	12, // 18: cache.CacheService.AddToList:output_type -> cache.AddToListResponse
-	14, // 19: cache.CacheService.GetList:output_type -> cache.GetListResponse
-	16, // 20: cache.CacheService.HSet:output_type -> cache.HSetResponse
-	18, // 21: cache.CacheService.HGet:output_type -> cache.HGetResponse
-	20, // 22: cache.CacheService.HealthCheck:output_type -> cache.HealthCheckResponse
-	13, // [13:23] is the sub-list for method output_type
-	3,  // [3:13] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	47, // 0: cache.HSetRequest.fields:type_name -> cache.HSetRequest.FieldsEntry
+	48, // 1: cache.HGetResponse.fields:type_name -> cache.HGetResponse.FieldsEntry
+	21, // 2: cache.MGetResponse.results:type_name -> cache.MGetResult
+	2,  // 3: cache.MSetRequest.items:type_name -> cache.SetRequest
+	26, // 4: cache.MDeleteResponse.results:type_name -> cache.MDeleteResult
+	0,  // 5: cache.RateLimitRequest.algorithm:type_name -> cache.RateLimitAlgorithm
+	41, // 6: cache.NamespaceStats.value_sizes:type_name -> cache.ValueSizeBucket
+	42, // 7: cache.GetStatsResponse.namespaces:type_name -> cache.NamespaceStats
+	43, // 8: cache.GetStatsResponse.hot_keys:type_name -> cache.HotKey
+	1,  // 9: cache.HealthCheckResponse.status:type_name -> cache.HealthCheckResponse.Status
+	2,  // 10: cache.CacheService.Set:input_type -> cache.SetRequest
+	4,  // 11: cache.CacheService.Get:input_type -> cache.GetRequest
+	6,  // 12: cache.CacheService.Delete:input_type -> cache.DeleteRequest
+	8,  // 13: cache.CacheService.Increment:input_type -> cache.IncrementRequest
+	10, // 14: cache.CacheService.Decrement:input_type -> cache.DecrementRequest
+	12, // 15: cache.CacheService.AddToList:input_type -> cache.AddToListRequest
+	14, // 16: cache.CacheService.GetList:input_type -> cache.GetListRequest
+	16, // 17: cache.CacheService.HSet:input_type -> cache.HSetRequest
+	18, // 18: cache.CacheService.HGet:input_type -> cache.HGetRequest
+	20, // 19: cache.CacheService.MGet:input_type -> cache.MGetRequest
+	23, // 20: cache.CacheService.MSet:input_type -> cache.MSetRequest
+	25, // 21: cache.CacheService.MDelete:input_type -> cache.MDeleteRequest
+	28, // 22: cache.CacheService.DeleteByPattern:input_type -> cache.DeleteByPatternRequest
+	30, // 23: cache.CacheService.DeleteByTag:input_type -> cache.DeleteByTagRequest
+	32, // 24: cache.CacheService.TryLock:input_type -> cache.TryLockRequest
+	34, // 25: cache.CacheService.Unlock:input_type -> cache.UnlockRequest
+	36, // 26: cache.CacheService.Extend:input_type -> cache.ExtendRequest
+	38, // 27: cache.CacheService.RateLimit:input_type -> cache.RateLimitRequest
+	40, // 28: cache.CacheService.GetStats:input_type -> cache.GetStatsRequest
+	45, // 29: cache.CacheService.HealthCheck:input_type -> cache.HealthCheckRequest
+	3,  // 30: cache.CacheService.Set:output_type -> cache.SetResponse
+	5,  // 31: cache.CacheService.Get:output_type -> cache.GetResponse
+	7,  // 32: cache.CacheService.Delete:output_type -> cache.DeleteResponse
+	9,  // 33: cache.CacheService.Increment:output_type -> cache.IncrementResponse
+	11, // 34: cache.CacheService.Decrement:output_type -> cache.DecrementResponse
+	13, // 35: cache.CacheService.AddToList:output_type -> cache.AddToListResponse
+	15, // 36: cache.CacheService.GetList:output_type -> cache.GetListResponse
+	17, // 37: cache.CacheService.HSet:output_type -> cache.HSetResponse
+	19, // 38: cache.CacheService.HGet:output_type -> cache.HGetResponse
+	22, // 39: cache.CacheService.MGet:output_type -> cache.MGetResponse
+	24, // 40: cache.CacheService.MSet:output_type -> cache.MSetResponse
+	27, // 41: cache.CacheService.MDelete:output_type -> cache.MDeleteResponse
+	29, // 42: cache.CacheService.DeleteByPattern:output_type -> cache.DeleteByPatternResponse
+	31, // 43: cache.CacheService.DeleteByTag:output_type -> cache.DeleteByTagResponse
+	33, // 44: cache.CacheService.TryLock:output_type -> cache.TryLockResponse
+	35, // 45: cache.CacheService.Unlock:output_type -> cache.UnlockResponse
+	37, // 46: cache.CacheService.Extend:output_type -> cache.ExtendResponse
+	39, // 47: cache.CacheService.RateLimit:output_type -> cache.RateLimitResponse
+	44, // 48: cache.CacheService.GetStats:output_type -> cache.GetStatsResponse
+	46, // 49: cache.CacheService.HealthCheck:output_type -> cache.HealthCheckResponse
+	30, // [30:50] is the sub-list for method output_type
+	10, // [10:30] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_cache_cache_proto_init() }
@@ -1234,8 +2963,8 @@ func file_cache_cache_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cache_cache_proto_rawDesc), len(file_cache_cache_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   22,
+			NumEnums:      2,
+			NumMessages:   47,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
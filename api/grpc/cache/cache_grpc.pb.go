@@ -19,16 +19,26 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CacheService_Set_FullMethodName         = "/cache.CacheService/Set"
-	CacheService_Get_FullMethodName         = "/cache.CacheService/Get"
-	CacheService_Delete_FullMethodName      = "/cache.CacheService/Delete"
-	CacheService_Increment_FullMethodName   = "/cache.CacheService/Increment"
-	CacheService_Decrement_FullMethodName   = "/cache.CacheService/Decrement"
-	CacheService_AddToList_FullMethodName   = "/cache.CacheService/AddToList"
-	CacheService_GetList_FullMethodName     = "/cache.CacheService/GetList"
-	CacheService_HSet_FullMethodName        = "/cache.CacheService/HSet"
-	CacheService_HGet_FullMethodName        = "/cache.CacheService/HGet"
-	CacheService_HealthCheck_FullMethodName = "/cache.CacheService/HealthCheck"
+	CacheService_Set_FullMethodName             = "/cache.CacheService/Set"
+	CacheService_Get_FullMethodName             = "/cache.CacheService/Get"
+	CacheService_Delete_FullMethodName          = "/cache.CacheService/Delete"
+	CacheService_Increment_FullMethodName       = "/cache.CacheService/Increment"
+	CacheService_Decrement_FullMethodName       = "/cache.CacheService/Decrement"
+	CacheService_AddToList_FullMethodName       = "/cache.CacheService/AddToList"
+	CacheService_GetList_FullMethodName         = "/cache.CacheService/GetList"
+	CacheService_HSet_FullMethodName            = "/cache.CacheService/HSet"
+	CacheService_HGet_FullMethodName            = "/cache.CacheService/HGet"
+	CacheService_MGet_FullMethodName            = "/cache.CacheService/MGet"
+	CacheService_MSet_FullMethodName            = "/cache.CacheService/MSet"
+	CacheService_MDelete_FullMethodName         = "/cache.CacheService/MDelete"
+	CacheService_DeleteByPattern_FullMethodName = "/cache.CacheService/DeleteByPattern"
+	CacheService_DeleteByTag_FullMethodName     = "/cache.CacheService/DeleteByTag"
+	CacheService_TryLock_FullMethodName         = "/cache.CacheService/TryLock"
+	CacheService_Unlock_FullMethodName          = "/cache.CacheService/Unlock"
+	CacheService_Extend_FullMethodName          = "/cache.CacheService/Extend"
+	CacheService_RateLimit_FullMethodName       = "/cache.CacheService/RateLimit"
+	CacheService_GetStats_FullMethodName        = "/cache.CacheService/GetStats"
+	CacheService_HealthCheck_FullMethodName     = "/cache.CacheService/HealthCheck"
 )
 
 // CacheServiceClient is the client API for CacheService service.
@@ -55,6 +65,26 @@ type CacheServiceClient interface {
 	HSet(ctx context.Context, in *HSetRequest, opts ...grpc.CallOption) (*HSetResponse, error)
 	// Получение хеша из кеша
 	HGet(ctx context.Context, in *HGetRequest, opts ...grpc.CallOption) (*HGetResponse, error)
+	// Получение нескольких значений за один запрос к Redis
+	MGet(ctx context.Context, in *MGetRequest, opts ...grpc.CallOption) (*MGetResponse, error)
+	// Установка нескольких значений (pipeline)
+	MSet(ctx context.Context, in *MSetRequest, opts ...grpc.CallOption) (*MSetResponse, error)
+	// Удаление нескольких значений (pipeline)
+	MDelete(ctx context.Context, in *MDeleteRequest, opts ...grpc.CallOption) (*MDeleteResponse, error)
+	// Удаление всех ключей, соответствующих паттерну
+	DeleteByPattern(ctx context.Context, in *DeleteByPatternRequest, opts ...grpc.CallOption) (*DeleteByPatternResponse, error)
+	// Удаление всех ключей, сохраненных с указанными тегами
+	DeleteByTag(ctx context.Context, in *DeleteByTagRequest, opts ...grpc.CallOption) (*DeleteByTagResponse, error)
+	// Захват распределенной блокировки без ожидания
+	TryLock(ctx context.Context, in *TryLockRequest, opts ...grpc.CallOption) (*TryLockResponse, error)
+	// Освобождение блокировки владельцем
+	Unlock(ctx context.Context, in *UnlockRequest, opts ...grpc.CallOption) (*UnlockResponse, error)
+	// Продление блокировки владельцем
+	Extend(ctx context.Context, in *ExtendRequest, opts ...grpc.CallOption) (*ExtendResponse, error)
+	// Проверка и учет запроса в лимите частоты
+	RateLimit(ctx context.Context, in *RateLimitRequest, opts ...grpc.CallOption) (*RateLimitResponse, error)
+	// Статистика попаданий, вытеснений и горячих ключей по пространствам имен
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
 	// Проверка здоровья сервиса
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
 }
@@ -157,6 +187,106 @@ func (c *cacheServiceClient) HGet(ctx context.Context, in *HGetRequest, opts ...
 	return out, nil
 }
 
+func (c *cacheServiceClient) MGet(ctx context.Context, in *MGetRequest, opts ...grpc.CallOption) (*MGetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MGetResponse)
+	err := c.cc.Invoke(ctx, CacheService_MGet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) MSet(ctx context.Context, in *MSetRequest, opts ...grpc.CallOption) (*MSetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MSetResponse)
+	err := c.cc.Invoke(ctx, CacheService_MSet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) MDelete(ctx context.Context, in *MDeleteRequest, opts ...grpc.CallOption) (*MDeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MDeleteResponse)
+	err := c.cc.Invoke(ctx, CacheService_MDelete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) DeleteByPattern(ctx context.Context, in *DeleteByPatternRequest, opts ...grpc.CallOption) (*DeleteByPatternResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteByPatternResponse)
+	err := c.cc.Invoke(ctx, CacheService_DeleteByPattern_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) DeleteByTag(ctx context.Context, in *DeleteByTagRequest, opts ...grpc.CallOption) (*DeleteByTagResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteByTagResponse)
+	err := c.cc.Invoke(ctx, CacheService_DeleteByTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) TryLock(ctx context.Context, in *TryLockRequest, opts ...grpc.CallOption) (*TryLockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TryLockResponse)
+	err := c.cc.Invoke(ctx, CacheService_TryLock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) Unlock(ctx context.Context, in *UnlockRequest, opts ...grpc.CallOption) (*UnlockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnlockResponse)
+	err := c.cc.Invoke(ctx, CacheService_Unlock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) Extend(ctx context.Context, in *ExtendRequest, opts ...grpc.CallOption) (*ExtendResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExtendResponse)
+	err := c.cc.Invoke(ctx, CacheService_Extend_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) RateLimit(ctx context.Context, in *RateLimitRequest, opts ...grpc.CallOption) (*RateLimitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RateLimitResponse)
+	err := c.cc.Invoke(ctx, CacheService_RateLimit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, CacheService_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *cacheServiceClient) HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(HealthCheckResponse)
@@ -191,6 +321,26 @@ type CacheServiceServer interface {
 	HSet(context.Context, *HSetRequest) (*HSetResponse, error)
 	// Получение хеша из кеша
 	HGet(context.Context, *HGetRequest) (*HGetResponse, error)
+	// Получение нескольких значений за один запрос к Redis
+	MGet(context.Context, *MGetRequest) (*MGetResponse, error)
+	// Установка нескольких значений (pipeline)
+	MSet(context.Context, *MSetRequest) (*MSetResponse, error)
+	// Удаление нескольких значений (pipeline)
+	MDelete(context.Context, *MDeleteRequest) (*MDeleteResponse, error)
+	// Удаление всех ключей, соответствующих паттерну
+	DeleteByPattern(context.Context, *DeleteByPatternRequest) (*DeleteByPatternResponse, error)
+	// Удаление всех ключей, сохраненных с указанными тегами
+	DeleteByTag(context.Context, *DeleteByTagRequest) (*DeleteByTagResponse, error)
+	// Захват распределенной блокировки без ожидания
+	TryLock(context.Context, *TryLockRequest) (*TryLockResponse, error)
+	// Освобождение блокировки владельцем
+	Unlock(context.Context, *UnlockRequest) (*UnlockResponse, error)
+	// Продление блокировки владельцем
+	Extend(context.Context, *ExtendRequest) (*ExtendResponse, error)
+	// Проверка и учет запроса в лимите частоты
+	RateLimit(context.Context, *RateLimitRequest) (*RateLimitResponse, error)
+	// Статистика попаданий, вытеснений и горячих ключей по пространствам имен
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
 	// Проверка здоровья сервиса
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
 	mustEmbedUnimplementedCacheServiceServer()
@@ -230,6 +380,36 @@ func (UnimplementedCacheServiceServer) HSet(context.Context, *HSetRequest) (*HSe
 func (UnimplementedCacheServiceServer) HGet(context.Context, *HGetRequest) (*HGetResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HGet not implemented")
 }
+func (UnimplementedCacheServiceServer) MGet(context.Context, *MGetRequest) (*MGetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MGet not implemented")
+}
+func (UnimplementedCacheServiceServer) MSet(context.Context, *MSetRequest) (*MSetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MSet not implemented")
+}
+func (UnimplementedCacheServiceServer) MDelete(context.Context, *MDeleteRequest) (*MDeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MDelete not implemented")
+}
+func (UnimplementedCacheServiceServer) DeleteByPattern(context.Context, *DeleteByPatternRequest) (*DeleteByPatternResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteByPattern not implemented")
+}
+func (UnimplementedCacheServiceServer) DeleteByTag(context.Context, *DeleteByTagRequest) (*DeleteByTagResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteByTag not implemented")
+}
+func (UnimplementedCacheServiceServer) TryLock(context.Context, *TryLockRequest) (*TryLockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TryLock not implemented")
+}
+func (UnimplementedCacheServiceServer) Unlock(context.Context, *UnlockRequest) (*UnlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unlock not implemented")
+}
+func (UnimplementedCacheServiceServer) Extend(context.Context, *ExtendRequest) (*ExtendResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Extend not implemented")
+}
+func (UnimplementedCacheServiceServer) RateLimit(context.Context, *RateLimitRequest) (*RateLimitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RateLimit not implemented")
+}
+func (UnimplementedCacheServiceServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
 func (UnimplementedCacheServiceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
@@ -416,6 +596,186 @@ func _CacheService_HGet_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CacheService_MGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).MGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_MGet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).MGet(ctx, req.(*MGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_MSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).MSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_MSet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).MSet(ctx, req.(*MSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_MDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).MDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_MDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).MDelete(ctx, req.(*MDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_DeleteByPattern_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteByPatternRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).DeleteByPattern(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_DeleteByPattern_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).DeleteByPattern(ctx, req.(*DeleteByPatternRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_DeleteByTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteByTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).DeleteByTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_DeleteByTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).DeleteByTag(ctx, req.(*DeleteByTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_TryLock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TryLockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).TryLock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_TryLock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).TryLock(ctx, req.(*TryLockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_Unlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).Unlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_Unlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).Unlock(ctx, req.(*UnlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_Extend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExtendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).Extend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_Extend_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).Extend(ctx, req.(*ExtendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_RateLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RateLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).RateLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_RateLimit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).RateLimit(ctx, req.(*RateLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _CacheService_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthCheckRequest)
 	if err := dec(in); err != nil {
@@ -477,6 +837,46 @@ var CacheService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "HGet",
 			Handler:    _CacheService_HGet_Handler,
 		},
+		{
+			MethodName: "MGet",
+			Handler:    _CacheService_MGet_Handler,
+		},
+		{
+			MethodName: "MSet",
+			Handler:    _CacheService_MSet_Handler,
+		},
+		{
+			MethodName: "MDelete",
+			Handler:    _CacheService_MDelete_Handler,
+		},
+		{
+			MethodName: "DeleteByPattern",
+			Handler:    _CacheService_DeleteByPattern_Handler,
+		},
+		{
+			MethodName: "DeleteByTag",
+			Handler:    _CacheService_DeleteByTag_Handler,
+		},
+		{
+			MethodName: "TryLock",
+			Handler:    _CacheService_TryLock_Handler,
+		},
+		{
+			MethodName: "Unlock",
+			Handler:    _CacheService_Unlock_Handler,
+		},
+		{
+			MethodName: "Extend",
+			Handler:    _CacheService_Extend_Handler,
+		},
+		{
+			MethodName: "RateLimit",
+			Handler:    _CacheService_RateLimit_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _CacheService_GetStats_Handler,
+		},
 		{
 			MethodName: "HealthCheck",
 			Handler:    _CacheService_HealthCheck_Handler,
@@ -21,6 +21,61 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type InvalidationStatus int32
+
+const (
+	InvalidationStatus_INVALIDATION_STATUS_UNKNOWN InvalidationStatus = 0
+	InvalidationStatus_INVALIDATION_STATUS_QUEUED  InvalidationStatus = 1
+	InvalidationStatus_INVALIDATION_STATUS_RUNNING InvalidationStatus = 2
+	InvalidationStatus_INVALIDATION_STATUS_DONE    InvalidationStatus = 3
+	InvalidationStatus_INVALIDATION_STATUS_FAILED  InvalidationStatus = 4
+)
+
+// Enum value maps for InvalidationStatus.
+var (
+	InvalidationStatus_name = map[int32]string{
+		0: "INVALIDATION_STATUS_UNKNOWN",
+		1: "INVALIDATION_STATUS_QUEUED",
+		2: "INVALIDATION_STATUS_RUNNING",
+		3: "INVALIDATION_STATUS_DONE",
+		4: "INVALIDATION_STATUS_FAILED",
+	}
+	InvalidationStatus_value = map[string]int32{
+		"INVALIDATION_STATUS_UNKNOWN": 0,
+		"INVALIDATION_STATUS_QUEUED":  1,
+		"INVALIDATION_STATUS_RUNNING": 2,
+		"INVALIDATION_STATUS_DONE":    3,
+		"INVALIDATION_STATUS_FAILED":  4,
+	}
+)
+
+func (x InvalidationStatus) Enum() *InvalidationStatus {
+	p := new(InvalidationStatus)
+	*p = x
+	return p
+}
+
+func (x InvalidationStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (InvalidationStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_cdn_cdn_proto_enumTypes[0].Descriptor()
+}
+
+func (InvalidationStatus) Type() protoreflect.EnumType {
+	return &file_cdn_cdn_proto_enumTypes[0]
+}
+
+func (x InvalidationStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use InvalidationStatus.Descriptor instead.
+func (InvalidationStatus) EnumDescriptor() ([]byte, []int) {
+	return file_cdn_cdn_proto_rawDescGZIP(), []int{0}
+}
+
 type HealthCheckResponse_Status int32
 
 const (
@@ -54,11 +109,11 @@ func (x HealthCheckResponse_Status) String() string {
 }
 
 func (HealthCheckResponse_Status) Descriptor() protoreflect.EnumDescriptor {
-	return file_cdn_cdn_proto_enumTypes[0].Descriptor()
+	return file_cdn_cdn_proto_enumTypes[1].Descriptor()
 }
 
 func (HealthCheckResponse_Status) Type() protoreflect.EnumType {
-	return &file_cdn_cdn_proto_enumTypes[0]
+	return &file_cdn_cdn_proto_enumTypes[1]
 }
 
 func (x HealthCheckResponse_Status) Number() protoreflect.EnumNumber {
@@ -67,7 +122,7 @@ func (x HealthCheckResponse_Status) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use HealthCheckResponse_Status.Descriptor instead.
 func (HealthCheckResponse_Status) EnumDescriptor() ([]byte, []int) {
-	return file_cdn_cdn_proto_rawDescGZIP(), []int{7, 0}
+	return file_cdn_cdn_proto_rawDescGZIP(), []int{12, 0}
 }
 
 type GetSignedURLRequest struct {
@@ -126,6 +181,7 @@ type GetSignedURLResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
 	ExpiresAt     int64                  `protobuf:"varint,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // Unix timestamp
+	KeyId         string                 `protobuf:"bytes,3,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`              // ID ключа, которым подписан URL
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -174,9 +230,19 @@ func (x *GetSignedURLResponse) GetExpiresAt() int64 {
 	return 0
 }
 
+func (x *GetSignedURLResponse) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
 type InvalidateCacheRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Paths         []string               `protobuf:"bytes,1,rep,name=paths,proto3" json:"paths,omitempty"` // Пути к файлам для инвалидации
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Пути к файлам для инвалидации: "*" соответствует любой последовательности символов (включая "/"),
+	// путь с "/" на конце - всем файлам в каталоге
+	Paths         []string `protobuf:"bytes,1,rep,name=paths,proto3" json:"paths,omitempty"`
+	WorldId       string   `protobuf:"bytes,2,opt,name=world_id,json=worldId,proto3" json:"world_id,omitempty"` // Если указан, пути задаются относительно файлов мира ("<world_id>/...")
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -218,10 +284,18 @@ func (x *InvalidateCacheRequest) GetPaths() []string {
 	return nil
 }
 
+func (x *InvalidateCacheRequest) GetWorldId() string {
+	if x != nil {
+		return x.WorldId
+	}
+	return ""
+}
+
 type InvalidateCacheResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	OperationId   string                 `protobuf:"bytes,2,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"` // ID операции инвалидации
+	Status        InvalidationStatus     `protobuf:"varint,3,opt,name=status,proto3,enum=cdn.InvalidationStatus" json:"status,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -270,6 +344,165 @@ func (x *InvalidateCacheResponse) GetOperationId() string {
 	return ""
 }
 
+func (x *InvalidateCacheResponse) GetStatus() InvalidationStatus {
+	if x != nil {
+		return x.Status
+	}
+	return InvalidationStatus_INVALIDATION_STATUS_UNKNOWN
+}
+
+type GetInvalidationStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OperationId   string                 `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInvalidationStatusRequest) Reset() {
+	*x = GetInvalidationStatusRequest{}
+	mi := &file_cdn_cdn_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInvalidationStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInvalidationStatusRequest) ProtoMessage() {}
+
+func (x *GetInvalidationStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cdn_cdn_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInvalidationStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetInvalidationStatusRequest) Descriptor() ([]byte, []int) {
+	return file_cdn_cdn_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetInvalidationStatusRequest) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+type GetInvalidationStatusResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	OperationId    string                 `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	Status         InvalidationStatus     `protobuf:"varint,2,opt,name=status,proto3,enum=cdn.InvalidationStatus" json:"status,omitempty"`
+	Paths          []string               `protobuf:"bytes,3,rep,name=paths,proto3" json:"paths,omitempty"`
+	WorldId        string                 `protobuf:"bytes,4,opt,name=world_id,json=worldId,proto3" json:"world_id,omitempty"`
+	EvictedObjects int32                  `protobuf:"varint,5,opt,name=evicted_objects,json=evictedObjects,proto3" json:"evicted_objects,omitempty"` // Число файлов, удаленных из кеша экземпляром, выполнившим операцию
+	Error          string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`                                          // Причина ошибки для статуса FAILED
+	CreatedAt      int64                  `protobuf:"varint,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`                // Unix timestamp
+	StartedAt      int64                  `protobuf:"varint,8,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`                // Unix timestamp, 0 если операция еще в очереди
+	FinishedAt     int64                  `protobuf:"varint,9,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`             // Unix timestamp, 0 если операция не завершена
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetInvalidationStatusResponse) Reset() {
+	*x = GetInvalidationStatusResponse{}
+	mi := &file_cdn_cdn_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInvalidationStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInvalidationStatusResponse) ProtoMessage() {}
+
+func (x *GetInvalidationStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cdn_cdn_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInvalidationStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetInvalidationStatusResponse) Descriptor() ([]byte, []int) {
+	return file_cdn_cdn_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetInvalidationStatusResponse) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+func (x *GetInvalidationStatusResponse) GetStatus() InvalidationStatus {
+	if x != nil {
+		return x.Status
+	}
+	return InvalidationStatus_INVALIDATION_STATUS_UNKNOWN
+}
+
+func (x *GetInvalidationStatusResponse) GetPaths() []string {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
+func (x *GetInvalidationStatusResponse) GetWorldId() string {
+	if x != nil {
+		return x.WorldId
+	}
+	return ""
+}
+
+func (x *GetInvalidationStatusResponse) GetEvictedObjects() int32 {
+	if x != nil {
+		return x.EvictedObjects
+	}
+	return 0
+}
+
+func (x *GetInvalidationStatusResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *GetInvalidationStatusResponse) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *GetInvalidationStatusResponse) GetStartedAt() int64 {
+	if x != nil {
+		return x.StartedAt
+	}
+	return 0
+}
+
+func (x *GetInvalidationStatusResponse) GetFinishedAt() int64 {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return 0
+}
+
 type GetCDNConfigRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -278,7 +511,7 @@ type GetCDNConfigRequest struct {
 
 func (x *GetCDNConfigRequest) Reset() {
 	*x = GetCDNConfigRequest{}
-	mi := &file_cdn_cdn_proto_msgTypes[4]
+	mi := &file_cdn_cdn_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -290,7 +523,7 @@ func (x *GetCDNConfigRequest) String() string {
 func (*GetCDNConfigRequest) ProtoMessage() {}
 
 func (x *GetCDNConfigRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cdn_cdn_proto_msgTypes[4]
+	mi := &file_cdn_cdn_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -303,7 +536,7 @@ func (x *GetCDNConfigRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetCDNConfigRequest.ProtoReflect.Descriptor instead.
 func (*GetCDNConfigRequest) Descriptor() ([]byte, []int) {
-	return file_cdn_cdn_proto_rawDescGZIP(), []int{4}
+	return file_cdn_cdn_proto_rawDescGZIP(), []int{6}
 }
 
 type GetCDNConfigResponse struct {
@@ -318,7 +551,7 @@ type GetCDNConfigResponse struct {
 
 func (x *GetCDNConfigResponse) Reset() {
 	*x = GetCDNConfigResponse{}
-	mi := &file_cdn_cdn_proto_msgTypes[5]
+	mi := &file_cdn_cdn_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -330,7 +563,7 @@ func (x *GetCDNConfigResponse) String() string {
 func (*GetCDNConfigResponse) ProtoMessage() {}
 
 func (x *GetCDNConfigResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cdn_cdn_proto_msgTypes[5]
+	mi := &file_cdn_cdn_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -343,7 +576,7 @@ func (x *GetCDNConfigResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetCDNConfigResponse.ProtoReflect.Descriptor instead.
 func (*GetCDNConfigResponse) Descriptor() ([]byte, []int) {
-	return file_cdn_cdn_proto_rawDescGZIP(), []int{5}
+	return file_cdn_cdn_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *GetCDNConfigResponse) GetCdnDomain() string {
@@ -374,6 +607,154 @@ func (x *GetCDNConfigResponse) GetAllowedHttpMethods() []string {
 	return nil
 }
 
+type ListSigningKeysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSigningKeysRequest) Reset() {
+	*x = ListSigningKeysRequest{}
+	mi := &file_cdn_cdn_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSigningKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSigningKeysRequest) ProtoMessage() {}
+
+func (x *ListSigningKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cdn_cdn_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSigningKeysRequest.ProtoReflect.Descriptor instead.
+func (*ListSigningKeysRequest) Descriptor() ([]byte, []int) {
+	return file_cdn_cdn_proto_rawDescGZIP(), []int{8}
+}
+
+type SigningKey struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	KeyId         string                 `protobuf:"bytes,1,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	Primary       bool                   `protobuf:"varint,2,opt,name=primary,proto3" json:"primary,omitempty"`                         // Ключ, которым подписываются новые URL
+	RetiredAt     int64                  `protobuf:"varint,3,opt,name=retired_at,json=retiredAt,proto3" json:"retired_at,omitempty"`    // Unix timestamp вывода ключа из использования, 0 для активных ключей
+	ValidUntil    int64                  `protobuf:"varint,4,opt,name=valid_until,json=validUntil,proto3" json:"valid_until,omitempty"` // Unix timestamp окончания периода отсрочки, 0 для активных ключей
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SigningKey) Reset() {
+	*x = SigningKey{}
+	mi := &file_cdn_cdn_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SigningKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SigningKey) ProtoMessage() {}
+
+func (x *SigningKey) ProtoReflect() protoreflect.Message {
+	mi := &file_cdn_cdn_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SigningKey.ProtoReflect.Descriptor instead.
+func (*SigningKey) Descriptor() ([]byte, []int) {
+	return file_cdn_cdn_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SigningKey) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *SigningKey) GetPrimary() bool {
+	if x != nil {
+		return x.Primary
+	}
+	return false
+}
+
+func (x *SigningKey) GetRetiredAt() int64 {
+	if x != nil {
+		return x.RetiredAt
+	}
+	return 0
+}
+
+func (x *SigningKey) GetValidUntil() int64 {
+	if x != nil {
+		return x.ValidUntil
+	}
+	return 0
+}
+
+type ListSigningKeysResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []*SigningKey          `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSigningKeysResponse) Reset() {
+	*x = ListSigningKeysResponse{}
+	mi := &file_cdn_cdn_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSigningKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSigningKeysResponse) ProtoMessage() {}
+
+func (x *ListSigningKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cdn_cdn_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSigningKeysResponse.ProtoReflect.Descriptor instead.
+func (*ListSigningKeysResponse) Descriptor() ([]byte, []int) {
+	return file_cdn_cdn_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListSigningKeysResponse) GetKeys() []*SigningKey {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
 type HealthCheckRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -382,7 +763,7 @@ type HealthCheckRequest struct {
 
 func (x *HealthCheckRequest) Reset() {
 	*x = HealthCheckRequest{}
-	mi := &file_cdn_cdn_proto_msgTypes[6]
+	mi := &file_cdn_cdn_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -394,7 +775,7 @@ func (x *HealthCheckRequest) String() string {
 func (*HealthCheckRequest) ProtoMessage() {}
 
 func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cdn_cdn_proto_msgTypes[6]
+	mi := &file_cdn_cdn_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -407,7 +788,7 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
 func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_cdn_cdn_proto_rawDescGZIP(), []int{6}
+	return file_cdn_cdn_proto_rawDescGZIP(), []int{11}
 }
 
 type HealthCheckResponse struct {
@@ -419,7 +800,7 @@ type HealthCheckResponse struct {
 
 func (x *HealthCheckResponse) Reset() {
 	*x = HealthCheckResponse{}
-	mi := &file_cdn_cdn_proto_msgTypes[7]
+	mi := &file_cdn_cdn_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -431,7 +812,7 @@ func (x *HealthCheckResponse) String() string {
 func (*HealthCheckResponse) ProtoMessage() {}
 
 func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cdn_cdn_proto_msgTypes[7]
+	mi := &file_cdn_cdn_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -444,7 +825,7 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
 func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_cdn_cdn_proto_rawDescGZIP(), []int{7}
+	return file_cdn_cdn_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *HealthCheckResponse) GetStatus() HealthCheckResponse_Status {
@@ -462,16 +843,34 @@ const file_cdn_cdn_proto_rawDesc = "" +
 	"\x13GetSignedURLRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1d\n" +
 	"\n" +
-	"expires_in\x18\x02 \x01(\x05R\texpiresIn\"G\n" +
+	"expires_in\x18\x02 \x01(\x05R\texpiresIn\"^\n" +
 	"\x14GetSignedURLResponse\x12\x10\n" +
 	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1d\n" +
 	"\n" +
-	"expires_at\x18\x02 \x01(\x03R\texpiresAt\".\n" +
+	"expires_at\x18\x02 \x01(\x03R\texpiresAt\x12\x15\n" +
+	"\x06key_id\x18\x03 \x01(\tR\x05keyId\"I\n" +
 	"\x16InvalidateCacheRequest\x12\x14\n" +
-	"\x05paths\x18\x01 \x03(\tR\x05paths\"V\n" +
+	"\x05paths\x18\x01 \x03(\tR\x05paths\x12\x19\n" +
+	"\bworld_id\x18\x02 \x01(\tR\aworldId\"\x87\x01\n" +
 	"\x17InvalidateCacheResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12!\n" +
-	"\foperation_id\x18\x02 \x01(\tR\voperationId\"\x15\n" +
+	"\foperation_id\x18\x02 \x01(\tR\voperationId\x12/\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x17.cdn.InvalidationStatusR\x06status\"A\n" +
+	"\x1cGetInvalidationStatusRequest\x12!\n" +
+	"\foperation_id\x18\x01 \x01(\tR\voperationId\"\xc2\x02\n" +
+	"\x1dGetInvalidationStatusResponse\x12!\n" +
+	"\foperation_id\x18\x01 \x01(\tR\voperationId\x12/\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x17.cdn.InvalidationStatusR\x06status\x12\x14\n" +
+	"\x05paths\x18\x03 \x03(\tR\x05paths\x12\x19\n" +
+	"\bworld_id\x18\x04 \x01(\tR\aworldId\x12'\n" +
+	"\x0fevicted_objects\x18\x05 \x01(\x05R\x0eevictedObjects\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\b \x01(\x03R\tstartedAt\x12\x1f\n" +
+	"\vfinished_at\x18\t \x01(\x03R\n" +
+	"finishedAt\"\x15\n" +
 	"\x13GetCDNConfigRequest\"\xb1\x01\n" +
 	"\x14GetCDNConfigResponse\x12\x1d\n" +
 	"\n" +
@@ -479,19 +878,38 @@ const file_cdn_cdn_proto_rawDesc = "" +
 	"\vdefault_ttl\x18\x02 \x01(\x05R\n" +
 	"defaultTtl\x12'\n" +
 	"\x0fallowed_origins\x18\x03 \x03(\tR\x0eallowedOrigins\x120\n" +
-	"\x14allowed_http_methods\x18\x04 \x03(\tR\x12allowedHttpMethods\"\x14\n" +
+	"\x14allowed_http_methods\x18\x04 \x03(\tR\x12allowedHttpMethods\"\x18\n" +
+	"\x16ListSigningKeysRequest\"}\n" +
+	"\n" +
+	"SigningKey\x12\x15\n" +
+	"\x06key_id\x18\x01 \x01(\tR\x05keyId\x12\x18\n" +
+	"\aprimary\x18\x02 \x01(\bR\aprimary\x12\x1d\n" +
+	"\n" +
+	"retired_at\x18\x03 \x01(\x03R\tretiredAt\x12\x1f\n" +
+	"\vvalid_until\x18\x04 \x01(\x03R\n" +
+	"validUntil\">\n" +
+	"\x17ListSigningKeysResponse\x12#\n" +
+	"\x04keys\x18\x01 \x03(\v2\x0f.cdn.SigningKeyR\x04keys\"\x14\n" +
 	"\x12HealthCheckRequest\"\x83\x01\n" +
 	"\x13HealthCheckResponse\x127\n" +
 	"\x06status\x18\x01 \x01(\x0e2\x1f.cdn.HealthCheckResponse.StatusR\x06status\"3\n" +
 	"\x06Status\x12\v\n" +
 	"\aUNKNOWN\x10\x00\x12\v\n" +
 	"\aSERVING\x10\x01\x12\x0f\n" +
-	"\vNOT_SERVING\x10\x022\xa6\x02\n" +
+	"\vNOT_SERVING\x10\x02*\xb4\x01\n" +
+	"\x12InvalidationStatus\x12\x1f\n" +
+	"\x1bINVALIDATION_STATUS_UNKNOWN\x10\x00\x12\x1e\n" +
+	"\x1aINVALIDATION_STATUS_QUEUED\x10\x01\x12\x1f\n" +
+	"\x1bINVALIDATION_STATUS_RUNNING\x10\x02\x12\x1c\n" +
+	"\x18INVALIDATION_STATUS_DONE\x10\x03\x12\x1e\n" +
+	"\x1aINVALIDATION_STATUS_FAILED\x10\x042\xd4\x03\n" +
 	"\n" +
 	"CDNService\x12C\n" +
 	"\fGetSignedURL\x12\x18.cdn.GetSignedURLRequest\x1a\x19.cdn.GetSignedURLResponse\x12L\n" +
-	"\x0fInvalidateCache\x12\x1b.cdn.InvalidateCacheRequest\x1a\x1c.cdn.InvalidateCacheResponse\x12C\n" +
-	"\fGetCDNConfig\x12\x18.cdn.GetCDNConfigRequest\x1a\x19.cdn.GetCDNConfigResponse\x12@\n" +
+	"\x0fInvalidateCache\x12\x1b.cdn.InvalidateCacheRequest\x1a\x1c.cdn.InvalidateCacheResponse\x12^\n" +
+	"\x15GetInvalidationStatus\x12!.cdn.GetInvalidationStatusRequest\x1a\".cdn.GetInvalidationStatusResponse\x12C\n" +
+	"\fGetCDNConfig\x12\x18.cdn.GetCDNConfigRequest\x1a\x19.cdn.GetCDNConfigResponse\x12L\n" +
+	"\x0fListSigningKeys\x12\x1b.cdn.ListSigningKeysRequest\x1a\x1c.cdn.ListSigningKeysResponse\x12@\n" +
 	"\vHealthCheck\x12\x17.cdn.HealthCheckRequest\x1a\x18.cdn.HealthCheckResponseB+Z)github.com/sdshorin/generia/api/proto/cdnb\x06proto3"
 
 var (
@@ -506,34 +924,47 @@ func file_cdn_cdn_proto_rawDescGZIP() []byte {
 	return file_cdn_cdn_proto_rawDescData
 }
 
-var file_cdn_cdn_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_cdn_cdn_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_cdn_cdn_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_cdn_cdn_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_cdn_cdn_proto_goTypes = []any{
-	(HealthCheckResponse_Status)(0), // 0: cdn.HealthCheckResponse.Status
-	(*GetSignedURLRequest)(nil),     // 1: cdn.GetSignedURLRequest
-	(*GetSignedURLResponse)(nil),    // 2: cdn.GetSignedURLResponse
-	(*InvalidateCacheRequest)(nil),  // 3: cdn.InvalidateCacheRequest
-	(*InvalidateCacheResponse)(nil), // 4: cdn.InvalidateCacheResponse
-	(*GetCDNConfigRequest)(nil),     // 5: cdn.GetCDNConfigRequest
-	(*GetCDNConfigResponse)(nil),    // 6: cdn.GetCDNConfigResponse
-	(*HealthCheckRequest)(nil),      // 7: cdn.HealthCheckRequest
-	(*HealthCheckResponse)(nil),     // 8: cdn.HealthCheckResponse
+	(InvalidationStatus)(0),               // 0: cdn.InvalidationStatus
+	(HealthCheckResponse_Status)(0),       // 1: cdn.HealthCheckResponse.Status
+	(*GetSignedURLRequest)(nil),           // 2: cdn.GetSignedURLRequest
+	(*GetSignedURLResponse)(nil),          // 3: cdn.GetSignedURLResponse
+	(*InvalidateCacheRequest)(nil),        // 4: cdn.InvalidateCacheRequest
+	(*InvalidateCacheResponse)(nil),       // 5: cdn.InvalidateCacheResponse
+	(*GetInvalidationStatusRequest)(nil),  // 6: cdn.GetInvalidationStatusRequest
+	(*GetInvalidationStatusResponse)(nil), // 7: cdn.GetInvalidationStatusResponse
+	(*GetCDNConfigRequest)(nil),           // 8: cdn.GetCDNConfigRequest
+	(*GetCDNConfigResponse)(nil),          // 9: cdn.GetCDNConfigResponse
+	(*ListSigningKeysRequest)(nil),        // 10: cdn.ListSigningKeysRequest
+	(*SigningKey)(nil),                    // 11: cdn.SigningKey
+	(*ListSigningKeysResponse)(nil),       // 12: cdn.ListSigningKeysResponse
+	(*HealthCheckRequest)(nil),            // 13: cdn.HealthCheckRequest
+	(*HealthCheckResponse)(nil),           // 14: cdn.HealthCheckResponse
 }
 var file_cdn_cdn_proto_depIdxs = []int32{
-	0, // 0: cdn.HealthCheckResponse.status:type_name -> cdn.HealthCheckResponse.Status
-	1, // 1: cdn.CDNService.GetSignedURL:input_type -> cdn.GetSignedURLRequest
-	3, // 2: cdn.CDNService.InvalidateCache:input_type -> cdn.InvalidateCacheRequest
-	5, // 3: cdn.CDNService.GetCDNConfig:input_type -> cdn.GetCDNConfigRequest
-	7, // 4: cdn.CDNService.HealthCheck:input_type -> cdn.HealthCheckRequest
-	2, // 5: cdn.CDNService.GetSignedURL:output_type -> cdn.GetSignedURLResponse
-	4, // 6: cdn.CDNService.InvalidateCache:output_type -> cdn.InvalidateCacheResponse
-	6, // 7: cdn.CDNService.GetCDNConfig:output_type -> cdn.GetCDNConfigResponse
-	8, // 8: cdn.CDNService.HealthCheck:output_type -> cdn.HealthCheckResponse
-	5, // [5:9] is the sub-list for method output_type
-	1, // [1:5] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	0,  // 0: cdn.InvalidateCacheResponse.status:type_name -> cdn.InvalidationStatus
+	0,  // 1: cdn.GetInvalidationStatusResponse.status:type_name -> cdn.InvalidationStatus
+	11, // 2: cdn.ListSigningKeysResponse.keys:type_name -> cdn.SigningKey
+	1,  // 3: cdn.HealthCheckResponse.status:type_name -> cdn.HealthCheckResponse.Status
+	2,  // 4: cdn.CDNService.GetSignedURL:input_type -> cdn.GetSignedURLRequest
+	4,  // 5: cdn.CDNService.InvalidateCache:input_type -> cdn.InvalidateCacheRequest
+	6,  // 6: cdn.CDNService.GetInvalidationStatus:input_type -> cdn.GetInvalidationStatusRequest
+	8,  // 7: cdn.CDNService.GetCDNConfig:input_type -> cdn.GetCDNConfigRequest
+	10, // 8: cdn.CDNService.ListSigningKeys:input_type -> cdn.ListSigningKeysRequest
+	13, // 9: cdn.CDNService.HealthCheck:input_type -> cdn.HealthCheckRequest
+	3,  // 10: cdn.CDNService.GetSignedURL:output_type -> cdn.GetSignedURLResponse
+	5,  // 11: cdn.CDNService.InvalidateCache:output_type -> cdn.InvalidateCacheResponse
+	7,  // 12: cdn.CDNService.GetInvalidationStatus:output_type -> cdn.GetInvalidationStatusResponse
+	9,  // 13: cdn.CDNService.GetCDNConfig:output_type -> cdn.GetCDNConfigResponse
+	12, // 14: cdn.CDNService.ListSigningKeys:output_type -> cdn.ListSigningKeysResponse
+	14, // 15: cdn.CDNService.HealthCheck:output_type -> cdn.HealthCheckResponse
+	10, // [10:16] is the sub-list for method output_type
+	4,  // [4:10] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_cdn_cdn_proto_init() }
@@ -546,8 +977,8 @@ func file_cdn_cdn_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cdn_cdn_proto_rawDesc), len(file_cdn_cdn_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   8,
+			NumEnums:      2,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,10 +19,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CDNService_GetSignedURL_FullMethodName    = "/cdn.CDNService/GetSignedURL"
-	CDNService_InvalidateCache_FullMethodName = "/cdn.CDNService/InvalidateCache"
-	CDNService_GetCDNConfig_FullMethodName    = "/cdn.CDNService/GetCDNConfig"
-	CDNService_HealthCheck_FullMethodName     = "/cdn.CDNService/HealthCheck"
+	CDNService_GetSignedURL_FullMethodName          = "/cdn.CDNService/GetSignedURL"
+	CDNService_InvalidateCache_FullMethodName       = "/cdn.CDNService/InvalidateCache"
+	CDNService_GetInvalidationStatus_FullMethodName = "/cdn.CDNService/GetInvalidationStatus"
+	CDNService_GetCDNConfig_FullMethodName          = "/cdn.CDNService/GetCDNConfig"
+	CDNService_ListSigningKeys_FullMethodName       = "/cdn.CDNService/ListSigningKeys"
+	CDNService_HealthCheck_FullMethodName           = "/cdn.CDNService/HealthCheck"
 )
 
 // CDNServiceClient is the client API for CDNService service.
@@ -33,10 +35,14 @@ const (
 type CDNServiceClient interface {
 	// Получение подписанного URL для доступа к файлу
 	GetSignedURL(ctx context.Context, in *GetSignedURLRequest, opts ...grpc.CallOption) (*GetSignedURLResponse, error)
-	// Инвалидация кеша для файла
+	// Инвалидация кеша для файлов (операция ставится в очередь)
 	InvalidateCache(ctx context.Context, in *InvalidateCacheRequest, opts ...grpc.CallOption) (*InvalidateCacheResponse, error)
+	// Получение состояния операции инвалидации
+	GetInvalidationStatus(ctx context.Context, in *GetInvalidationStatusRequest, opts ...grpc.CallOption) (*GetInvalidationStatusResponse, error)
 	// Получение конфигурации CDN
 	GetCDNConfig(ctx context.Context, in *GetCDNConfigRequest, opts ...grpc.CallOption) (*GetCDNConfigResponse, error)
+	// Список ключей подписи, которыми проверяются URL (без секретов)
+	ListSigningKeys(ctx context.Context, in *ListSigningKeysRequest, opts ...grpc.CallOption) (*ListSigningKeysResponse, error)
 	// Проверка здоровья сервиса
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
 }
@@ -69,6 +75,16 @@ func (c *cDNServiceClient) InvalidateCache(ctx context.Context, in *InvalidateCa
 	return out, nil
 }
 
+func (c *cDNServiceClient) GetInvalidationStatus(ctx context.Context, in *GetInvalidationStatusRequest, opts ...grpc.CallOption) (*GetInvalidationStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetInvalidationStatusResponse)
+	err := c.cc.Invoke(ctx, CDNService_GetInvalidationStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *cDNServiceClient) GetCDNConfig(ctx context.Context, in *GetCDNConfigRequest, opts ...grpc.CallOption) (*GetCDNConfigResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetCDNConfigResponse)
@@ -79,6 +95,16 @@ func (c *cDNServiceClient) GetCDNConfig(ctx context.Context, in *GetCDNConfigReq
 	return out, nil
 }
 
+func (c *cDNServiceClient) ListSigningKeys(ctx context.Context, in *ListSigningKeysRequest, opts ...grpc.CallOption) (*ListSigningKeysResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSigningKeysResponse)
+	err := c.cc.Invoke(ctx, CDNService_ListSigningKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *cDNServiceClient) HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(HealthCheckResponse)
@@ -97,10 +123,14 @@ func (c *cDNServiceClient) HealthCheck(ctx context.Context, in *HealthCheckReque
 type CDNServiceServer interface {
 	// Получение подписанного URL для доступа к файлу
 	GetSignedURL(context.Context, *GetSignedURLRequest) (*GetSignedURLResponse, error)
-	// Инвалидация кеша для файла
+	// Инвалидация кеша для файлов (операция ставится в очередь)
 	InvalidateCache(context.Context, *InvalidateCacheRequest) (*InvalidateCacheResponse, error)
+	// Получение состояния операции инвалидации
+	GetInvalidationStatus(context.Context, *GetInvalidationStatusRequest) (*GetInvalidationStatusResponse, error)
 	// Получение конфигурации CDN
 	GetCDNConfig(context.Context, *GetCDNConfigRequest) (*GetCDNConfigResponse, error)
+	// Список ключей подписи, которыми проверяются URL (без секретов)
+	ListSigningKeys(context.Context, *ListSigningKeysRequest) (*ListSigningKeysResponse, error)
 	// Проверка здоровья сервиса
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
 	mustEmbedUnimplementedCDNServiceServer()
@@ -119,9 +149,15 @@ func (UnimplementedCDNServiceServer) GetSignedURL(context.Context, *GetSignedURL
 func (UnimplementedCDNServiceServer) InvalidateCache(context.Context, *InvalidateCacheRequest) (*InvalidateCacheResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method InvalidateCache not implemented")
 }
+func (UnimplementedCDNServiceServer) GetInvalidationStatus(context.Context, *GetInvalidationStatusRequest) (*GetInvalidationStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInvalidationStatus not implemented")
+}
 func (UnimplementedCDNServiceServer) GetCDNConfig(context.Context, *GetCDNConfigRequest) (*GetCDNConfigResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetCDNConfig not implemented")
 }
+func (UnimplementedCDNServiceServer) ListSigningKeys(context.Context, *ListSigningKeysRequest) (*ListSigningKeysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSigningKeys not implemented")
+}
 func (UnimplementedCDNServiceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
@@ -182,6 +218,24 @@ func _CDNService_InvalidateCache_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CDNService_GetInvalidationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInvalidationStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CDNServiceServer).GetInvalidationStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CDNService_GetInvalidationStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CDNServiceServer).GetInvalidationStatus(ctx, req.(*GetInvalidationStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _CDNService_GetCDNConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetCDNConfigRequest)
 	if err := dec(in); err != nil {
@@ -200,6 +254,24 @@ func _CDNService_GetCDNConfig_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CDNService_ListSigningKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSigningKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CDNServiceServer).ListSigningKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CDNService_ListSigningKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CDNServiceServer).ListSigningKeys(ctx, req.(*ListSigningKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _CDNService_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthCheckRequest)
 	if err := dec(in); err != nil {
@@ -233,10 +305,18 @@ var CDNService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "InvalidateCache",
 			Handler:    _CDNService_InvalidateCache_Handler,
 		},
+		{
+			MethodName: "GetInvalidationStatus",
+			Handler:    _CDNService_GetInvalidationStatus_Handler,
+		},
 		{
 			MethodName: "GetCDNConfig",
 			Handler:    _CDNService_GetCDNConfig_Handler,
 		},
+		{
+			MethodName: "ListSigningKeys",
+			Handler:    _CDNService_ListSigningKeys_Handler,
+		},
 		{
 			MethodName: "HealthCheck",
 			Handler:    _CDNService_HealthCheck_Handler,
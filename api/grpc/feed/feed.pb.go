@@ -21,12 +21,60 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type GetUserFeedRequest_Mode int32
+
+const (
+	GetUserFeedRequest_CHRONOLOGICAL GetUserFeedRequest_Mode = 0 // по времени создания, новые сверху
+	GetUserFeedRequest_FOR_YOU       GetUserFeedRequest_Mode = 1 // ранжированная лента "For You"
+)
+
+// Enum value maps for GetUserFeedRequest_Mode.
+var (
+	GetUserFeedRequest_Mode_name = map[int32]string{
+		0: "CHRONOLOGICAL",
+		1: "FOR_YOU",
+	}
+	GetUserFeedRequest_Mode_value = map[string]int32{
+		"CHRONOLOGICAL": 0,
+		"FOR_YOU":       1,
+	}
+)
+
+func (x GetUserFeedRequest_Mode) Enum() *GetUserFeedRequest_Mode {
+	p := new(GetUserFeedRequest_Mode)
+	*p = x
+	return p
+}
+
+func (x GetUserFeedRequest_Mode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GetUserFeedRequest_Mode) Descriptor() protoreflect.EnumDescriptor {
+	return file_feed_feed_proto_enumTypes[0].Descriptor()
+}
+
+func (GetUserFeedRequest_Mode) Type() protoreflect.EnumType {
+	return &file_feed_feed_proto_enumTypes[0]
+}
+
+func (x GetUserFeedRequest_Mode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GetUserFeedRequest_Mode.Descriptor instead.
+func (GetUserFeedRequest_Mode) EnumDescriptor() ([]byte, []int) {
+	return file_feed_feed_proto_rawDescGZIP(), []int{0, 0}
+}
+
 type InvalidateFeedCacheRequest_InvalidationType int32
 
 const (
-	InvalidateFeedCacheRequest_ALL  InvalidateFeedCacheRequest_InvalidationType = 0
-	InvalidateFeedCacheRequest_POST InvalidateFeedCacheRequest_InvalidationType = 1
-	InvalidateFeedCacheRequest_USER InvalidateFeedCacheRequest_InvalidationType = 2
+	InvalidateFeedCacheRequest_ALL       InvalidateFeedCacheRequest_InvalidationType = 0 // все ленты и кэш постов
+	InvalidateFeedCacheRequest_POST      InvalidateFeedCacheRequest_InvalidationType = 1 // пост убирается из всех лент (удаление, модерация)
+	InvalidateFeedCacheRequest_USER      InvalidateFeedCacheRequest_InvalidationType = 2 // лента постов пользователя и кэш его постов
+	InvalidateFeedCacheRequest_CHARACTER InvalidateFeedCacheRequest_InvalidationType = 3 // кэш постов персонажа (например, после смены имени или аватара)
+	InvalidateFeedCacheRequest_WORLD     InvalidateFeedCacheRequest_InvalidationType = 4 // лента мира и кэш её постов
 )
 
 // Enum value maps for InvalidateFeedCacheRequest_InvalidationType.
@@ -35,11 +83,15 @@ var (
 		0: "ALL",
 		1: "POST",
 		2: "USER",
+		3: "CHARACTER",
+		4: "WORLD",
 	}
 	InvalidateFeedCacheRequest_InvalidationType_value = map[string]int32{
-		"ALL":  0,
-		"POST": 1,
-		"USER": 2,
+		"ALL":       0,
+		"POST":      1,
+		"USER":      2,
+		"CHARACTER": 3,
+		"WORLD":     4,
 	}
 )
 
@@ -54,11 +106,11 @@ func (x InvalidateFeedCacheRequest_InvalidationType) String() string {
 }
 
 func (InvalidateFeedCacheRequest_InvalidationType) Descriptor() protoreflect.EnumDescriptor {
-	return file_feed_feed_proto_enumTypes[0].Descriptor()
+	return file_feed_feed_proto_enumTypes[1].Descriptor()
 }
 
 func (InvalidateFeedCacheRequest_InvalidationType) Type() protoreflect.EnumType {
-	return &file_feed_feed_proto_enumTypes[0]
+	return &file_feed_feed_proto_enumTypes[1]
 }
 
 func (x InvalidateFeedCacheRequest_InvalidationType) Number() protoreflect.EnumNumber {
@@ -103,11 +155,11 @@ func (x HealthCheckResponse_Status) String() string {
 }
 
 func (HealthCheckResponse_Status) Descriptor() protoreflect.EnumDescriptor {
-	return file_feed_feed_proto_enumTypes[1].Descriptor()
+	return file_feed_feed_proto_enumTypes[2].Descriptor()
 }
 
 func (HealthCheckResponse_Status) Type() protoreflect.EnumType {
-	return &file_feed_feed_proto_enumTypes[1]
+	return &file_feed_feed_proto_enumTypes[2]
 }
 
 func (x HealthCheckResponse_Status) Number() protoreflect.EnumNumber {
@@ -120,11 +172,13 @@ func (HealthCheckResponse_Status) EnumDescriptor() ([]byte, []int) {
 }
 
 type GetUserFeedRequest struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	UserId           string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                                 // ID пользователя, ленту которого запрашиваем
-	RequestingUserId string                 `protobuf:"bytes,2,opt,name=requesting_user_id,json=requestingUserId,proto3" json:"requesting_user_id,omitempty"` // ID пользователя, который делает запрос (может быть пустым)
-	Limit            int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
-	Cursor           string                 `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"` // курсор для пагинации
+	state            protoimpl.MessageState  `protogen:"open.v1"`
+	UserId           string                  `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                                 // ID пользователя, ленту которого запрашиваем
+	RequestingUserId string                  `protobuf:"bytes,2,opt,name=requesting_user_id,json=requestingUserId,proto3" json:"requesting_user_id,omitempty"` // ID пользователя, который делает запрос (может быть пустым)
+	Limit            int32                   `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor           string                  `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`                  // непрозрачный курсор из next_cursor предыдущей страницы
+	WorldId          string                  `protobuf:"bytes,5,opt,name=world_id,json=worldId,proto3" json:"world_id,omitempty"` // ID мира: если задан, возвращается лента мира, иначе лента постов user_id
+	Mode             GetUserFeedRequest_Mode `protobuf:"varint,6,opt,name=mode,proto3,enum=feed.GetUserFeedRequest_Mode" json:"mode,omitempty"`
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
@@ -187,6 +241,20 @@ func (x *GetUserFeedRequest) GetCursor() string {
 	return ""
 }
 
+func (x *GetUserFeedRequest) GetWorldId() string {
+	if x != nil {
+		return x.WorldId
+	}
+	return ""
+}
+
+func (x *GetUserFeedRequest) GetMode() GetUserFeedRequest_Mode {
+	if x != nil {
+		return x.Mode
+	}
+	return GetUserFeedRequest_CHRONOLOGICAL
+}
+
 type GetUserFeedResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Posts         []*PostInfo            `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"`
@@ -248,16 +316,22 @@ func (x *GetUserFeedResponse) GetHasMore() bool {
 }
 
 type PostInfo struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Caption       string                 `protobuf:"bytes,3,opt,name=caption,proto3" json:"caption,omitempty"`
-	MediaId       string                 `protobuf:"bytes,4,opt,name=media_id,json=mediaId,proto3" json:"media_id,omitempty"`
-	CreatedAt     int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // Unix timestamp
-	Character     *CharacterInfo         `protobuf:"bytes,6,opt,name=character,proto3" json:"character,omitempty"`
-	Stats         *PostStats             `protobuf:"bytes,7,opt,name=stats,proto3" json:"stats,omitempty"`
-	MediaUrl      string                 `protobuf:"bytes,8,opt,name=media_url,json=mediaUrl,proto3" json:"media_url,omitempty"` // URL для доступа к медиафайлу
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Caption   string                 `protobuf:"bytes,3,opt,name=caption,proto3" json:"caption,omitempty"`
+	MediaId   string                 `protobuf:"bytes,4,opt,name=media_id,json=mediaId,proto3" json:"media_id,omitempty"`
+	CreatedAt int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // Unix timestamp
+	Character *CharacterInfo         `protobuf:"bytes,6,opt,name=character,proto3" json:"character,omitempty"`
+	Stats     *PostStats             `protobuf:"bytes,7,opt,name=stats,proto3" json:"stats,omitempty"`
+	MediaUrl  string                 `protobuf:"bytes,8,opt,name=media_url,json=mediaUrl,proto3" json:"media_url,omitempty"` // URL для доступа к медиафайлу
+	// Плейсхолдер изображения поста: размеры оригинала, blurhash и основной цвет (#rrggbb)
+	MediaWidth         int32  `protobuf:"varint,9,opt,name=media_width,json=mediaWidth,proto3" json:"media_width,omitempty"`
+	MediaHeight        int32  `protobuf:"varint,10,opt,name=media_height,json=mediaHeight,proto3" json:"media_height,omitempty"`
+	MediaBlurhash      string `protobuf:"bytes,11,opt,name=media_blurhash,json=mediaBlurhash,proto3" json:"media_blurhash,omitempty"`
+	MediaDominantColor string `protobuf:"bytes,12,opt,name=media_dominant_color,json=mediaDominantColor,proto3" json:"media_dominant_color,omitempty"`
+	IsAi               bool   `protobuf:"varint,13,opt,name=is_ai,json=isAi,proto3" json:"is_ai,omitempty"` // Был ли пост создан через AI
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *PostInfo) Reset() {
@@ -339,6 +413,41 @@ func (x *PostInfo) GetMediaUrl() string {
 	return ""
 }
 
+func (x *PostInfo) GetMediaWidth() int32 {
+	if x != nil {
+		return x.MediaWidth
+	}
+	return 0
+}
+
+func (x *PostInfo) GetMediaHeight() int32 {
+	if x != nil {
+		return x.MediaHeight
+	}
+	return 0
+}
+
+func (x *PostInfo) GetMediaBlurhash() string {
+	if x != nil {
+		return x.MediaBlurhash
+	}
+	return ""
+}
+
+func (x *PostInfo) GetMediaDominantColor() string {
+	if x != nil {
+		return x.MediaDominantColor
+	}
+	return ""
+}
+
+func (x *PostInfo) GetIsAi() bool {
+	if x != nil {
+		return x.IsAi
+	}
+	return false
+}
+
 type CharacterInfo struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -462,7 +571,7 @@ func (x *PostStats) GetUserLiked() bool {
 type InvalidateFeedCacheRequest struct {
 	state         protoimpl.MessageState                      `protogen:"open.v1"`
 	Type          InvalidateFeedCacheRequest_InvalidationType `protobuf:"varint,1,opt,name=type,proto3,enum=feed.InvalidateFeedCacheRequest_InvalidationType" json:"type,omitempty"`
-	Id            string                                      `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"` // post_id или user_id в зависимости от типа
+	Id            string                                      `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"` // post_id, user_id, character_id или world_id в зависимости от типа
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -514,6 +623,7 @@ func (x *InvalidateFeedCacheRequest) GetId() string {
 type InvalidateFeedCacheResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	RemovedCount  int32                  `protobuf:"varint,2,opt,name=removed_count,json=removedCount,proto3" json:"removed_count,omitempty"` // количество удалённых записей
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -555,6 +665,13 @@ func (x *InvalidateFeedCacheResponse) GetSuccess() bool {
 	return false
 }
 
+func (x *InvalidateFeedCacheResponse) GetRemovedCount() int32 {
+	if x != nil {
+		return x.RemovedCount
+	}
+	return 0
+}
+
 type HealthCheckRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -639,17 +756,22 @@ var File_feed_feed_proto protoreflect.FileDescriptor
 
 const file_feed_feed_proto_rawDesc = "" +
 	"\n" +
-	"\x0ffeed/feed.proto\x12\x04feed\"\x89\x01\n" +
+	"\x0ffeed/feed.proto\x12\x04feed\"\xff\x01\n" +
 	"\x12GetUserFeedRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12,\n" +
 	"\x12requesting_user_id\x18\x02 \x01(\tR\x10requestingUserId\x12\x14\n" +
 	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
-	"\x06cursor\x18\x04 \x01(\tR\x06cursor\"w\n" +
+	"\x06cursor\x18\x04 \x01(\tR\x06cursor\x12\x19\n" +
+	"\bworld_id\x18\x05 \x01(\tR\aworldId\x121\n" +
+	"\x04mode\x18\x06 \x01(\x0e2\x1d.feed.GetUserFeedRequest.ModeR\x04mode\"&\n" +
+	"\x04Mode\x12\x11\n" +
+	"\rCHRONOLOGICAL\x10\x00\x12\v\n" +
+	"\aFOR_YOU\x10\x01\"w\n" +
 	"\x13GetUserFeedResponse\x12$\n" +
 	"\x05posts\x18\x01 \x03(\v2\x0e.feed.PostInfoR\x05posts\x12\x1f\n" +
 	"\vnext_cursor\x18\x02 \x01(\tR\n" +
 	"nextCursor\x12\x19\n" +
-	"\bhas_more\x18\x03 \x01(\bR\ahasMore\"\xe5\x01\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\"\x97\x03\n" +
 	"\bPostInfo\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
 	"\acaption\x18\x03 \x01(\tR\acaption\x12\x19\n" +
@@ -658,7 +780,14 @@ const file_feed_feed_proto_rawDesc = "" +
 	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x121\n" +
 	"\tcharacter\x18\x06 \x01(\v2\x13.feed.CharacterInfoR\tcharacter\x12%\n" +
 	"\x05stats\x18\a \x01(\v2\x0f.feed.PostStatsR\x05stats\x12\x1b\n" +
-	"\tmedia_url\x18\b \x01(\tR\bmediaUrl\"r\n" +
+	"\tmedia_url\x18\b \x01(\tR\bmediaUrl\x12\x1f\n" +
+	"\vmedia_width\x18\t \x01(\x05R\n" +
+	"mediaWidth\x12!\n" +
+	"\fmedia_height\x18\n" +
+	" \x01(\x05R\vmediaHeight\x12%\n" +
+	"\x0emedia_blurhash\x18\v \x01(\tR\rmediaBlurhash\x120\n" +
+	"\x14media_dominant_color\x18\f \x01(\tR\x12mediaDominantColor\x12\x13\n" +
+	"\x05is_ai\x18\r \x01(\bR\x04isAi\"r\n" +
 	"\rCharacterInfo\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
 	"\fdisplay_name\x18\x02 \x01(\tR\vdisplayName\x12.\n" +
@@ -668,16 +797,19 @@ const file_feed_feed_proto_rawDesc = "" +
 	"likesCount\x12%\n" +
 	"\x0ecomments_count\x18\x02 \x01(\x05R\rcommentsCount\x12\x1d\n" +
 	"\n" +
-	"user_liked\x18\x03 \x01(\bR\tuserLiked\"\xa4\x01\n" +
+	"user_liked\x18\x03 \x01(\bR\tuserLiked\"\xbe\x01\n" +
 	"\x1aInvalidateFeedCacheRequest\x12E\n" +
 	"\x04type\x18\x01 \x01(\x0e21.feed.InvalidateFeedCacheRequest.InvalidationTypeR\x04type\x12\x0e\n" +
-	"\x02id\x18\x02 \x01(\tR\x02id\"/\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"I\n" +
 	"\x10InvalidationType\x12\a\n" +
 	"\x03ALL\x10\x00\x12\b\n" +
 	"\x04POST\x10\x01\x12\b\n" +
-	"\x04USER\x10\x02\"7\n" +
+	"\x04USER\x10\x02\x12\r\n" +
+	"\tCHARACTER\x10\x03\x12\t\n" +
+	"\x05WORLD\x10\x04\"\\\n" +
 	"\x1bInvalidateFeedCacheResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x14\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12#\n" +
+	"\rremoved_count\x18\x02 \x01(\x05R\fremovedCount\"\x14\n" +
 	"\x12HealthCheckRequest\"\x84\x01\n" +
 	"\x13HealthCheckResponse\x128\n" +
 	"\x06status\x18\x01 \x01(\x0e2 .feed.HealthCheckResponse.StatusR\x06status\"3\n" +
@@ -702,38 +834,40 @@ func file_feed_feed_proto_rawDescGZIP() []byte {
 	return file_feed_feed_proto_rawDescData
 }
 
-var file_feed_feed_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_feed_feed_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
 var file_feed_feed_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_feed_feed_proto_goTypes = []any{
-	(InvalidateFeedCacheRequest_InvalidationType)(0), // 0: feed.InvalidateFeedCacheRequest.InvalidationType
-	(HealthCheckResponse_Status)(0),                  // 1: feed.HealthCheckResponse.Status
-	(*GetUserFeedRequest)(nil),                       // 2: feed.GetUserFeedRequest
-	(*GetUserFeedResponse)(nil),                      // 3: feed.GetUserFeedResponse
-	(*PostInfo)(nil),                                 // 4: feed.PostInfo
-	(*CharacterInfo)(nil),                            // 5: feed.CharacterInfo
-	(*PostStats)(nil),                                // 6: feed.PostStats
-	(*InvalidateFeedCacheRequest)(nil),               // 7: feed.InvalidateFeedCacheRequest
-	(*InvalidateFeedCacheResponse)(nil),              // 8: feed.InvalidateFeedCacheResponse
-	(*HealthCheckRequest)(nil),                       // 9: feed.HealthCheckRequest
-	(*HealthCheckResponse)(nil),                      // 10: feed.HealthCheckResponse
+	(GetUserFeedRequest_Mode)(0),                     // 0: feed.GetUserFeedRequest.Mode
+	(InvalidateFeedCacheRequest_InvalidationType)(0), // 1: feed.InvalidateFeedCacheRequest.InvalidationType
+	(HealthCheckResponse_Status)(0),                  // 2: feed.HealthCheckResponse.Status
+	(*GetUserFeedRequest)(nil),                       // 3: feed.GetUserFeedRequest
+	(*GetUserFeedResponse)(nil),                      // 4: feed.GetUserFeedResponse
+	(*PostInfo)(nil),                                 // 5: feed.PostInfo
+	(*CharacterInfo)(nil),                            // 6: feed.CharacterInfo
+	(*PostStats)(nil),                                // 7: feed.PostStats
+	(*InvalidateFeedCacheRequest)(nil),               // 8: feed.InvalidateFeedCacheRequest
+	(*InvalidateFeedCacheResponse)(nil),              // 9: feed.InvalidateFeedCacheResponse
+	(*HealthCheckRequest)(nil),                       // 10: feed.HealthCheckRequest
+	(*HealthCheckResponse)(nil),                      // 11: feed.HealthCheckResponse
 }
 var file_feed_feed_proto_depIdxs = []int32{
-	4,  // 0: feed.GetUserFeedResponse.posts:type_name -> feed.PostInfo
-	5,  // 1: feed.PostInfo.character:type_name -> feed.CharacterInfo
-	6,  // 2: feed.PostInfo.stats:type_name -> feed.PostStats
-	0,  // 3: feed.InvalidateFeedCacheRequest.type:type_name -> feed.InvalidateFeedCacheRequest.InvalidationType
-	1,  // 4: feed.HealthCheckResponse.status:type_name -> feed.HealthCheckResponse.Status
-	2,  // 5: feed.FeedService.GetUserFeed:input_type -> feed.GetUserFeedRequest
-	7,  // 6: feed.FeedService.InvalidateFeedCache:input_type -> feed.InvalidateFeedCacheRequest
-	9,  // 7: feed.FeedService.HealthCheck:input_type -> feed.HealthCheckRequest
-	3,  // 8: feed.FeedService.GetUserFeed:output_type -> feed.GetUserFeedResponse
-	8,  // 9: feed.FeedService.InvalidateFeedCache:output_type -> feed.InvalidateFeedCacheResponse
-	10, // 10: feed.FeedService.HealthCheck:output_type -> feed.HealthCheckResponse
-	8,  // [8:11] is the sub-list for method output_type
-	5,  // [5:8] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+	0,  // 0: feed.GetUserFeedRequest.mode:type_name -> feed.GetUserFeedRequest.Mode
+	5,  // 1: feed.GetUserFeedResponse.posts:type_name -> feed.PostInfo
+	6,  // 2: feed.PostInfo.character:type_name -> feed.CharacterInfo
+	7,  // 3: feed.PostInfo.stats:type_name -> feed.PostStats
+	1,  // 4: feed.InvalidateFeedCacheRequest.type:type_name -> feed.InvalidateFeedCacheRequest.InvalidationType
+	2,  // 5: feed.HealthCheckResponse.status:type_name -> feed.HealthCheckResponse.Status
+	3,  // 6: feed.FeedService.GetUserFeed:input_type -> feed.GetUserFeedRequest
+	8,  // 7: feed.FeedService.InvalidateFeedCache:input_type -> feed.InvalidateFeedCacheRequest
+	10, // 8: feed.FeedService.HealthCheck:input_type -> feed.HealthCheckRequest
+	4,  // 9: feed.FeedService.GetUserFeed:output_type -> feed.GetUserFeedResponse
+	9,  // 10: feed.FeedService.InvalidateFeedCache:output_type -> feed.InvalidateFeedCacheResponse
+	11, // 11: feed.FeedService.HealthCheck:output_type -> feed.HealthCheckResponse
+	9,  // [9:12] is the sub-list for method output_type
+	6,  // [6:9] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_feed_feed_proto_init() }
@@ -746,7 +880,7 @@ func file_feed_feed_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_feed_feed_proto_rawDesc), len(file_feed_feed_proto_rawDesc)),
-			NumEnums:      2,
+			NumEnums:      3,
 			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
@@ -67,7 +67,7 @@ func (x HealthCheckResponse_Status) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use HealthCheckResponse_Status.Descriptor instead.
 func (HealthCheckResponse_Status) EnumDescriptor() ([]byte, []int) {
-	return file_interaction_interaction_proto_rawDescGZIP(), []int{19, 0}
+	return file_interaction_interaction_proto_rawDescGZIP(), []int{21, 0}
 }
 
 // Лайки
@@ -571,6 +571,102 @@ func (x *CheckUserLikedResponse) GetLiked() bool {
 	return false
 }
 
+type CheckUserLikedPostsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	PostIds       []string               `protobuf:"bytes,2,rep,name=post_ids,json=postIds,proto3" json:"post_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckUserLikedPostsRequest) Reset() {
+	*x = CheckUserLikedPostsRequest{}
+	mi := &file_interaction_interaction_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckUserLikedPostsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckUserLikedPostsRequest) ProtoMessage() {}
+
+func (x *CheckUserLikedPostsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_interaction_interaction_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckUserLikedPostsRequest.ProtoReflect.Descriptor instead.
+func (*CheckUserLikedPostsRequest) Descriptor() ([]byte, []int) {
+	return file_interaction_interaction_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CheckUserLikedPostsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CheckUserLikedPostsRequest) GetPostIds() []string {
+	if x != nil {
+		return x.PostIds
+	}
+	return nil
+}
+
+type CheckUserLikedPostsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LikedPostIds  []string               `protobuf:"bytes,1,rep,name=liked_post_ids,json=likedPostIds,proto3" json:"liked_post_ids,omitempty"` // Подмножество post_ids, которые пользователь лайкнул
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckUserLikedPostsResponse) Reset() {
+	*x = CheckUserLikedPostsResponse{}
+	mi := &file_interaction_interaction_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckUserLikedPostsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckUserLikedPostsResponse) ProtoMessage() {}
+
+func (x *CheckUserLikedPostsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_interaction_interaction_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckUserLikedPostsResponse.ProtoReflect.Descriptor instead.
+func (*CheckUserLikedPostsResponse) Descriptor() ([]byte, []int) {
+	return file_interaction_interaction_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CheckUserLikedPostsResponse) GetLikedPostIds() []string {
+	if x != nil {
+		return x.LikedPostIds
+	}
+	return nil
+}
+
 // Комментарии
 type AddCommentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -584,7 +680,7 @@ type AddCommentRequest struct {
 
 func (x *AddCommentRequest) Reset() {
 	*x = AddCommentRequest{}
-	mi := &file_interaction_interaction_proto_msgTypes[9]
+	mi := &file_interaction_interaction_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -596,7 +692,7 @@ func (x *AddCommentRequest) String() string {
 func (*AddCommentRequest) ProtoMessage() {}
 
 func (x *AddCommentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_interaction_interaction_proto_msgTypes[9]
+	mi := &file_interaction_interaction_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -609,7 +705,7 @@ func (x *AddCommentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AddCommentRequest.ProtoReflect.Descriptor instead.
 func (*AddCommentRequest) Descriptor() ([]byte, []int) {
-	return file_interaction_interaction_proto_rawDescGZIP(), []int{9}
+	return file_interaction_interaction_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *AddCommentRequest) GetPostId() string {
@@ -650,7 +746,7 @@ type AddCommentResponse struct {
 
 func (x *AddCommentResponse) Reset() {
 	*x = AddCommentResponse{}
-	mi := &file_interaction_interaction_proto_msgTypes[10]
+	mi := &file_interaction_interaction_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -662,7 +758,7 @@ func (x *AddCommentResponse) String() string {
 func (*AddCommentResponse) ProtoMessage() {}
 
 func (x *AddCommentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_interaction_interaction_proto_msgTypes[10]
+	mi := &file_interaction_interaction_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -675,7 +771,7 @@ func (x *AddCommentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AddCommentResponse.ProtoReflect.Descriptor instead.
 func (*AddCommentResponse) Descriptor() ([]byte, []int) {
-	return file_interaction_interaction_proto_rawDescGZIP(), []int{10}
+	return file_interaction_interaction_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *AddCommentResponse) GetCommentId() string {
@@ -704,7 +800,7 @@ type GetPostCommentsRequest struct {
 
 func (x *GetPostCommentsRequest) Reset() {
 	*x = GetPostCommentsRequest{}
-	mi := &file_interaction_interaction_proto_msgTypes[11]
+	mi := &file_interaction_interaction_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -716,7 +812,7 @@ func (x *GetPostCommentsRequest) String() string {
 func (*GetPostCommentsRequest) ProtoMessage() {}
 
 func (x *GetPostCommentsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_interaction_interaction_proto_msgTypes[11]
+	mi := &file_interaction_interaction_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -729,7 +825,7 @@ func (x *GetPostCommentsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPostCommentsRequest.ProtoReflect.Descriptor instead.
 func (*GetPostCommentsRequest) Descriptor() ([]byte, []int) {
-	return file_interaction_interaction_proto_rawDescGZIP(), []int{11}
+	return file_interaction_interaction_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *GetPostCommentsRequest) GetPostId() string {
@@ -770,7 +866,7 @@ type PostCommentsResponse struct {
 
 func (x *PostCommentsResponse) Reset() {
 	*x = PostCommentsResponse{}
-	mi := &file_interaction_interaction_proto_msgTypes[12]
+	mi := &file_interaction_interaction_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -782,7 +878,7 @@ func (x *PostCommentsResponse) String() string {
 func (*PostCommentsResponse) ProtoMessage() {}
 
 func (x *PostCommentsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_interaction_interaction_proto_msgTypes[12]
+	mi := &file_interaction_interaction_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -795,7 +891,7 @@ func (x *PostCommentsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PostCommentsResponse.ProtoReflect.Descriptor instead.
 func (*PostCommentsResponse) Descriptor() ([]byte, []int) {
-	return file_interaction_interaction_proto_rawDescGZIP(), []int{12}
+	return file_interaction_interaction_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *PostCommentsResponse) GetComments() []*Comment {
@@ -826,7 +922,7 @@ type Comment struct {
 
 func (x *Comment) Reset() {
 	*x = Comment{}
-	mi := &file_interaction_interaction_proto_msgTypes[13]
+	mi := &file_interaction_interaction_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -838,7 +934,7 @@ func (x *Comment) String() string {
 func (*Comment) ProtoMessage() {}
 
 func (x *Comment) ProtoReflect() protoreflect.Message {
-	mi := &file_interaction_interaction_proto_msgTypes[13]
+	mi := &file_interaction_interaction_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -851,7 +947,7 @@ func (x *Comment) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Comment.ProtoReflect.Descriptor instead.
 func (*Comment) Descriptor() ([]byte, []int) {
-	return file_interaction_interaction_proto_rawDescGZIP(), []int{13}
+	return file_interaction_interaction_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *Comment) GetCommentId() string {
@@ -906,7 +1002,7 @@ type GetPostStatsRequest struct {
 
 func (x *GetPostStatsRequest) Reset() {
 	*x = GetPostStatsRequest{}
-	mi := &file_interaction_interaction_proto_msgTypes[14]
+	mi := &file_interaction_interaction_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -918,7 +1014,7 @@ func (x *GetPostStatsRequest) String() string {
 func (*GetPostStatsRequest) ProtoMessage() {}
 
 func (x *GetPostStatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_interaction_interaction_proto_msgTypes[14]
+	mi := &file_interaction_interaction_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -931,7 +1027,7 @@ func (x *GetPostStatsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPostStatsRequest.ProtoReflect.Descriptor instead.
 func (*GetPostStatsRequest) Descriptor() ([]byte, []int) {
-	return file_interaction_interaction_proto_rawDescGZIP(), []int{14}
+	return file_interaction_interaction_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *GetPostStatsRequest) GetPostId() string {
@@ -952,7 +1048,7 @@ type PostStatsResponse struct {
 
 func (x *PostStatsResponse) Reset() {
 	*x = PostStatsResponse{}
-	mi := &file_interaction_interaction_proto_msgTypes[15]
+	mi := &file_interaction_interaction_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -964,7 +1060,7 @@ func (x *PostStatsResponse) String() string {
 func (*PostStatsResponse) ProtoMessage() {}
 
 func (x *PostStatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_interaction_interaction_proto_msgTypes[15]
+	mi := &file_interaction_interaction_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -977,7 +1073,7 @@ func (x *PostStatsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PostStatsResponse.ProtoReflect.Descriptor instead.
 func (*PostStatsResponse) Descriptor() ([]byte, []int) {
-	return file_interaction_interaction_proto_rawDescGZIP(), []int{15}
+	return file_interaction_interaction_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *PostStatsResponse) GetPostId() string {
@@ -1010,7 +1106,7 @@ type GetPostsStatsRequest struct {
 
 func (x *GetPostsStatsRequest) Reset() {
 	*x = GetPostsStatsRequest{}
-	mi := &file_interaction_interaction_proto_msgTypes[16]
+	mi := &file_interaction_interaction_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1022,7 +1118,7 @@ func (x *GetPostsStatsRequest) String() string {
 func (*GetPostsStatsRequest) ProtoMessage() {}
 
 func (x *GetPostsStatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_interaction_interaction_proto_msgTypes[16]
+	mi := &file_interaction_interaction_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1035,7 +1131,7 @@ func (x *GetPostsStatsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPostsStatsRequest.ProtoReflect.Descriptor instead.
 func (*GetPostsStatsRequest) Descriptor() ([]byte, []int) {
-	return file_interaction_interaction_proto_rawDescGZIP(), []int{16}
+	return file_interaction_interaction_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *GetPostsStatsRequest) GetPostIds() []string {
@@ -1054,7 +1150,7 @@ type PostsStatsResponse struct {
 
 func (x *PostsStatsResponse) Reset() {
 	*x = PostsStatsResponse{}
-	mi := &file_interaction_interaction_proto_msgTypes[17]
+	mi := &file_interaction_interaction_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1066,7 +1162,7 @@ func (x *PostsStatsResponse) String() string {
 func (*PostsStatsResponse) ProtoMessage() {}
 
 func (x *PostsStatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_interaction_interaction_proto_msgTypes[17]
+	mi := &file_interaction_interaction_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1079,7 +1175,7 @@ func (x *PostsStatsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PostsStatsResponse.ProtoReflect.Descriptor instead.
 func (*PostsStatsResponse) Descriptor() ([]byte, []int) {
-	return file_interaction_interaction_proto_rawDescGZIP(), []int{17}
+	return file_interaction_interaction_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *PostsStatsResponse) GetStats() map[string]*PostStatsResponse {
@@ -1097,7 +1193,7 @@ type HealthCheckRequest struct {
 
 func (x *HealthCheckRequest) Reset() {
 	*x = HealthCheckRequest{}
-	mi := &file_interaction_interaction_proto_msgTypes[18]
+	mi := &file_interaction_interaction_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1109,7 +1205,7 @@ func (x *HealthCheckRequest) String() string {
 func (*HealthCheckRequest) ProtoMessage() {}
 
 func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_interaction_interaction_proto_msgTypes[18]
+	mi := &file_interaction_interaction_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1122,7 +1218,7 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
 func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_interaction_interaction_proto_rawDescGZIP(), []int{18}
+	return file_interaction_interaction_proto_rawDescGZIP(), []int{20}
 }
 
 type HealthCheckResponse struct {
@@ -1134,7 +1230,7 @@ type HealthCheckResponse struct {
 
 func (x *HealthCheckResponse) Reset() {
 	*x = HealthCheckResponse{}
-	mi := &file_interaction_interaction_proto_msgTypes[19]
+	mi := &file_interaction_interaction_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1146,7 +1242,7 @@ func (x *HealthCheckResponse) String() string {
 func (*HealthCheckResponse) ProtoMessage() {}
 
 func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_interaction_interaction_proto_msgTypes[19]
+	mi := &file_interaction_interaction_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1159,7 +1255,7 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
 func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_interaction_interaction_proto_rawDescGZIP(), []int{19}
+	return file_interaction_interaction_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *HealthCheckResponse) GetStatus() HealthCheckResponse_Status {
@@ -1207,7 +1303,12 @@ const file_interaction_interaction_proto_rawDesc = "" +
 	"\apost_id\x18\x01 \x01(\tR\x06postId\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\tR\x06userId\".\n" +
 	"\x16CheckUserLikedResponse\x12\x14\n" +
-	"\x05liked\x18\x01 \x01(\bR\x05liked\"t\n" +
+	"\x05liked\x18\x01 \x01(\bR\x05liked\"P\n" +
+	"\x1aCheckUserLikedPostsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
+	"\bpost_ids\x18\x02 \x03(\tR\apostIds\"C\n" +
+	"\x1bCheckUserLikedPostsResponse\x12$\n" +
+	"\x0eliked_post_ids\x18\x01 \x03(\tR\flikedPostIds\"t\n" +
 	"\x11AddCommentRequest\x12\x17\n" +
 	"\apost_id\x18\x01 \x01(\tR\x06postId\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x12\n" +
@@ -1256,13 +1357,14 @@ const file_interaction_interaction_proto_rawDesc = "" +
 	"\x06Status\x12\v\n" +
 	"\aUNKNOWN\x10\x00\x12\v\n" +
 	"\aSERVING\x10\x01\x12\x0f\n" +
-	"\vNOT_SERVING\x10\x022\xfc\x05\n" +
+	"\vNOT_SERVING\x10\x022\xe6\x06\n" +
 	"\x12InteractionService\x12G\n" +
 	"\bLikePost\x12\x1c.interaction.LikePostRequest\x1a\x1d.interaction.LikePostResponse\x12M\n" +
 	"\n" +
 	"UnlikePost\x12\x1e.interaction.UnlikePostRequest\x1a\x1f.interaction.UnlikePostResponse\x12P\n" +
 	"\fGetPostLikes\x12 .interaction.GetPostLikesRequest\x1a\x1e.interaction.PostLikesResponse\x12Y\n" +
-	"\x0eCheckUserLiked\x12\".interaction.CheckUserLikedRequest\x1a#.interaction.CheckUserLikedResponse\x12M\n" +
+	"\x0eCheckUserLiked\x12\".interaction.CheckUserLikedRequest\x1a#.interaction.CheckUserLikedResponse\x12h\n" +
+	"\x13CheckUserLikedPosts\x12'.interaction.CheckUserLikedPostsRequest\x1a(.interaction.CheckUserLikedPostsResponse\x12M\n" +
 	"\n" +
 	"AddComment\x12\x1e.interaction.AddCommentRequest\x1a\x1f.interaction.AddCommentResponse\x12Y\n" +
 	"\x0fGetPostComments\x12#.interaction.GetPostCommentsRequest\x1a!.interaction.PostCommentsResponse\x12P\n" +
@@ -1283,57 +1385,61 @@ func file_interaction_interaction_proto_rawDescGZIP() []byte {
 }
 
 var file_interaction_interaction_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_interaction_interaction_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_interaction_interaction_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
 var file_interaction_interaction_proto_goTypes = []any{
-	(HealthCheckResponse_Status)(0), // 0: interaction.HealthCheckResponse.Status
-	(*LikePostRequest)(nil),         // 1: interaction.LikePostRequest
-	(*LikePostResponse)(nil),        // 2: interaction.LikePostResponse
-	(*UnlikePostRequest)(nil),       // 3: interaction.UnlikePostRequest
-	(*UnlikePostResponse)(nil),      // 4: interaction.UnlikePostResponse
-	(*GetPostLikesRequest)(nil),     // 5: interaction.GetPostLikesRequest
-	(*PostLikesResponse)(nil),       // 6: interaction.PostLikesResponse
-	(*Like)(nil),                    // 7: interaction.Like
-	(*CheckUserLikedRequest)(nil),   // 8: interaction.CheckUserLikedRequest
-	(*CheckUserLikedResponse)(nil),  // 9: interaction.CheckUserLikedResponse
-	(*AddCommentRequest)(nil),       // 10: interaction.AddCommentRequest
-	(*AddCommentResponse)(nil),      // 11: interaction.AddCommentResponse
-	(*GetPostCommentsRequest)(nil),  // 12: interaction.GetPostCommentsRequest
-	(*PostCommentsResponse)(nil),    // 13: interaction.PostCommentsResponse
-	(*Comment)(nil),                 // 14: interaction.Comment
-	(*GetPostStatsRequest)(nil),     // 15: interaction.GetPostStatsRequest
-	(*PostStatsResponse)(nil),       // 16: interaction.PostStatsResponse
-	(*GetPostsStatsRequest)(nil),    // 17: interaction.GetPostsStatsRequest
-	(*PostsStatsResponse)(nil),      // 18: interaction.PostsStatsResponse
-	(*HealthCheckRequest)(nil),      // 19: interaction.HealthCheckRequest
-	(*HealthCheckResponse)(nil),     // 20: interaction.HealthCheckResponse
-	nil,                             // 21: interaction.PostsStatsResponse.StatsEntry
+	(HealthCheckResponse_Status)(0),     // 0: interaction.HealthCheckResponse.Status
+	(*LikePostRequest)(nil),             // 1: interaction.LikePostRequest
+	(*LikePostResponse)(nil),            // 2: interaction.LikePostResponse
+	(*UnlikePostRequest)(nil),           // 3: interaction.UnlikePostRequest
+	(*UnlikePostResponse)(nil),          // 4: interaction.UnlikePostResponse
+	(*GetPostLikesRequest)(nil),         // 5: interaction.GetPostLikesRequest
+	(*PostLikesResponse)(nil),           // 6: interaction.PostLikesResponse
+	(*Like)(nil),                        // 7: interaction.Like
+	(*CheckUserLikedRequest)(nil),       // 8: interaction.CheckUserLikedRequest
+	(*CheckUserLikedResponse)(nil),      // 9: interaction.CheckUserLikedResponse
+	(*CheckUserLikedPostsRequest)(nil),  // 10: interaction.CheckUserLikedPostsRequest
+	(*CheckUserLikedPostsResponse)(nil), // 11: interaction.CheckUserLikedPostsResponse
+	(*AddCommentRequest)(nil),           // 12: interaction.AddCommentRequest
+	(*AddCommentResponse)(nil),          // 13: interaction.AddCommentResponse
+	(*GetPostCommentsRequest)(nil),      // 14: interaction.GetPostCommentsRequest
+	(*PostCommentsResponse)(nil),        // 15: interaction.PostCommentsResponse
+	(*Comment)(nil),                     // 16: interaction.Comment
+	(*GetPostStatsRequest)(nil),         // 17: interaction.GetPostStatsRequest
+	(*PostStatsResponse)(nil),           // 18: interaction.PostStatsResponse
+	(*GetPostsStatsRequest)(nil),        // 19: interaction.GetPostsStatsRequest
+	(*PostsStatsResponse)(nil),          // 20: interaction.PostsStatsResponse
+	(*HealthCheckRequest)(nil),          // 21: interaction.HealthCheckRequest
+	(*HealthCheckResponse)(nil),         // 22: interaction.HealthCheckResponse
+	nil,                                 // 23: interaction.PostsStatsResponse.StatsEntry
 }
 var file_interaction_interaction_proto_depIdxs = []int32{
 	7,  // 0: interaction.PostLikesResponse.likes:type_name -> interaction.Like
-	14, // 1: interaction.PostCommentsResponse.comments:type_name -> interaction.Comment
-	21, // 2: interaction.PostsStatsResponse.stats:type_name -> interaction.PostsStatsResponse.StatsEntry
+	16, // 1: interaction.PostCommentsResponse.comments:type_name -> interaction.Comment
+	23, // 2: interaction.PostsStatsResponse.stats:type_name -> interaction.PostsStatsResponse.StatsEntry
 	0,  // 3: interaction.HealthCheckResponse.status:type_name -> interaction.HealthCheckResponse.Status
-	16, // 4: interaction.PostsStatsResponse.StatsEntry.value:type_name -> interaction.PostStatsResponse
+	18, // 4: interaction.PostsStatsResponse.StatsEntry.value:type_name -> interaction.PostStatsResponse
 	1,  // 5: interaction.InteractionService.LikePost:input_type -> interaction.LikePostRequest
 	3,  // 6: interaction.InteractionService.UnlikePost:input_type -> interaction.UnlikePostRequest
 	5,  // 7: interaction.InteractionService.GetPostLikes:input_type -> interaction.GetPostLikesRequest
 	8,  // 8: interaction.InteractionService.CheckUserLiked:input_type -> interaction.CheckUserLikedRequest
-	10, // 9: interaction.InteractionService.AddComment:input_type -> interaction.AddCommentRequest
-	12, // 10: interaction.InteractionService.GetPostComments:input_type -> interaction.GetPostCommentsRequest
-	15, // 11: interaction.InteractionService.GetPostStats:input_type -> interaction.GetPostStatsRequest
-	17, // 12: interaction.InteractionService.GetPostsStats:input_type -> interaction.GetPostsStatsRequest
-	19, // 13: interaction.InteractionService.HealthCheck:input_type -> interaction.HealthCheckRequest
-	2,  // 14: interaction.InteractionService.LikePost:output_type -> interaction.LikePostResponse
-	4,  // 15: interaction.InteractionService.UnlikePost:output_type -> interaction.UnlikePostResponse
-	6,  // 16: interaction.InteractionService.GetPostLikes:output_type -> interaction.PostLikesResponse
-	9,  // 17: interaction.InteractionService.CheckUserLiked:output_type -> interaction.CheckUserLikedResponse
-	11, // 18: interaction.InteractionService.AddComment:output_type -> interaction.AddCommentResponse
-	13, // 19: interaction.InteractionService.GetPostComments:output_type -> interaction.PostCommentsResponse
-	16, // 20: interaction.InteractionService.GetPostStats:output_type -> interaction.PostStatsResponse
-	18, // 21: interaction.InteractionService.GetPostsStats:output_type -> interaction.PostsStatsResponse
-	20, // 22: interaction.InteractionService.HealthCheck:output_type -> interaction.HealthCheckResponse
-	14, // [14:23] is the sub-list for method output_type
-	5,  // [5:14] is the sub-list for method input_type
+	10, // 9: interaction.InteractionService.CheckUserLikedPosts:input_type -> interaction.CheckUserLikedPostsRequest
+	12, // 10: interaction.InteractionService.AddComment:input_type -> interaction.AddCommentRequest
+	14, // 11: interaction.InteractionService.GetPostComments:input_type -> interaction.GetPostCommentsRequest
+	17, // 12: interaction.InteractionService.GetPostStats:input_type -> interaction.GetPostStatsRequest
+	19, // 13: interaction.InteractionService.GetPostsStats:input_type -> interaction.GetPostsStatsRequest
+	21, // 14: interaction.InteractionService.HealthCheck:input_type -> interaction.HealthCheckRequest
+	2,  // 15: interaction.InteractionService.LikePost:output_type -> interaction.LikePostResponse
+	4,  // 16: interaction.InteractionService.UnlikePost:output_type -> interaction.UnlikePostResponse
+	6,  // 17: interaction.InteractionService.GetPostLikes:output_type -> interaction.PostLikesResponse
+	9,  // 18: interaction.InteractionService.CheckUserLiked:output_type -> interaction.CheckUserLikedResponse
+	11, // 19: interaction.InteractionService.CheckUserLikedPosts:output_type -> interaction.CheckUserLikedPostsResponse
+	13, // 20: interaction.InteractionService.AddComment:output_type -> interaction.AddCommentResponse
+	15, // 21: interaction.InteractionService.GetPostComments:output_type -> interaction.PostCommentsResponse
+	18, // 22: interaction.InteractionService.GetPostStats:output_type -> interaction.PostStatsResponse
+	20, // 23: interaction.InteractionService.GetPostsStats:output_type -> interaction.PostsStatsResponse
+	22, // 24: interaction.InteractionService.HealthCheck:output_type -> interaction.HealthCheckResponse
+	15, // [15:25] is the sub-list for method output_type
+	5,  // [5:15] is the sub-list for method input_type
 	5,  // [5:5] is the sub-list for extension type_name
 	5,  // [5:5] is the sub-list for extension extendee
 	0,  // [0:5] is the sub-list for field type_name
@@ -1350,7 +1456,7 @@ func file_interaction_interaction_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_interaction_interaction_proto_rawDesc), len(file_interaction_interaction_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   21,
+			NumMessages:   23,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
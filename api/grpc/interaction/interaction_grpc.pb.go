@@ -19,15 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	InteractionService_LikePost_FullMethodName        = "/interaction.InteractionService/LikePost"
-	InteractionService_UnlikePost_FullMethodName      = "/interaction.InteractionService/UnlikePost"
-	InteractionService_GetPostLikes_FullMethodName    = "/interaction.InteractionService/GetPostLikes"
-	InteractionService_CheckUserLiked_FullMethodName  = "/interaction.InteractionService/CheckUserLiked"
-	InteractionService_AddComment_FullMethodName      = "/interaction.InteractionService/AddComment"
-	InteractionService_GetPostComments_FullMethodName = "/interaction.InteractionService/GetPostComments"
-	InteractionService_GetPostStats_FullMethodName    = "/interaction.InteractionService/GetPostStats"
-	InteractionService_GetPostsStats_FullMethodName   = "/interaction.InteractionService/GetPostsStats"
-	InteractionService_HealthCheck_FullMethodName     = "/interaction.InteractionService/HealthCheck"
+	InteractionService_LikePost_FullMethodName            = "/interaction.InteractionService/LikePost"
+	InteractionService_UnlikePost_FullMethodName          = "/interaction.InteractionService/UnlikePost"
+	InteractionService_GetPostLikes_FullMethodName        = "/interaction.InteractionService/GetPostLikes"
+	InteractionService_CheckUserLiked_FullMethodName      = "/interaction.InteractionService/CheckUserLiked"
+	InteractionService_CheckUserLikedPosts_FullMethodName = "/interaction.InteractionService/CheckUserLikedPosts"
+	InteractionService_AddComment_FullMethodName          = "/interaction.InteractionService/AddComment"
+	InteractionService_GetPostComments_FullMethodName     = "/interaction.InteractionService/GetPostComments"
+	InteractionService_GetPostStats_FullMethodName        = "/interaction.InteractionService/GetPostStats"
+	InteractionService_GetPostsStats_FullMethodName       = "/interaction.InteractionService/GetPostsStats"
+	InteractionService_HealthCheck_FullMethodName         = "/interaction.InteractionService/HealthCheck"
 )
 
 // InteractionServiceClient is the client API for InteractionService service.
@@ -41,6 +42,7 @@ type InteractionServiceClient interface {
 	UnlikePost(ctx context.Context, in *UnlikePostRequest, opts ...grpc.CallOption) (*UnlikePostResponse, error)
 	GetPostLikes(ctx context.Context, in *GetPostLikesRequest, opts ...grpc.CallOption) (*PostLikesResponse, error)
 	CheckUserLiked(ctx context.Context, in *CheckUserLikedRequest, opts ...grpc.CallOption) (*CheckUserLikedResponse, error)
+	CheckUserLikedPosts(ctx context.Context, in *CheckUserLikedPostsRequest, opts ...grpc.CallOption) (*CheckUserLikedPostsResponse, error)
 	// Комментарии
 	AddComment(ctx context.Context, in *AddCommentRequest, opts ...grpc.CallOption) (*AddCommentResponse, error)
 	GetPostComments(ctx context.Context, in *GetPostCommentsRequest, opts ...grpc.CallOption) (*PostCommentsResponse, error)
@@ -99,6 +101,16 @@ func (c *interactionServiceClient) CheckUserLiked(ctx context.Context, in *Check
 	return out, nil
 }
 
+func (c *interactionServiceClient) CheckUserLikedPosts(ctx context.Context, in *CheckUserLikedPostsRequest, opts ...grpc.CallOption) (*CheckUserLikedPostsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckUserLikedPostsResponse)
+	err := c.cc.Invoke(ctx, InteractionService_CheckUserLikedPosts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *interactionServiceClient) AddComment(ctx context.Context, in *AddCommentRequest, opts ...grpc.CallOption) (*AddCommentResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(AddCommentResponse)
@@ -160,6 +172,7 @@ type InteractionServiceServer interface {
 	UnlikePost(context.Context, *UnlikePostRequest) (*UnlikePostResponse, error)
 	GetPostLikes(context.Context, *GetPostLikesRequest) (*PostLikesResponse, error)
 	CheckUserLiked(context.Context, *CheckUserLikedRequest) (*CheckUserLikedResponse, error)
+	CheckUserLikedPosts(context.Context, *CheckUserLikedPostsRequest) (*CheckUserLikedPostsResponse, error)
 	// Комментарии
 	AddComment(context.Context, *AddCommentRequest) (*AddCommentResponse, error)
 	GetPostComments(context.Context, *GetPostCommentsRequest) (*PostCommentsResponse, error)
@@ -190,6 +203,9 @@ func (UnimplementedInteractionServiceServer) GetPostLikes(context.Context, *GetP
 func (UnimplementedInteractionServiceServer) CheckUserLiked(context.Context, *CheckUserLikedRequest) (*CheckUserLikedResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CheckUserLiked not implemented")
 }
+func (UnimplementedInteractionServiceServer) CheckUserLikedPosts(context.Context, *CheckUserLikedPostsRequest) (*CheckUserLikedPostsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckUserLikedPosts not implemented")
+}
 func (UnimplementedInteractionServiceServer) AddComment(context.Context, *AddCommentRequest) (*AddCommentResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddComment not implemented")
 }
@@ -298,6 +314,24 @@ func _InteractionService_CheckUserLiked_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _InteractionService_CheckUserLikedPosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckUserLikedPostsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractionServiceServer).CheckUserLikedPosts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractionService_CheckUserLikedPosts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractionServiceServer).CheckUserLikedPosts(ctx, req.(*CheckUserLikedPostsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _InteractionService_AddComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AddCommentRequest)
 	if err := dec(in); err != nil {
@@ -411,6 +445,10 @@ var InteractionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CheckUserLiked",
 			Handler:    _InteractionService_CheckUserLiked_Handler,
 		},
+		{
+			MethodName: "CheckUserLikedPosts",
+			Handler:    _InteractionService_CheckUserLikedPosts_Handler,
+		},
 		{
 			MethodName: "AddComment",
 			Handler:    _InteractionService_AddComment_Handler,
@@ -77,6 +77,71 @@ func (MediaType) EnumDescriptor() ([]byte, []int) {
 	return file_media_media_proto_rawDescGZIP(), []int{0}
 }
 
+// Статус обработки загруженного файла
+type MediaStatus int32
+
+const (
+	MediaStatus_MEDIA_STATUS_UNKNOWN            MediaStatus = 0
+	MediaStatus_MEDIA_STATUS_PENDING            MediaStatus = 1 // URL для загрузки выдан, загрузка не подтверждена
+	MediaStatus_MEDIA_STATUS_PENDING_PROCESSING MediaStatus = 2 // Загрузка подтверждена, файл в очереди на обработку
+	MediaStatus_MEDIA_STATUS_READY              MediaStatus = 3 // Файл проверен, очищен от EXIF, варианты созданы
+	MediaStatus_MEDIA_STATUS_FAILED             MediaStatus = 4 // Файл не является поддерживаемым изображением
+	MediaStatus_MEDIA_STATUS_UPLOADED           MediaStatus = 5 // Файл загружен, но загрузка не подтверждена до истечения URL
+	MediaStatus_MEDIA_STATUS_ORPHANED           MediaStatus = 6 // На медиа не ссылаются посты, персонажи и миры, ожидает удаления
+	MediaStatus_MEDIA_STATUS_DELETED            MediaStatus = 7 // Медиа удалено, объекты удалены из хранилища
+)
+
+// Enum value maps for MediaStatus.
+var (
+	MediaStatus_name = map[int32]string{
+		0: "MEDIA_STATUS_UNKNOWN",
+		1: "MEDIA_STATUS_PENDING",
+		2: "MEDIA_STATUS_PENDING_PROCESSING",
+		3: "MEDIA_STATUS_READY",
+		4: "MEDIA_STATUS_FAILED",
+		5: "MEDIA_STATUS_UPLOADED",
+		6: "MEDIA_STATUS_ORPHANED",
+		7: "MEDIA_STATUS_DELETED",
+	}
+	MediaStatus_value = map[string]int32{
+		"MEDIA_STATUS_UNKNOWN":            0,
+		"MEDIA_STATUS_PENDING":            1,
+		"MEDIA_STATUS_PENDING_PROCESSING": 2,
+		"MEDIA_STATUS_READY":              3,
+		"MEDIA_STATUS_FAILED":             4,
+		"MEDIA_STATUS_UPLOADED":           5,
+		"MEDIA_STATUS_ORPHANED":           6,
+		"MEDIA_STATUS_DELETED":            7,
+	}
+)
+
+func (x MediaStatus) Enum() *MediaStatus {
+	p := new(MediaStatus)
+	*p = x
+	return p
+}
+
+func (x MediaStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MediaStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_media_media_proto_enumTypes[1].Descriptor()
+}
+
+func (MediaStatus) Type() protoreflect.EnumType {
+	return &file_media_media_proto_enumTypes[1]
+}
+
+func (x MediaStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MediaStatus.Descriptor instead.
+func (MediaStatus) EnumDescriptor() ([]byte, []int) {
+	return file_media_media_proto_rawDescGZIP(), []int{1}
+}
+
 type HealthCheckResponse_Status int32
 
 const (
@@ -110,11 +175,11 @@ func (x HealthCheckResponse_Status) String() string {
 }
 
 func (HealthCheckResponse_Status) Descriptor() protoreflect.EnumDescriptor {
-	return file_media_media_proto_enumTypes[1].Descriptor()
+	return file_media_media_proto_enumTypes[2].Descriptor()
 }
 
 func (HealthCheckResponse_Status) Type() protoreflect.EnumType {
-	return &file_media_media_proto_enumTypes[1]
+	return &file_media_media_proto_enumTypes[2]
 }
 
 func (x HealthCheckResponse_Status) Number() protoreflect.EnumNumber {
@@ -123,7 +188,7 @@ func (x HealthCheckResponse_Status) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use HealthCheckResponse_Status.Descriptor instead.
 func (HealthCheckResponse_Status) EnumDescriptor() ([]byte, []int) {
-	return file_media_media_proto_rawDescGZIP(), []int{13, 0}
+	return file_media_media_proto_rawDescGZIP(), []int{16, 0}
 }
 
 type MediaMetadata struct {
@@ -204,7 +269,7 @@ func (x *MediaMetadata) GetSize() int64 {
 
 type MediaVariant struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // original, thumbnail, medium
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // original, thumb, small, medium, large и WebP-версии PNG-вариантов (medium_webp)
 	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
 	Width         int32                  `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`
 	Height        int32                  `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
@@ -315,18 +380,24 @@ func (x *GetMediaRequest) GetMediaId() string {
 }
 
 type Media struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	MediaId       string                 `protobuf:"bytes,1,opt,name=media_id,json=mediaId,proto3" json:"media_id,omitempty"`
-	CharacterId   string                 `protobuf:"bytes,2,opt,name=character_id,json=characterId,proto3" json:"character_id,omitempty"` // Optional for world-level media
-	WorldId       string                 `protobuf:"bytes,3,opt,name=world_id,json=worldId,proto3" json:"world_id,omitempty"`
-	Filename      string                 `protobuf:"bytes,4,opt,name=filename,proto3" json:"filename,omitempty"`
-	ContentType   string                 `protobuf:"bytes,5,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
-	Size          int64                  `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`
-	Variants      []*MediaVariant        `protobuf:"bytes,7,rep,name=variants,proto3" json:"variants,omitempty"`
-	CreatedAt     string                 `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // ISO 8601 format
-	MediaType     MediaType              `protobuf:"varint,9,opt,name=media_type,json=mediaType,proto3,enum=media.MediaType" json:"media_type,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	MediaId         string                 `protobuf:"bytes,1,opt,name=media_id,json=mediaId,proto3" json:"media_id,omitempty"`
+	CharacterId     string                 `protobuf:"bytes,2,opt,name=character_id,json=characterId,proto3" json:"character_id,omitempty"` // Optional for world-level media
+	WorldId         string                 `protobuf:"bytes,3,opt,name=world_id,json=worldId,proto3" json:"world_id,omitempty"`
+	Filename        string                 `protobuf:"bytes,4,opt,name=filename,proto3" json:"filename,omitempty"`
+	ContentType     string                 `protobuf:"bytes,5,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Size            int64                  `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`
+	Variants        []*MediaVariant        `protobuf:"bytes,7,rep,name=variants,proto3" json:"variants,omitempty"`
+	CreatedAt       string                 `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // ISO 8601 format
+	MediaType       MediaType              `protobuf:"varint,9,opt,name=media_type,json=mediaType,proto3,enum=media.MediaType" json:"media_type,omitempty"`
+	Status          MediaStatus            `protobuf:"varint,10,opt,name=status,proto3,enum=media.MediaStatus" json:"status,omitempty"` // Статус обработки, клиенты опрашивают его после ConfirmUpload
+	Width           int32                  `protobuf:"varint,11,opt,name=width,proto3" json:"width,omitempty"`                          // Размеры оригинала, заполняются после обработки
+	Height          int32                  `protobuf:"varint,12,opt,name=height,proto3" json:"height,omitempty"`
+	ProcessingError string                 `protobuf:"bytes,13,opt,name=processing_error,json=processingError,proto3" json:"processing_error,omitempty"` // Причина ошибки для статуса MEDIA_STATUS_FAILED
+	Blurhash        string                 `protobuf:"bytes,14,opt,name=blurhash,proto3" json:"blurhash,omitempty"`                                      // Плейсхолдер (https://blurha.sh), заполняется после обработки
+	DominantColor   string                 `protobuf:"bytes,15,opt,name=dominant_color,json=dominantColor,proto3" json:"dominant_color,omitempty"`       // Основной цвет изображения, #rrggbb
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *Media) Reset() {
@@ -422,10 +493,52 @@ func (x *Media) GetMediaType() MediaType {
 	return MediaType_MEDIA_TYPE_UNKNOWN
 }
 
+func (x *Media) GetStatus() MediaStatus {
+	if x != nil {
+		return x.Status
+	}
+	return MediaStatus_MEDIA_STATUS_UNKNOWN
+}
+
+func (x *Media) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Media) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Media) GetProcessingError() string {
+	if x != nil {
+		return x.ProcessingError
+	}
+	return ""
+}
+
+func (x *Media) GetBlurhash() string {
+	if x != nil {
+		return x.Blurhash
+	}
+	return ""
+}
+
+func (x *Media) GetDominantColor() string {
+	if x != nil {
+		return x.DominantColor
+	}
+	return ""
+}
+
 type GetMediaURLRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	MediaId       string                 `protobuf:"bytes,1,opt,name=media_id,json=mediaId,proto3" json:"media_id,omitempty"`
-	Variant       string                 `protobuf:"bytes,2,opt,name=variant,proto3" json:"variant,omitempty"`                       // original, thumbnail, medium
+	Variant       string                 `protobuf:"bytes,2,opt,name=variant,proto3" json:"variant,omitempty"`                       // original, thumb, small, medium, large, medium_webp и т.д. (без WebP-версии - базовый вариант)
 	ExpiresIn     int64                  `protobuf:"varint,3,opt,name=expires_in,json=expiresIn,proto3" json:"expires_in,omitempty"` // Время жизни URL в секундах
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -483,9 +596,14 @@ func (x *GetMediaURLRequest) GetExpiresIn() int64 {
 }
 
 type GetMediaURLResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
-	ExpiresAt     int64                  `protobuf:"varint,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // Unix timestamp
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Url       string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	ExpiresAt int64                  `protobuf:"varint,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // Unix timestamp
+	// Плейсхолдер оригинала, чтобы клиент мог отрисовать блок до загрузки изображения
+	Width         int32  `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32  `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	Blurhash      string `protobuf:"bytes,5,opt,name=blurhash,proto3" json:"blurhash,omitempty"`
+	DominantColor string `protobuf:"bytes,6,opt,name=dominant_color,json=dominantColor,proto3" json:"dominant_color,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -534,10 +652,38 @@ func (x *GetMediaURLResponse) GetExpiresAt() int64 {
 	return 0
 }
 
+func (x *GetMediaURLResponse) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *GetMediaURLResponse) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *GetMediaURLResponse) GetBlurhash() string {
+	if x != nil {
+		return x.Blurhash
+	}
+	return ""
+}
+
+func (x *GetMediaURLResponse) GetDominantColor() string {
+	if x != nil {
+		return x.DominantColor
+	}
+	return ""
+}
+
 type OptimizeImageRequest struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	MediaId          string                 `protobuf:"bytes,1,opt,name=media_id,json=mediaId,proto3" json:"media_id,omitempty"`
-	VariantsToCreate []string               `protobuf:"bytes,2,rep,name=variants_to_create,json=variantsToCreate,proto3" json:"variants_to_create,omitempty"` // thumbnail, medium, etc.
+	VariantsToCreate []string               `protobuf:"bytes,2,rep,name=variants_to_create,json=variantsToCreate,proto3" json:"variants_to_create,omitempty"` // thumb, small, medium, large; пустой список - все настроенные варианты
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
@@ -638,6 +784,7 @@ type GetPresignedUploadURLRequest struct {
 	ContentType   string                 `protobuf:"bytes,4,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
 	Size          int64                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
 	MediaType     MediaType              `protobuf:"varint,6,opt,name=media_type,json=mediaType,proto3,enum=media.MediaType" json:"media_type,omitempty"`
+	UserId        string                 `protobuf:"bytes,7,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // Загружающий пользователь, учитывается в его квоте; пусто для медиа от AI
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -714,6 +861,13 @@ func (x *GetPresignedUploadURLRequest) GetMediaType() MediaType {
 	return MediaType_MEDIA_TYPE_UNKNOWN
 }
 
+func (x *GetPresignedUploadURLRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
 type GetPresignedUploadURLResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	MediaId       string                 `protobuf:"bytes,1,opt,name=media_id,json=mediaId,proto3" json:"media_id,omitempty"`
@@ -774,6 +928,170 @@ func (x *GetPresignedUploadURLResponse) GetExpiresAt() int64 {
 	return 0
 }
 
+type GetStorageUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`    // Необязательно
+	WorldId       string                 `protobuf:"bytes,2,opt,name=world_id,json=worldId,proto3" json:"world_id,omitempty"` // Необязательно
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStorageUsageRequest) Reset() {
+	*x = GetStorageUsageRequest{}
+	mi := &file_media_media_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStorageUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStorageUsageRequest) ProtoMessage() {}
+
+func (x *GetStorageUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_media_media_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStorageUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetStorageUsageRequest) Descriptor() ([]byte, []int) {
+	return file_media_media_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetStorageUsageRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetStorageUsageRequest) GetWorldId() string {
+	if x != nil {
+		return x.WorldId
+	}
+	return ""
+}
+
+type StorageUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UsedBytes     int64                  `protobuf:"varint,1,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`    // Каждое медиа учитывается со своим размером, даже если файл общий с дубликатами
+	QuotaBytes    int64                  `protobuf:"varint,2,opt,name=quota_bytes,json=quotaBytes,proto3" json:"quota_bytes,omitempty"` // 0 - без ограничения
+	MediaCount    int64                  `protobuf:"varint,3,opt,name=media_count,json=mediaCount,proto3" json:"media_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StorageUsage) Reset() {
+	*x = StorageUsage{}
+	mi := &file_media_media_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorageUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageUsage) ProtoMessage() {}
+
+func (x *StorageUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_media_media_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageUsage.ProtoReflect.Descriptor instead.
+func (*StorageUsage) Descriptor() ([]byte, []int) {
+	return file_media_media_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *StorageUsage) GetUsedBytes() int64 {
+	if x != nil {
+		return x.UsedBytes
+	}
+	return 0
+}
+
+func (x *StorageUsage) GetQuotaBytes() int64 {
+	if x != nil {
+		return x.QuotaBytes
+	}
+	return 0
+}
+
+func (x *StorageUsage) GetMediaCount() int64 {
+	if x != nil {
+		return x.MediaCount
+	}
+	return 0
+}
+
+type GetStorageUsageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *StorageUsage          `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`   // Не заполняется без user_id
+	World         *StorageUsage          `protobuf:"bytes,2,opt,name=world,proto3" json:"world,omitempty"` // Не заполняется без world_id
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStorageUsageResponse) Reset() {
+	*x = GetStorageUsageResponse{}
+	mi := &file_media_media_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStorageUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStorageUsageResponse) ProtoMessage() {}
+
+func (x *GetStorageUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_media_media_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStorageUsageResponse.ProtoReflect.Descriptor instead.
+func (*GetStorageUsageResponse) Descriptor() ([]byte, []int) {
+	return file_media_media_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetStorageUsageResponse) GetUser() *StorageUsage {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *GetStorageUsageResponse) GetWorld() *StorageUsage {
+	if x != nil {
+		return x.World
+	}
+	return nil
+}
+
 type ConfirmUploadRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	MediaId       string                 `protobuf:"bytes,1,opt,name=media_id,json=mediaId,proto3" json:"media_id,omitempty"`
@@ -783,7 +1101,7 @@ type ConfirmUploadRequest struct {
 
 func (x *ConfirmUploadRequest) Reset() {
 	*x = ConfirmUploadRequest{}
-	mi := &file_media_media_proto_msgTypes[10]
+	mi := &file_media_media_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -795,7 +1113,7 @@ func (x *ConfirmUploadRequest) String() string {
 func (*ConfirmUploadRequest) ProtoMessage() {}
 
 func (x *ConfirmUploadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_media_media_proto_msgTypes[10]
+	mi := &file_media_media_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -808,7 +1126,7 @@ func (x *ConfirmUploadRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConfirmUploadRequest.ProtoReflect.Descriptor instead.
 func (*ConfirmUploadRequest) Descriptor() ([]byte, []int) {
-	return file_media_media_proto_rawDescGZIP(), []int{10}
+	return file_media_media_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *ConfirmUploadRequest) GetMediaId() string {
@@ -822,13 +1140,14 @@ type ConfirmUploadResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Variants      []*MediaVariant        `protobuf:"bytes,2,rep,name=variants,proto3" json:"variants,omitempty"`
+	Status        MediaStatus            `protobuf:"varint,3,opt,name=status,proto3,enum=media.MediaStatus" json:"status,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ConfirmUploadResponse) Reset() {
 	*x = ConfirmUploadResponse{}
-	mi := &file_media_media_proto_msgTypes[11]
+	mi := &file_media_media_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -840,7 +1159,7 @@ func (x *ConfirmUploadResponse) String() string {
 func (*ConfirmUploadResponse) ProtoMessage() {}
 
 func (x *ConfirmUploadResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_media_media_proto_msgTypes[11]
+	mi := &file_media_media_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -853,7 +1172,7 @@ func (x *ConfirmUploadResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConfirmUploadResponse.ProtoReflect.Descriptor instead.
 func (*ConfirmUploadResponse) Descriptor() ([]byte, []int) {
-	return file_media_media_proto_rawDescGZIP(), []int{11}
+	return file_media_media_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *ConfirmUploadResponse) GetSuccess() bool {
@@ -870,6 +1189,13 @@ func (x *ConfirmUploadResponse) GetVariants() []*MediaVariant {
 	return nil
 }
 
+func (x *ConfirmUploadResponse) GetStatus() MediaStatus {
+	if x != nil {
+		return x.Status
+	}
+	return MediaStatus_MEDIA_STATUS_UNKNOWN
+}
+
 type HealthCheckRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -878,7 +1204,7 @@ type HealthCheckRequest struct {
 
 func (x *HealthCheckRequest) Reset() {
 	*x = HealthCheckRequest{}
-	mi := &file_media_media_proto_msgTypes[12]
+	mi := &file_media_media_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -890,7 +1216,7 @@ func (x *HealthCheckRequest) String() string {
 func (*HealthCheckRequest) ProtoMessage() {}
 
 func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_media_media_proto_msgTypes[12]
+	mi := &file_media_media_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -903,7 +1229,7 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
 func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_media_media_proto_rawDescGZIP(), []int{12}
+	return file_media_media_proto_rawDescGZIP(), []int{15}
 }
 
 type HealthCheckResponse struct {
@@ -915,7 +1241,7 @@ type HealthCheckResponse struct {
 
 func (x *HealthCheckResponse) Reset() {
 	*x = HealthCheckResponse{}
-	mi := &file_media_media_proto_msgTypes[13]
+	mi := &file_media_media_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -927,7 +1253,7 @@ func (x *HealthCheckResponse) String() string {
 func (*HealthCheckResponse) ProtoMessage() {}
 
 func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_media_media_proto_msgTypes[13]
+	mi := &file_media_media_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -940,7 +1266,7 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
 func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_media_media_proto_rawDescGZIP(), []int{13}
+	return file_media_media_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *HealthCheckResponse) GetStatus() HealthCheckResponse_Status {
@@ -967,7 +1293,7 @@ const file_media_media_proto_rawDesc = "" +
 	"\x05width\x18\x03 \x01(\x05R\x05width\x12\x16\n" +
 	"\x06height\x18\x04 \x01(\x05R\x06height\",\n" +
 	"\x0fGetMediaRequest\x12\x19\n" +
-	"\bmedia_id\x18\x01 \x01(\tR\amediaId\"\xb4\x02\n" +
+	"\bmedia_id\x18\x01 \x01(\tR\amediaId\"\xfc\x03\n" +
 	"\x05Media\x12\x19\n" +
 	"\bmedia_id\x18\x01 \x01(\tR\amediaId\x12!\n" +
 	"\fcharacter_id\x18\x02 \x01(\tR\vcharacterId\x12\x19\n" +
@@ -979,21 +1305,32 @@ const file_media_media_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\b \x01(\tR\tcreatedAt\x12/\n" +
 	"\n" +
-	"media_type\x18\t \x01(\x0e2\x10.media.MediaTypeR\tmediaType\"h\n" +
+	"media_type\x18\t \x01(\x0e2\x10.media.MediaTypeR\tmediaType\x12*\n" +
+	"\x06status\x18\n" +
+	" \x01(\x0e2\x12.media.MediaStatusR\x06status\x12\x14\n" +
+	"\x05width\x18\v \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\f \x01(\x05R\x06height\x12)\n" +
+	"\x10processing_error\x18\r \x01(\tR\x0fprocessingError\x12\x1a\n" +
+	"\bblurhash\x18\x0e \x01(\tR\bblurhash\x12%\n" +
+	"\x0edominant_color\x18\x0f \x01(\tR\rdominantColor\"h\n" +
 	"\x12GetMediaURLRequest\x12\x19\n" +
 	"\bmedia_id\x18\x01 \x01(\tR\amediaId\x12\x18\n" +
 	"\avariant\x18\x02 \x01(\tR\avariant\x12\x1d\n" +
 	"\n" +
-	"expires_in\x18\x03 \x01(\x03R\texpiresIn\"F\n" +
+	"expires_in\x18\x03 \x01(\x03R\texpiresIn\"\xb7\x01\n" +
 	"\x13GetMediaURLResponse\x12\x10\n" +
 	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1d\n" +
 	"\n" +
-	"expires_at\x18\x02 \x01(\x03R\texpiresAt\"_\n" +
+	"expires_at\x18\x02 \x01(\x03R\texpiresAt\x12\x14\n" +
+	"\x05width\x18\x03 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x04 \x01(\x05R\x06height\x12\x1a\n" +
+	"\bblurhash\x18\x05 \x01(\tR\bblurhash\x12%\n" +
+	"\x0edominant_color\x18\x06 \x01(\tR\rdominantColor\"_\n" +
 	"\x14OptimizeImageRequest\x12\x19\n" +
 	"\bmedia_id\x18\x01 \x01(\tR\amediaId\x12,\n" +
 	"\x12variants_to_create\x18\x02 \x03(\tR\x10variantsToCreate\"H\n" +
 	"\x15OptimizeImageResponse\x12/\n" +
-	"\bvariants\x18\x01 \x03(\v2\x13.media.MediaVariantR\bvariants\"\xe0\x01\n" +
+	"\bvariants\x18\x01 \x03(\v2\x13.media.MediaVariantR\bvariants\"\xf9\x01\n" +
 	"\x1cGetPresignedUploadURLRequest\x12\x19\n" +
 	"\bworld_id\x18\x01 \x01(\tR\aworldId\x12!\n" +
 	"\fcharacter_id\x18\x02 \x01(\tR\vcharacterId\x12\x1a\n" +
@@ -1001,18 +1338,33 @@ const file_media_media_proto_rawDesc = "" +
 	"\fcontent_type\x18\x04 \x01(\tR\vcontentType\x12\x12\n" +
 	"\x04size\x18\x05 \x01(\x03R\x04size\x12/\n" +
 	"\n" +
-	"media_type\x18\x06 \x01(\x0e2\x10.media.MediaTypeR\tmediaType\"x\n" +
+	"media_type\x18\x06 \x01(\x0e2\x10.media.MediaTypeR\tmediaType\x12\x17\n" +
+	"\auser_id\x18\a \x01(\tR\x06userId\"x\n" +
 	"\x1dGetPresignedUploadURLResponse\x12\x19\n" +
 	"\bmedia_id\x18\x01 \x01(\tR\amediaId\x12\x1d\n" +
 	"\n" +
 	"upload_url\x18\x02 \x01(\tR\tuploadUrl\x12\x1d\n" +
 	"\n" +
-	"expires_at\x18\x03 \x01(\x03R\texpiresAt\"1\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\"L\n" +
+	"\x16GetStorageUsageRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
+	"\bworld_id\x18\x02 \x01(\tR\aworldId\"o\n" +
+	"\fStorageUsage\x12\x1d\n" +
+	"\n" +
+	"used_bytes\x18\x01 \x01(\x03R\tusedBytes\x12\x1f\n" +
+	"\vquota_bytes\x18\x02 \x01(\x03R\n" +
+	"quotaBytes\x12\x1f\n" +
+	"\vmedia_count\x18\x03 \x01(\x03R\n" +
+	"mediaCount\"m\n" +
+	"\x17GetStorageUsageResponse\x12'\n" +
+	"\x04user\x18\x01 \x01(\v2\x13.media.StorageUsageR\x04user\x12)\n" +
+	"\x05world\x18\x02 \x01(\v2\x13.media.StorageUsageR\x05world\"1\n" +
 	"\x14ConfirmUploadRequest\x12\x19\n" +
-	"\bmedia_id\x18\x01 \x01(\tR\amediaId\"b\n" +
+	"\bmedia_id\x18\x01 \x01(\tR\amediaId\"\x8e\x01\n" +
 	"\x15ConfirmUploadResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12/\n" +
-	"\bvariants\x18\x02 \x03(\v2\x13.media.MediaVariantR\bvariants\"\x14\n" +
+	"\bvariants\x18\x02 \x03(\v2\x13.media.MediaVariantR\bvariants\x12*\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x12.media.MediaStatusR\x06status\"\x14\n" +
 	"\x12HealthCheckRequest\"\x85\x01\n" +
 	"\x13HealthCheckResponse\x129\n" +
 	"\x06status\x18\x01 \x01(\x0e2!.media.HealthCheckResponse.StatusR\x06status\"3\n" +
@@ -1025,13 +1377,23 @@ const file_media_media_proto_rawDesc = "" +
 	"\x17MEDIA_TYPE_WORLD_HEADER\x10\x01\x12\x19\n" +
 	"\x15MEDIA_TYPE_WORLD_ICON\x10\x02\x12\x1f\n" +
 	"\x1bMEDIA_TYPE_CHARACTER_AVATAR\x10\x03\x12\x19\n" +
-	"\x15MEDIA_TYPE_POST_IMAGE\x10\x042\xc8\x03\n" +
+	"\x15MEDIA_TYPE_POST_IMAGE\x10\x04*\xe7\x01\n" +
+	"\vMediaStatus\x12\x18\n" +
+	"\x14MEDIA_STATUS_UNKNOWN\x10\x00\x12\x18\n" +
+	"\x14MEDIA_STATUS_PENDING\x10\x01\x12#\n" +
+	"\x1fMEDIA_STATUS_PENDING_PROCESSING\x10\x02\x12\x16\n" +
+	"\x12MEDIA_STATUS_READY\x10\x03\x12\x17\n" +
+	"\x13MEDIA_STATUS_FAILED\x10\x04\x12\x19\n" +
+	"\x15MEDIA_STATUS_UPLOADED\x10\x05\x12\x19\n" +
+	"\x15MEDIA_STATUS_ORPHANED\x10\x06\x12\x18\n" +
+	"\x14MEDIA_STATUS_DELETED\x10\a2\x9a\x04\n" +
 	"\fMediaService\x12b\n" +
 	"\x15GetPresignedUploadURL\x12#.media.GetPresignedUploadURLRequest\x1a$.media.GetPresignedUploadURLResponse\x12J\n" +
 	"\rConfirmUpload\x12\x1b.media.ConfirmUploadRequest\x1a\x1c.media.ConfirmUploadResponse\x120\n" +
 	"\bGetMedia\x12\x16.media.GetMediaRequest\x1a\f.media.Media\x12D\n" +
 	"\vGetMediaURL\x12\x19.media.GetMediaURLRequest\x1a\x1a.media.GetMediaURLResponse\x12J\n" +
-	"\rOptimizeImage\x12\x1b.media.OptimizeImageRequest\x1a\x1c.media.OptimizeImageResponse\x12D\n" +
+	"\rOptimizeImage\x12\x1b.media.OptimizeImageRequest\x1a\x1c.media.OptimizeImageResponse\x12P\n" +
+	"\x0fGetStorageUsage\x12\x1d.media.GetStorageUsageRequest\x1a\x1e.media.GetStorageUsageResponse\x12D\n" +
 	"\vHealthCheck\x12\x19.media.HealthCheckRequest\x1a\x1a.media.HealthCheckResponseB-Z+github.com/sdshorin/generia/api/proto/mediab\x06proto3"
 
 var (
@@ -1046,50 +1408,60 @@ func file_media_media_proto_rawDescGZIP() []byte {
 	return file_media_media_proto_rawDescData
 }
 
-var file_media_media_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_media_media_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_media_media_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_media_media_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
 var file_media_media_proto_goTypes = []any{
 	(MediaType)(0),                        // 0: media.MediaType
-	(HealthCheckResponse_Status)(0),       // 1: media.HealthCheckResponse.Status
-	(*MediaMetadata)(nil),                 // 2: media.MediaMetadata
-	(*MediaVariant)(nil),                  // 3: media.MediaVariant
-	(*GetMediaRequest)(nil),               // 4: media.GetMediaRequest
-	(*Media)(nil),                         // 5: media.Media
-	(*GetMediaURLRequest)(nil),            // 6: media.GetMediaURLRequest
-	(*GetMediaURLResponse)(nil),           // 7: media.GetMediaURLResponse
-	(*OptimizeImageRequest)(nil),          // 8: media.OptimizeImageRequest
-	(*OptimizeImageResponse)(nil),         // 9: media.OptimizeImageResponse
-	(*GetPresignedUploadURLRequest)(nil),  // 10: media.GetPresignedUploadURLRequest
-	(*GetPresignedUploadURLResponse)(nil), // 11: media.GetPresignedUploadURLResponse
-	(*ConfirmUploadRequest)(nil),          // 12: media.ConfirmUploadRequest
-	(*ConfirmUploadResponse)(nil),         // 13: media.ConfirmUploadResponse
-	(*HealthCheckRequest)(nil),            // 14: media.HealthCheckRequest
-	(*HealthCheckResponse)(nil),           // 15: media.HealthCheckResponse
+	(MediaStatus)(0),                      // 1: media.MediaStatus
+	(HealthCheckResponse_Status)(0),       // 2: media.HealthCheckResponse.Status
+	(*MediaMetadata)(nil),                 // 3: media.MediaMetadata
+	(*MediaVariant)(nil),                  // 4: media.MediaVariant
+	(*GetMediaRequest)(nil),               // 5: media.GetMediaRequest
+	(*Media)(nil),                         // 6: media.Media
+	(*GetMediaURLRequest)(nil),            // 7: media.GetMediaURLRequest
+	(*GetMediaURLResponse)(nil),           // 8: media.GetMediaURLResponse
+	(*OptimizeImageRequest)(nil),          // 9: media.OptimizeImageRequest
+	(*OptimizeImageResponse)(nil),         // 10: media.OptimizeImageResponse
+	(*GetPresignedUploadURLRequest)(nil),  // 11: media.GetPresignedUploadURLRequest
+	(*GetPresignedUploadURLResponse)(nil), // 12: media.GetPresignedUploadURLResponse
+	(*GetStorageUsageRequest)(nil),        // 13: media.GetStorageUsageRequest
+	(*StorageUsage)(nil),                  // 14: media.StorageUsage
+	(*GetStorageUsageResponse)(nil),       // 15: media.GetStorageUsageResponse
+	(*ConfirmUploadRequest)(nil),          // 16: media.ConfirmUploadRequest
+	(*ConfirmUploadResponse)(nil),         // 17: media.ConfirmUploadResponse
+	(*HealthCheckRequest)(nil),            // 18: media.HealthCheckRequest
+	(*HealthCheckResponse)(nil),           // 19: media.HealthCheckResponse
 }
 var file_media_media_proto_depIdxs = []int32{
-	3,  // 0: media.Media.variants:type_name -> media.MediaVariant
+	4,  // 0: media.Media.variants:type_name -> media.MediaVariant
 	0,  // 1: media.Media.media_type:type_name -> media.MediaType
-	3,  // 2: media.OptimizeImageResponse.variants:type_name -> media.MediaVariant
-	0,  // 3: media.GetPresignedUploadURLRequest.media_type:type_name -> media.MediaType
-	3,  // 4: media.ConfirmUploadResponse.variants:type_name -> media.MediaVariant
-	1,  // 5: media.HealthCheckResponse.status:type_name -> media.HealthCheckResponse.Status
-	10, // 6: media.MediaService.GetPresignedUploadURL:input_type -> media.GetPresignedUploadURLRequest
-	12, // 7: media.MediaService.ConfirmUpload:input_type -> media.ConfirmUploadRequest
-	4,  // 8: media.MediaService.GetMedia:input_type -> media.GetMediaRequest
-	6,  // 9: media.MediaService.GetMediaURL:input_type -> media.GetMediaURLRequest
-	8,  // 10: media.MediaService.OptimizeImage:input_type -> media.OptimizeImageRequest
-	14, // 11: media.MediaService.HealthCheck:input_type -> media.HealthCheckRequest
-	11, // 12: media.MediaService.GetPresignedUploadURL:output_type -> media.GetPresignedUploadURLResponse
-	13, // 13: media.MediaService.ConfirmUpload:output_type -> media.ConfirmUploadResponse
-	5,  // 14: media.MediaService.GetMedia:output_type -> media.Media
-	7,  // 15: media.MediaService.GetMediaURL:output_type -> media.GetMediaURLResponse
-	9,  // 16: media.MediaService.OptimizeImage:output_type -> media.OptimizeImageResponse
-	15, // 17: media.MediaService.HealthCheck:output_type -> media.HealthCheckResponse
-	12, // [12:18] is the sub-list for method output_type
-	6,  // [6:12] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	1,  // 2: media.Media.status:type_name -> media.MediaStatus
+	4,  // 3: media.OptimizeImageResponse.variants:type_name -> media.MediaVariant
+	0,  // 4: media.GetPresignedUploadURLRequest.media_type:type_name -> media.MediaType
+	14, // 5: media.GetStorageUsageResponse.user:type_name -> media.StorageUsage
+	14, // 6: media.GetStorageUsageResponse.world:type_name -> media.StorageUsage
+	4,  // 7: media.ConfirmUploadResponse.variants:type_name -> media.MediaVariant
+	1,  // 8: media.ConfirmUploadResponse.status:type_name -> media.MediaStatus
+	2,  // 9: media.HealthCheckResponse.status:type_name -> media.HealthCheckResponse.Status
+	11, // 10: media.MediaService.GetPresignedUploadURL:input_type -> media.GetPresignedUploadURLRequest
+	16, // 11: media.MediaService.ConfirmUpload:input_type -> media.ConfirmUploadRequest
+	5,  // 12: media.MediaService.GetMedia:input_type -> media.GetMediaRequest
+	7,  // 13: media.MediaService.GetMediaURL:input_type -> media.GetMediaURLRequest
+	9,  // 14: media.MediaService.OptimizeImage:input_type -> media.OptimizeImageRequest
+	13, // 15: media.MediaService.GetStorageUsage:input_type -> media.GetStorageUsageRequest
+	18, // 16: media.MediaService.HealthCheck:input_type -> media.HealthCheckRequest
+	12, // 17: media.MediaService.GetPresignedUploadURL:output_type -> media.GetPresignedUploadURLResponse
+	17, // 18: media.MediaService.ConfirmUpload:output_type -> media.ConfirmUploadResponse
+	6,  // 19: media.MediaService.GetMedia:output_type -> media.Media
+	8,  // 20: media.MediaService.GetMediaURL:output_type -> media.GetMediaURLResponse
+	10, // 21: media.MediaService.OptimizeImage:output_type -> media.OptimizeImageResponse
+	15, // 22: media.MediaService.GetStorageUsage:output_type -> media.GetStorageUsageResponse
+	19, // 23: media.MediaService.HealthCheck:output_type -> media.HealthCheckResponse
+	17, // [17:24] is the sub-list for method output_type
+	10, // [10:17] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_media_media_proto_init() }
@@ -1102,8 +1474,8 @@ func file_media_media_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_media_media_proto_rawDesc), len(file_media_media_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   14,
+			NumEnums:      3,
+			NumMessages:   17,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
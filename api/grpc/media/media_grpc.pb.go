@@ -24,6 +24,7 @@ const (
 	MediaService_GetMedia_FullMethodName              = "/media.MediaService/GetMedia"
 	MediaService_GetMediaURL_FullMethodName           = "/media.MediaService/GetMediaURL"
 	MediaService_OptimizeImage_FullMethodName         = "/media.MediaService/OptimizeImage"
+	MediaService_GetStorageUsage_FullMethodName       = "/media.MediaService/GetStorageUsage"
 	MediaService_HealthCheck_FullMethodName           = "/media.MediaService/HealthCheck"
 )
 
@@ -43,6 +44,8 @@ type MediaServiceClient interface {
 	GetMediaURL(ctx context.Context, in *GetMediaURLRequest, opts ...grpc.CallOption) (*GetMediaURLResponse, error)
 	// Оптимизация изображения
 	OptimizeImage(ctx context.Context, in *OptimizeImageRequest, opts ...grpc.CallOption) (*OptimizeImageResponse, error)
+	// Использование хранилища пользователем и миром и их квоты
+	GetStorageUsage(ctx context.Context, in *GetStorageUsageRequest, opts ...grpc.CallOption) (*GetStorageUsageResponse, error)
 	// Проверка здоровья сервиса
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
 }
@@ -105,6 +108,16 @@ func (c *mediaServiceClient) OptimizeImage(ctx context.Context, in *OptimizeImag
 	return out, nil
 }
 
+func (c *mediaServiceClient) GetStorageUsage(ctx context.Context, in *GetStorageUsageRequest, opts ...grpc.CallOption) (*GetStorageUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStorageUsageResponse)
+	err := c.cc.Invoke(ctx, MediaService_GetStorageUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *mediaServiceClient) HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(HealthCheckResponse)
@@ -131,6 +144,8 @@ type MediaServiceServer interface {
 	GetMediaURL(context.Context, *GetMediaURLRequest) (*GetMediaURLResponse, error)
 	// Оптимизация изображения
 	OptimizeImage(context.Context, *OptimizeImageRequest) (*OptimizeImageResponse, error)
+	// Использование хранилища пользователем и миром и их квоты
+	GetStorageUsage(context.Context, *GetStorageUsageRequest) (*GetStorageUsageResponse, error)
 	// Проверка здоровья сервиса
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
 	mustEmbedUnimplementedMediaServiceServer()
@@ -158,6 +173,9 @@ func (UnimplementedMediaServiceServer) GetMediaURL(context.Context, *GetMediaURL
 func (UnimplementedMediaServiceServer) OptimizeImage(context.Context, *OptimizeImageRequest) (*OptimizeImageResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method OptimizeImage not implemented")
 }
+func (UnimplementedMediaServiceServer) GetStorageUsage(context.Context, *GetStorageUsageRequest) (*GetStorageUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStorageUsage not implemented")
+}
 func (UnimplementedMediaServiceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
@@ -272,6 +290,24 @@ func _MediaService_OptimizeImage_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MediaService_GetStorageUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStorageUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MediaServiceServer).GetStorageUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MediaService_GetStorageUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MediaServiceServer).GetStorageUsage(ctx, req.(*GetStorageUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MediaService_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthCheckRequest)
 	if err := dec(in); err != nil {
@@ -317,6 +353,10 @@ var MediaService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "OptimizeImage",
 			Handler:    _MediaService_OptimizeImage_Handler,
 		},
+		{
+			MethodName: "GetStorageUsage",
+			Handler:    _MediaService_GetStorageUsage_Handler,
+		},
 		{
 			MethodName: "HealthCheck",
 			Handler:    _MediaService_HealthCheck_Handler,
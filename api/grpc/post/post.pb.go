@@ -67,7 +67,7 @@ func (x HealthCheckResponse_Status) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use HealthCheckResponse_Status.Descriptor instead.
 func (HealthCheckResponse_Status) EnumDescriptor() ([]byte, []int) {
-	return file_post_post_proto_rawDescGZIP(), []int{11, 0}
+	return file_post_post_proto_rawDescGZIP(), []int{13, 0}
 }
 
 type CreatePostRequest struct {
@@ -582,6 +582,118 @@ func (x *GetGlobalFeedRequest) GetWorldId() string {
 	return ""
 }
 
+type DeletePostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                // Автор поста; не требуется при удалении модерацией
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`                              // "deleted" (по умолчанию) или "moderated" (модерация)
+	AccessToken   string                 `protobuf:"bytes,4,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"` // Access-токен вызывающего, для "moderated" нужна роль moderator или admin
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePostRequest) Reset() {
+	*x = DeletePostRequest{}
+	mi := &file_post_post_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePostRequest) ProtoMessage() {}
+
+func (x *DeletePostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_post_post_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePostRequest.ProtoReflect.Descriptor instead.
+func (*DeletePostRequest) Descriptor() ([]byte, []int) {
+	return file_post_post_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeletePostRequest) GetPostId() string {
+	if x != nil {
+		return x.PostId
+	}
+	return ""
+}
+
+func (x *DeletePostRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *DeletePostRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *DeletePostRequest) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
+}
+
+type DeletePostResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePostResponse) Reset() {
+	*x = DeletePostResponse{}
+	mi := &file_post_post_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePostResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePostResponse) ProtoMessage() {}
+
+func (x *DeletePostResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_post_post_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePostResponse.ProtoReflect.Descriptor instead.
+func (*DeletePostResponse) Descriptor() ([]byte, []int) {
+	return file_post_post_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeletePostResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
 type Post struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PostId        string                 `protobuf:"bytes,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
@@ -596,13 +708,18 @@ type Post struct {
 	AvatarUrl     string                 `protobuf:"bytes,10,opt,name=avatar_url,json=avatarUrl,proto3" json:"avatar_url,omitempty"`             // URL аватара персонажа
 	IsAi          bool                   `protobuf:"varint,11,opt,name=is_ai,json=isAi,proto3" json:"is_ai,omitempty"`                           // Был ли пост создан через AI
 	Tags          []string               `protobuf:"bytes,12,rep,name=tags,proto3" json:"tags,omitempty"`                                        // Опционально, для будущего расширения
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	// Плейсхолдер изображения поста: размеры оригинала, blurhash и основной цвет (#rrggbb)
+	MediaWidth         int32  `protobuf:"varint,13,opt,name=media_width,json=mediaWidth,proto3" json:"media_width,omitempty"`
+	MediaHeight        int32  `protobuf:"varint,14,opt,name=media_height,json=mediaHeight,proto3" json:"media_height,omitempty"`
+	MediaBlurhash      string `protobuf:"bytes,15,opt,name=media_blurhash,json=mediaBlurhash,proto3" json:"media_blurhash,omitempty"`
+	MediaDominantColor string `protobuf:"bytes,16,opt,name=media_dominant_color,json=mediaDominantColor,proto3" json:"media_dominant_color,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *Post) Reset() {
 	*x = Post{}
-	mi := &file_post_post_proto_msgTypes[8]
+	mi := &file_post_post_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -614,7 +731,7 @@ func (x *Post) String() string {
 func (*Post) ProtoMessage() {}
 
 func (x *Post) ProtoReflect() protoreflect.Message {
-	mi := &file_post_post_proto_msgTypes[8]
+	mi := &file_post_post_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -627,7 +744,7 @@ func (x *Post) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Post.ProtoReflect.Descriptor instead.
 func (*Post) Descriptor() ([]byte, []int) {
-	return file_post_post_proto_rawDescGZIP(), []int{8}
+	return file_post_post_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Post) GetPostId() string {
@@ -714,6 +831,34 @@ func (x *Post) GetTags() []string {
 	return nil
 }
 
+func (x *Post) GetMediaWidth() int32 {
+	if x != nil {
+		return x.MediaWidth
+	}
+	return 0
+}
+
+func (x *Post) GetMediaHeight() int32 {
+	if x != nil {
+		return x.MediaHeight
+	}
+	return 0
+}
+
+func (x *Post) GetMediaBlurhash() string {
+	if x != nil {
+		return x.MediaBlurhash
+	}
+	return ""
+}
+
+func (x *Post) GetMediaDominantColor() string {
+	if x != nil {
+		return x.MediaDominantColor
+	}
+	return ""
+}
+
 type PostList struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Posts         []*Post                `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"`
@@ -725,7 +870,7 @@ type PostList struct {
 
 func (x *PostList) Reset() {
 	*x = PostList{}
-	mi := &file_post_post_proto_msgTypes[9]
+	mi := &file_post_post_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -737,7 +882,7 @@ func (x *PostList) String() string {
 func (*PostList) ProtoMessage() {}
 
 func (x *PostList) ProtoReflect() protoreflect.Message {
-	mi := &file_post_post_proto_msgTypes[9]
+	mi := &file_post_post_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -750,7 +895,7 @@ func (x *PostList) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PostList.ProtoReflect.Descriptor instead.
 func (*PostList) Descriptor() ([]byte, []int) {
-	return file_post_post_proto_rawDescGZIP(), []int{9}
+	return file_post_post_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *PostList) GetPosts() []*Post {
@@ -782,7 +927,7 @@ type HealthCheckRequest struct {
 
 func (x *HealthCheckRequest) Reset() {
 	*x = HealthCheckRequest{}
-	mi := &file_post_post_proto_msgTypes[10]
+	mi := &file_post_post_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -794,7 +939,7 @@ func (x *HealthCheckRequest) String() string {
 func (*HealthCheckRequest) ProtoMessage() {}
 
 func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_post_post_proto_msgTypes[10]
+	mi := &file_post_post_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -807,7 +952,7 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
 func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_post_post_proto_rawDescGZIP(), []int{10}
+	return file_post_post_proto_rawDescGZIP(), []int{12}
 }
 
 type HealthCheckResponse struct {
@@ -819,7 +964,7 @@ type HealthCheckResponse struct {
 
 func (x *HealthCheckResponse) Reset() {
 	*x = HealthCheckResponse{}
-	mi := &file_post_post_proto_msgTypes[11]
+	mi := &file_post_post_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -831,7 +976,7 @@ func (x *HealthCheckResponse) String() string {
 func (*HealthCheckResponse) ProtoMessage() {}
 
 func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_post_post_proto_msgTypes[11]
+	mi := &file_post_post_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -844,7 +989,7 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
 func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_post_post_proto_rawDescGZIP(), []int{11}
+	return file_post_post_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *HealthCheckResponse) GetStatus() HealthCheckResponse_Status {
@@ -895,7 +1040,14 @@ const file_post_post_proto_rawDesc = "" +
 	"\x14GetGlobalFeedRequest\x12\x14\n" +
 	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12\x16\n" +
 	"\x06cursor\x18\x02 \x01(\tR\x06cursor\x12\x19\n" +
-	"\bworld_id\x18\x03 \x01(\tR\aworldId\"\xe6\x02\n" +
+	"\bworld_id\x18\x03 \x01(\tR\aworldId\"\x80\x01\n" +
+	"\x11DeletePostRequest\x12\x17\n" +
+	"\apost_id\x18\x01 \x01(\tR\x06postId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12!\n" +
+	"\faccess_token\x18\x04 \x01(\tR\vaccessToken\".\n" +
+	"\x12DeletePostResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x83\x04\n" +
 	"\x04Post\x12\x17\n" +
 	"\apost_id\x18\x01 \x01(\tR\x06postId\x12!\n" +
 	"\fcharacter_id\x18\x02 \x01(\tR\vcharacterId\x12!\n" +
@@ -912,7 +1064,12 @@ const file_post_post_proto_rawDesc = "" +
 	"avatar_url\x18\n" +
 	" \x01(\tR\tavatarUrl\x12\x13\n" +
 	"\x05is_ai\x18\v \x01(\bR\x04isAi\x12\x12\n" +
-	"\x04tags\x18\f \x03(\tR\x04tags\"c\n" +
+	"\x04tags\x18\f \x03(\tR\x04tags\x12\x1f\n" +
+	"\vmedia_width\x18\r \x01(\x05R\n" +
+	"mediaWidth\x12!\n" +
+	"\fmedia_height\x18\x0e \x01(\x05R\vmediaHeight\x12%\n" +
+	"\x0emedia_blurhash\x18\x0f \x01(\tR\rmediaBlurhash\x120\n" +
+	"\x14media_dominant_color\x18\x10 \x01(\tR\x12mediaDominantColor\"c\n" +
 	"\bPostList\x12 \n" +
 	"\x05posts\x18\x01 \x03(\v2\n" +
 	".post.PostR\x05posts\x12\x14\n" +
@@ -925,7 +1082,7 @@ const file_post_post_proto_rawDesc = "" +
 	"\x06Status\x12\v\n" +
 	"\aUNKNOWN\x10\x00\x12\v\n" +
 	"\aSERVING\x10\x01\x12\x0f\n" +
-	"\vNOT_SERVING\x10\x022\xfe\x03\n" +
+	"\vNOT_SERVING\x10\x022\xbf\x04\n" +
 	"\vPostService\x12?\n" +
 	"\n" +
 	"CreatePost\x12\x17.post.CreatePostRequest\x1a\x18.post.CreatePostResponse\x12C\n" +
@@ -935,7 +1092,9 @@ const file_post_post_proto_rawDesc = "" +
 	"\fGetUserPosts\x12\x19.post.GetUserPostsRequest\x1a\x0e.post.PostList\x12C\n" +
 	"\x11GetCharacterPosts\x12\x1e.post.GetCharacterPostsRequest\x1a\x0e.post.PostList\x12;\n" +
 	"\rGetPostsByIds\x12\x1a.post.GetPostsByIdsRequest\x1a\x0e.post.PostList\x12;\n" +
-	"\rGetGlobalFeed\x12\x1a.post.GetGlobalFeedRequest\x1a\x0e.post.PostList\x12B\n" +
+	"\rGetGlobalFeed\x12\x1a.post.GetGlobalFeedRequest\x1a\x0e.post.PostList\x12?\n" +
+	"\n" +
+	"DeletePost\x12\x17.post.DeletePostRequest\x1a\x18.post.DeletePostResponse\x12B\n" +
 	"\vHealthCheck\x12\x18.post.HealthCheckRequest\x1a\x19.post.HealthCheckResponseB,Z*github.com/sdshorin/generia/api/proto/postb\x06proto3"
 
 var (
@@ -951,7 +1110,7 @@ func file_post_post_proto_rawDescGZIP() []byte {
 }
 
 var file_post_post_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_post_post_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_post_post_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_post_post_proto_goTypes = []any{
 	(HealthCheckResponse_Status)(0),  // 0: post.HealthCheckResponse.Status
 	(*CreatePostRequest)(nil),        // 1: post.CreatePostRequest
@@ -962,13 +1121,15 @@ var file_post_post_proto_goTypes = []any{
 	(*GetCharacterPostsRequest)(nil), // 6: post.GetCharacterPostsRequest
 	(*GetPostsByIdsRequest)(nil),     // 7: post.GetPostsByIdsRequest
 	(*GetGlobalFeedRequest)(nil),     // 8: post.GetGlobalFeedRequest
-	(*Post)(nil),                     // 9: post.Post
-	(*PostList)(nil),                 // 10: post.PostList
-	(*HealthCheckRequest)(nil),       // 11: post.HealthCheckRequest
-	(*HealthCheckResponse)(nil),      // 12: post.HealthCheckResponse
+	(*DeletePostRequest)(nil),        // 9: post.DeletePostRequest
+	(*DeletePostResponse)(nil),       // 10: post.DeletePostResponse
+	(*Post)(nil),                     // 11: post.Post
+	(*PostList)(nil),                 // 12: post.PostList
+	(*HealthCheckRequest)(nil),       // 13: post.HealthCheckRequest
+	(*HealthCheckResponse)(nil),      // 14: post.HealthCheckResponse
 }
 var file_post_post_proto_depIdxs = []int32{
-	9,  // 0: post.PostList.posts:type_name -> post.Post
+	11, // 0: post.PostList.posts:type_name -> post.Post
 	0,  // 1: post.HealthCheckResponse.status:type_name -> post.HealthCheckResponse.Status
 	1,  // 2: post.PostService.CreatePost:input_type -> post.CreatePostRequest
 	2,  // 3: post.PostService.CreateAIPost:input_type -> post.CreateAIPostRequest
@@ -977,17 +1138,19 @@ var file_post_post_proto_depIdxs = []int32{
 	6,  // 6: post.PostService.GetCharacterPosts:input_type -> post.GetCharacterPostsRequest
 	7,  // 7: post.PostService.GetPostsByIds:input_type -> post.GetPostsByIdsRequest
 	8,  // 8: post.PostService.GetGlobalFeed:input_type -> post.GetGlobalFeedRequest
-	11, // 9: post.PostService.HealthCheck:input_type -> post.HealthCheckRequest
-	3,  // 10: post.PostService.CreatePost:output_type -> post.CreatePostResponse
-	3,  // 11: post.PostService.CreateAIPost:output_type -> post.CreatePostResponse
-	9,  // 12: post.PostService.GetPost:output_type -> post.Post
-	10, // 13: post.PostService.GetUserPosts:output_type -> post.PostList
-	10, // 14: post.PostService.GetCharacterPosts:output_type -> post.PostList
-	10, // 15: post.PostService.GetPostsByIds:output_type -> post.PostList
-	10, // 16: post.PostService.GetGlobalFeed:output_type -> post.PostList
-	12, // 17: post.PostService.HealthCheck:output_type -> post.HealthCheckResponse
-	10, // [10:18] is the sub-list for method output_type
-	2,  // [2:10] is the sub-list for method input_type
+	9,  // 9: post.PostService.DeletePost:input_type -> post.DeletePostRequest
+	13, // 10: post.PostService.HealthCheck:input_type -> post.HealthCheckRequest
+	3,  // 11: post.PostService.CreatePost:output_type -> post.CreatePostResponse
+	3,  // 12: post.PostService.CreateAIPost:output_type -> post.CreatePostResponse
+	11, // 13: post.PostService.GetPost:output_type -> post.Post
+	12, // 14: post.PostService.GetUserPosts:output_type -> post.PostList
+	12, // 15: post.PostService.GetCharacterPosts:output_type -> post.PostList
+	12, // 16: post.PostService.GetPostsByIds:output_type -> post.PostList
+	12, // 17: post.PostService.GetGlobalFeed:output_type -> post.PostList
+	10, // 18: post.PostService.DeletePost:output_type -> post.DeletePostResponse
+	14, // 19: post.PostService.HealthCheck:output_type -> post.HealthCheckResponse
+	11, // [11:20] is the sub-list for method output_type
+	2,  // [2:11] is the sub-list for method input_type
 	2,  // [2:2] is the sub-list for extension type_name
 	2,  // [2:2] is the sub-list for extension extendee
 	0,  // [0:2] is the sub-list for field type_name
@@ -1004,7 +1167,7 @@ func file_post_post_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_post_post_proto_rawDesc), len(file_post_post_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   12,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -26,6 +26,7 @@ const (
 	PostService_GetCharacterPosts_FullMethodName = "/post.PostService/GetCharacterPosts"
 	PostService_GetPostsByIds_FullMethodName     = "/post.PostService/GetPostsByIds"
 	PostService_GetGlobalFeed_FullMethodName     = "/post.PostService/GetGlobalFeed"
+	PostService_DeletePost_FullMethodName        = "/post.PostService/DeletePost"
 	PostService_HealthCheck_FullMethodName       = "/post.PostService/HealthCheck"
 )
 
@@ -49,6 +50,8 @@ type PostServiceClient interface {
 	GetPostsByIds(ctx context.Context, in *GetPostsByIdsRequest, opts ...grpc.CallOption) (*PostList, error)
 	// Получение постов для глобальной ленты
 	GetGlobalFeed(ctx context.Context, in *GetGlobalFeedRequest, opts ...grpc.CallOption) (*PostList, error)
+	// Удаление поста автором или модерацией
+	DeletePost(ctx context.Context, in *DeletePostRequest, opts ...grpc.CallOption) (*DeletePostResponse, error)
 	// Проверка здоровья сервиса
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
 }
@@ -131,6 +134,16 @@ func (c *postServiceClient) GetGlobalFeed(ctx context.Context, in *GetGlobalFeed
 	return out, nil
 }
 
+func (c *postServiceClient) DeletePost(ctx context.Context, in *DeletePostRequest, opts ...grpc.CallOption) (*DeletePostResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeletePostResponse)
+	err := c.cc.Invoke(ctx, PostService_DeletePost_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *postServiceClient) HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(HealthCheckResponse)
@@ -161,6 +174,8 @@ type PostServiceServer interface {
 	GetPostsByIds(context.Context, *GetPostsByIdsRequest) (*PostList, error)
 	// Получение постов для глобальной ленты
 	GetGlobalFeed(context.Context, *GetGlobalFeedRequest) (*PostList, error)
+	// Удаление поста автором или модерацией
+	DeletePost(context.Context, *DeletePostRequest) (*DeletePostResponse, error)
 	// Проверка здоровья сервиса
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
 	mustEmbedUnimplementedPostServiceServer()
@@ -194,6 +209,9 @@ func (UnimplementedPostServiceServer) GetPostsByIds(context.Context, *GetPostsBy
 func (UnimplementedPostServiceServer) GetGlobalFeed(context.Context, *GetGlobalFeedRequest) (*PostList, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetGlobalFeed not implemented")
 }
+func (UnimplementedPostServiceServer) DeletePost(context.Context, *DeletePostRequest) (*DeletePostResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePost not implemented")
+}
 func (UnimplementedPostServiceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
@@ -344,6 +362,24 @@ func _PostService_GetGlobalFeed_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PostService_DeletePost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).DeletePost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_DeletePost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).DeletePost(ctx, req.(*DeletePostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PostService_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthCheckRequest)
 	if err := dec(in); err != nil {
@@ -397,6 +433,10 @@ var PostService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetGlobalFeed",
 			Handler:    _PostService_GetGlobalFeed_Handler,
 		},
+		{
+			MethodName: "DeletePost",
+			Handler:    _PostService_DeletePost_Handler,
+		},
 		{
 			MethodName: "HealthCheck",
 			Handler:    _PostService_HealthCheck_Handler,
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
+)
+
+// Ключи deny-листа в cache-service
+const (
+	deniedTokenPrefix   = "auth:denied:jti:"
+	deniedSessionPrefix = "auth:denied:session:"
+	deniedUserPrefix    = "auth:denied:user:"
+)
+
+// DenyList хранит отозванные access-токены в cache-service, пока они не истекут.
+// Отзывается либо один токен по jti, либо все токены сессии, либо все токены
+// пользователя, выданные до момента отзыва (выход на всех устройствах).
+// Проверяющие принимают токен еще leeway после exp, поэтому записи живут
+// на leeway дольше токенов.
+type DenyList struct {
+	client cachepb.CacheServiceClient
+	leeway time.Duration
+}
+
+// NewDenyList создает новый DenyList. leeway - допуск на расхождение часов
+// при проверке токенов (JWT_LEEWAY).
+func NewDenyList(client cachepb.CacheServiceClient, leeway time.Duration) *DenyList {
+	return &DenyList{
+		client: client,
+		leeway: leeway,
+	}
+}
+
+// Revoke отзывает токен с указанным jti, пока его принимают проверяющие
+func (d *DenyList) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt) + d.leeway
+	if jti == "" || ttl <= 0 {
+		return nil
+	}
+
+	_, err := d.client.Set(ctx, &cachepb.SetRequest{
+		Key:   deniedTokenPrefix + jti,
+		Value: []byte("1"),
+		Ttl:   ttlSeconds(ttl),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+// RevokeSession отзывает все токены сессии. Запись живет tokenTTL и leeway -
+// новых токенов у завершенной сессии не бывает.
+func (d *DenyList) RevokeSession(ctx context.Context, sessionID string, tokenTTL time.Duration) error {
+	_, err := d.client.Set(ctx, &cachepb.SetRequest{
+		Key:   deniedSessionPrefix + sessionID,
+		Value: []byte("1"),
+		Ttl:   ttlSeconds(tokenTTL + d.leeway),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeUser отзывает все токены пользователя, выданные раньше before.
+// Запись живет tokenTTL и leeway - дольше не принимается ни один из отзываемых токенов.
+func (d *DenyList) RevokeUser(ctx context.Context, userID string, before time.Time, tokenTTL time.Duration) error {
+	_, err := d.client.Set(ctx, &cachepb.SetRequest{
+		Key:   deniedUserPrefix + userID,
+		Value: []byte(strconv.FormatInt(before.Unix(), 10)),
+		Ttl:   ttlSeconds(tokenTTL + d.leeway),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+	return nil
+}
+
+// IsRevoked проверяет токен одним запросом к cache-service.
+// iat хранится с точностью до секунды, поэтому токен, выданный в ту же
+// секунду, что и RevokeUser, не считается отозванным.
+func (d *DenyList) IsRevoked(ctx context.Context, jti, sessionID, userID string, issuedAt time.Time) (bool, error) {
+	keys := []string{deniedUserPrefix + userID}
+	if jti != "" {
+		keys = append(keys, deniedTokenPrefix+jti)
+	}
+	if sessionID != "" {
+		keys = append(keys, deniedSessionPrefix+sessionID)
+	}
+
+	resp, err := d.client.MGet(ctx, &cachepb.MGetRequest{Keys: keys})
+	if err != nil {
+		return false, fmt.Errorf("failed to check revoked tokens: %w", err)
+	}
+
+	for _, result := range resp.Results {
+		if !result.Exists {
+			continue
+		}
+		if result.Key != deniedUserPrefix+userID {
+			return true, nil
+		}
+		before, err := strconv.ParseInt(string(result.Value), 10, 64)
+		if err == nil && issuedAt.Unix() < before {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ttlSeconds округляет TTL вверх до секунды
+func ttlSeconds(ttl time.Duration) int32 {
+	return int32((ttl + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sdshorin/generia/pkg/config"
+	"github.com/sdshorin/generia/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
+)
+
+func TestMain(m *testing.M) {
+	logger.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// fakeCache is an in-memory cache-service, err fails every call
+type fakeCache struct {
+	cachepb.CacheServiceClient
+
+	mu      sync.Mutex
+	entries map[string][]byte
+	ttls    map[string]int32
+	err     error
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{entries: make(map[string][]byte), ttls: make(map[string]int32)}
+}
+
+func (f *fakeCache) Set(ctx context.Context, req *cachepb.SetRequest, opts ...grpc.CallOption) (*cachepb.SetResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.entries[req.Key] = req.Value
+	f.ttls[req.Key] = req.Ttl
+	return &cachepb.SetResponse{Success: true}, nil
+}
+
+func (f *fakeCache) MGet(ctx context.Context, req *cachepb.MGetRequest, opts ...grpc.CallOption) (*cachepb.MGetResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	resp := &cachepb.MGetResponse{}
+	for _, key := range req.Keys {
+		value, ok := f.entries[key]
+		resp.Results = append(resp.Results, &cachepb.MGetResult{Key: key, Exists: ok, Value: value})
+	}
+	return resp, nil
+}
+
+func testConfig() config.JWTConfig {
+	return config.JWTConfig{
+		Expiration: 15 * time.Minute,
+		Issuer:     "generia-auth",
+		Audience:   "generia",
+		Leeway:     30 * time.Second,
+
+		AllowEphemeralKey: true,
+	}
+}
+
+// newTestAuth returns an issuer with an ephemeral key and a verifier of its tokens
+func newTestAuth(t *testing.T, cfg config.JWTConfig, cache *fakeCache) (*TokenIssuer, *TokenVerifier) {
+	t.Helper()
+	keyring, err := NewKeyring(cfg)
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	return NewTokenIssuer(keyring, cfg), NewTokenVerifier(keyring.Keyfunc, NewDenyList(cache, cfg.Leeway), cfg)
+}
+
+// issue returns a new token of a user and its claims
+func issue(t *testing.T, issuer *TokenIssuer, verifier *TokenVerifier) (string, *Claims) {
+	t.Helper()
+	token, err := issuer.Issue(Claims{UserID: "user-1", SessionID: uuid.New().String()})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	claims, err := verifier.VerifySignature(context.Background(), token)
+	if err != nil {
+		t.Fatalf("VerifySignature: %v", err)
+	}
+	return token, claims
+}
+
+func TestVerifyRejectsRevokedTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		revoke  func(ctx context.Context, d *DenyList, claims *Claims) error
+		wantErr error
+	}{
+		{"not revoked", func(ctx context.Context, d *DenyList, claims *Claims) error {
+			return nil
+		}, nil},
+		{"token revoked", func(ctx context.Context, d *DenyList, claims *Claims) error {
+			return d.Revoke(ctx, claims.ID, claims.ExpiresAt.Time)
+		}, ErrTokenRevoked},
+		{"another token revoked", func(ctx context.Context, d *DenyList, claims *Claims) error {
+			return d.Revoke(ctx, uuid.New().String(), claims.ExpiresAt.Time)
+		}, nil},
+		{"session revoked", func(ctx context.Context, d *DenyList, claims *Claims) error {
+			return d.RevokeSession(ctx, claims.SessionID, time.Minute)
+		}, ErrTokenRevoked},
+		{"another session revoked", func(ctx context.Context, d *DenyList, claims *Claims) error {
+			return d.RevokeSession(ctx, uuid.New().String(), time.Minute)
+		}, nil},
+		{"user revoked", func(ctx context.Context, d *DenyList, claims *Claims) error {
+			return d.RevokeUser(ctx, claims.UserID, time.Now().Add(time.Second), time.Minute)
+		}, ErrTokenRevoked},
+		{"user revoked before the token was issued", func(ctx context.Context, d *DenyList, claims *Claims) error {
+			return d.RevokeUser(ctx, claims.UserID, time.Now().Add(-time.Second), time.Minute)
+		}, nil},
+		{"another user revoked", func(ctx context.Context, d *DenyList, claims *Claims) error {
+			return d.RevokeUser(ctx, "user-2", time.Now().Add(time.Second), time.Minute)
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cache := newFakeCache()
+			issuer, verifier := newTestAuth(t, testConfig(), cache)
+			token, claims := issue(t, issuer, verifier)
+
+			if err := tt.revoke(ctx, NewDenyList(cache, testConfig().Leeway), claims); err != nil {
+				t.Fatalf("revoke: %v", err)
+			}
+			_, err := verifier.Verify(ctx, token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify error = %v, want %v", err, tt.wantErr)
+			}
+
+			// Logout must work with a revoked token too
+			if _, err := verifier.VerifySignature(ctx, token); err != nil {
+				t.Errorf("VerifySignature: %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyWhenDenyListUnavailable(t *testing.T) {
+	for _, failOpen := range []bool{false, true} {
+		cfg := testConfig()
+		cfg.DenyListFailOpen = failOpen
+		cache := newFakeCache()
+		issuer, verifier := newTestAuth(t, cfg, cache)
+		token, _ := issue(t, issuer, verifier)
+
+		cache.err = errors.New("cache-service is down")
+		claims, err := verifier.Verify(context.Background(), token)
+		if failOpen {
+			if err != nil || claims == nil || claims.UserID != "user-1" {
+				t.Errorf("fail-open: Verify = %+v, %v, want the claims", claims, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrRevocationUnknown) {
+			t.Errorf("fail-closed: Verify error = %v, want ErrRevocationUnknown", err)
+		}
+	}
+}
+
+func TestRevokeUserSameSecond(t *testing.T) {
+	ctx := context.Background()
+	d := NewDenyList(newFakeCache(), testConfig().Leeway)
+	revokedAt := time.Unix(1000, int64(500*time.Millisecond))
+	if err := d.RevokeUser(ctx, "user-1", revokedAt, time.Minute); err != nil {
+		t.Fatalf("RevokeUser: %v", err)
+	}
+
+	// iat has second precision: a token of the same second may have been
+	// issued right after the revocation, e.g. by the next login, and is kept
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+		want     bool
+	}{
+		{"previous second", time.Unix(999, int64(900*time.Millisecond)), true},
+		{"same second, earlier", time.Unix(1000, 0), false},
+		{"same second, later", time.Unix(1000, int64(900*time.Millisecond)), false},
+		{"next second", time.Unix(1001, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoked, err := d.IsRevoked(ctx, "", "", "user-1", tt.issuedAt)
+			if err != nil {
+				t.Fatalf("IsRevoked: %v", err)
+			}
+			if revoked != tt.want {
+				t.Errorf("revoked = %v, want %v", revoked, tt.want)
+			}
+		})
+	}
+}
+
+func TestDenyListTTLIncludesLeeway(t *testing.T) {
+	ctx := context.Background()
+	leeway := testConfig().Leeway // 30s
+	tokenTTL := 15 * time.Minute
+
+	tests := []struct {
+		name    string
+		revoke  func(d *DenyList) error
+		key     string
+		wantTTL int32 // 0 when nothing is stored
+	}{
+		{"token", func(d *DenyList) error {
+			return d.Revoke(ctx, "jti-1", time.Now().Add(time.Minute))
+		}, deniedTokenPrefix + "jti-1", 90},
+		{"token expired within the leeway", func(d *DenyList) error {
+			return d.Revoke(ctx, "jti-1", time.Now().Add(-10*time.Second))
+		}, deniedTokenPrefix + "jti-1", 20},
+		{"token expired before the leeway", func(d *DenyList) error {
+			return d.Revoke(ctx, "jti-1", time.Now().Add(-time.Minute))
+		}, deniedTokenPrefix + "jti-1", 0},
+		{"session", func(d *DenyList) error {
+			return d.RevokeSession(ctx, "s1", tokenTTL)
+		}, deniedSessionPrefix + "s1", 930},
+		{"user", func(d *DenyList) error {
+			return d.RevokeUser(ctx, "user-1", time.Now(), tokenTTL)
+		}, deniedUserPrefix + "user-1", 930},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newFakeCache()
+			if err := tt.revoke(NewDenyList(cache, leeway)); err != nil {
+				t.Fatalf("revoke: %v", err)
+			}
+			ttl, stored := cache.ttls[tt.key]
+			if tt.wantTTL == 0 {
+				if stored {
+					t.Errorf("stored %s with TTL %d, want nothing", tt.key, ttl)
+				}
+				return
+			}
+			// Rounded up to a second, the clock may have moved by one
+			if !stored || ttl < tt.wantTTL-1 || ttl > tt.wantTTL {
+				t.Errorf("TTL of %s = %d (stored %v), want %d", tt.key, ttl, stored, tt.wantTTL)
+			}
+		})
+	}
+}
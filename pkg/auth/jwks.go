@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sdshorin/generia/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// jwksRetryInterval - пауза перед повторной загрузкой JWKS после ошибки
+	jwksRetryInterval = 5 * time.Second
+	// jwksMinRefreshInterval ограничивает загрузки из-за неизвестного kid,
+	// чтобы токены с выдуманными kid не нагружали auth-service
+	jwksMinRefreshInterval = 10 * time.Second
+	// jwksFetchTimeout ограничивает одну загрузку JWKS
+	jwksFetchTimeout = 2 * time.Second
+)
+
+// JWK - публичный ключ Ed25519 в формате JSON Web Key (RFC 7517, RFC 8037)
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+}
+
+// JWKS - набор публичных ключей
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// newJWK описывает публичный ключ подписи токенов
+func newJWK(kid string, key ed25519.PublicKey) JWK {
+	return JWK{
+		Kty: "OKP",
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(key),
+		Kid: kid,
+		Alg: "EdDSA",
+		Use: "sig",
+	}
+}
+
+// parseJWKS разбирает документ JWKS, ключи других типов пропускаются
+func parseJWKS(document []byte) (map[string]ed25519.PublicKey, error) {
+	var doc JWKS
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]ed25519.PublicKey, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.Kid == "" {
+			continue
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid key %q in JWKS", jwk.Kid)
+		}
+		keys[jwk.Kid] = ed25519.PublicKey(x)
+	}
+	return keys, nil
+}
+
+// KeySource загружает документ JWKS, например из auth-service
+type KeySource func(ctx context.Context) ([]byte, error)
+
+// JWKSCache хранит публичные ключи для проверки токенов и периодически их
+// обновляет. Токен с неизвестным kid вызывает внеочередное обновление, так
+// что новый ключ auth-service начинает проверяться без ожидания интервала.
+type JWKSCache struct {
+	source   KeySource
+	interval time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]ed25519.PublicKey
+	document    []byte
+	attemptedAt time.Time // Время последней загрузки, в том числе неудачной
+	lastErr     error     // Ошибка последней загрузки
+
+	refreshing chan struct{} // Одна загрузка за раз
+
+	cancel    context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewJWKSCache создает новый JWKSCache
+func NewJWKSCache(source KeySource, interval time.Duration) *JWKSCache {
+	return &JWKSCache{
+		source:     source,
+		interval:   interval,
+		keys:       make(map[string]ed25519.PublicKey),
+		refreshing: make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start загружает ключи и запускает их периодическое обновление.
+// Ошибка первой загрузки не фатальна: загрузка повторяется в фоне.
+func (c *JWKSCache) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	go func() {
+		defer close(c.done)
+		for {
+			wait := c.interval
+			if err := c.refresh(ctx); err != nil {
+				logger.Logger.Warn("Failed to refresh JWKS", zap.Error(err))
+				wait = min(wait, jwksRetryInterval)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+	}()
+}
+
+// Close останавливает обновление ключей
+func (c *JWKSCache) Close() {
+	c.closeOnce.Do(func() {
+		if c.cancel != nil {
+			c.cancel()
+			<-c.done
+		}
+	})
+}
+
+// Document возвращает последний загруженный документ JWKS
+func (c *JWKSCache) Document() []byte {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.document
+}
+
+// Keyfunc возвращает ключ проверки токена по kid. Неизвестный kid вызывает
+// загрузку ключей, которая не переживает ctx вызывающего.
+func (c *JWKSCache) Keyfunc(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	kid, err := tokenKeyID(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, ok := c.key(kid); ok {
+		return key, nil
+	}
+
+	// Ключ мог появиться после последнего обновления
+	if err := c.refreshStale(ctx); err != nil {
+		logger.Logger.Warn("Failed to refresh JWKS", zap.Error(err))
+	}
+	if key, ok := c.key(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key ID %q", kid)
+}
+
+// tokenKeyID возвращает kid токена. Принимаем только EdDSA, чтобы исключить подмену алгоритма.
+func tokenKeyID(token *jwt.Token) (string, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodEd25519); !ok {
+		return "", fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return "", errors.New("token has no key ID")
+	}
+	return kid, nil
+}
+
+func (c *JWKSCache) key(kid string) (ed25519.PublicKey, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+// refreshStale обновляет ключи, если последняя загрузка была раньше
+// jwksMinRefreshInterval. Неудачные загрузки тоже учитываются, иначе при
+// недоступном auth-service каждый токен с неизвестным kid ждал бы загрузку.
+func (c *JWKSCache) refreshStale(ctx context.Context) error {
+	c.mu.RLock()
+	fresh := time.Since(c.attemptedAt) < jwksMinRefreshInterval
+	c.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+	return c.refresh(ctx)
+}
+
+// refresh загружает ключи. Запросы, пришедшие во время загрузки, ждут ее
+// (но не дольше своего ctx) и получают ее результат, не загружая ключи повторно.
+func (c *JWKSCache) refresh(ctx context.Context) error {
+	started := time.Now()
+	select {
+	case c.refreshing <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-c.refreshing }()
+
+	c.mu.RLock()
+	done, lastErr := c.attemptedAt.After(started), c.lastErr
+	c.mu.RUnlock()
+	if done {
+		return lastErr
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+	document, err := c.source(fetchCtx)
+	if err != nil && ctx.Err() != nil {
+		// Вызывающий ушел, auth-service тут ни при чем: попытка не учитывается
+		return err
+	}
+	var keys map[string]ed25519.PublicKey
+	if err == nil {
+		keys, err = parseJWKS(document)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.attemptedAt = time.Now()
+	c.lastErr = err
+	if err != nil {
+		return err
+	}
+	c.keys = keys
+	c.document = document
+	return nil
+}
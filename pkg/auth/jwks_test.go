@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sdshorin/generia/pkg/config"
+)
+
+// countingSource serves the JWKS of a keyring, or err, and counts the loads
+type countingSource struct {
+	mu      sync.Mutex
+	keyring *Keyring
+	err     error
+	loads   int
+}
+
+func (s *countingSource) load(ctx context.Context) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loads++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.keyring.JWKS()
+}
+
+func (s *countingSource) set(keyring *Keyring, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keyring, s.err = keyring, err
+}
+
+func (s *countingSource) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loads
+}
+
+func newTestKeyring(t *testing.T, signingKeys string) *Keyring {
+	t.Helper()
+	kr, err := NewKeyring(config.JWTConfig{Expiration: time.Hour, SigningKeys: signingKeys})
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	return kr
+}
+
+// tokenWithKeyID returns an unsigned token header naming kid
+func tokenWithKeyID(kid string) *jwt.Token {
+	return &jwt.Token{Method: jwt.SigningMethodEdDSA, Header: map[string]interface{}{"alg": "EdDSA", "kid": kid}}
+}
+
+func TestJWKSCacheRefreshesOnUnknownKeyID(t *testing.T) {
+	ctx := context.Background()
+	source := &countingSource{keyring: newTestKeyring(t, keyEntry("k1", 1))}
+	cache := NewJWKSCache(source.load, time.Hour)
+
+	// The first unknown kid loads the keys
+	if _, err := cache.Keyfunc(ctx, tokenWithKeyID("k1")); err != nil {
+		t.Fatalf("Keyfunc(k1): %v", err)
+	}
+	if loads := source.count(); loads != 1 {
+		t.Fatalf("%d loads, want 1", loads)
+	}
+
+	// Known keys don't load
+	if _, err := cache.Keyfunc(ctx, tokenWithKeyID("k1")); err != nil {
+		t.Fatalf("Keyfunc(k1): %v", err)
+	}
+
+	// A new key isn't looked up again within jwksMinRefreshInterval
+	source.set(newTestKeyring(t, keyEntry("k1", 1)+","+keyEntry("k2", 2)), nil)
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Keyfunc(ctx, tokenWithKeyID("k2")); err == nil {
+			t.Fatal("Keyfunc found k2 without a load")
+		}
+	}
+	if loads := source.count(); loads != 1 {
+		t.Fatalf("%d loads, want 1", loads)
+	}
+
+	// After the interval it is
+	cache.mu.Lock()
+	cache.attemptedAt = time.Now().Add(-jwksMinRefreshInterval)
+	cache.mu.Unlock()
+	if _, err := cache.Keyfunc(ctx, tokenWithKeyID("k2")); err != nil {
+		t.Fatalf("Keyfunc(k2): %v", err)
+	}
+	if loads := source.count(); loads != 2 {
+		t.Fatalf("%d loads, want 2", loads)
+	}
+}
+
+func TestJWKSCacheRateLimitsFailedRefreshes(t *testing.T) {
+	ctx := context.Background()
+	source := &countingSource{err: errors.New("auth-service is down")}
+	cache := NewJWKSCache(source.load, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Keyfunc(ctx, tokenWithKeyID("k1")); err == nil {
+			t.Fatal("Keyfunc succeeded without keys")
+		}
+	}
+	if loads := source.count(); loads != 1 {
+		t.Errorf("%d loads, want 1", loads)
+	}
+}
+
+func TestJWKSCacheRejectsOtherAlgorithms(t *testing.T) {
+	source := &countingSource{keyring: newTestKeyring(t, keyEntry("k1", 1))}
+	cache := NewJWKSCache(source.load, time.Hour)
+
+	token := &jwt.Token{Method: jwt.SigningMethodHS256, Header: map[string]interface{}{"alg": "HS256", "kid": "k1"}}
+	if _, err := cache.Keyfunc(context.Background(), token); err == nil || !strings.Contains(err.Error(), "unexpected signing method") {
+		t.Errorf("Keyfunc error = %v, want unexpected signing method", err)
+	}
+	if loads := source.count(); loads != 0 {
+		t.Errorf("%d loads, want none", loads)
+	}
+}
+
+func TestIssueVerifyThroughJWKS(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.SigningKeys = keyEntry("k1", 1)
+	keyring, err := NewKeyring(cfg)
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	issuer := NewTokenIssuer(keyring, cfg)
+
+	// Verifiers outside auth-service only see the published document
+	cache := NewJWKSCache(func(ctx context.Context) ([]byte, error) { return issuer.JWKS() }, time.Hour)
+	verifier := NewTokenVerifier(cache.Keyfunc, NewDenyList(newFakeCache(), cfg.Leeway), cfg)
+
+	token, err := issuer.Issue(Claims{UserID: "user-1", Roles: []string{RoleModerator}, SessionID: "s1", EmailVerified: true})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	claims, err := verifier.Verify(ctx, token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.UserID != "user-1" || !claims.HasRole(RoleModerator) || claims.SessionID != "s1" || !claims.EmailVerified {
+		t.Errorf("claims = %+v", claims)
+	}
+	if len(cache.Document()) == 0 {
+		t.Error("document was not cached")
+	}
+
+	// A token signed by another key with the same kid fails the signature check
+	forged, err := NewTokenIssuer(newTestKeyring(t, keyEntry("k1", 9)), cfg).Issue(Claims{UserID: "user-1"})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if _, err := verifier.Verify(ctx, forged); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify of a forged token: %v, want ErrInvalidToken", err)
+	}
+}
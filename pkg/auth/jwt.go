@@ -1,89 +1,194 @@
 package auth
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"github.com/sdshorin/generia/pkg/config"
+	"github.com/sdshorin/generia/pkg/logger"
+	"go.uber.org/zap"
 )
 
-// JWTClaims структура для хранения JWT-клеймов
-type JWTClaims struct {
-	UserID string `json:"user_id"`
-	jwt.StandardClaims
+// Роли пользователей, роль хранится в auth-service и попадает в клейм roles
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// denyListTimeout ограничивает проверку deny-листа, которая добавляется к каждому запросу
+const denyListTimeout = 200 * time.Millisecond
+
+// Ошибки проверки токена
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token has expired")
+	ErrTokenRevoked = errors.New("token has been revoked")
+	// ErrRevocationUnknown - deny-лист недоступен, и токен нельзя ни принять, ни отвергнуть
+	ErrRevocationUnknown = errors.New("token revocation could not be checked")
+)
+
+// Keyfunc выбирает ключ проверки токена. ctx ограничивает загрузку ключей, если она нужна.
+type Keyfunc func(ctx context.Context, token *jwt.Token) (interface{}, error)
+
+// Claims - клеймы access-токена
+type Claims struct {
+	UserID        string   `json:"user_id"`
+	Roles         []string `json:"roles,omitempty"`
+	SessionID     string   `json:"sid,omitempty"` // Позволяет отозвать все токены сессии
+	EmailVerified bool     `json:"email_verified"`
+
+	jwt.RegisteredClaims // jti, iss, aud, iat, nbf, exp
 }
 
-// TokenService интерфейс для работы с JWT токенами
-type TokenService interface {
-	GenerateToken(userID string) (string, error)
-	ValidateToken(tokenString string) (*JWTClaims, error)
+// HasRole проверяет, есть ли у пользователя роль
+func (c *Claims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
 }
 
-// JWTService реализует TokenService
-type JWTService struct {
-	config *config.Config
+// TokenIssuer выдает access-токены. Нужен только auth-service: закрытые
+// ключи есть только у него.
+type TokenIssuer struct {
+	keyring    *Keyring
+	issuer     string
+	audience   string
+	expiration time.Duration
 }
 
-// NewJWTService создает новый JWTService
-func NewJWTService(config *config.Config) TokenService {
-	return &JWTService{
-		config: config,
+// NewTokenIssuer создает новый TokenIssuer
+func NewTokenIssuer(keyring *Keyring, cfg config.JWTConfig) *TokenIssuer {
+	return &TokenIssuer{
+		keyring:    keyring,
+		issuer:     cfg.Issuer,
+		audience:   cfg.Audience,
+		expiration: cfg.Expiration,
 	}
 }
 
-// GenerateToken генерирует новый JWT токен
-func (s *JWTService) GenerateToken(userID string) (string, error) {
-	// Устанавливаем время истечения
-	expirationTime := time.Now().Add(s.config.JWT.Expiration)
-
-	// Создаем клеймы
-	claims := &JWTClaims{
-		UserID: userID,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expirationTime.Unix(),
-			IssuedAt:  time.Now().Unix(),
-			Issuer:    "github.com/sdshorin/generia",
-		},
+// Issue подписывает токен основным ключом. Стандартные клеймы заполняются
+// здесь, из переданных берутся только клеймы пользователя.
+func (i *TokenIssuer) Issue(claims Claims) (string, error) {
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.New().String(), // Позволяет отозвать один токен при выходе
+		Issuer:    i.issuer,
+		Audience:  jwt.ClaimStrings{i.audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(i.expiration)),
 	}
 
-	// Создаем токен
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// Проверяющие находят публичный ключ по kid
+	kid, key := i.keyring.Primary()
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, &claims)
+	token.Header["kid"] = kid
 
-	// Подписываем токен
-	tokenString, err := token.SignedString([]byte(s.config.JWT.Secret))
+	tokenString, err := token.SignedString(key)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
-
 	return tokenString, nil
 }
 
-// ValidateToken проверяет JWT токен
-func (s *JWTService) ValidateToken(tokenString string) (*JWTClaims, error) {
-	// Парсим токен
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Проверяем метод подписи
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(s.config.JWT.Secret), nil
-	})
+// JWKS возвращает публичные ключи, которыми проверяются выданные токены
+func (i *TokenIssuer) JWKS() ([]byte, error) {
+	return i.keyring.JWKS()
+}
+
+// TokenVerifier проверяет access-токены: подпись, издателя, аудиторию, время
+// жизни с допуском на расхождение часов и deny-лист
+type TokenVerifier struct {
+	keyfunc  Keyfunc
+	denyList *DenyList
+	issuer   string
+	audience string
+	leeway   time.Duration
+	failOpen bool // Принимать токены, если deny-лист недоступен
+}
+
+// NewTokenVerifier создает новый TokenVerifier. keyfunc выбирает ключ проверки:
+// Keyring.Keyfunc в auth-service, JWKSCache.Keyfunc в остальных сервисах.
+func NewTokenVerifier(keyfunc Keyfunc, denyList *DenyList, cfg config.JWTConfig) *TokenVerifier {
+	return &TokenVerifier{
+		keyfunc:  keyfunc,
+		denyList: denyList,
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		leeway:   cfg.Leeway,
+		failOpen: cfg.DenyListFailOpen,
+	}
+}
 
+// Verify проверяет токен и возвращает его клеймы. Если deny-лист недоступен,
+// возвращается ErrRevocationUnknown: отозванный токен нельзя отличить от
+// действующего. С JWT_DENYLIST_FAIL_OPEN токен в этом случае принимается.
+func (v *TokenVerifier) Verify(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := v.parse(ctx, tokenString)
 	if err != nil {
 		return nil, err
 	}
 
-	// Проверяем валидность
-	if !token.Valid {
-		return nil, errors.New("invalid token")
+	now := time.Now()
+	if !claims.VerifyExpiresAt(now.Add(-v.leeway), true) {
+		return nil, ErrTokenExpired
+	}
+	if !claims.VerifyNotBefore(now.Add(v.leeway), false) || !claims.VerifyIssuedAt(now.Add(v.leeway), false) {
+		return nil, fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
 	}
 
-	// Получаем клеймы
-	claims, ok := token.Claims.(*JWTClaims)
-	if !ok {
-		return nil, errors.New("invalid token claims")
+	ctx, cancel := context.WithTimeout(ctx, denyListTimeout)
+	defer cancel()
+	revoked, err := v.denyList.IsRevoked(ctx, claims.ID, claims.SessionID, claims.UserID, claims.IssuedAt.Time)
+	if err != nil {
+		logger.Logger.Warn("Failed to check revoked tokens", zap.Error(err), zap.Bool("fail_open", v.failOpen))
+		if v.failOpen {
+			return claims, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrRevocationUnknown, err)
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
 	}
+	return claims, nil
+}
+
+// VerifySignature проверяет подпись, издателя и аудиторию, но не время жизни
+// и не deny-лист. Нужен для выхода: клиент должен завершить сессию и с истекшим токеном.
+func (v *TokenVerifier) VerifySignature(ctx context.Context, tokenString string) (*Claims, error) {
+	return v.parse(ctx, tokenString)
+}
 
+func (v *TokenVerifier) parse(ctx context.Context, tokenString string) (*Claims, error) {
+	// Время проверяется отдельно, с допуском на расхождение часов
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation(), jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}))
+	claims := &Claims{}
+	keyfunc := func(token *jwt.Token) (interface{}, error) {
+		return v.keyfunc(ctx, token)
+	}
+	if _, err := parser.ParseWithClaims(tokenString, claims, keyfunc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if claims.UserID == "" {
+		return nil, fmt.Errorf("%w: token has no user ID", ErrInvalidToken)
+	}
+	if claims.IssuedAt == nil {
+		return nil, fmt.Errorf("%w: token has no issue time", ErrInvalidToken)
+	}
+	if !claims.VerifyIssuer(v.issuer, true) {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+	if !claims.VerifyAudience(v.audience, true) {
+		return nil, fmt.Errorf("%w: unexpected audience %v", ErrInvalidToken, claims.Audience)
+	}
 	return claims, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestVerifyClaims(t *testing.T) {
+	cfg := testConfig() // Leeway of 30s
+	cfg.SigningKeys = keyEntry("k1", 1)
+	keyring, err := NewKeyring(cfg)
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	verifier := NewTokenVerifier(keyring.Keyfunc, NewDenyList(newFakeCache(), cfg.Leeway), cfg)
+	kid, key := keyring.Primary()
+
+	now := time.Now()
+	at := func(d time.Duration) int64 { return now.Add(d).Unix() }
+	claims := func(change func(c jwt.MapClaims)) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"user_id": "user-1",
+			"jti":     "jti-1",
+			"iss":     cfg.Issuer,
+			"aud":     cfg.Audience,
+			"iat":     at(0),
+			"nbf":     at(0),
+			"exp":     at(time.Minute),
+		}
+		if change != nil {
+			change(c)
+		}
+		return c
+	}
+	signed := func(c jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, c)
+		token.Header["kid"] = kid
+		s, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return s
+	}
+
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims(nil))
+	hmacToken.Header["kid"] = kid
+	hmacSigned, err := hmacToken.SignedString([]byte(key.Public().(ed25519.PublicKey)))
+	if err != nil {
+		t.Fatalf("sign HS256: %v", err)
+	}
+	noneToken := jwt.NewWithClaims(jwt.SigningMethodNone, claims(nil))
+	noneToken.Header["kid"] = kid
+	noneSigned, err := noneToken.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("sign none: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  error // Of Verify
+		// VerifySignature, used by logout, skips the time checks
+		wantSignature error
+	}{
+		{"valid", signed(claims(nil)), nil, nil},
+		{"audience in a list", signed(claims(func(c jwt.MapClaims) { c["aud"] = []string{"other", cfg.Audience} })), nil, nil},
+		{"wrong issuer", signed(claims(func(c jwt.MapClaims) { c["iss"] = "someone-else" })), ErrInvalidToken, ErrInvalidToken},
+		{"no issuer", signed(claims(func(c jwt.MapClaims) { delete(c, "iss") })), ErrInvalidToken, ErrInvalidToken},
+		{"wrong audience", signed(claims(func(c jwt.MapClaims) { c["aud"] = "other-app" })), ErrInvalidToken, ErrInvalidToken},
+		{"no user ID", signed(claims(func(c jwt.MapClaims) { delete(c, "user_id") })), ErrInvalidToken, ErrInvalidToken},
+		{"no issue time", signed(claims(func(c jwt.MapClaims) { delete(c, "iat") })), ErrInvalidToken, ErrInvalidToken},
+		{"expired within the leeway", signed(claims(func(c jwt.MapClaims) { c["exp"] = at(-10 * time.Second) })), nil, nil},
+		{"expired beyond the leeway", signed(claims(func(c jwt.MapClaims) { c["exp"] = at(-time.Minute) })), ErrTokenExpired, nil},
+		{"no expiry", signed(claims(func(c jwt.MapClaims) { delete(c, "exp") })), ErrTokenExpired, nil},
+		{"issued ahead within the leeway", signed(claims(func(c jwt.MapClaims) { c["iat"], c["nbf"] = at(10*time.Second), at(10*time.Second) })), nil, nil},
+		{"issued ahead beyond the leeway", signed(claims(func(c jwt.MapClaims) { c["iat"] = at(time.Minute) })), ErrInvalidToken, nil},
+		{"not valid yet", signed(claims(func(c jwt.MapClaims) { c["nbf"] = at(time.Minute) })), ErrInvalidToken, nil},
+		{"HS256 with the public key", hmacSigned, ErrInvalidToken, ErrInvalidToken},
+		{"unsigned", noneSigned, ErrInvalidToken, ErrInvalidToken},
+		{"malformed", "not.a.token", ErrInvalidToken, ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			got, err := verifier.Verify(ctx, tt.token)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Verify error = %v, want %v", err, tt.want)
+			}
+			if err == nil && got.UserID != "user-1" {
+				t.Errorf("user ID = %q", got.UserID)
+			}
+			if _, err := verifier.VerifySignature(ctx, tt.token); !errors.Is(err, tt.wantSignature) {
+				t.Errorf("VerifySignature error = %v, want %v", err, tt.wantSignature)
+			}
+		})
+	}
+}
+
+func TestIssue(t *testing.T) {
+	cfg := testConfig()
+	cfg.SigningKeys = keyEntry("k1", 1) + "," + keyEntry("k2", 2)
+	cfg.PrimaryKeyID = "k2"
+	keyring, err := NewKeyring(cfg)
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	issuer := NewTokenIssuer(keyring, cfg)
+
+	// Standard claims passed in are replaced
+	tokenString, err := issuer.Issue(Claims{
+		UserID:           "user-1",
+		RegisteredClaims: jwt.RegisteredClaims{Issuer: "forged", ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour))},
+	})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return keyring.Keyfunc(context.Background(), token)
+	})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if token.Header["kid"] != "k2" || token.Method != jwt.SigningMethodEdDSA {
+		t.Errorf("header = %v, want EdDSA signed by k2", token.Header)
+	}
+	if claims.Issuer != cfg.Issuer || !claims.VerifyAudience(cfg.Audience, true) || claims.ID == "" {
+		t.Errorf("claims = %+v", claims.RegisteredClaims)
+	}
+	if lifetime := claims.ExpiresAt.Sub(claims.IssuedAt.Time); lifetime != cfg.Expiration {
+		t.Errorf("lifetime = %v, want %v", lifetime, cfg.Expiration)
+	}
+}
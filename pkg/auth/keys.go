@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sdshorin/generia/pkg/config"
+)
+
+// signingKey - ключ Ed25519, на который токены ссылаются по ID (kid)
+type signingKey struct {
+	id        string
+	private   ed25519.PrivateKey
+	retiredAt time.Time // Нулевое для активных ключей
+}
+
+// Keyring хранит ключи подписи токенов. Новые токены подписываются основным
+// ключом, в заголовке токена указан kid, поэтому смена основного ключа не
+// делает недействительными уже выданные токены. Выведенный ключ больше не
+// подписывает, но публикуется в JWKS, пока живут подписанные им токены.
+// Keyring нужен только auth-service: остальные сервисы получают публичные ключи из JWKS.
+type Keyring struct {
+	keys      map[string]*signingKey
+	primary   *signingKey
+	grace     time.Duration
+	ephemeral bool
+}
+
+// NewKeyring создает Keyring из конфигурации JWT. Без JWT_SIGNING_KEYS ключ
+// генерируется, только если это явно разрешено (JWT_ALLOW_EPHEMERAL_KEY):
+// токены перестают проверяться после перезапуска, и несколько экземпляров
+// auth-service не могут работать вместе.
+func NewKeyring(cfg config.JWTConfig) (*Keyring, error) {
+	kr := &Keyring{
+		keys:  make(map[string]*signingKey),
+		grace: cfg.Expiration + cfg.Leeway, // Токены принимаются еще Leeway после exp
+	}
+
+	active, err := parseSigningKeys(cfg.SigningKeys, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing keys: %w", err)
+	}
+	if len(active) == 0 {
+		if !cfg.AllowEphemeralKey {
+			return nil, errors.New("no signing key configured: set JWT_SIGNING_KEYS, or JWT_ALLOW_EPHEMERAL_KEY=true for local runs")
+		}
+		key, err := generateSigningKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		active = []*signingKey{key}
+		kr.ephemeral = true
+	}
+	retired, err := parseSigningKeys(cfg.RetiredSigningKeys, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retired signing keys: %w", err)
+	}
+
+	for _, key := range append(active, retired...) {
+		if _, ok := kr.keys[key.id]; ok {
+			return nil, fmt.Errorf("duplicate signing key ID %q", key.id)
+		}
+		kr.keys[key.id] = key
+	}
+
+	kr.primary = active[0]
+	if cfg.PrimaryKeyID != "" {
+		primary, ok := kr.keys[cfg.PrimaryKeyID]
+		if !ok || !primary.retiredAt.IsZero() {
+			return nil, fmt.Errorf("primary key %q is not an active signing key", cfg.PrimaryKeyID)
+		}
+		kr.primary = primary
+	}
+	return kr, nil
+}
+
+// parseSigningKeys разбирает пары "id:<seed в base64>" через запятую,
+// у выведенных ключей еще и "@<время в RFC 3339>"
+func parseSigningKeys(value string, retired bool) ([]*signingKey, error) {
+	var keys []*signingKey
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key := &signingKey{}
+		if retired {
+			at := strings.LastIndex(entry, "@")
+			if at < 0 {
+				return nil, fmt.Errorf("retired key %q has no retirement time", signingKeyIDOf(entry))
+			}
+			retiredAt, err := time.Parse(time.RFC3339, entry[at+1:])
+			if err != nil {
+				return nil, fmt.Errorf("retired key %q: %w", signingKeyIDOf(entry), err)
+			}
+			key.retiredAt = retiredAt
+			entry = entry[:at]
+		}
+
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || encoded == "" {
+			return nil, fmt.Errorf("key %q is not in the id:seed format", signingKeyIDOf(entry))
+		}
+		seed, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("key %q must be a base64 encoded %d byte Ed25519 seed", id, ed25519.SeedSize)
+		}
+		key.id, key.private = id, ed25519.NewKeyFromSeed(seed)
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// signingKeyIDOf возвращает ID из записи ключа, чтобы ошибки не содержали секрет.
+// В записи без ID двоеточие может найтись только во времени вывода после "@".
+func signingKeyIDOf(entry string) string {
+	entry, _, _ = strings.Cut(entry, "@")
+	id, _, ok := strings.Cut(entry, ":")
+	if !ok {
+		return ""
+	}
+	return id
+}
+
+// generateSigningKey создает временный ключ со случайным ID
+func generateSigningKey() (*signingKey, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	return &signingKey{id: "ephemeral-" + hex.EncodeToString(id), private: private}, nil
+}
+
+// Ephemeral сообщает, что ключ сгенерирован при запуске, а не задан в конфигурации
+func (kr *Keyring) Ephemeral() bool {
+	return kr.ephemeral
+}
+
+// Primary возвращает ID и закрытый ключ, которым подписываются новые токены
+func (kr *Keyring) Primary() (string, ed25519.PrivateKey) {
+	return kr.primary.id, kr.primary.private
+}
+
+// PublicKey возвращает публичный ключ по kid, если он еще проверяет токены
+func (kr *Keyring) PublicKey(kid string) (ed25519.PublicKey, bool) {
+	key, ok := kr.keys[kid]
+	if !ok || !kr.usable(key, time.Now()) {
+		return nil, false
+	}
+	return key.private.Public().(ed25519.PublicKey), true
+}
+
+// Keyfunc возвращает ключ проверки токена по kid. Ключи в памяти, ctx не нужен.
+func (kr *Keyring) Keyfunc(_ context.Context, token *jwt.Token) (interface{}, error) {
+	kid, err := tokenKeyID(token)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := kr.PublicKey(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+	return key, nil
+}
+
+// JWKS возвращает документ JWKS (RFC 7517) с публичными ключами, которые
+// еще проверяют токены: сначала активные, затем выведенные
+func (kr *Keyring) JWKS() ([]byte, error) {
+	now := time.Now()
+	keys := make([]*signingKey, 0, len(kr.keys))
+	for _, key := range kr.keys {
+		if kr.usable(key, now) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].retiredAt.IsZero() != keys[j].retiredAt.IsZero() {
+			return keys[i].retiredAt.IsZero()
+		}
+		return keys[i].id < keys[j].id
+	})
+
+	doc := JWKS{Keys: make([]JWK, 0, len(keys))}
+	for _, key := range keys {
+		doc.Keys = append(doc.Keys, newJWK(key.id, key.private.Public().(ed25519.PublicKey)))
+	}
+	return json.Marshal(doc)
+}
+
+// usable сообщает, проверяет ли ключ токены: выведенный ключ нужен, пока не истекут его токены
+func (kr *Keyring) usable(key *signingKey, now time.Time) bool {
+	return key.retiredAt.IsZero() || now.Before(key.retiredAt.Add(kr.grace))
+}
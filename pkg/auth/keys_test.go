@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sdshorin/generia/pkg/config"
+)
+
+// keyEntry returns a JWT_SIGNING_KEYS entry with a seed filled with b
+func keyEntry(id string, b byte) string {
+	return id + ":" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+}
+
+// jwksKeyIDs returns the kids of a JWKS document in order
+func jwksKeyIDs(t *testing.T, document []byte) []string {
+	t.Helper()
+	var doc JWKS
+	if err := json.Unmarshal(document, &doc); err != nil {
+		t.Fatalf("invalid JWKS: %v", err)
+	}
+	ids := make([]string, len(doc.Keys))
+	for i, key := range doc.Keys {
+		ids[i] = key.Kid
+	}
+	return ids
+}
+
+func TestNewKeyring(t *testing.T) {
+	retiredAt := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name        string
+		cfg         config.JWTConfig
+		wantPrimary string
+		wantErr     string // Part of the error, empty when the keyring is valid
+	}{
+		{
+			name:        "first key is primary",
+			cfg:         config.JWTConfig{SigningKeys: keyEntry("k1", 1) + ", " + keyEntry("k2", 2)},
+			wantPrimary: "k1",
+		},
+		{
+			name:        "primary key ID",
+			cfg:         config.JWTConfig{SigningKeys: keyEntry("k1", 1) + "," + keyEntry("k2", 2), PrimaryKeyID: "k2"},
+			wantPrimary: "k2",
+		},
+		{
+			name: "retired keys",
+			cfg: config.JWTConfig{
+				SigningKeys:        keyEntry("k2", 2),
+				RetiredSigningKeys: keyEntry("k1", 1) + "@" + retiredAt,
+			},
+			wantPrimary: "k2",
+		},
+		{
+			name:    "no keys",
+			cfg:     config.JWTConfig{},
+			wantErr: "no signing key configured",
+		},
+		{
+			name:    "seed is not base64",
+			cfg:     config.JWTConfig{SigningKeys: "k1:not base64!"},
+			wantErr: `key "k1" must be a base64 encoded 32 byte Ed25519 seed`,
+		},
+		{
+			name:    "seed is too short",
+			cfg:     config.JWTConfig{SigningKeys: "k1:" + base64.StdEncoding.EncodeToString([]byte("short"))},
+			wantErr: `key "k1" must be a base64 encoded 32 byte Ed25519 seed`,
+		},
+		{
+			name:    "no ID",
+			cfg:     config.JWTConfig{SigningKeys: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))},
+			wantErr: "is not in the id:seed format",
+		},
+		{
+			name: "retired key without ID",
+			cfg: config.JWTConfig{
+				SigningKeys:        keyEntry("k2", 2),
+				RetiredSigningKeys: strings.TrimPrefix(keyEntry("", 1), ":") + "@" + retiredAt,
+			},
+			wantErr: "is not in the id:seed format",
+		},
+		{
+			name:    "duplicate key ID",
+			cfg:     config.JWTConfig{SigningKeys: keyEntry("k1", 1) + "," + keyEntry("k1", 2)},
+			wantErr: `duplicate signing key ID "k1"`,
+		},
+		{
+			name: "active key ID reused by a retired key",
+			cfg: config.JWTConfig{
+				SigningKeys:        keyEntry("k1", 1),
+				RetiredSigningKeys: keyEntry("k1", 2) + "@" + retiredAt,
+			},
+			wantErr: `duplicate signing key ID "k1"`,
+		},
+		{
+			name:    "retired key without time",
+			cfg:     config.JWTConfig{SigningKeys: keyEntry("k2", 2), RetiredSigningKeys: keyEntry("k1", 1)},
+			wantErr: `retired key "k1" has no retirement time`,
+		},
+		{
+			name:    "retired key with invalid time",
+			cfg:     config.JWTConfig{SigningKeys: keyEntry("k2", 2), RetiredSigningKeys: keyEntry("k1", 1) + "@yesterday"},
+			wantErr: `retired key "k1"`,
+		},
+		{
+			name:    "unknown primary key",
+			cfg:     config.JWTConfig{SigningKeys: keyEntry("k1", 1), PrimaryKeyID: "k9"},
+			wantErr: `primary key "k9" is not an active signing key`,
+		},
+		{
+			name: "retired primary key",
+			cfg: config.JWTConfig{
+				SigningKeys:        keyEntry("k2", 2),
+				RetiredSigningKeys: keyEntry("k1", 1) + "@" + retiredAt,
+				PrimaryKeyID:       "k1",
+			},
+			wantErr: `primary key "k1" is not an active signing key`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kr, err := NewKeyring(tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				// The seed must not leak into errors
+				if strings.Contains(err.Error(), strings.TrimPrefix(keyEntry("", 1), ":")) {
+					t.Errorf("error contains the seed: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewKeyring: %v", err)
+			}
+			if kid, _ := kr.Primary(); kid != tt.wantPrimary {
+				t.Errorf("primary key = %q, want %q", kid, tt.wantPrimary)
+			}
+			if kr.Ephemeral() {
+				t.Error("configured keyring is ephemeral")
+			}
+		})
+	}
+}
+
+func TestNewKeyringEphemeral(t *testing.T) {
+	kr, err := NewKeyring(config.JWTConfig{AllowEphemeralKey: true})
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	if kid, _ := kr.Primary(); !kr.Ephemeral() || !strings.HasPrefix(kid, "ephemeral-") {
+		t.Errorf("primary key %q, ephemeral %v", kid, kr.Ephemeral())
+	}
+}
+
+func TestRetiredKeyGrace(t *testing.T) {
+	// Tokens of a retired key are accepted for their lifetime and the leeway
+	const expiration, leeway = time.Hour, time.Minute
+	tests := []struct {
+		name      string
+		retiredAt time.Time
+		published bool
+	}{
+		{"tokens not expired", time.Now().Add(-expiration + time.Minute), true},
+		{"tokens expired within the leeway", time.Now().Add(-expiration - leeway/2), true},
+		{"tokens expired before the leeway", time.Now().Add(-expiration - 2*leeway), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kr, err := NewKeyring(config.JWTConfig{
+				Expiration:         expiration,
+				Leeway:             leeway,
+				SigningKeys:        keyEntry("k2", 2),
+				RetiredSigningKeys: keyEntry("k1", 1) + "@" + tt.retiredAt.UTC().Format(time.RFC3339),
+			})
+			if err != nil {
+				t.Fatalf("NewKeyring: %v", err)
+			}
+
+			document, err := kr.JWKS()
+			if err != nil {
+				t.Fatalf("JWKS: %v", err)
+			}
+			want := []string{"k2"}
+			if tt.published {
+				want = append(want, "k1") // Active keys come first
+			}
+			if got := jwksKeyIDs(t, document); !slices.Equal(got, want) {
+				t.Errorf("JWKS keys = %q, want %q", got, want)
+			}
+			if _, ok := kr.PublicKey("k1"); ok != tt.published {
+				t.Errorf("retired key usable = %v, want %v", ok, tt.published)
+			}
+		})
+	}
+}
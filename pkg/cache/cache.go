@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sdshorin/generia/pkg/logger"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
+)
+
+// ErrNotFound is returned by a loader when the value does not exist.
+// It is cached for NegativeTTL, so lookups of missing IDs do not reach the database.
+var ErrNotFound = errors.New("cache: not found")
+
+// Entry markers, the first byte of every stored value
+const (
+	entryValue   byte = 'v'
+	entryMissing byte = 'n'
+)
+
+// Config holds cache-aside configuration
+type Config struct {
+	TTL         time.Duration // Defaults to 5m
+	Jitter      float64       // Fraction of TTL added or taken at random, so entries loaded together expire apart; defaults to 0.1
+	NegativeTTL time.Duration // How long ErrNotFound is cached, defaults to 30s
+	Timeout     time.Duration // Deadline of a cache-service call, defaults to 200ms
+	LoadTimeout time.Duration // Deadline of a loader, defaults to 10s
+}
+
+var requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "cache_client_requests_total",
+	Help: "Number of cache-aside lookups by namespace and result (hit, negative_hit, miss, error)",
+}, []string{"namespace", "result"})
+
+// Cache is a cache-aside client of cache-service. Keys are prefixed with the
+// namespace, normally the service name, so services never read each other's entries.
+// Cache-service failures are logged and the value is loaded directly: the
+// cache may slow a request down by at most Timeout but never fail it.
+type Cache struct {
+	client    cachepb.CacheServiceClient
+	namespace string
+	config    Config
+	group     singleflight.Group
+}
+
+// New creates a new cache. A nil client disables caching, values are always loaded.
+func New(client cachepb.CacheServiceClient, namespace string, config Config) *Cache {
+	if config.TTL <= 0 {
+		config.TTL = 5 * time.Minute
+	}
+	if config.Jitter <= 0 {
+		config.Jitter = 0.1
+	}
+	if config.NegativeTTL <= 0 {
+		config.NegativeTTL = 30 * time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 200 * time.Millisecond
+	}
+	if config.LoadTimeout <= 0 {
+		config.LoadTimeout = 10 * time.Second
+	}
+
+	return &Cache{
+		client:    client,
+		namespace: namespace,
+		config:    config,
+	}
+}
+
+// GetOrLoad returns the cached value of key or loads, caches and returns it.
+// Concurrent misses of one key share a single load. A loader returning
+// ErrNotFound has the miss cached, other loader errors are not cached.
+func GetOrLoad[T any](ctx context.Context, c *Cache, key string, codec Codec[T], load func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	key = c.key(key)
+
+	// Callers share encoded entries, each decodes its own copy and may modify it
+	result := c.group.DoChan(key, func() (interface{}, error) {
+		// The load outlives a caller that gives up, others may still wait for it
+		ctx := context.WithoutCancel(ctx)
+
+		if entry, ok := c.get(ctx, key); ok {
+			return entry, nil
+		}
+
+		loadCtx, cancel := context.WithTimeout(ctx, c.config.LoadTimeout)
+		value, err := load(loadCtx)
+		cancel()
+		if errors.Is(err, ErrNotFound) {
+			entry := []byte{entryMissing}
+			c.set(ctx, key, entry, c.config.NegativeTTL)
+			return entry, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := codec.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", key, err)
+		}
+		entry := append([]byte{entryValue}, data...)
+		c.set(ctx, key, entry, c.config.TTL)
+		return entry, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		entry := res.Val.([]byte)
+		if entry[0] == entryMissing {
+			return zero, ErrNotFound
+		}
+		value, err := codec.Unmarshal(entry[1:])
+		if err != nil {
+			return zero, fmt.Errorf("failed to decode %s: %w", key, err)
+		}
+		return value, nil
+	}
+}
+
+// Delete removes keys, to be called after the values they cache change
+func (c *Cache) Delete(ctx context.Context, keys ...string) {
+	if c.client == nil || len(keys) == 0 {
+		return
+	}
+
+	namespaced := make([]string, len(keys))
+	for i, key := range keys {
+		namespaced[i] = c.key(key)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	if _, err := c.client.MDelete(ctx, &cachepb.MDeleteRequest{Keys: namespaced}); err != nil {
+		logger.Logger.Warn("Failed to delete cache keys", zap.Strings("keys", namespaced), zap.Error(err))
+	}
+}
+
+func (c *Cache) key(key string) string {
+	return c.namespace + ":" + key
+}
+
+// get returns a stored entry, cache-service errors count as misses
+func (c *Cache) get(ctx context.Context, key string) ([]byte, bool) {
+	if c.client == nil {
+		return nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	resp, err := c.client.Get(ctx, &cachepb.GetRequest{Key: key})
+	if err != nil {
+		logger.Logger.Warn("Failed to get cache key", zap.String("key", key), zap.Error(err))
+		requestsTotal.WithLabelValues(c.namespace, "error").Inc()
+		return nil, false
+	}
+	if !resp.Exists || len(resp.Value) == 0 || (resp.Value[0] != entryValue && resp.Value[0] != entryMissing) {
+		requestsTotal.WithLabelValues(c.namespace, "miss").Inc()
+		return nil, false
+	}
+
+	if resp.Value[0] == entryMissing {
+		requestsTotal.WithLabelValues(c.namespace, "negative_hit").Inc()
+	} else {
+		requestsTotal.WithLabelValues(c.namespace, "hit").Inc()
+	}
+	return resp.Value, true
+}
+
+// set stores an entry with a jittered TTL, failures are only logged
+func (c *Cache) set(ctx context.Context, key string, entry []byte, ttl time.Duration) {
+	if c.client == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	_, err := c.client.Set(ctx, &cachepb.SetRequest{
+		Key:   key,
+		Value: entry,
+		Ttl:   int32(c.jitter(ttl) / time.Second),
+	})
+	if err != nil {
+		logger.Logger.Warn("Failed to set cache key", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// jitter moves ttl by up to Jitter of its length in either direction, keeping it at least a second
+func (c *Cache) jitter(ttl time.Duration) time.Duration {
+	delta := time.Duration((rand.Float64()*2 - 1) * c.config.Jitter * float64(ttl))
+	return max(ttl+delta, time.Second)
+}
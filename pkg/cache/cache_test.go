@@ -0,0 +1,309 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sdshorin/generia/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
+)
+
+func TestMain(m *testing.M) {
+	logger.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// fakeClient is an in-memory cache-service, err fails every call
+type fakeClient struct {
+	cachepb.CacheServiceClient
+
+	mu      sync.Mutex
+	entries map[string][]byte
+	ttls    map[string]int32
+	err     error
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{entries: make(map[string][]byte), ttls: make(map[string]int32)}
+}
+
+func (f *fakeClient) Get(ctx context.Context, req *cachepb.GetRequest, opts ...grpc.CallOption) (*cachepb.GetResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	value, ok := f.entries[req.Key]
+	return &cachepb.GetResponse{Exists: ok, Value: value}, nil
+}
+
+func (f *fakeClient) Set(ctx context.Context, req *cachepb.SetRequest, opts ...grpc.CallOption) (*cachepb.SetResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.entries[req.Key] = req.Value
+	f.ttls[req.Key] = req.Ttl
+	return &cachepb.SetResponse{Success: true}, nil
+}
+
+func (f *fakeClient) MDelete(ctx context.Context, req *cachepb.MDeleteRequest, opts ...grpc.CallOption) (*cachepb.MDeleteResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, key := range req.Keys {
+		delete(f.entries, key)
+	}
+	return &cachepb.MDeleteResponse{}, nil
+}
+
+type item struct {
+	Name string `json:"name"`
+}
+
+// countingLoader returns value or err and counts its calls
+type countingLoader struct {
+	value item
+	err   error
+	calls int
+}
+
+func (l *countingLoader) load(ctx context.Context) (item, error) {
+	l.calls++
+	return l.value, l.err
+}
+
+func TestGetOrLoad(t *testing.T) {
+	loadErr := errors.New("database is down")
+
+	tests := []struct {
+		name      string
+		client    *fakeClient
+		cached    map[string][]byte // Entries stored before the lookups
+		loadValue item
+		loadErr   error
+		lookups   int
+		want      item
+		wantErr   error
+		wantLoads int
+	}{
+		{
+			name:      "miss is loaded once",
+			client:    newFakeClient(),
+			loadValue: item{Name: "a"},
+			lookups:   3,
+			want:      item{Name: "a"},
+			wantLoads: 1,
+		},
+		{
+			name:      "hit is not loaded",
+			client:    newFakeClient(),
+			cached:    map[string][]byte{"svc:key": []byte(`v{"name":"cached"}`)},
+			loadValue: item{Name: "a"},
+			lookups:   2,
+			want:      item{Name: "cached"},
+		},
+		{
+			name:      "not found is cached",
+			client:    newFakeClient(),
+			loadErr:   ErrNotFound,
+			lookups:   3,
+			wantErr:   ErrNotFound,
+			wantLoads: 1,
+		},
+		{
+			name:      "loader errors are not cached",
+			client:    newFakeClient(),
+			loadErr:   loadErr,
+			lookups:   3,
+			wantErr:   loadErr,
+			wantLoads: 3,
+		},
+		{
+			name:      "unknown entries are reloaded",
+			client:    newFakeClient(),
+			cached:    map[string][]byte{"svc:key": []byte("garbage")},
+			loadValue: item{Name: "a"},
+			lookups:   2,
+			want:      item{Name: "a"},
+			wantLoads: 1,
+		},
+		{
+			name:      "cache-service errors fall back to the loader",
+			client:    &fakeClient{err: errors.New("unavailable")},
+			loadValue: item{Name: "a"},
+			lookups:   2,
+			want:      item{Name: "a"},
+			wantLoads: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.cached {
+				tt.client.entries[key] = value
+			}
+			c := New(tt.client, "svc", Config{})
+			loader := &countingLoader{value: tt.loadValue, err: tt.loadErr}
+
+			for i := 0; i < tt.lookups; i++ {
+				got, err := GetOrLoad(context.Background(), c, "key", JSON[item](), loader.load)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("lookup %d: error = %v, want %v", i, err, tt.wantErr)
+				}
+				if got != tt.want {
+					t.Fatalf("lookup %d: value = %+v, want %+v", i, got, tt.want)
+				}
+			}
+			if loader.calls != tt.wantLoads {
+				t.Errorf("loads = %d, want %d", loader.calls, tt.wantLoads)
+			}
+		})
+	}
+}
+
+func TestGetOrLoadWithoutClient(t *testing.T) {
+	c := New(nil, "svc", Config{})
+	loader := &countingLoader{value: item{Name: "a"}}
+
+	for i := 0; i < 2; i++ {
+		got, err := GetOrLoad(context.Background(), c, "key", JSON[item](), loader.load)
+		if err != nil || got.Name != "a" {
+			t.Fatalf("GetOrLoad = %+v, %v", got, err)
+		}
+	}
+	if loader.calls != 2 {
+		t.Errorf("loads = %d, want 2", loader.calls)
+	}
+	c.Delete(context.Background(), "key")
+}
+
+func TestGetOrLoadTTL(t *testing.T) {
+	client := newFakeClient()
+	c := New(client, "svc", Config{TTL: 100 * time.Second, Jitter: 0.1, NegativeTTL: 10 * time.Second})
+
+	if _, err := GetOrLoad(context.Background(), c, "found", JSON[item](), func(ctx context.Context) (item, error) {
+		return item{Name: "a"}, nil
+	}); err != nil {
+		t.Fatalf("GetOrLoad: %v", err)
+	}
+	if _, err := GetOrLoad(context.Background(), c, "missing", JSON[item](), func(ctx context.Context) (item, error) {
+		return item{}, ErrNotFound
+	}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetOrLoad error = %v, want ErrNotFound", err)
+	}
+
+	if ttl := client.ttls["svc:found"]; ttl < 90 || ttl > 110 {
+		t.Errorf("value TTL = %ds, want 90-110s", ttl)
+	}
+	if ttl := client.ttls["svc:missing"]; ttl < 9 || ttl > 11 {
+		t.Errorf("not found TTL = %ds, want 9-11s", ttl)
+	}
+}
+
+func TestGetOrLoadSharesConcurrentLoads(t *testing.T) {
+	c := New(newFakeClient(), "svc", Config{})
+	release := make(chan struct{})
+	var mu sync.Mutex
+	calls := 0
+
+	load := func(ctx context.Context) (item, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		<-release
+		return item{Name: "a"}, nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make(chan item, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := GetOrLoad(context.Background(), c, "key", JSON[item](), load)
+			if err != nil {
+				t.Errorf("GetOrLoad: %v", err)
+			}
+			results <- value
+		}()
+	}
+
+	// Let every caller join the load in flight before it finishes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for value := range results {
+		if value.Name != "a" {
+			t.Errorf("value = %+v, want a", value)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("loads = %d, want 1", calls)
+	}
+}
+
+func TestGetOrLoadCallerGivesUp(t *testing.T) {
+	client := newFakeClient()
+	c := New(client, "svc", Config{})
+	release := make(chan struct{})
+	loaded := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := GetOrLoad(ctx, c, "key", JSON[item](), func(ctx context.Context) (item, error) {
+		defer close(loaded)
+		<-release
+		return item{Name: "a"}, ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+
+	// The load keeps going for other callers and is cached
+	close(release)
+	<-loaded
+	deadline := time.Now().Add(time.Second)
+	for {
+		client.mu.Lock()
+		_, ok := client.entries["svc:key"]
+		client.mu.Unlock()
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("abandoned load was not cached")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	client := newFakeClient()
+	client.entries["svc:a"] = []byte("va")
+	client.entries["svc:b"] = []byte("vb")
+	client.entries["other:a"] = []byte("va")
+
+	New(client, "svc", Config{}).Delete(context.Background(), "a", "b")
+
+	if len(client.entries) != 1 || client.entries["other:a"] == nil {
+		t.Errorf("entries after Delete = %v, want only other:a", client.entries)
+	}
+}
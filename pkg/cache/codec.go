@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"encoding/json"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec encodes cached values
+type Codec[T any] interface {
+	Marshal(value T) ([]byte, error)
+	Unmarshal(data []byte) (T, error)
+}
+
+// JSON returns a codec storing values as JSON
+func JSON[T any]() Codec[T] {
+	return jsonCodec[T]{}
+}
+
+type jsonCodec[T any] struct{}
+
+func (jsonCodec[T]) Marshal(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (jsonCodec[T]) Unmarshal(data []byte) (T, error) {
+	var value T
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+// Proto returns a codec storing protobuf messages in the wire format
+func Proto[T proto.Message]() Codec[T] {
+	return protoCodec[T]{}
+}
+
+type protoCodec[T proto.Message] struct{}
+
+func (protoCodec[T]) Marshal(value T) ([]byte, error) {
+	return proto.Marshal(value)
+}
+
+func (protoCodec[T]) Unmarshal(data []byte) (T, error) {
+	// A nil message still knows its type, New allocates an empty one
+	var zero T
+	value := zero.ProtoReflect().New().Interface().(T)
+	err := proto.Unmarshal(data, value)
+	return value, err
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -22,6 +23,7 @@ type Config struct {
 	Kafka        KafkaConfig
 	CDN          CDNConfig
 	Jaeger       JaegerConfig
+	Mail         MailConfig
 }
 
 // ServiceConfig holds service-related configuration
@@ -44,8 +46,25 @@ type DatabaseConfig struct {
 
 // JWTConfig holds JWT-related configuration
 type JWTConfig struct {
-	Secret     string
 	Expiration time.Duration
+	Issuer     string        // "iss" of access tokens
+	Audience   string        // "aud" of access tokens
+	Leeway     time.Duration // Allowed clock skew between services when checking token times
+
+	// Ed25519 signing keys of auth-service as "id:<base64 seed>" pairs, retired keys
+	// as "id:<base64 seed>@<RFC 3339 time>". Retired keys stay published for Expiration plus Leeway.
+	SigningKeys        string
+	PrimaryKeyID       string // Key that signs new tokens, defaults to the first signing key
+	RetiredSigningKeys string
+	// Without SigningKeys auth-service refuses to start unless this is set; it then
+	// generates a key that dies with the process, for local single-instance runs only.
+	AllowEphemeralKey bool
+
+	JWKSRefreshInterval time.Duration // How often verifiers reload the published keys
+
+	// Accept tokens when the deny-list can't be checked, so a cache-service outage
+	// doesn't log everyone out. By default such tokens are rejected.
+	DenyListFailOpen bool
 }
 
 // ConsulConfig holds Consul-related configuration
@@ -96,6 +115,15 @@ type CDNConfig struct {
 	Domain     string
 	DefaultTTL int
 	SigningKey string
+	HTTPPort   int    // Port of the HTTP listener serving signed URLs
+	PublicURL  string // Base of signed URLs, e.g. "https://cdn.example.com"
+
+	// Key rotation: "id:secret" pairs, retired keys as "id:secret@<RFC 3339 time>".
+	// Without SigningKeys, SigningKey is used with the key ID "default"; one of them is required.
+	SigningKeys        string
+	PrimaryKeyID       string // Key that signs new URLs, defaults to the first signing key
+	RetiredSigningKeys string
+	KeyGracePeriod     time.Duration // How long retired keys still verify URLs
 }
 
 // JaegerConfig holds Jaeger-related configuration
@@ -104,6 +132,18 @@ type JaegerConfig struct {
 	Port string
 }
 
+// MailConfig holds mail-related configuration
+type MailConfig struct {
+	Driver   string // "smtp", "file" or "memory"
+	Host     string // SMTP server
+	Port     int
+	Username string
+	Password string
+	From     string
+	Dir      string // Directory the file driver writes messages to
+	AppURL   string // Base of links in emails, e.g. "https://generia.example.com"
+}
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() (*Config, error) {
 	// Load .env file if it exists
@@ -127,13 +167,36 @@ func LoadConfig() (*Config, error) {
 	dbSSLMode := getEnv("DB_SSL_MODE", "disable")
 
 	// JWT configuration
-	jwtSecret := getEnv("JWT_SECRET", "your_jwt_secret_key")
 	jwtExpirationStr := getEnv("JWT_EXPIRATION", "24h")
 	jwtExpiration, err := time.ParseDuration(jwtExpirationStr)
 	if err != nil {
 		return nil, fmt.Errorf("invalid JWT expiration: %s", jwtExpirationStr)
 	}
 
+	jwksRefreshIntervalStr := getEnv("JWT_JWKS_REFRESH_INTERVAL", "5m")
+	jwksRefreshInterval, err := time.ParseDuration(jwksRefreshIntervalStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWKS refresh interval: %s", jwksRefreshIntervalStr)
+	}
+
+	jwtLeewayStr := getEnv("JWT_LEEWAY", "30s")
+	jwtLeeway, err := time.ParseDuration(jwtLeewayStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT leeway: %s", jwtLeewayStr)
+	}
+
+	allowEphemeralKeyStr := getEnv("JWT_ALLOW_EPHEMERAL_KEY", "false")
+	allowEphemeralKey, err := strconv.ParseBool(allowEphemeralKeyStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral JWT key flag: %s", allowEphemeralKeyStr)
+	}
+
+	denyListFailOpenStr := getEnv("JWT_DENYLIST_FAIL_OPEN", "false")
+	denyListFailOpen, err := strconv.ParseBool(denyListFailOpenStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deny-list fail open flag: %s", denyListFailOpenStr)
+	}
+
 	// Consul configuration
 	consulAddress := getEnv("CONSUL_ADDRESS", "localhost:8500")
 
@@ -184,7 +247,7 @@ func LoadConfig() (*Config, error) {
 
 	// Kafka configuration
 	kafkaBrokersStr := getEnv("KAFKA_BROKERS", "localhost:9092")
-	kafkaBrokers := []string{kafkaBrokersStr}
+	kafkaBrokers := strings.Split(kafkaBrokersStr, ",")
 
 	// CDN configuration
 	cdnDomain := getEnv("CDN_DOMAIN", "localhost")
@@ -193,12 +256,32 @@ func LoadConfig() (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid CDN default TTL: %s", cdnDefaultTTLStr)
 	}
-	cdnSigningKey := getEnv("CDN_SIGNING_KEY", "your_cdn_signing_key")
+	cdnSigningKey := getEnv("CDN_SIGNING_KEY", "")
+	cdnHTTPPortStr := getEnv("CDN_HTTP_PORT", "8092")
+	cdnHTTPPort, err := strconv.Atoi(cdnHTTPPortStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CDN HTTP port: %s", cdnHTTPPortStr)
+	}
+	cdnPublicURL := strings.TrimSuffix(getEnv("CDN_PUBLIC_URL", "https://"+cdnDomain), "/")
+	cdnKeyGracePeriodStr := getEnv("CDN_KEY_GRACE_PERIOD", "24h")
+	cdnKeyGracePeriod, err := time.ParseDuration(cdnKeyGracePeriodStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CDN key grace period: %s", cdnKeyGracePeriodStr)
+	}
 	
 	// Jaeger configuration
 	jaegerHost := getEnv("JAEGER_HOST", "jaeger")
 	jaegerPort := getEnv("JAEGER_PORT", "6831")
 
+	// Mail configuration
+	mailDriver := getEnv("MAIL_DRIVER", "file")
+	mailSMTPPortStr := getEnv("MAIL_SMTP_PORT", "587")
+	mailSMTPPort, err := strconv.Atoi(mailSMTPPortStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mail SMTP port: %s", mailSMTPPortStr)
+	}
+	appURL := strings.TrimSuffix(getEnv("APP_URL", "http://localhost"), "/")
+
 	// Create database URL
 	dbURL := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s", 
 		dbUser, dbPassword, dbHost, dbPort, dbName, dbSSLMode)
@@ -219,8 +302,17 @@ func LoadConfig() (*Config, error) {
 			URL:      dbURL,
 		},
 		JWT: JWTConfig{
-			Secret:     jwtSecret,
 			Expiration: jwtExpiration,
+			Issuer:     getEnv("JWT_ISSUER", "auth-service"),
+			Audience:   getEnv("JWT_AUDIENCE", "generia"),
+			Leeway:     jwtLeeway,
+
+			SigningKeys:         getEnv("JWT_SIGNING_KEYS", ""),
+			PrimaryKeyID:        getEnv("JWT_PRIMARY_KEY_ID", ""),
+			RetiredSigningKeys:  getEnv("JWT_RETIRED_SIGNING_KEYS", ""),
+			AllowEphemeralKey:   allowEphemeralKey,
+			JWKSRefreshInterval: jwksRefreshInterval,
+			DenyListFailOpen:    denyListFailOpen,
 		},
 		Consul: ConsulConfig{
 			Address: consulAddress,
@@ -257,11 +349,28 @@ func LoadConfig() (*Config, error) {
 			Domain:     cdnDomain,
 			DefaultTTL: cdnDefaultTTL,
 			SigningKey: cdnSigningKey,
+			HTTPPort:   cdnHTTPPort,
+			PublicURL:  cdnPublicURL,
+
+			SigningKeys:        getEnv("CDN_SIGNING_KEYS", ""),
+			PrimaryKeyID:       getEnv("CDN_PRIMARY_KEY_ID", ""),
+			RetiredSigningKeys: getEnv("CDN_RETIRED_SIGNING_KEYS", ""),
+			KeyGracePeriod:     cdnKeyGracePeriod,
 		},
 		Jaeger: JaegerConfig{
 			Host: jaegerHost,
 			Port: jaegerPort,
 		},
+		Mail: MailConfig{
+			Driver:   mailDriver,
+			Host:     getEnv("MAIL_SMTP_HOST", "localhost"),
+			Port:     mailSMTPPort,
+			Username: getEnv("MAIL_SMTP_USERNAME", ""),
+			Password: getEnv("MAIL_SMTP_PASSWORD", ""),
+			From:     getEnv("MAIL_FROM", "Generia <no-reply@generia.local>"),
+			Dir:      getEnv("MAIL_DIR", "/tmp/generia-mail"),
+			AppURL:   appURL,
+		},
 	}, nil
 }
 
@@ -0,0 +1,36 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sdshorin/generia/pkg/kafka"
+)
+
+// Handler adapts a typed event handler to a Kafka message handler.
+// Malformed events are treated as poison messages and dead-lettered.
+func Handler[T any](fn func(ctx context.Context, event *Event, payload T) error) kafka.Handler {
+	return func(ctx context.Context, msg kafka.Message) error {
+		var event Event
+		if err := json.Unmarshal(msg.Value, &event); err != nil {
+			return kafka.Permanent(fmt.Errorf("failed to unmarshal event: %w", err))
+		}
+
+		var payload T
+		if err := event.DecodePayload(&payload); err != nil {
+			return kafka.Permanent(err)
+		}
+
+		return fn(ctx, &event, payload)
+	}
+}
+
+// NewConsumer creates a consumer group member for the current version of an event type
+func NewConsumer(brokers []string, groupID, eventType string, handler kafka.Handler) *kafka.Consumer {
+	return kafka.NewConsumer(kafka.ConsumerConfig{
+		Brokers: brokers,
+		GroupID: groupID,
+		Topic:   Topic(eventType),
+	}, handler)
+}
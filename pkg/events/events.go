@@ -0,0 +1,165 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types of the domain event catalog
+const (
+	PostCreated      = "post.created"
+	PostDeleted      = "post.deleted"
+	LikeAdded        = "like.added"
+	CommentAdded     = "comment.added"
+	WorldCreated     = "world.created"
+	WorldJoined      = "world.joined"
+	CharacterCreated = "character.created"
+	MediaUploaded    = "media.uploaded"
+)
+
+// versions holds the current schema version of every event type in the catalog.
+// Bump the version when a payload changes incompatibly: the new version is
+// published to its own topic, so existing consumers keep working.
+var versions = map[string]int{
+	PostCreated:      1,
+	PostDeleted:      1,
+	LikeAdded:        1,
+	CommentAdded:     1,
+	WorldCreated:     1,
+	WorldJoined:      1,
+	CharacterCreated: 1,
+	MediaUploaded:    1,
+}
+
+// Version returns the current schema version of an event type
+func Version(eventType string) int {
+	return versions[eventType]
+}
+
+// Topic returns the Kafka topic an event type is published to, e.g. "post.created.v1"
+func Topic(eventType string) string {
+	return fmt.Sprintf("%s.v%d", eventType, Version(eventType))
+}
+
+// Actor identifies who caused an event. AI characters have no user.
+type Actor struct {
+	UserID      string `json:"user_id,omitempty"`
+	CharacterID string `json:"character_id,omitempty"`
+}
+
+// Event is the envelope shared by all domain events
+type Event struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Version    int             `json:"version"`
+	WorldID    string          `json:"world_id"`
+	Actor      Actor           `json:"actor"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// NewEvent creates a new event of the given type with a marshalled payload
+func NewEvent(eventType, worldID string, actor Actor, payload interface{}) (*Event, error) {
+	version, ok := versions[eventType]
+	if !ok {
+		return nil, fmt.Errorf("unknown event type: %s", eventType)
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+
+	return &Event{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		Version:    version,
+		WorldID:    worldID,
+		Actor:      actor,
+		OccurredAt: time.Now().UTC(),
+		Payload:    payloadJSON,
+	}, nil
+}
+
+// DecodePayload unmarshals the event payload into v
+func (e *Event) DecodePayload(v interface{}) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("failed to unmarshal %s payload: %w", e.Type, err)
+	}
+	return nil
+}
+
+// PostCreatedPayload is the payload of post.created
+type PostCreatedPayload struct {
+	PostID      string    `json:"post_id"`
+	CharacterID string    `json:"character_id"`
+	MediaID     string    `json:"media_id"`
+	Caption     string    `json:"caption"`
+	IsAI        bool      `json:"is_ai"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Reasons of post.deleted
+const (
+	PostDeletedByAuthor     = "deleted"
+	PostDeletedByModeration = "moderated"
+)
+
+// PostDeletedPayload is the payload of post.deleted
+type PostDeletedPayload struct {
+	PostID      string    `json:"post_id"`
+	CharacterID string    `json:"character_id"`
+	MediaID     string    `json:"media_id"`
+	Reason      string    `json:"reason"`
+	DeletedAt   time.Time `json:"deleted_at"`
+}
+
+// LikeAddedPayload is the payload of like.added
+type LikeAddedPayload struct {
+	PostID     string    `json:"post_id"`
+	UserID     string    `json:"user_id"`
+	LikesCount int32     `json:"likes_count"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CommentAddedPayload is the payload of comment.added
+type CommentAddedPayload struct {
+	CommentID string    `json:"comment_id"`
+	PostID    string    `json:"post_id"`
+	UserID    string    `json:"user_id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WorldCreatedPayload is the payload of world.created
+type WorldCreatedPayload struct {
+	Name      string    `json:"name"`
+	CreatorID string    `json:"creator_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WorldJoinedPayload is the payload of world.joined
+type WorldJoinedPayload struct {
+	UserID   string    `json:"user_id"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// CharacterCreatedPayload is the payload of character.created
+type CharacterCreatedPayload struct {
+	CharacterID   string    `json:"character_id"`
+	RealUserID    string    `json:"real_user_id,omitempty"`
+	IsAI          bool      `json:"is_ai"`
+	DisplayName   string    `json:"display_name"`
+	AvatarMediaID string    `json:"avatar_media_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// MediaUploadedPayload is the payload of media.uploaded.
+// The media service consumes it to process the uploaded original.
+type MediaUploadedPayload struct {
+	MediaID    string    `json:"media_id"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
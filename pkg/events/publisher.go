@@ -0,0 +1,71 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sdshorin/generia/pkg/kafka"
+	"github.com/sdshorin/generia/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// IdempotencyKeyHeader carries the event ID so consumers can drop redeliveries
+const IdempotencyKeyHeader = "x-idempotency-key"
+
+// Publisher publishes domain events to the event bus
+type Publisher interface {
+	Publish(ctx context.Context, event *Event) error
+	Close() error
+}
+
+// KafkaPublisher implements Publisher on top of the Kafka producer
+type KafkaPublisher struct {
+	producer *kafka.Producer
+}
+
+// NewKafkaPublisher creates a new KafkaPublisher
+func NewKafkaPublisher(producer *kafka.Producer) Publisher {
+	return &KafkaPublisher{
+		producer: producer,
+	}
+}
+
+// Publish sends an event to its topic. Events are keyed by world ID so that
+// all events of a world land in the same partition and keep their order.
+func (p *KafkaPublisher) Publish(ctx context.Context, event *Event) error {
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	return p.producer.SendMessage(ctx, Topic(event.Type), []byte(event.WorldID), eventJSON,
+		kafka.Header{Key: IdempotencyKeyHeader, Value: []byte(event.ID)})
+}
+
+// Close closes the underlying producer
+func (p *KafkaPublisher) Close() error {
+	return p.producer.Close()
+}
+
+// Emit builds and publishes an event on a best-effort basis.
+// Failures are logged and never fail the calling request.
+func Emit(ctx context.Context, publisher Publisher, eventType, worldID string, actor Actor, payload interface{}) {
+	if publisher == nil {
+		return
+	}
+
+	event, err := NewEvent(eventType, worldID, actor, payload)
+	if err != nil {
+		logger.Logger.Error("Failed to build event", zap.Error(err), zap.String("type", eventType))
+		return
+	}
+
+	if err := publisher.Publish(ctx, event); err != nil {
+		logger.Logger.Error("Failed to publish event",
+			zap.Error(err),
+			zap.String("type", eventType),
+			zap.String("event_id", event.ID),
+			zap.String("world_id", worldID))
+	}
+}
@@ -0,0 +1,350 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sdshorin/generia/pkg/logger"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// Message is a message received from Kafka
+type Message = kafka.Message
+
+// Handler processes a single message. Returning an error triggers a retry,
+// unless the error is marked with Permanent.
+type Handler func(ctx context.Context, msg Message) error
+
+// permanentError marks an error that must not be retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps an error so the message is sent to the dead-letter topic
+// without further retries (e.g. malformed payloads)
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether an error was marked with Permanent
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+// JSONHandler adapts a typed handler to a Handler by unmarshalling the message value
+func JSONHandler[T any](fn func(ctx context.Context, value T) error) Handler {
+	return func(ctx context.Context, msg Message) error {
+		var value T
+		if err := json.Unmarshal(msg.Value, &value); err != nil {
+			return Permanent(fmt.Errorf("failed to unmarshal message: %w", err))
+		}
+		return fn(ctx, value)
+	}
+}
+
+// Headers added to messages sent to the dead-letter topic
+const (
+	HeaderOriginalTopic     = "x-original-topic"
+	HeaderOriginalPartition = "x-original-partition"
+	HeaderOriginalOffset    = "x-original-offset"
+	HeaderError             = "x-error"
+	HeaderAttempts          = "x-attempts"
+)
+
+// ConsumerConfig holds consumer configuration
+type ConsumerConfig struct {
+	Brokers         []string
+	GroupID         string
+	Topic           string
+	DeadLetterTopic string        // Defaults to "<topic>.dlq"
+	MaxRetries      int           // Retries after the first attempt, defaults to 3 (negative disables retries)
+	InitialBackoff  time.Duration // Defaults to 200ms
+	MaxBackoff      time.Duration // Defaults to 10s
+}
+
+var (
+	consumerMessagesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_consumer_messages_total",
+		Help: "Number of consumed messages by result (success, retry, dead_letter)",
+	}, []string{"topic", "group", "result"})
+
+	consumerFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_consumer_failures_total",
+		Help: "Number of failed handler invocations",
+	}, []string{"topic", "group"})
+
+	consumerHandleDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kafka_consumer_handle_duration_seconds",
+		Help:    "Duration of message handling including retries",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"topic", "group"})
+
+	consumerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_consumer_lag",
+		Help: "Number of messages behind the partition high watermark",
+	}, []string{"topic", "group", "partition"})
+)
+
+// withDefaults fills in the unset fields of the configuration
+func (config ConsumerConfig) withDefaults() ConsumerConfig {
+	if config.DeadLetterTopic == "" {
+		config.DeadLetterTopic = config.Topic + ".dlq"
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	} else if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = 200 * time.Millisecond
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 10 * time.Second
+	}
+	return config
+}
+
+// messageReader is the part of kafka.Reader the consumer uses
+type messageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// messageWriter is the part of kafka.Writer the consumer uses
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// Consumer reads messages of a topic as part of a consumer group.
+// Offsets are committed only after a message was handled or dead-lettered,
+// so delivery is at-least-once and handlers must be idempotent.
+type Consumer struct {
+	config     ConsumerConfig
+	reader     messageReader
+	deadLetter messageWriter
+	handler    Handler
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewConsumer creates a new Kafka consumer
+func NewConsumer(config ConsumerConfig, handler Handler) *Consumer {
+	config = config.withDefaults()
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     config.Brokers,
+		GroupID:     config.GroupID,
+		Topic:       config.Topic,
+		MinBytes:    1,
+		MaxBytes:    10e6,
+		StartOffset: kafka.FirstOffset,
+	})
+
+	deadLetter := &kafka.Writer{
+		Addr:                   kafka.TCP(config.Brokers...),
+		Topic:                  config.DeadLetterTopic,
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+	}
+
+	return newConsumer(config, handler, reader, deadLetter)
+}
+
+// newConsumer creates a consumer on top of the given reader and dead-letter writer
+func newConsumer(config ConsumerConfig, handler Handler, reader messageReader, deadLetter messageWriter) *Consumer {
+	return &Consumer{
+		config:     config,
+		reader:     reader,
+		deadLetter: deadLetter,
+		handler:    handler,
+		done:       make(chan struct{}),
+	}
+}
+
+// Start runs the consume loop in the background until Close is called
+func (c *Consumer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	go func() {
+		defer close(c.done)
+		c.run(ctx)
+	}()
+
+	logger.Logger.Info("Kafka consumer started",
+		zap.String("topic", c.config.Topic),
+		zap.String("group", c.config.GroupID))
+}
+
+// Close stops fetching, waits for the in-flight message and closes the
+// underlying connections. It is meant to be called on SIGTERM.
+func (c *Consumer) Close() error {
+	var err error
+	c.once.Do(func() {
+		if c.cancel != nil {
+			c.cancel()
+			<-c.done
+		}
+
+		if closeErr := c.reader.Close(); closeErr != nil {
+			err = fmt.Errorf("failed to close Kafka reader: %w", closeErr)
+		}
+		if closeErr := c.deadLetter.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close Kafka dead-letter writer: %w", closeErr)
+		}
+
+		logger.Logger.Info("Kafka consumer stopped",
+			zap.String("topic", c.config.Topic),
+			zap.String("group", c.config.GroupID))
+	})
+	return err
+}
+
+func (c *Consumer) run(ctx context.Context) {
+	for {
+		// A fetch may still return a buffered message after Close
+		if ctx.Err() != nil {
+			return
+		}
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Logger.Error("Failed to fetch message from Kafka",
+				zap.Error(err),
+				zap.String("topic", c.config.Topic))
+			if !sleep(ctx, c.config.InitialBackoff) {
+				return
+			}
+			continue
+		}
+
+		consumerLag.WithLabelValues(c.config.Topic, c.config.GroupID, strconv.Itoa(msg.Partition)).
+			Set(float64(msg.HighWaterMark - msg.Offset - 1))
+
+		if !c.process(ctx, msg) {
+			// Shutting down: leave the offset uncommitted so the message is redelivered
+			return
+		}
+
+		// The commit must survive shutdown of the consume loop
+		if err := c.reader.CommitMessages(context.Background(), msg); err != nil {
+			logger.Logger.Error("Failed to commit Kafka offset",
+				zap.Error(err),
+				zap.String("topic", msg.Topic),
+				zap.Int("partition", msg.Partition),
+				zap.Int64("offset", msg.Offset))
+		}
+	}
+}
+
+// process handles a message with retries and dead-lettering.
+// It returns false if the consumer was stopped before the message was settled.
+func (c *Consumer) process(ctx context.Context, msg Message) bool {
+	start := time.Now()
+	defer func() {
+		consumerHandleDuration.WithLabelValues(c.config.Topic, c.config.GroupID).Observe(time.Since(start).Seconds())
+	}()
+
+	backoff := c.config.InitialBackoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = c.handler(ctx, msg)
+		if err == nil {
+			consumerMessagesTotal.WithLabelValues(c.config.Topic, c.config.GroupID, "success").Inc()
+			return true
+		}
+
+		consumerFailuresTotal.WithLabelValues(c.config.Topic, c.config.GroupID).Inc()
+		logger.Logger.Warn("Failed to handle Kafka message",
+			zap.Error(err),
+			zap.String("topic", msg.Topic),
+			zap.Int("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+			zap.Int("attempt", attempt))
+
+		if IsPermanent(err) || attempt > c.config.MaxRetries {
+			return c.sendToDeadLetter(ctx, msg, err, attempt)
+		}
+
+		consumerMessagesTotal.WithLabelValues(c.config.Topic, c.config.GroupID, "retry").Inc()
+		if !sleep(ctx, backoff) {
+			return false
+		}
+		backoff *= 2
+		if backoff > c.config.MaxBackoff {
+			backoff = c.config.MaxBackoff
+		}
+	}
+}
+
+// sendToDeadLetter moves a poison message to the dead-letter topic.
+// If the dead-letter topic is unavailable it keeps retrying, because
+// committing the offset without it would lose the message.
+func (c *Consumer) sendToDeadLetter(ctx context.Context, msg Message, handleErr error, attempts int) bool {
+	headers := append(msg.Headers[:len(msg.Headers):len(msg.Headers)],
+		kafka.Header{Key: HeaderOriginalTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderOriginalPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderOriginalOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: HeaderError, Value: []byte(handleErr.Error())},
+		kafka.Header{Key: HeaderAttempts, Value: []byte(strconv.Itoa(attempts))},
+	)
+
+	for {
+		err := c.deadLetter.WriteMessages(ctx, kafka.Message{
+			Key:     msg.Key,
+			Value:   msg.Value,
+			Headers: headers,
+		})
+		if err == nil {
+			consumerMessagesTotal.WithLabelValues(c.config.Topic, c.config.GroupID, "dead_letter").Inc()
+			logger.Logger.Error("Message sent to dead-letter topic",
+				zap.Error(handleErr),
+				zap.String("topic", msg.Topic),
+				zap.String("dead_letter_topic", c.config.DeadLetterTopic),
+				zap.Int64("offset", msg.Offset))
+			return true
+		}
+
+		logger.Logger.Error("Failed to write message to dead-letter topic",
+			zap.Error(err),
+			zap.String("dead_letter_topic", c.config.DeadLetterTopic))
+		if !sleep(ctx, c.config.MaxBackoff) {
+			return false
+		}
+	}
+}
+
+// sleep waits for the given duration and returns false if the context was cancelled
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,271 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sdshorin/generia/pkg/logger"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// fakeReader serves queued messages and records commits
+type fakeReader struct {
+	messages chan kafka.Message
+
+	mu        sync.Mutex
+	committed []int64
+}
+
+func newFakeReader(msgs ...kafka.Message) *fakeReader {
+	r := &fakeReader{messages: make(chan kafka.Message, len(msgs))}
+	for _, msg := range msgs {
+		r.messages <- msg
+	}
+	return r
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case msg := <-r.messages:
+		return msg, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+	return nil
+}
+
+func (r *fakeReader) Close() error { return nil }
+
+func (r *fakeReader) commits() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.committed...)
+}
+
+// fakeWriter records dead-lettered messages, the first failures writes fail
+type fakeWriter struct {
+	mu       sync.Mutex
+	failures int
+	written  []kafka.Message
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.failures > 0 {
+		w.failures--
+		return errors.New("broker unavailable")
+	}
+	w.written = append(w.written, msgs...)
+	return nil
+}
+
+func (w *fakeWriter) Close() error { return nil }
+
+func (w *fakeWriter) messages() []kafka.Message {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]kafka.Message(nil), w.written...)
+}
+
+// waitFor polls cond until it holds or a second passes
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func header(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func TestConsumerRetriesAndDeadLetters(t *testing.T) {
+	handleErr := errors.New("database is down")
+
+	tests := []struct {
+		name           string
+		maxRetries     int
+		failures       int   // Failed attempts before the handler succeeds, -1 fails every attempt
+		err            error // Returned by failed attempts
+		dlqFailures    int
+		wantAttempts   int
+		wantDeadLetter bool
+	}{
+		{"success", 3, 0, handleErr, 0, 1, false},
+		{"retried until success", 3, 2, handleErr, 0, 3, false},
+		{"dead-lettered after max attempts", 3, -1, handleErr, 0, 4, true},
+		{"retries disabled", -1, -1, handleErr, 0, 1, true},
+		{"permanent error skips retries", 3, -1, Permanent(handleErr), 0, 1, true},
+		{"dead-letter write is retried", 1, -1, handleErr, 2, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := kafka.Message{
+				Topic:     "events",
+				Partition: 2,
+				Offset:    7,
+				Key:       []byte("key"),
+				Value:     []byte("value"),
+				Headers:   []kafka.Header{{Key: "trace", Value: []byte("t")}},
+			}
+			reader := newFakeReader(msg)
+			writer := &fakeWriter{failures: tt.dlqFailures}
+
+			var mu sync.Mutex
+			attempts := 0
+			handler := func(ctx context.Context, msg Message) error {
+				mu.Lock()
+				defer mu.Unlock()
+				attempts++
+				if tt.failures < 0 || attempts <= tt.failures {
+					return tt.err
+				}
+				return nil
+			}
+
+			config := ConsumerConfig{
+				Topic:          "events",
+				GroupID:        "group",
+				MaxRetries:     tt.maxRetries,
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     time.Millisecond,
+			}.withDefaults()
+			if config.DeadLetterTopic != "events.dlq" {
+				t.Fatalf("dead-letter topic = %q, want events.dlq", config.DeadLetterTopic)
+			}
+			c := newConsumer(config, handler, reader, writer)
+			c.Start()
+			waitFor(t, "commit", func() bool { return len(reader.commits()) == 1 })
+			if err := c.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			dead := writer.messages()
+			if !tt.wantDeadLetter {
+				if len(dead) != 0 {
+					t.Errorf("%d messages dead-lettered, want none", len(dead))
+				}
+				return
+			}
+			if len(dead) != 1 {
+				t.Fatalf("%d messages dead-lettered, want 1", len(dead))
+			}
+			got := dead[0]
+			if string(got.Key) != "key" || string(got.Value) != "value" {
+				t.Errorf("dead-lettered message = %q: %q", got.Key, got.Value)
+			}
+			wantHeaders := map[string]string{
+				"trace":                 "t",
+				HeaderOriginalTopic:     "events",
+				HeaderOriginalPartition: "2",
+				HeaderOriginalOffset:    "7",
+				HeaderError:             handleErr.Error(),
+				HeaderAttempts:          strconv.Itoa(tt.wantAttempts),
+			}
+			for key, want := range wantHeaders {
+				if value := header(got, key); value != want {
+					t.Errorf("header %s = %q, want %q", key, value, want)
+				}
+			}
+		})
+	}
+}
+
+func TestConsumerCloseWaitsForInFlightMessage(t *testing.T) {
+	reader := newFakeReader(kafka.Message{Offset: 1}, kafka.Message{Offset: 2})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handled := 0
+	handler := func(ctx context.Context, msg Message) error {
+		handled++
+		close(started)
+		<-release
+		return nil
+	}
+
+	config := ConsumerConfig{Topic: "events", InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}.withDefaults()
+	c := newConsumer(config, handler, reader, &fakeWriter{})
+	c.Start()
+	<-started
+
+	closed := make(chan error)
+	go func() { closed <- c.Close() }()
+	select {
+	case <-closed:
+		t.Fatal("Close returned while a message was being handled")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-closed; err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if commits := reader.commits(); len(commits) != 1 || commits[0] != 1 {
+		t.Errorf("committed offsets = %v, want [1]", commits)
+	}
+	if handled != 1 {
+		t.Errorf("handled %d messages, want 1", handled)
+	}
+}
+
+func TestConsumerCloseDuringBackoffLeavesMessageUncommitted(t *testing.T) {
+	reader := newFakeReader(kafka.Message{Offset: 1})
+	failed := make(chan struct{}, 1)
+	handler := func(ctx context.Context, msg Message) error {
+		select {
+		case failed <- struct{}{}:
+		default:
+		}
+		return errors.New("database is down")
+	}
+
+	config := ConsumerConfig{Topic: "events", InitialBackoff: time.Hour, MaxBackoff: time.Hour}.withDefaults()
+	writer := &fakeWriter{}
+	c := newConsumer(config, handler, reader, writer)
+	c.Start()
+	<-failed
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// The message is redelivered after a restart
+	if commits := reader.commits(); len(commits) != 0 {
+		t.Errorf("committed offsets = %v, want none", commits)
+	}
+	if dead := writer.messages(); len(dead) != 0 {
+		t.Errorf("%d messages dead-lettered, want none", len(dead))
+	}
+}
@@ -11,6 +11,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// Header is a Kafka message header
+type Header = kafka.Header
+
 // Producer is a Kafka producer client
 type Producer struct {
 	writer *kafka.Writer
@@ -24,6 +27,8 @@ func NewProducer(brokers []string) *Producer {
 		Balancer:     &kafka.LeastBytes{},
 		BatchTimeout: 10 * time.Millisecond,
 		RequiredAcks: kafka.RequireOne,
+		// Topics of the event catalog are created on first publish
+		AllowAutoTopicCreation: true,
 	}
 
 	return &Producer{
@@ -33,12 +38,20 @@ func NewProducer(brokers []string) *Producer {
 
 // Send sends a message to a topic
 func (p *Producer) Send(topic string, message []byte) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	return p.SendMessage(context.Background(), topic, nil, message)
+}
+
+// SendMessage sends a keyed message with optional headers to a topic.
+// Messages with the same key are written to the same partition.
+func (p *Producer) SendMessage(ctx context.Context, topic string, key, message []byte, headers ...Header) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	err := p.writer.WriteMessages(ctx, kafka.Message{
 		Topic: topic,
-		Value: message,
+		Key:     key,
+		Value:   message,
+		Headers: headers,
 	})
 
 	if err != nil {
@@ -0,0 +1,42 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sdshorin/generia/pkg/events"
+)
+
+// Insert stores an event in the outbox table. It must be called with the same
+// transaction that writes the domain row, so the event is persisted if and
+// only if the row is.
+func Insert(ctx context.Context, tx sqlx.ExecerContext, event *events.Event) error {
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox event: %w", err)
+	}
+
+	query := `
+		INSERT INTO outbox_events (id, event_type, world_id, payload, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err = tx.ExecContext(ctx, query, event.ID, event.Type, event.WorldID, eventJSON, event.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert outbox event: %w", err)
+	}
+
+	return nil
+}
+
+// InsertEvent builds an event and stores it in the outbox table
+func InsertEvent(ctx context.Context, tx sqlx.ExecerContext, eventType, worldID string, actor events.Actor, payload interface{}) error {
+	event, err := events.NewEvent(eventType, worldID, actor, payload)
+	if err != nil {
+		return err
+	}
+
+	return Insert(ctx, tx, event)
+}
@@ -0,0 +1,231 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sdshorin/generia/pkg/events"
+	"github.com/sdshorin/generia/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RelayConfig holds outbox relay configuration
+type RelayConfig struct {
+	PollInterval time.Duration // Defaults to 1s
+	BatchSize    int           // Defaults to 100
+	Retention    time.Duration // How long published events are kept, defaults to 7 days
+}
+
+var (
+	relayPublishedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "outbox_relay_published_total",
+		Help: "Number of outbox events published to Kafka",
+	})
+
+	relayFailuresTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "outbox_relay_failures_total",
+		Help: "Number of failed attempts to publish outbox events",
+	})
+)
+
+// outboxRow is a row of the outbox_events table
+type outboxRow struct {
+	ID      string          `db:"id"`
+	Payload json.RawMessage `db:"payload"`
+}
+
+// Relay drains the outbox table into Kafka.
+// An event is marked as published only after Kafka acknowledged it, so a
+// crash in between leads to a redelivery: consumers deduplicate by event ID.
+type Relay struct {
+	db        *sqlx.DB
+	publisher events.Publisher
+	config    RelayConfig
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewRelay creates a new outbox relay
+func NewRelay(db *sqlx.DB, publisher events.Publisher, config RelayConfig) *Relay {
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.Retention <= 0 {
+		config.Retention = 7 * 24 * time.Hour
+	}
+
+	return &Relay{
+		db:        db,
+		publisher: publisher,
+		config:    config,
+		done:      make(chan struct{}),
+	}
+}
+
+// Start runs the relay loop in the background until Close is called
+func (r *Relay) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	go func() {
+		defer close(r.done)
+		r.run(ctx)
+	}()
+
+	logger.Logger.Info("Outbox relay started", zap.Duration("poll_interval", r.config.PollInterval))
+}
+
+// Close stops the relay after the current batch
+func (r *Relay) Close() {
+	r.once.Do(func() {
+		if r.cancel != nil {
+			r.cancel()
+			<-r.done
+		}
+		logger.Logger.Info("Outbox relay stopped")
+	})
+}
+
+func (r *Relay) run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Now()
+	for {
+		// Keep draining while full batches are found
+		for {
+			published, err := r.relayBatch(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.Logger.Error("Failed to relay outbox events", zap.Error(err))
+				}
+				break
+			}
+			if published < r.config.BatchSize {
+				break
+			}
+		}
+
+		if time.Since(lastCleanup) > time.Hour {
+			r.cleanup(ctx)
+			lastCleanup = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relayBatch publishes one batch of pending events and returns how many were published.
+// Rows are locked with SKIP LOCKED so several replicas can relay concurrently.
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT id, payload
+		FROM outbox_events
+		WHERE published_at IS NULL
+		ORDER BY created_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows := []outboxRow{}
+	if err := tx.SelectContext(ctx, &rows, query, r.config.BatchSize); err != nil {
+		return 0, fmt.Errorf("failed to select outbox events: %w", err)
+	}
+
+	published := 0
+	for _, row := range rows {
+		var event events.Event
+		if err := json.Unmarshal(row.Payload, &event); err != nil {
+			// A corrupt row can never be published, park it instead of blocking the queue
+			logger.Logger.Error("Failed to unmarshal outbox event", zap.Error(err), zap.String("id", row.ID))
+			if err := r.markFailed(ctx, tx, row.ID, err, true); err != nil {
+				return published, err
+			}
+			continue
+		}
+
+		if err := r.publisher.Publish(ctx, &event); err != nil {
+			relayFailuresTotal.Inc()
+			if markErr := r.markFailed(ctx, tx, row.ID, err, false); markErr != nil {
+				return published, markErr
+			}
+			// Stop here to keep events of a world in order; retry on next poll
+			if err := tx.Commit(); err != nil {
+				return published, fmt.Errorf("failed to commit transaction: %w", err)
+			}
+			return published, fmt.Errorf("failed to publish event %s: %w", event.ID, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE outbox_events SET published_at = NOW() WHERE id = $1`, row.ID); err != nil {
+			return published, fmt.Errorf("failed to mark outbox event as published: %w", err)
+		}
+		published++
+		relayPublishedTotal.Inc()
+	}
+
+	if err := tx.Commit(); err != nil {
+		return published, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return published, nil
+}
+
+// markFailed records a failed publish attempt. Parked rows are also marked as
+// published so they stop blocking the relay; they stay in the table with their
+// error for inspection.
+func (r *Relay) markFailed(ctx context.Context, tx *sqlx.Tx, id string, cause error, park bool) error {
+	query := `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, last_error = $1
+		WHERE id = $2
+	`
+	if park {
+		query = `
+			UPDATE outbox_events
+			SET attempts = attempts + 1, last_error = $1, published_at = NOW()
+			WHERE id = $2
+		`
+	}
+
+	if _, err := tx.ExecContext(ctx, query, cause.Error(), id); err != nil {
+		return fmt.Errorf("failed to record outbox failure: %w", err)
+	}
+
+	return nil
+}
+
+// cleanup removes published events older than the retention period
+func (r *Relay) cleanup(ctx context.Context) {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM outbox_events WHERE published_at < $1`,
+		time.Now().Add(-r.config.Retention))
+	if err != nil {
+		logger.Logger.Error("Failed to clean up outbox events", zap.Error(err))
+		return
+	}
+
+	if deleted, err := result.RowsAffected(); err == nil && deleted > 0 {
+		logger.Logger.Info("Cleaned up outbox events", zap.Int64("deleted", deleted))
+	}
+}
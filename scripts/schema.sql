@@ -25,6 +25,8 @@ CREATE TABLE IF NOT EXISTS users (
     username VARCHAR(30) NOT NULL UNIQUE,
     email VARCHAR(255) UNIQUE, -- Allow NULL for AI users
     password_hash VARCHAR(255), -- Allow NULL for AI users
+    email_verified_at TIMESTAMP WITH TIME ZONE, -- NULL until the email is confirmed
+    role VARCHAR(20) NOT NULL DEFAULT 'user', -- user, moderator or admin; granted manually
     -- todo - add credits
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
@@ -44,12 +46,26 @@ CREATE TABLE IF NOT EXISTS user_worlds (
     UNIQUE(user_id, world_id)
 );
 
--- Refresh tokens table (used by auth-service)
+-- Refresh tokens table (used by auth-service), one row per session; the id is the session id
 CREATE TABLE IF NOT EXISTS refresh_tokens (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(255) NOT NULL UNIQUE, -- replaced on every refresh
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '', -- address of the last use
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    last_used_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Single-use tokens sent by email (used by auth-service): email verification and password reset
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(20) NOT NULL, -- verify_email, reset_password
     token_hash VARCHAR(255) NOT NULL UNIQUE,
     expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE, -- set once the token is used or replaced by a newer one
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
@@ -84,18 +100,40 @@ CREATE TABLE IF NOT EXISTS media (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     character_id UUID, -- Nullable for world-level media
     world_id UUID NOT NULL REFERENCES worlds(id) ON DELETE CASCADE,
+    user_id UUID, -- Uploader charged for the media, NULL for AI-generated media
     filename TEXT NOT NULL,
     content_type TEXT NOT NULL,
     size BIGINT NOT NULL,
     bucket TEXT NOT NULL,
     object_name TEXT NOT NULL,
     media_type INTEGER NOT NULL DEFAULT 0, -- 0=unknown, 1=world_header, 2=world_icon, 3=character_avatar, 4=post_image
+    status TEXT NOT NULL DEFAULT 'pending', -- pending, uploaded, pending_processing, ready, failed, orphaned, deleted
+    width INT NOT NULL DEFAULT 0, -- filled in by processing
+    height INT NOT NULL DEFAULT 0,
+    blurhash TEXT NOT NULL DEFAULT '', -- placeholder shown while the image loads
+    dominant_color TEXT NOT NULL DEFAULT '', -- #rrggbb
+    processing_error TEXT,
+    content_hash TEXT, -- SHA-256 of the processed original, references media_blobs
+    orphaned_at TIMESTAMP WITH TIME ZONE, -- when the sweeper found the media unreferenced
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    purged_at TIMESTAMP WITH TIME ZONE, -- when the objects of a deleted media were removed from storage
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
 -- Media variants table (used by media-service)
 -- TODO: Разобарться как хранить медиа (все медиа - в s3)
+-- Content-addressed objects shared by all media with identical bytes (used by media-service)
+CREATE TABLE IF NOT EXISTS media_blobs (
+    content_hash TEXT PRIMARY KEY, -- hex SHA-256
+    bucket TEXT NOT NULL,
+    object_name TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    ref_count INT NOT NULL DEFAULT 0, -- media pointing at the blob; objects are removed once it drops to 0
+    released_at TIMESTAMP WITH TIME ZONE, -- when ref_count dropped to 0
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
 CREATE TABLE IF NOT EXISTS media_variants (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     media_id UUID NOT NULL REFERENCES media(id) ON DELETE CASCADE,
@@ -106,6 +144,32 @@ CREATE TABLE IF NOT EXISTS media_variants (
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
+-- Transactional outbox (used by post-service and world-service)
+-- Events are written in the same transaction as the domain row and relayed to Kafka
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id UUID PRIMARY KEY, -- event ID, doubles as idempotency key
+    event_type TEXT NOT NULL,
+    world_id UUID,
+    payload JSONB NOT NULL, -- full event envelope
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+-- CDN cache invalidations (used by cdn-service), a queue with the state of each operation
+CREATE TABLE IF NOT EXISTS cdn_invalidations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    world_id UUID, -- Patterns are relative to the world's objects, NULL for absolute object paths
+    patterns TEXT[] NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued', -- queued, running, done, failed
+    evicted INTEGER NOT NULL DEFAULT 0, -- Cached objects evicted by the instance that ran the operation
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
 
 
 -- Create indexes
@@ -115,23 +179,39 @@ CREATE INDEX IF NOT EXISTS idx_user_worlds_user_id ON user_worlds(user_id);
 CREATE INDEX IF NOT EXISTS idx_user_worlds_world_id ON user_worlds(world_id);
 CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id);
 CREATE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens(token_hash);
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_id_purpose ON user_tokens(user_id, purpose) WHERE used_at IS NULL;
 
 -- World user characters indexes
 CREATE INDEX IF NOT EXISTS idx_world_user_characters_real_user_id ON world_user_characters(real_user_id);
 CREATE INDEX IF NOT EXISTS idx_world_user_characters_world_id ON world_user_characters(world_id);
 CREATE INDEX IF NOT EXISTS idx_world_user_characters_real_user_id_world_id ON world_user_characters(real_user_id, world_id);
 CREATE INDEX IF NOT EXISTS idx_world_user_characters_is_ai ON world_user_characters(is_ai);
+CREATE INDEX IF NOT EXISTS idx_world_user_characters_avatar_media_id ON world_user_characters(avatar_media_id);
 
 -- Posts indexes
 CREATE INDEX IF NOT EXISTS idx_posts_character_id ON posts(character_id);
 CREATE INDEX IF NOT EXISTS idx_posts_world_id ON posts(world_id);
 CREATE INDEX IF NOT EXISTS idx_posts_created_at ON posts(created_at);
 CREATE INDEX IF NOT EXISTS idx_posts_is_ai ON posts(is_ai);
+CREATE INDEX IF NOT EXISTS idx_posts_media_id ON posts(media_id);
 
 -- Media indexes
 CREATE INDEX IF NOT EXISTS idx_media_character_id ON media(character_id);
 CREATE INDEX IF NOT EXISTS idx_media_world_id ON media(world_id);
 CREATE INDEX IF NOT EXISTS idx_media_variants_media_id ON media_variants(media_id);
+CREATE INDEX IF NOT EXISTS idx_media_status_updated_at ON media(status, updated_at);
+CREATE INDEX IF NOT EXISTS idx_media_user_id ON media(user_id);
+CREATE INDEX IF NOT EXISTS idx_media_content_hash ON media(content_hash);
+CREATE INDEX IF NOT EXISTS idx_media_blobs_released_at ON media_blobs(released_at) WHERE ref_count = 0;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_media_variants_media_id_name ON media_variants(media_id, name);
+
+-- Outbox indexes
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events(created_at) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at ON outbox_events(published_at);
+
+-- CDN invalidation indexes
+CREATE INDEX IF NOT EXISTS idx_cdn_invalidations_queued ON cdn_invalidations(created_at) WHERE status IN ('queued', 'running');
+CREATE INDEX IF NOT EXISTS idx_cdn_invalidations_finished_at ON cdn_invalidations(finished_at) WHERE status = 'done';
 
 
 INSERT INTO users (id,username,email,password_hash,created_at,updated_at) VALUES
@@ -15,6 +15,7 @@ import (
 	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sdshorin/generia/pkg/auth"
 	"github.com/sdshorin/generia/pkg/config"
 	"github.com/sdshorin/generia/pkg/discovery"
 	"github.com/sdshorin/generia/pkg/logger"
@@ -32,6 +33,7 @@ import (
 	cachepb "github.com/sdshorin/generia/api/grpc/cache"
 	cdnpb "github.com/sdshorin/generia/api/grpc/cdn"
 	characterpb "github.com/sdshorin/generia/api/grpc/character"
+	feedpb "github.com/sdshorin/generia/api/grpc/feed"
 	interactionpb "github.com/sdshorin/generia/api/grpc/interaction"
 	mediapb "github.com/sdshorin/generia/api/grpc/media"
 	postpb "github.com/sdshorin/generia/api/grpc/post"
@@ -48,6 +50,7 @@ type grpcClients struct {
 	cdnClient         cdnpb.CDNServiceClient
 	worldClient       worldpb.WorldServiceClient
 	characterClient   characterpb.CharacterServiceClient
+	feedClient        feedpb.FeedServiceClient
 }
 
 func main() {
@@ -106,16 +109,28 @@ func main() {
 		logger.Logger.Fatal("Failed to initialize gRPC clients after multiple attempts", zap.Error(err))
 	}
 
-	// Initialize JWT middleware
-	jwtMiddleware := middleware.NewJWTMiddleware(cfg.JWT.Secret)
+	// Load the public keys of access tokens from auth-service, only it holds the signing keys
+	jwks := auth.NewJWKSCache(func(ctx context.Context) ([]byte, error) {
+		resp, err := clients.authClient.GetJWKS(ctx, &authpb.GetJWKSRequest{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get JWKS: %w", err)
+		}
+		return resp.Jwks, nil
+	}, cfg.JWT.JWKSRefreshInterval)
+	jwks.Start()
+	defer jwks.Close()
+
+	// Initialize JWT middleware, the SSE endpoint checks tokens with the same verifier
+	tokenVerifier := auth.NewTokenVerifier(jwks.Keyfunc, auth.NewDenyList(clients.cacheClient, cfg.JWT.Leeway), cfg.JWT)
+	jwtMiddleware := middleware.NewJWTMiddleware(tokenVerifier)
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(clients.authClient, tracer)
-	postHandler := handlers.NewPostHandler(clients.postClient, clients.mediaClient, clients.interactionClient, tracer)
+	authHandler := handlers.NewAuthHandler(clients.authClient, jwks, tracer)
+	postHandler := handlers.NewPostHandler(clients.postClient, clients.mediaClient, clients.interactionClient, clients.feedClient, tracer)
 	mediaHandler := handlers.NewMediaHandler(clients.mediaClient, clients.cdnClient, tracer)
 	interactionHandler := handlers.NewInteractionHandler(clients.interactionClient, tracer)
 
-	worldHandler := handlers.NewWorldHandler(clients.worldClient, 30*time.Second, cfg.JWT.Secret)
+	worldHandler := handlers.NewWorldHandler(clients.worldClient, 30*time.Second, tokenVerifier)
 	characterHandler := handlers.NewCharacterHandler(clients.characterClient, 30*time.Second)
 
 	// Initialize router
@@ -131,24 +146,37 @@ func main() {
 	router.Handle("/metrics", promhttp.Handler())
 	router.HandleFunc("/health", handlers.HealthCheckHandler).Methods("GET")
 	router.HandleFunc("/ready", handlers.ReadinessCheckHandler).Methods("GET")
+	router.HandleFunc("/.well-known/jwks.json", authHandler.JWKS).Methods("GET")
 
 	// Auth routes
 	router.HandleFunc("/api/v1/auth/register", authHandler.Register).Methods("POST")
 	router.HandleFunc("/api/v1/auth/login", authHandler.Login).Methods("POST")
 	router.Handle("/api/v1/auth/me", jwtMiddleware.RequireAuth(http.HandlerFunc(authHandler.Me))).Methods("GET")
 	router.HandleFunc("/api/v1/auth/refresh", authHandler.RefreshToken).Methods("POST")
+	router.HandleFunc("/api/v1/auth/logout", authHandler.Logout).Methods("POST")
+	router.Handle("/api/v1/auth/logout-all", jwtMiddleware.RequireAuth(http.HandlerFunc(authHandler.LogoutAll))).Methods("POST")
+	router.Handle("/api/v1/auth/sessions", jwtMiddleware.RequireAuth(http.HandlerFunc(authHandler.ListSessions))).Methods("GET")
+	router.Handle("/api/v1/auth/sessions/{session_id}", jwtMiddleware.RequireAuth(http.HandlerFunc(authHandler.RevokeSession))).Methods("DELETE")
+	router.Handle("/api/v1/auth/email/verification", jwtMiddleware.RequireAuth(http.HandlerFunc(authHandler.SendVerificationEmail))).Methods("POST")
+	router.HandleFunc("/api/v1/auth/email/verify", authHandler.VerifyEmail).Methods("POST")
+	router.HandleFunc("/api/v1/auth/password/forgot", authHandler.ForgotPassword).Methods("POST")
+	router.HandleFunc("/api/v1/auth/password/reset", authHandler.ResetPassword).Methods("POST")
 
 	// Post routes
 	router.Handle("/api/v1/worlds/{world_id}/post", jwtMiddleware.RequireAuth(http.HandlerFunc(postHandler.CreatePost))).Methods("POST")
 	router.Handle("/api/v1/worlds/{world_id}/posts", jwtMiddleware.Optional(http.HandlerFunc(postHandler.GetGlobalPosts))).Methods("GET")
+	router.Handle("/api/v1/worlds/{world_id}/feed", jwtMiddleware.Optional(http.HandlerFunc(postHandler.GetGlobalPosts))).Methods("GET")
 	router.Handle("/api/v1/worlds/{world_id}/posts/{id}", jwtMiddleware.Optional(http.HandlerFunc(postHandler.GetPost))).Methods("GET")
+	router.Handle("/api/v1/worlds/{world_id}/posts/{id}", jwtMiddleware.RequireAuth(http.HandlerFunc(postHandler.DeletePost))).Methods("DELETE")
 	router.Handle("/api/v1/worlds/{world_id}/users/{user_id}/posts", jwtMiddleware.Optional(http.HandlerFunc(postHandler.GetUserPosts))).Methods("GET")
 	router.Handle("/api/v1/worlds/{world_id}/character/{character_id}/posts", jwtMiddleware.Optional(http.HandlerFunc(postHandler.GetCharacterPosts))).Methods("GET")
 
 	// Media routes - Legacy and Direct Upload
-	router.Handle("/api/v1/media/upload-url", jwtMiddleware.RequireAuth(http.HandlerFunc(mediaHandler.GetUploadURL))).Methods("POST")
+	router.Handle("/api/v1/media/upload-url", jwtMiddleware.RequireAuth(jwtMiddleware.RequireVerifiedEmail(http.HandlerFunc(mediaHandler.GetUploadURL)))).Methods("POST")
 	router.Handle("/api/v1/media/confirm", jwtMiddleware.RequireAuth(http.HandlerFunc(mediaHandler.ConfirmUpload))).Methods("POST")
+	router.Handle("/api/v1/media/usage", jwtMiddleware.RequireAuth(http.HandlerFunc(mediaHandler.GetStorageUsage))).Methods("GET")
 	router.HandleFunc("/api/v1/media/{id}", mediaHandler.GetMediaURLs).Methods("GET")
+	router.HandleFunc("/api/v1/media/{id}/status", mediaHandler.GetMediaStatus).Methods("GET")
 
 	// Interaction routes
 	router.Handle("/api/v1/worlds/{world_id}/posts/{id}/like", jwtMiddleware.RequireAuth(http.HandlerFunc(interactionHandler.LikePost))).Methods("POST")
@@ -159,7 +187,7 @@ func main() {
 
 	// World routes - сначала конкретные маршруты, затем маршруты с параметрами
 	router.Handle("/api/v1/worlds", jwtMiddleware.RequireAuth(http.HandlerFunc(worldHandler.GetWorlds))).Methods("GET")
-	router.Handle("/api/v1/worlds", jwtMiddleware.RequireAuth(http.HandlerFunc(worldHandler.CreateWorld))).Methods("POST")
+	router.Handle("/api/v1/worlds", jwtMiddleware.RequireAuth(jwtMiddleware.RequireVerifiedEmail(http.HandlerFunc(worldHandler.CreateWorld)))).Methods("POST")
 	router.Handle("/api/v1/worlds/{world_id}/join", jwtMiddleware.RequireAuth(http.HandlerFunc(worldHandler.JoinWorld))).Methods("POST")
 	router.Handle("/api/v1/worlds/{world_id}/status", jwtMiddleware.RequireAuth(http.HandlerFunc(worldHandler.GetWorldStatus))).Methods("GET")
 	router.HandleFunc("/api/v1/worlds/{world_id}/status/stream", worldHandler.StreamWorldStatus).Methods("GET")
@@ -267,6 +295,11 @@ func initGrpcClients(discoveryClient discovery.ServiceDiscovery) (*grpcClients,
 		return nil, fmt.Errorf("failed to resolve character service: %w", err)
 	}
 
+	feedAddr, err := discoveryClient.ResolveService("feed-service")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve feed service: %w", err)
+	}
+
 	// Create connections
 	authConn, err := grpc.Dial(authAddr, opts...)
 	if err != nil {
@@ -308,6 +341,11 @@ func initGrpcClients(discoveryClient discovery.ServiceDiscovery) (*grpcClients,
 		return nil, fmt.Errorf("failed to connect to character service: %w", err)
 	}
 
+	feedConn, err := grpc.Dial(feedAddr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to feed service: %w", err)
+	}
+
 	// Create clients
 	return &grpcClients{
 		authClient:        authpb.NewAuthServiceClient(authConn),
@@ -318,5 +356,6 @@ func initGrpcClients(discoveryClient discovery.ServiceDiscovery) (*grpcClients,
 		cdnClient:         cdnpb.NewCDNServiceClient(cdnConn),
 		worldClient:       worldpb.NewWorldServiceClient(worldConn),
 		characterClient:   characterpb.NewCharacterServiceClient(characterConn),
+		feedClient:        feedpb.NewFeedServiceClient(feedConn),
 	}, nil
 }
@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/gorilla/mux"
+	"github.com/sdshorin/generia/pkg/auth"
 	"github.com/sdshorin/generia/pkg/logger"
 	"github.com/sdshorin/generia/services/api-gateway/middleware"
 	"go.opentelemetry.io/otel/attribute"
@@ -19,13 +23,15 @@ import (
 // AuthHandler handles authentication-related HTTP requests
 type AuthHandler struct {
 	authClient authpb.AuthServiceClient
+	keys       *auth.JWKSCache
 	tracer     trace.Tracer
 }
 
 // NewAuthHandler creates a new AuthHandler
-func NewAuthHandler(authClient authpb.AuthServiceClient, tracer trace.Tracer) *AuthHandler {
+func NewAuthHandler(authClient authpb.AuthServiceClient, keys *auth.JWKSCache, tracer trace.Tracer) *AuthHandler {
 	return &AuthHandler{
 		authClient: authClient,
+		keys:       keys,
 		tracer:     tracer,
 	}
 }
@@ -47,10 +53,11 @@ type AuthResponse struct {
 
 // UserResponse represents a user in the API response
 type UserResponse struct {
-	ID        string    `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at,omitempty"`
+	ID            string    `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at,omitempty"`
 }
 
 // Register handles user registration
@@ -79,6 +86,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		Username: req.Username,
 		Email:    req.Email,
 		Password: req.Password,
+		Client:   clientInfo(r),
 	})
 	if err != nil {
 		statusErr, ok := status.FromError(err)
@@ -156,6 +164,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	resp, err := h.authClient.Login(ctx, &authpb.LoginRequest{
 		EmailOrUsername: req.EmailOrUsername,
 		Password:        req.Password,
+		Client:          clientInfo(r),
 	})
 	if err != nil {
 		statusErr, ok := status.FromError(err)
@@ -196,9 +205,10 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		ExpiresAt: expiresAt,
 		User: UserResponse{
 			ID:        resp.UserId,
-			Username:  userInfo.Username,
-			Email:     userInfo.Email,
-			CreatedAt: createdAt,
+			Username:      userInfo.Username,
+			Email:         userInfo.Email,
+			EmailVerified: userInfo.EmailVerified,
+			CreatedAt:     createdAt,
 		},
 	}
 
@@ -242,10 +252,11 @@ func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 
 	// Prepare response
 	response := UserResponse{
-		ID:        userInfo.UserId,
-		Username:  userInfo.Username,
-		Email:     userInfo.Email,
-		CreatedAt: createdAt,
+		ID:            userInfo.UserId,
+		Username:      userInfo.Username,
+		Email:         userInfo.Email,
+		EmailVerified: userInfo.EmailVerified,
+		CreatedAt:     createdAt,
 	}
 
 	// Send response
@@ -285,6 +296,7 @@ func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	// Refresh token
 	resp, err := h.authClient.RefreshToken(ctx, &authpb.RefreshTokenRequest{
 		RefreshToken: req.RefreshToken,
+		Client:       clientInfo(r),
 	})
 	if err != nil {
 		statusErr, ok := status.FromError(err)
@@ -318,4 +330,412 @@ func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		logger.Logger.Error("Failed to encode response", zap.Error(err))
 	}
-}
\ No newline at end of file
+}
+
+// LogoutRequest represents a request to end the current session
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Logout revokes the access token of the request and the refresh token of the session
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "AuthHandler.Logout")
+	defer span.End()
+
+	// The body is optional, without a refresh token only the access token is revoked
+	var req LogoutRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			span.SetAttributes(attribute.Bool("error", true))
+			logger.Logger.Error("Failed to decode request body", zap.Error(err))
+			return
+		}
+	}
+
+	// The route is not behind RequireAuth: expired and revoked tokens must still
+	// end their session, Auth Service checks only the signature
+	tokenParts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+		http.Error(w, "Authorization header required", http.StatusUnauthorized)
+		span.SetAttributes(attribute.Bool("error", true))
+		return
+	}
+	accessToken := tokenParts[1]
+
+	_, err := h.authClient.Logout(ctx, &authpb.LogoutRequest{
+		AccessToken:  accessToken,
+		RefreshToken: req.RefreshToken,
+	})
+	if err != nil {
+		statusErr, ok := status.FromError(err)
+		if ok && statusErr.Code() == codes.PermissionDenied {
+			http.Error(w, "Refresh token belongs to another user", http.StatusForbidden)
+		} else if ok && statusErr.Code() == codes.Unauthenticated {
+			http.Error(w, "Invalid access token", http.StatusUnauthorized)
+		} else {
+			http.Error(w, "Failed to logout", http.StatusInternalServerError)
+		}
+		span.SetAttributes(attribute.Bool("error", true))
+		logger.Logger.Error("Failed to logout", zap.Error(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutAll revokes every session of the current user, e.g. after a device was lost
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "AuthHandler.LogoutAll")
+	defer span.End()
+
+	// Get user ID from context
+	userID, ok := ctx.Value(middleware.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		span.SetAttributes(attribute.Bool("error", true))
+		return
+	}
+
+	resp, err := h.authClient.LogoutAll(ctx, &authpb.LogoutAllRequest{
+		UserId: userID,
+	})
+	if err != nil {
+		http.Error(w, "Failed to logout", http.StatusInternalServerError)
+		span.SetAttributes(attribute.Bool("error", true))
+		logger.Logger.Error("Failed to logout on all devices", zap.Error(err), zap.String("user_id", userID))
+		return
+	}
+
+	// Prepare response
+	response := struct {
+		RevokedSessions int64 `json:"revoked_sessions"`
+	}{
+		RevokedSessions: resp.RevokedSessions,
+	}
+
+	// Send response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Logger.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+// SessionResponse represents an active session in the API response
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ListSessions handles listing the active sessions of the current user
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "AuthHandler.ListSessions")
+	defer span.End()
+
+	// Get user ID from context
+	userID, ok := ctx.Value(middleware.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		span.SetAttributes(attribute.Bool("error", true))
+		return
+	}
+	currentSessionID, _ := ctx.Value(middleware.SessionIDKey).(string)
+
+	resp, err := h.authClient.ListSessions(ctx, &authpb.ListSessionsRequest{
+		UserId: userID,
+	})
+	if err != nil {
+		http.Error(w, "Failed to list sessions", http.StatusInternalServerError)
+		span.SetAttributes(attribute.Bool("error", true))
+		logger.Logger.Error("Failed to list sessions", zap.Error(err), zap.String("user_id", userID))
+		return
+	}
+
+	// Prepare response
+	sessions := make([]SessionResponse, 0, len(resp.Sessions))
+	for _, session := range resp.Sessions {
+		createdAt, _ := time.Parse(time.RFC3339, session.CreatedAt)
+		lastUsedAt, _ := time.Parse(time.RFC3339, session.LastUsedAt)
+		expiresAt, _ := time.Parse(time.RFC3339, session.ExpiresAt)
+		sessions = append(sessions, SessionResponse{
+			ID:         session.SessionId,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IpAddress,
+			Current:    session.SessionId == currentSessionID,
+			CreatedAt:  createdAt,
+			LastUsedAt: lastUsedAt,
+			ExpiresAt:  expiresAt,
+		})
+	}
+	response := struct {
+		Sessions []SessionResponse `json:"sessions"`
+	}{
+		Sessions: sessions,
+	}
+
+	// Send response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Logger.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+// RevokeSession handles ending a single session of the current user
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "AuthHandler.RevokeSession")
+	defer span.End()
+
+	// Get user ID from context
+	userID, ok := ctx.Value(middleware.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		span.SetAttributes(attribute.Bool("error", true))
+		return
+	}
+
+	sessionID := mux.Vars(r)["session_id"]
+	span.SetAttributes(attribute.String("session_id", sessionID))
+
+	_, err := h.authClient.RevokeSession(ctx, &authpb.RevokeSessionRequest{
+		UserId:    userID,
+		SessionId: sessionID,
+	})
+	if err != nil {
+		statusErr, ok := status.FromError(err)
+		if ok && statusErr.Code() == codes.NotFound {
+			http.Error(w, "Session not found", http.StatusNotFound)
+		} else if ok && statusErr.Code() == codes.InvalidArgument {
+			http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		} else {
+			http.Error(w, "Failed to revoke session", http.StatusInternalServerError)
+		}
+		span.SetAttributes(attribute.Bool("error", true))
+		logger.Logger.Error("Failed to revoke session", zap.Error(err), zap.String("user_id", userID))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SendVerificationEmail handles sending another email verification link to the current user
+func (h *AuthHandler) SendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "AuthHandler.SendVerificationEmail")
+	defer span.End()
+
+	// Get user ID from context
+	userID, ok := ctx.Value(middleware.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		span.SetAttributes(attribute.Bool("error", true))
+		return
+	}
+
+	resp, err := h.authClient.SendVerificationEmail(ctx, &authpb.SendVerificationEmailRequest{
+		UserId: userID,
+	})
+	if err != nil {
+		statusErr, ok := status.FromError(err)
+		if ok && statusErr.Code() == codes.ResourceExhausted {
+			http.Error(w, "Too many emails sent, try again later", http.StatusTooManyRequests)
+		} else {
+			http.Error(w, "Failed to send verification email", http.StatusInternalServerError)
+		}
+		span.SetAttributes(attribute.Bool("error", true))
+		logger.Logger.Error("Failed to send verification email", zap.Error(err), zap.String("user_id", userID))
+		return
+	}
+
+	// Prepare response
+	response := struct {
+		Sent bool `json:"sent"`
+	}{
+		Sent: resp.Sent,
+	}
+
+	// Send response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Logger.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+// VerifyEmailRequest represents a request to confirm an email
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// VerifyEmail handles confirming an email with the token from the verification email
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "AuthHandler.VerifyEmail")
+	defer span.End()
+
+	// Parse request body
+	var req VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		span.SetAttributes(attribute.Bool("error", true))
+		logger.Logger.Error("Failed to decode request body", zap.Error(err))
+		return
+	}
+
+	// Validate request
+	if req.Token == "" {
+		http.Error(w, "Token is required", http.StatusBadRequest)
+		span.SetAttributes(attribute.Bool("error", true))
+		return
+	}
+
+	_, err := h.authClient.VerifyEmail(ctx, &authpb.VerifyEmailRequest{
+		Token: req.Token,
+	})
+	if err != nil {
+		statusErr, ok := status.FromError(err)
+		if ok && statusErr.Code() == codes.NotFound {
+			http.Error(w, "Invalid or expired token", http.StatusBadRequest)
+		} else {
+			http.Error(w, "Failed to verify email", http.StatusInternalServerError)
+		}
+		span.SetAttributes(attribute.Bool("error", true))
+		logger.Logger.Error("Failed to verify email", zap.Error(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ForgotPasswordRequest represents a request to send a password reset email
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ForgotPassword handles sending a password reset email. It answers the same way
+// for unknown emails, so it cannot be used to find out who is registered.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "AuthHandler.ForgotPassword")
+	defer span.End()
+
+	// Parse request body
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		span.SetAttributes(attribute.Bool("error", true))
+		logger.Logger.Error("Failed to decode request body", zap.Error(err))
+		return
+	}
+
+	// Validate request
+	if req.Email == "" {
+		http.Error(w, "Email is required", http.StatusBadRequest)
+		span.SetAttributes(attribute.Bool("error", true))
+		return
+	}
+
+	_, err := h.authClient.RequestPasswordReset(ctx, &authpb.RequestPasswordResetRequest{
+		Email: req.Email,
+	})
+	if err != nil {
+		http.Error(w, "Failed to request password reset", http.StatusInternalServerError)
+		span.SetAttributes(attribute.Bool("error", true))
+		logger.Logger.Error("Failed to request password reset", zap.Error(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResetPasswordRequest represents a request to set a new password
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// ResetPassword handles setting a new password with the token from the password reset email
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "AuthHandler.ResetPassword")
+	defer span.End()
+
+	// Parse request body
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		span.SetAttributes(attribute.Bool("error", true))
+		logger.Logger.Error("Failed to decode request body", zap.Error(err))
+		return
+	}
+
+	// Validate request
+	if req.Token == "" || req.NewPassword == "" {
+		http.Error(w, "Token and new password are required", http.StatusBadRequest)
+		span.SetAttributes(attribute.Bool("error", true))
+		return
+	}
+
+	_, err := h.authClient.ResetPassword(ctx, &authpb.ResetPasswordRequest{
+		Token:       req.Token,
+		NewPassword: req.NewPassword,
+	})
+	if err != nil {
+		statusErr, ok := status.FromError(err)
+		if ok && statusErr.Code() == codes.InvalidArgument {
+			http.Error(w, statusErr.Message(), http.StatusBadRequest)
+		} else if ok && statusErr.Code() == codes.NotFound {
+			http.Error(w, "Invalid or expired token", http.StatusBadRequest)
+		} else {
+			http.Error(w, "Failed to reset password", http.StatusInternalServerError)
+		}
+		span.SetAttributes(attribute.Bool("error", true))
+		logger.Logger.Error("Failed to reset password", zap.Error(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// JWKS publishes the public keys of access tokens, so other parties can verify them
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	document := h.keys.Document()
+	if document == nil {
+		http.Error(w, "Keys are not loaded yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Verifiers may cache the keys for a while, new keys are published before they sign tokens
+	w.Header().Set("Content-Type", "application/jwk-set+json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(document); err != nil {
+		logger.Logger.Error("Failed to write response", zap.Error(err))
+	}
+}
+
+// clientInfo describes the device of the request, it is shown in the sessions list
+func clientInfo(r *http.Request) *authpb.ClientInfo {
+	return &authpb.ClientInfo{
+		UserAgent: r.UserAgent(),
+		IpAddress: clientIP(r),
+	}
+}
+
+// clientIP returns the address of the client, the gateway may run behind a proxy
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		ip, _, _ := strings.Cut(forwarded, ",")
+		return strings.TrimSpace(ip)
+	}
+	if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/sdshorin/generia/pkg/logger"
@@ -60,15 +61,48 @@ type ConfirmUploadRequest struct {
 // UploadMediaResponse represents a response after uploading media
 type UploadMediaResponse struct {
 	MediaID  string            `json:"media_id"`
+	Status   string            `json:"status"` // Processing status, poll /api/v1/media/{id}/status until "ready"
 	Variants map[string]string `json:"variants"`
 }
 
 // GetMediaURLsResponse represents the response for a media URLs request
 type GetMediaURLsResponse struct {
-	MediaID     string            `json:"media_id"`
-	CharacterID string            `json:"character_id"`
-	WorldID     string            `json:"world_id,omitempty"`
-	Variants    map[string]string `json:"variants"`
+	MediaID       string            `json:"media_id"`
+	CharacterID   string            `json:"character_id"`
+	WorldID       string            `json:"world_id,omitempty"`
+	Status        string            `json:"status"`
+	Width         int32             `json:"width,omitempty"`
+	Height        int32             `json:"height,omitempty"`
+	Blurhash      string            `json:"blurhash,omitempty"`
+	DominantColor string            `json:"dominant_color,omitempty"`
+	Variants      map[string]string `json:"variants"`
+}
+
+// MediaStatusResponse represents the processing status of an upload
+type MediaStatusResponse struct {
+	MediaID string `json:"media_id"`
+	Status  string `json:"status"` // pending, uploaded, pending_processing, ready, failed, orphaned, deleted
+	Width   int32  `json:"width,omitempty"`
+	Height  int32  `json:"height,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// StorageUsage represents the storage used against a quota
+type StorageUsage struct {
+	UsedBytes  int64 `json:"used_bytes"`
+	QuotaBytes int64 `json:"quota_bytes"` // 0 means unlimited
+	MediaCount int64 `json:"media_count"`
+}
+
+// StorageUsageResponse represents the storage usage of the current user and optionally a world
+type StorageUsageResponse struct {
+	User  *StorageUsage `json:"user,omitempty"`
+	World *StorageUsage `json:"world,omitempty"`
+}
+
+// mediaStatusName converts the proto status to its JSON name, e.g. "pending_processing"
+func mediaStatusName(status mediapb.MediaStatus) string {
+	return strings.ToLower(strings.TrimPrefix(status.String(), "MEDIA_STATUS_"))
 }
 
 // GetUploadURL handles requests to get a presigned upload URL
@@ -76,8 +110,8 @@ func (h *MediaHandler) GetUploadURL(w http.ResponseWriter, r *http.Request) {
 	ctx, span := h.tracer.Start(r.Context(), "MediaHandler.GetUploadURL")
 	defer span.End()
 
-	// Check user is authenticated (for authorization check only)
-	_, ok := ctx.Value(middleware.UserIDKey).(string)
+	// Check user is authenticated, the upload is charged to the user's storage quota
+	userID, ok := ctx.Value(middleware.UserIDKey).(string)
 	if !ok {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		span.SetAttributes(attribute.Bool("error", true))
@@ -132,9 +166,10 @@ func (h *MediaHandler) GetUploadURL(w http.ResponseWriter, r *http.Request) {
 		ContentType: req.ContentType,
 		Size:        req.Size,
 		MediaType:   mediapb.MediaType(req.MediaType),
+		UserId:      userID,
 	})
 	if err != nil {
-		http.Error(w, "Failed to generate upload URL", http.StatusInternalServerError)
+		http.Error(w, "Failed to generate upload URL", grpcStatusToHTTP(err))
 		span.SetAttributes(attribute.Bool("error", true))
 		logger.Logger.Error("Failed to generate upload URL", zap.Error(err))
 		return
@@ -189,7 +224,7 @@ func (h *MediaHandler) ConfirmUpload(w http.ResponseWriter, r *http.Request) {
 		MediaId: req.MediaID,
 	})
 	if err != nil {
-		http.Error(w, "Failed to confirm upload", http.StatusInternalServerError)
+		http.Error(w, "Failed to confirm upload", grpcStatusToHTTP(err))
 		span.SetAttributes(attribute.Bool("error", true))
 		logger.Logger.Error("Failed to confirm upload", zap.Error(err))
 		return
@@ -204,6 +239,7 @@ func (h *MediaHandler) ConfirmUpload(w http.ResponseWriter, r *http.Request) {
 	// Prepare response
 	response := UploadMediaResponse{
 		MediaID:  req.MediaID,
+		Status:   mediaStatusName(resp.Status),
 		Variants: variants,
 	}
 
@@ -234,7 +270,7 @@ func (h *MediaHandler) GetMediaURLs(w http.ResponseWriter, r *http.Request) {
 		MediaId: mediaID,
 	})
 	if err != nil {
-		http.Error(w, "Failed to get media info", http.StatusInternalServerError)
+		http.Error(w, "Failed to get media info", grpcStatusToHTTP(err))
 		span.SetAttributes(attribute.Bool("error", true))
 		logger.Logger.Error("Failed to get media info", zap.Error(err), zap.String("media_id", mediaID))
 		return
@@ -262,10 +298,15 @@ func (h *MediaHandler) GetMediaURLs(w http.ResponseWriter, r *http.Request) {
 
 	// Prepare response
 	response := GetMediaURLsResponse{
-		MediaID:     mediaInfo.MediaId,
-		CharacterID: mediaInfo.CharacterId,
-		WorldID:     mediaInfo.WorldId,
-		Variants:    variants,
+		MediaID:       mediaInfo.MediaId,
+		CharacterID:   mediaInfo.CharacterId,
+		WorldID:       mediaInfo.WorldId,
+		Status:        mediaStatusName(mediaInfo.Status),
+		Width:         mediaInfo.Width,
+		Height:        mediaInfo.Height,
+		Blurhash:      mediaInfo.Blurhash,
+		DominantColor: mediaInfo.DominantColor,
+		Variants:      variants,
 	}
 
 	// Send response
@@ -275,3 +316,91 @@ func (h *MediaHandler) GetMediaURLs(w http.ResponseWriter, r *http.Request) {
 		logger.Logger.Error("Failed to encode response", zap.Error(err))
 	}
 }
+
+// GetMediaStatus handles polling of the processing status of an upload
+func (h *MediaHandler) GetMediaStatus(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "MediaHandler.GetMediaStatus")
+	defer span.End()
+
+	// Get media ID from URL path
+	mediaID := mux.Vars(r)["id"]
+	if mediaID == "" {
+		http.Error(w, "Media ID is required", http.StatusBadRequest)
+		span.SetAttributes(attribute.Bool("error", true))
+		return
+	}
+
+	mediaInfo, err := h.mediaClient.GetMedia(ctx, &mediapb.GetMediaRequest{
+		MediaId: mediaID,
+	})
+	if err != nil {
+		http.Error(w, "Failed to get media info", grpcStatusToHTTP(err))
+		span.SetAttributes(attribute.Bool("error", true))
+		logger.Logger.Error("Failed to get media info", zap.Error(err), zap.String("media_id", mediaID))
+		return
+	}
+
+	response := MediaStatusResponse{
+		MediaID: mediaInfo.MediaId,
+		Status:  mediaStatusName(mediaInfo.Status),
+		Width:   mediaInfo.Width,
+		Height:  mediaInfo.Height,
+		Error:   mediaInfo.ProcessingError,
+	}
+
+	// Status changes while processing, so it must not be cached
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Logger.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+// GetStorageUsage handles requests for the storage usage of the current user.
+// The usage of a world is included when world_id is given.
+func (h *MediaHandler) GetStorageUsage(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "MediaHandler.GetStorageUsage")
+	defer span.End()
+
+	userID, ok := ctx.Value(middleware.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		span.SetAttributes(attribute.Bool("error", true))
+		return
+	}
+
+	resp, err := h.mediaClient.GetStorageUsage(ctx, &mediapb.GetStorageUsageRequest{
+		UserId:  userID,
+		WorldId: r.URL.Query().Get("world_id"),
+	})
+	if err != nil {
+		http.Error(w, "Failed to get storage usage", grpcStatusToHTTP(err))
+		span.SetAttributes(attribute.Bool("error", true))
+		logger.Logger.Error("Failed to get storage usage", zap.Error(err))
+		return
+	}
+
+	response := StorageUsageResponse{
+		User:  storageUsageFromProto(resp.User),
+		World: storageUsageFromProto(resp.World),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Logger.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+func storageUsageFromProto(usage *mediapb.StorageUsage) *StorageUsage {
+	if usage == nil {
+		return nil
+	}
+	return &StorageUsage{
+		UsedBytes:  usage.UsedBytes,
+		QuotaBytes: usage.QuotaBytes,
+		MediaCount: usage.MediaCount,
+	}
+}
@@ -2,9 +2,11 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -13,7 +15,10 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	feedpb "github.com/sdshorin/generia/api/grpc/feed"
 	interactionpb "github.com/sdshorin/generia/api/grpc/interaction"
 	mediapb "github.com/sdshorin/generia/api/grpc/media"
 	postpb "github.com/sdshorin/generia/api/grpc/post"
@@ -24,6 +29,7 @@ type PostHandler struct {
 	postClient        postpb.PostServiceClient
 	mediaClient       mediapb.MediaServiceClient
 	interactionClient interactionpb.InteractionServiceClient
+	feedClient        feedpb.FeedServiceClient
 	tracer            trace.Tracer
 }
 
@@ -32,12 +38,14 @@ func NewPostHandler(
 	postClient postpb.PostServiceClient,
 	mediaClient mediapb.MediaServiceClient,
 	interactionClient interactionpb.InteractionServiceClient,
+	feedClient feedpb.FeedServiceClient,
 	tracer trace.Tracer,
 ) *PostHandler {
 	return &PostHandler{
 		postClient:        postClient,
 		mediaClient:       mediaClient,
 		interactionClient: interactionClient,
+		feedClient:        feedClient,
 		tracer:            tracer,
 	}
 }
@@ -136,17 +144,21 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 
 // PostResponse represents a post in the API response
 type PostResponse struct {
-	ID            string    `json:"id"`
-	CharacterID   string    `json:"character_id"`
-	DisplayName   string    `json:"display_name"`
-	Caption       string    `json:"caption"`
-	MediaURL      string    `json:"media_url"`
-	AvatarURL     string    `json:"avatar_url"`
-	CreatedAt     time.Time `json:"created_at"`
-	LikesCount    int       `json:"likes_count"`
-	CommentsCount int       `json:"comments_count"`
-	UserLiked     bool      `json:"user_liked,omitempty"`
-	IsAI          bool      `json:"is_ai"`
+	ID                 string    `json:"id"`
+	CharacterID        string    `json:"character_id"`
+	DisplayName        string    `json:"display_name"`
+	Caption            string    `json:"caption"`
+	MediaURL           string    `json:"media_url"`
+	MediaWidth         int32     `json:"media_width,omitempty"` // Placeholder shown until the image loads
+	MediaHeight        int32     `json:"media_height,omitempty"`
+	MediaBlurhash      string    `json:"media_blurhash,omitempty"`
+	MediaDominantColor string    `json:"media_dominant_color,omitempty"`
+	AvatarURL          string    `json:"avatar_url"`
+	CreatedAt          time.Time `json:"created_at"`
+	LikesCount         int       `json:"likes_count"`
+	CommentsCount      int       `json:"comments_count"`
+	UserLiked          bool      `json:"user_liked,omitempty"`
+	IsAI               bool      `json:"is_ai"`
 }
 
 // GetPost handles requests to get a post by ID
@@ -204,17 +216,21 @@ func (h *PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
 
 	// Prepare response
 	response := PostResponse{
-		ID:            resp.PostId,
-		CharacterID:   resp.CharacterId,
-		DisplayName:   resp.DisplayName,
-		Caption:       resp.Caption,
-		MediaURL:      resp.MediaUrl,
-		AvatarURL:     resp.AvatarUrl,
-		CreatedAt:     createdAt,
-		LikesCount:    int(resp.LikesCount),
-		CommentsCount: int(resp.CommentsCount),
-		UserLiked:     userLiked,
-		IsAI:          resp.IsAi,
+		ID:                 resp.PostId,
+		CharacterID:        resp.CharacterId,
+		DisplayName:        resp.DisplayName,
+		Caption:            resp.Caption,
+		MediaURL:           resp.MediaUrl,
+		MediaWidth:         resp.MediaWidth,
+		MediaHeight:        resp.MediaHeight,
+		MediaBlurhash:      resp.MediaBlurhash,
+		MediaDominantColor: resp.MediaDominantColor,
+		AvatarURL:          resp.AvatarUrl,
+		CreatedAt:          createdAt,
+		LikesCount:         int(resp.LikesCount),
+		CommentsCount:      int(resp.CommentsCount),
+		UserLiked:          userLiked,
+		IsAI:               resp.IsAi,
 	}
 
 	// Send response
@@ -233,6 +249,48 @@ type FeedResponse struct {
 	HasMore    bool           `json:"has_more"`
 }
 
+// DeletePost handles requests to delete a post by its author. With reason=moderated
+// a moderator removes any post, post service checks the role from the access token.
+func (h *PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
+	ctx, span := h.tracer.Start(r.Context(), "PostHandler.DeletePost")
+	defer span.End()
+
+	// Get user ID from context
+	userID, ok := ctx.Value(middleware.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		span.SetAttributes(attribute.Bool("error", true))
+		return
+	}
+
+	// Get post ID from URL path
+	postID := mux.Vars(r)["id"]
+	if postID == "" {
+		http.Error(w, "Post ID is required", http.StatusBadRequest)
+		span.SetAttributes(attribute.Bool("error", true))
+		return
+	}
+
+	req := &postpb.DeletePostRequest{
+		PostId: postID,
+		UserId: userID,
+	}
+	if reason := r.URL.Query().Get("reason"); reason != "" {
+		req.Reason = reason
+		req.AccessToken = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+
+	_, err := h.postClient.DeletePost(ctx, req)
+	if err != nil {
+		http.Error(w, "Failed to delete post", grpcStatusToHTTP(err))
+		span.SetAttributes(attribute.Bool("error", true))
+		logger.Logger.Error("Failed to delete post", zap.Error(err), zap.String("post_id", postID))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // GetUserPosts handles requests to get posts by user ID
 func (h *PostHandler) GetUserPosts(w http.ResponseWriter, r *http.Request) {
 	ctx, span := h.tracer.Start(r.Context(), "PostHandler.GetUserPosts")
@@ -288,20 +346,11 @@ func (h *PostHandler) GetUserPosts(w http.ResponseWriter, r *http.Request) {
 	// Check if current user has liked these posts (if authenticated)
 	currentUserID, _ := ctx.Value(middleware.UserIDKey).(string)
 
+	likedPosts := h.likedPosts(ctx, currentUserID, resp.Posts)
+
 	// Prepare response
 	posts := make([]PostResponse, 0, len(resp.Posts))
 	for _, post := range resp.Posts {
-		// Check if current user has liked this post
-		userLiked := false
-		if currentUserID != "" {
-			likeResp, err := h.interactionClient.CheckUserLiked(ctx, &interactionpb.CheckUserLikedRequest{
-				PostId: post.PostId,
-				UserId: currentUserID,
-			})
-			if err == nil {
-				userLiked = likeResp.Liked
-			}
-		}
 
 		// Parse created time
 		createdAt, err := time.Parse(time.RFC3339, post.CreatedAt)
@@ -310,17 +359,21 @@ func (h *PostHandler) GetUserPosts(w http.ResponseWriter, r *http.Request) {
 		}
 
 		posts = append(posts, PostResponse{
-			ID:            post.PostId,
-			CharacterID:   post.CharacterId,
-			DisplayName:   post.DisplayName,
-			Caption:       post.Caption,
-			MediaURL:      post.MediaUrl,
-			AvatarURL:     post.AvatarUrl,
-			CreatedAt:     createdAt,
-			LikesCount:    int(post.LikesCount),
-			CommentsCount: int(post.CommentsCount),
-			UserLiked:     userLiked,
-			IsAI:          post.IsAi,
+			ID:                 post.PostId,
+			CharacterID:        post.CharacterId,
+			DisplayName:        post.DisplayName,
+			Caption:            post.Caption,
+			MediaURL:           post.MediaUrl,
+			MediaWidth:         post.MediaWidth,
+			MediaHeight:        post.MediaHeight,
+			MediaBlurhash:      post.MediaBlurhash,
+			MediaDominantColor: post.MediaDominantColor,
+			AvatarURL:          post.AvatarUrl,
+			CreatedAt:          createdAt,
+			LikesCount:         int(post.LikesCount),
+			CommentsCount:      int(post.CommentsCount),
+			UserLiked:          likedPosts[post.PostId],
+			IsAI:               post.IsAi,
 		})
 	}
 
@@ -380,20 +433,29 @@ func (h *PostHandler) GetCharacterPosts(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Check if current user has liked these posts (if authenticated)
+	currentUserID, _ := ctx.Value(middleware.UserIDKey).(string)
+	likedPosts := h.likedPosts(ctx, currentUserID, resp.Posts)
+
 	// Convert posts to response format
 	posts := make([]PostResponse, len(resp.Posts))
 	for i, post := range resp.Posts {
 		posts[i] = PostResponse{
-			ID:            post.PostId,
-			CharacterID:   post.CharacterId,
-			DisplayName:   post.DisplayName,
-			Caption:       post.Caption,
-			MediaURL:      post.MediaUrl,
-			AvatarURL:     post.AvatarUrl,
-			CreatedAt:     time.Unix(0, 0), // TODO: Parse created_at from string
-			LikesCount:    int(post.LikesCount),
-			CommentsCount: int(post.CommentsCount),
-			IsAI:          post.IsAi,
+			ID:                 post.PostId,
+			CharacterID:        post.CharacterId,
+			DisplayName:        post.DisplayName,
+			Caption:            post.Caption,
+			MediaURL:           post.MediaUrl,
+			MediaWidth:         post.MediaWidth,
+			MediaHeight:        post.MediaHeight,
+			MediaBlurhash:      post.MediaBlurhash,
+			MediaDominantColor: post.MediaDominantColor,
+			AvatarURL:          post.AvatarUrl,
+			CreatedAt:          time.Unix(0, 0), // TODO: Parse created_at from string
+			LikesCount:         int(post.LikesCount),
+			CommentsCount:      int(post.CommentsCount),
+			UserLiked:          likedPosts[post.PostId],
+			IsAI:               post.IsAi,
 		}
 	}
 
@@ -406,7 +468,8 @@ func (h *PostHandler) GetCharacterPosts(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-// GetGlobalPosts handles requests to get the global feed directly from post service
+// GetGlobalPosts handles requests to get the feed of a world from the materialized
+// timelines of feed service. mode=for_you returns the ranked feed.
 func (h *PostHandler) GetGlobalPosts(w http.ResponseWriter, r *http.Request) {
 	ctx, span := h.tracer.Start(r.Context(), "PostHandler.GetGlobalPosts")
 	defer span.End()
@@ -424,6 +487,11 @@ func (h *PostHandler) GetGlobalPosts(w http.ResponseWriter, r *http.Request) {
 
 	cursor := r.URL.Query().Get("cursor")
 
+	mode := feedpb.GetUserFeedRequest_CHRONOLOGICAL
+	if r.URL.Query().Get("mode") == "for_you" {
+		mode = feedpb.GetUserFeedRequest_FOR_YOU
+	}
+
 	// Get world_id from URL parameters
 	vars := mux.Vars(r)
 	worldID := vars["world_id"]
@@ -434,52 +502,45 @@ func (h *PostHandler) GetGlobalPosts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get posts directly from post service
-	resp, err := h.postClient.GetGlobalFeed(ctx, &postpb.GetGlobalFeedRequest{
-		Limit:   int32(limit),
-		Cursor:  cursor,
-		WorldId: worldID,
+	// Get posts from feed service, it also reports which posts the user liked
+	resp, err := h.feedClient.GetUserFeed(ctx, &feedpb.GetUserFeedRequest{
+		WorldId:          worldID,
+		RequestingUserId: userID,
+		Limit:            int32(limit),
+		Cursor:           cursor,
+		Mode:             mode,
 	})
 	if err != nil {
-		http.Error(w, "Failed to get posts", http.StatusInternalServerError)
 		span.SetAttributes(attribute.Bool("error", true))
-		logger.Logger.Error("Failed to get posts", zap.Error(err))
+		switch status.Code(err) {
+		case codes.InvalidArgument, codes.FailedPrecondition:
+			http.Error(w, "Invalid or expired cursor", http.StatusBadRequest)
+		default:
+			http.Error(w, "Failed to get posts", http.StatusInternalServerError)
+			logger.Logger.Error("Failed to get posts", zap.Error(err))
+		}
 		return
 	}
 
 	// Prepare response
 	posts := make([]PostResponse, 0, len(resp.Posts))
 	for _, post := range resp.Posts {
-		// Check if current user has liked this post
-		userLiked := false
-		if userID != "" {
-			likeResp, err := h.interactionClient.CheckUserLiked(ctx, &interactionpb.CheckUserLikedRequest{
-				PostId: post.PostId,
-				UserId: userID,
-			})
-			if err == nil {
-				userLiked = likeResp.Liked
-			}
-		}
-
-		// Parse created time
-		createdAt, err := time.Parse(time.RFC3339, post.CreatedAt)
-		if err != nil {
-			createdAt = time.Now() // Fallback
-		}
-
 		posts = append(posts, PostResponse{
-			ID:            post.PostId,
-			CharacterID:   post.CharacterId,
-			DisplayName:   post.DisplayName,
-			Caption:       post.Caption,
-			MediaURL:      post.MediaUrl,
-			AvatarURL:     post.AvatarUrl,
-			CreatedAt:     createdAt,
-			LikesCount:    int(post.LikesCount),
-			CommentsCount: int(post.CommentsCount),
-			UserLiked:     userLiked,
-			IsAI:          post.IsAi,
+			ID:                 post.Id,
+			CharacterID:        post.GetCharacter().GetId(),
+			DisplayName:        post.GetCharacter().GetDisplayName(),
+			Caption:            post.Caption,
+			MediaURL:           post.MediaUrl,
+			MediaWidth:         post.MediaWidth,
+			MediaHeight:        post.MediaHeight,
+			MediaBlurhash:      post.MediaBlurhash,
+			MediaDominantColor: post.MediaDominantColor,
+			AvatarURL:          post.GetCharacter().GetProfilePictureUrl(),
+			CreatedAt:          time.Unix(post.CreatedAt, 0),
+			LikesCount:         int(post.GetStats().GetLikesCount()),
+			CommentsCount:      int(post.GetStats().GetCommentsCount()),
+			UserLiked:          post.GetStats().GetUserLiked(),
+			IsAI:               post.IsAi,
 		})
 	}
 
@@ -488,7 +549,7 @@ func (h *PostHandler) GetGlobalPosts(w http.ResponseWriter, r *http.Request) {
 		Posts:      posts,
 		Total:      len(posts),
 		NextCursor: resp.NextCursor,
-		HasMore:    resp.NextCursor != "",
+		HasMore:    resp.HasMore,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -497,3 +558,31 @@ func (h *PostHandler) GetGlobalPosts(w http.ResponseWriter, r *http.Request) {
 		logger.Logger.Error("Failed to encode response", zap.Error(err))
 	}
 }
+
+// likedPosts returns which of the posts the user has liked, using a single
+// batched call. Errors are logged and treated as "not liked".
+func (h *PostHandler) likedPosts(ctx context.Context, userID string, posts []*postpb.Post) map[string]bool {
+	liked := make(map[string]bool)
+	if userID == "" || len(posts) == 0 {
+		return liked
+	}
+
+	postIDs := make([]string, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.PostId
+	}
+
+	resp, err := h.interactionClient.CheckUserLikedPosts(ctx, &interactionpb.CheckUserLikedPostsRequest{
+		UserId:  userID,
+		PostIds: postIDs,
+	})
+	if err != nil {
+		logger.Logger.Warn("Failed to check liked posts", zap.Error(err), zap.String("user_id", userID))
+		return liked
+	}
+
+	for _, postID := range resp.LikedPostIds {
+		liked[postID] = true
+	}
+	return liked
+}
@@ -3,14 +3,15 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strconv"
 	"time"
 
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/gorilla/mux"
+	"github.com/sdshorin/generia/pkg/auth"
 	"github.com/sdshorin/generia/pkg/logger"
 	"github.com/sdshorin/generia/services/api-gateway/middleware"
 	"go.opentelemetry.io/otel/trace"
@@ -34,15 +35,15 @@ type WorldHandler struct {
 	worldClient worldpb.WorldServiceClient
 	timeout     time.Duration
 	tracer      trace.Tracer
-	jwtSecret   string
+	verifier    *auth.TokenVerifier
 }
 
 // NewWorldHandler creates a new WorldHandler
-func NewWorldHandler(worldClient worldpb.WorldServiceClient, timeout time.Duration, jwtSecret string) *WorldHandler {
+func NewWorldHandler(worldClient worldpb.WorldServiceClient, timeout time.Duration, verifier *auth.TokenVerifier) *WorldHandler {
 	return &WorldHandler{
 		worldClient: worldClient,
 		timeout:     timeout,
-		jwtSecret:   jwtSecret,
+		verifier:    verifier,
 	}
 }
 
@@ -312,24 +313,13 @@ func grpcStatusToHTTP(err error) int {
 	return http.StatusInternalServerError
 }
 
-// validateTokenFromQuery validates JWT token from query parameters
-func (h *WorldHandler) validateTokenFromQuery(tokenString string) (string, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(h.jwtSecret), nil
-	})
-
-	if err != nil || !token.Valid {
-		return "", fmt.Errorf("invalid token: %w", err)
-	}
-
-	if claims, ok := token.Claims.(jwt.MapClaims); ok {
-		if userID, ok := claims["user_id"].(string); ok {
-			return userID, nil
-		}
-		return "", fmt.Errorf("user_id not found in token")
+// validateTokenFromQuery validates JWT token from query parameters, revoked tokens are rejected
+func (h *WorldHandler) validateTokenFromQuery(ctx context.Context, tokenString string) (string, error) {
+	claims, err := h.verifier.Verify(ctx, tokenString)
+	if err != nil {
+		return "", err
 	}
-
-	return "", fmt.Errorf("invalid token format")
+	return claims.UserID, nil
 }
 
 // StreamWorldStatus handles SSE for world generation status
@@ -356,7 +346,11 @@ func (h *WorldHandler) StreamWorldStatus(w http.ResponseWriter, r *http.Request)
 	} else {
 		// Validate token manually for SSE
 		var err error
-		userID, err = h.validateTokenFromQuery(token)
+		userID, err = h.validateTokenFromQuery(ctx, token)
+		if errors.Is(err, auth.ErrRevocationUnknown) {
+			http.Error(w, "Authentication is temporarily unavailable", http.StatusServiceUnavailable)
+			return
+		}
 		if err != nil {
 			logger.Logger.Debug("SSE token validation failed", zap.Error(err))
 			http.Error(w, "Unauthorized: invalid token", http.StatusUnauthorized)
@@ -2,11 +2,11 @@ package middleware
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"net/http"
 	"strings"
 
-	"github.com/golang-jwt/jwt/v4"
+	"github.com/sdshorin/generia/pkg/auth"
 	"github.com/sdshorin/generia/pkg/logger"
 	"go.uber.org/zap"
 )
@@ -14,15 +14,24 @@ import (
 // UserIDKey is the key to store the user ID in the request context
 const UserIDKey = "user_id"
 
+// SessionIDKey is the key to store the session ID of the token in the request context
+const SessionIDKey = "session_id"
+
+// EmailVerifiedKey is the key to store whether the user has confirmed the email in the request context
+const EmailVerifiedKey = "email_verified"
+
+// RolesKey is the key to store the roles of the user in the request context
+const RolesKey = "roles"
+
 // JWTMiddleware handles JWT authentication
 type JWTMiddleware struct {
-	jwtSecret string
+	verifier *auth.TokenVerifier
 }
 
 // NewJWTMiddleware creates a new JWTMiddleware
-func NewJWTMiddleware(jwtSecret string) *JWTMiddleware {
+func NewJWTMiddleware(verifier *auth.TokenVerifier) *JWTMiddleware {
 	return &JWTMiddleware{
-		jwtSecret: jwtSecret,
+		verifier: verifier,
 	}
 }
 
@@ -46,36 +55,23 @@ func (m *JWTMiddleware) RequireAuth(next http.Handler) http.Handler {
 		tokenString := tokenParts[1]
 
 		// Validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Ensure the signing method is HMAC to prevent alg=none attacks
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(m.jwtSecret), nil
-		})
-
-		if err != nil || !token.Valid {
+		claims, err := m.verifier.Verify(r.Context(), tokenString)
+		if errors.Is(err, auth.ErrTokenRevoked) {
+			http.Error(w, "Token has been revoked", http.StatusUnauthorized)
+			return
+		}
+		if errors.Is(err, auth.ErrRevocationUnknown) {
+			// The token may be valid, the client should retry rather than log in again
+			http.Error(w, "Authentication is temporarily unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
 			logger.Logger.Debug("Invalid token", zap.Error(err))
 			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
 			return
 		}
 
-		// Extract claims
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			userId, ok := claims["user_id"].(string)
-			if !ok {
-				logger.Logger.Error("User ID not found in token")
-				http.Error(w, "Invalid token format", http.StatusUnauthorized)
-				return
-			}
-
-			// Add user ID to context
-			ctx := context.WithValue(r.Context(), UserIDKey, userId)
-			next.ServeHTTP(w, r.WithContext(ctx))
-		} else {
-			logger.Logger.Error("Failed to parse token claims")
-			http.Error(w, "Invalid token format", http.StatusUnauthorized)
-		}
+		next.ServeHTTP(w, r.WithContext(withClaims(r.Context(), claims)))
 	})
 }
 
@@ -101,26 +97,35 @@ func (m *JWTMiddleware) Optional(next http.Handler) http.Handler {
 
 		tokenString := tokenParts[1]
 
-		// Validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(m.jwtSecret), nil
-		})
-
-		// If token is valid, add user ID to context
-		if err == nil && token.Valid {
-			if claims, ok := token.Claims.(jwt.MapClaims); ok {
-				if userId, ok := claims["user_id"].(string); ok {
-					ctx := context.WithValue(r.Context(), UserIDKey, userId)
-					next.ServeHTTP(w, r.WithContext(ctx))
-					return
-				}
-			}
+		// If token is valid, add user information to context
+		if claims, err := m.verifier.Verify(r.Context(), tokenString); err == nil {
+			next.ServeHTTP(w, r.WithContext(withClaims(r.Context(), claims)))
+			return
 		}
 
 		// If token is invalid, continue without authentication
 		next.ServeHTTP(w, r)
 	})
 }
+
+// RequireVerifiedEmail rejects users that have not confirmed the email, it is used after RequireAuth.
+// The status comes from the access token, after verification the client refreshes it.
+func (m *JWTMiddleware) RequireVerifiedEmail(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if verified, _ := r.Context().Value(EmailVerifiedKey).(bool); !verified {
+			http.Error(w, "Email verification required", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withClaims adds the user information of a verified token to the context
+func withClaims(ctx context.Context, claims *auth.Claims) context.Context {
+	ctx = context.WithValue(ctx, UserIDKey, claims.UserID)
+	if claims.SessionID != "" {
+		ctx = context.WithValue(ctx, SessionIDKey, claims.SessionID)
+	}
+	ctx = context.WithValue(ctx, EmailVerifiedKey, claims.EmailVerified)
+	return context.WithValue(ctx, RolesKey, claims.Roles)
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sdshorin/generia/pkg/auth"
+	"github.com/sdshorin/generia/pkg/config"
+	"github.com/sdshorin/generia/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
+)
+
+func TestMain(m *testing.M) {
+	logger.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// emptyCache is a deny-list without revoked tokens
+type emptyCache struct {
+	cachepb.CacheServiceClient
+}
+
+func (emptyCache) MGet(ctx context.Context, req *cachepb.MGetRequest, opts ...grpc.CallOption) (*cachepb.MGetResponse, error) {
+	resp := &cachepb.MGetResponse{}
+	for _, key := range req.Keys {
+		resp.Results = append(resp.Results, &cachepb.MGetResult{Key: key})
+	}
+	return resp, nil
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	cfg := config.JWTConfig{Expiration: 15 * time.Minute, Issuer: "generia-auth", Audience: "generia", AllowEphemeralKey: true}
+	keyring, err := auth.NewKeyring(cfg)
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	issuer := auth.NewTokenIssuer(keyring, cfg)
+	m := NewJWTMiddleware(auth.NewTokenVerifier(keyring.Keyfunc, auth.NewDenyList(emptyCache{}, cfg.Leeway), cfg))
+
+	issue := func(verified bool) string {
+		token, err := issuer.Issue(auth.Claims{UserID: "user-1", EmailVerified: verified})
+		if err != nil {
+			t.Fatalf("Issue: %v", err)
+		}
+		return "Bearer " + token
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name          string
+		handler       http.Handler
+		authorization string
+		want          int
+	}{
+		{"verified", m.RequireAuth(m.RequireVerifiedEmail(ok)), issue(true), http.StatusNoContent},
+		{"unverified", m.RequireAuth(m.RequireVerifiedEmail(ok)), issue(false), http.StatusForbidden},
+		{"no token", m.RequireAuth(m.RequireVerifiedEmail(ok)), "", http.StatusUnauthorized},
+		{"without RequireAuth", m.RequireVerifiedEmail(ok), issue(true), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/worlds", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			tt.handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
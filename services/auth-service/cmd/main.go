@@ -14,19 +14,24 @@ import (
 	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sdshorin/generia/pkg/auth"
 	"github.com/sdshorin/generia/pkg/config"
 	"github.com/sdshorin/generia/pkg/database"
 	"github.com/sdshorin/generia/pkg/discovery"
 	"github.com/sdshorin/generia/pkg/logger"
+	"github.com/sdshorin/generia/pkg/mailer"
 	"github.com/sdshorin/generia/pkg/telemetry"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 
 	authpb "github.com/sdshorin/generia/api/grpc/auth"
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
 	"github.com/sdshorin/generia/services/auth-service/internal/repository"
 	"github.com/sdshorin/generia/services/auth-service/internal/service"
 )
@@ -85,11 +90,38 @@ func main() {
 	}
 	defer discoveryClient.Deregister(serviceID)
 
+	// Initialize cache service client, it stores the deny-list of revoked access tokens
+	cacheConn, cacheClient, err := createCacheClient(discoveryClient)
+	if err != nil {
+		logger.Logger.Fatal("Failed to create cache client", zap.Error(err))
+	}
+	defer cacheConn.Close()
+
+	// Initialize mailer for verification and password reset emails
+	mail, err := mailer.New(&cfg.Mail)
+	if err != nil {
+		logger.Logger.Fatal("Failed to create mailer", zap.Error(err))
+	}
+
+	// Initialize token signing keys, other services get the public keys through GetJWKS
+	keyring, err := auth.NewKeyring(cfg.JWT)
+	if err != nil {
+		logger.Logger.Fatal("Failed to load JWT signing keys", zap.Error(err))
+	}
+	if keyring.Ephemeral() {
+		logger.Logger.Warn("JWT_ALLOW_EPHEMERAL_KEY is set, using a generated signing key: tokens will not survive a restart")
+	}
+
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 
+	// Initialize token issuer and verifier
+	denyList := auth.NewDenyList(cacheClient, cfg.JWT.Leeway)
+	tokenIssuer := auth.NewTokenIssuer(keyring, cfg.JWT)
+	tokenVerifier := auth.NewTokenVerifier(keyring.Keyfunc, denyList, cfg.JWT)
+
 	// Initialize services
-	authService := service.NewAuthService(userRepo, cfg.JWT.Secret, cfg.JWT.Expiration)
+	authService := service.NewAuthService(userRepo, tokenIssuer, tokenVerifier, cfg.JWT.Expiration, denyList, mail, cfg.Mail.AppURL)
 
 	// Create gRPC server with middleware
 	grpcServer := grpc.NewServer(
@@ -147,3 +179,30 @@ func main() {
 	logger.Logger.Info("Auth service stopped")
 }
 
+func createCacheClient(discoveryClient discovery.ServiceDiscovery) (*grpc.ClientConn, cachepb.CacheServiceClient, error) {
+	// Get service address from Consul
+	serviceAddress, err := discoveryClient.ResolveService("cache-service")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve cache service: %w", err)
+	}
+
+	// Create gRPC connection
+	conn, err := grpc.Dial(
+		serviceAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                10 * time.Second,
+			Timeout:             time.Second,
+			PermitWithoutStream: true,
+		}),
+		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to cache service: %w", err)
+	}
+
+	// Create client
+	client := cachepb.NewCacheServiceClient(conn)
+
+	return conn, client, nil
+}
@@ -8,12 +8,19 @@ import (
 
 // User represents a user in the system
 type User struct {
-	ID           string    `db:"id"`
-	Username     string    `db:"username"`
-	Email        string    `db:"email"`
-	PasswordHash string    `db:"password_hash"`
-	CreatedAt    time.Time `db:"created_at"`
-	UpdatedAt    time.Time `db:"updated_at"`
+	ID              string     `db:"id"`
+	Username        string     `db:"username"`
+	Email           string     `db:"email"`
+	PasswordHash    string     `db:"password_hash"`
+	EmailVerifiedAt *time.Time `db:"email_verified_at"`
+	Role            string     `db:"role"`
+	CreatedAt       time.Time  `db:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at"`
+}
+
+// EmailVerified reports whether the user has confirmed the email
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
 // HashPassword hashes the provided password using bcrypt
@@ -28,11 +35,32 @@ func CheckPassword(providedPassword, hashedPassword string) bool {
 	return err == nil
 }
 
-// RefreshToken represents a refresh token in the system
+// RefreshToken represents a refresh token in the system. Each token is a
+// session: refreshing replaces the hash but keeps the ID and creation time.
 type RefreshToken struct {
-	ID        string    `db:"id"`
-	UserID    string    `db:"user_id"`
-	TokenHash string    `db:"token_hash"`
-	ExpiresAt time.Time `db:"expires_at"`
-	CreatedAt time.Time `db:"created_at"`
-}
\ No newline at end of file
+	ID         string    `db:"id"`
+	UserID     string    `db:"user_id"`
+	TokenHash  string    `db:"token_hash"`
+	UserAgent  string    `db:"user_agent"`
+	IPAddress  string    `db:"ip_address"`
+	ExpiresAt  time.Time `db:"expires_at"`
+	CreatedAt  time.Time `db:"created_at"`
+	LastUsedAt time.Time `db:"last_used_at"`
+}
+
+// Purposes of user tokens
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+)
+
+// UserToken is a single-use token sent to the user by email
+type UserToken struct {
+	ID        string     `db:"id"`
+	UserID    string     `db:"user_id"`
+	Purpose   string     `db:"purpose"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
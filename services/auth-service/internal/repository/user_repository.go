@@ -21,6 +21,14 @@ type UserRepository interface {
 	SaveRefreshToken(ctx context.Context, token *models.RefreshToken) error
 	GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
 	DeleteRefreshToken(ctx context.Context, tokenHash string) error
+	DeleteUserRefreshTokens(ctx context.Context, userID string) (int64, error)
+	RotateRefreshToken(ctx context.Context, token *models.RefreshToken, oldTokenHash string) (bool, error)
+	ListUserRefreshTokens(ctx context.Context, userID string) ([]*models.RefreshToken, error)
+	DeleteUserRefreshToken(ctx context.Context, userID, id string) (bool, error)
+	CreateUserToken(ctx context.Context, token *models.UserToken) error
+	CountUserTokensSince(ctx context.Context, userID, purpose string, since time.Time) (int, error)
+	VerifyEmail(ctx context.Context, tokenHash string) (string, error)
+	ResetPassword(ctx context.Context, tokenHash, passwordHash string) (string, error)
 }
 
 type userRepository struct {
@@ -39,7 +47,7 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 	query := `
 		INSERT INTO users (id, username, email, password_hash, created_at, updated_at)
 		VALUES (uuid_generate_v4(), $1, $2, $3, $4, $5)
-		RETURNING id
+		RETURNING id, role
 	`
 
 	now := time.Now()
@@ -55,7 +63,7 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 		user.PasswordHash,
 		user.CreatedAt,
 		user.UpdatedAt,
-	).Scan(&id)
+	).Scan(&id, &user.Role)
 
 	if err != nil {
 		logger.Logger.Error("Failed to create user", zap.Error(err))
@@ -69,7 +77,7 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 // GetByID retrieves a user by ID
 func (r *userRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, created_at, updated_at
+		SELECT id, username, email, password_hash, email_verified_at, role, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
@@ -90,7 +98,7 @@ func (r *userRepository) GetByID(ctx context.Context, id string) (*models.User,
 // GetByEmail retrieves a user by email
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, created_at, updated_at
+		SELECT id, username, email, password_hash, email_verified_at, role, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
@@ -111,7 +119,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.
 // GetByUsername retrieves a user by username
 func (r *userRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, created_at, updated_at
+		SELECT id, username, email, password_hash, email_verified_at, role, created_at, updated_at
 		FROM users
 		WHERE username = $1
 	`
@@ -150,23 +158,28 @@ func (r *userRepository) SaveRefreshToken(ctx context.Context, token *models.Ref
 		// Continue even if cleanup fails
 	}
 
-	// Insert the new token with ON CONFLICT handling for unique token hash
+	// Insert the new token with ON CONFLICT handling for unique token hash.
+	// The ID is the session ID, it is generated in advance to be put into access tokens.
 	query := `
-		INSERT INTO refresh_tokens (id, user_id, token_hash, expires_at, created_at)
-		VALUES (uuid_generate_v4(), $1, $2, $3, $4)
+		INSERT INTO refresh_tokens (id, user_id, token_hash, user_agent, ip_address, expires_at, created_at, last_used_at)
+		VALUES (COALESCE(NULLIF($1, '')::uuid, uuid_generate_v4()), $2, $3, $4, $5, $6, $7, $7)
 		ON CONFLICT (token_hash) DO NOTHING
 		RETURNING id
 	`
 
 	now := time.Now()
 	token.CreatedAt = now
+	token.LastUsedAt = now
 
 	var id string
 	err = r.db.QueryRowContext(
 		ctx,
 		query,
+		token.ID,
 		token.UserID,
 		token.TokenHash,
+		token.UserAgent,
+		token.IPAddress,
 		token.ExpiresAt,
 		token.CreatedAt,
 	).Scan(&id)
@@ -190,7 +203,7 @@ func (r *userRepository) SaveRefreshToken(ctx context.Context, token *models.Ref
 // GetRefreshToken retrieves a refresh token by token hash
 func (r *userRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
 	query := `
-		SELECT id, user_id, token_hash, expires_at, created_at
+		SELECT id, user_id, token_hash, user_agent, ip_address, expires_at, created_at, last_used_at
 		FROM refresh_tokens
 		WHERE token_hash = $1
 	`
@@ -222,4 +235,239 @@ func (r *userRepository) DeleteRefreshToken(ctx context.Context, tokenHash strin
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// DeleteUserRefreshTokens deletes every refresh token of a user and returns how many were deleted
+func (r *userRepository) DeleteUserRefreshTokens(ctx context.Context, userID string) (int64, error) {
+	query := `
+		DELETE FROM refresh_tokens
+		WHERE user_id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		logger.Logger.Error("Failed to delete user refresh tokens", zap.Error(err), zap.String("user_id", userID))
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// RotateRefreshToken replaces the hash of a refresh token, keeping its session.
+// It returns false if the old hash was already replaced, e.g. by a concurrent refresh.
+func (r *userRepository) RotateRefreshToken(ctx context.Context, token *models.RefreshToken, oldTokenHash string) (bool, error) {
+	query := `
+		UPDATE refresh_tokens
+		SET token_hash = $1, user_agent = $2, ip_address = $3, expires_at = $4, last_used_at = $5
+		WHERE id = $6 AND token_hash = $7
+	`
+
+	token.LastUsedAt = time.Now()
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		token.TokenHash,
+		token.UserAgent,
+		token.IPAddress,
+		token.ExpiresAt,
+		token.LastUsedAt,
+		token.ID,
+		oldTokenHash,
+	)
+	if err != nil {
+		logger.Logger.Error("Failed to rotate refresh token", zap.Error(err), zap.String("id", token.ID))
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// ListUserRefreshTokens retrieves the unexpired refresh tokens of a user, most recently used first
+func (r *userRepository) ListUserRefreshTokens(ctx context.Context, userID string) ([]*models.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, user_agent, ip_address, expires_at, created_at, last_used_at
+		FROM refresh_tokens
+		WHERE user_id = $1 AND expires_at > $2
+		ORDER BY last_used_at DESC
+	`
+
+	var tokens []*models.RefreshToken
+	err := r.db.SelectContext(ctx, &tokens, query, userID, time.Now())
+	if err != nil {
+		logger.Logger.Error("Failed to list user refresh tokens", zap.Error(err), zap.String("user_id", userID))
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// DeleteUserRefreshToken deletes a refresh token of a user by ID and reports whether it existed
+func (r *userRepository) DeleteUserRefreshToken(ctx context.Context, userID, id string) (bool, error) {
+	query := `
+		DELETE FROM refresh_tokens
+		WHERE id = $1 AND user_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		logger.Logger.Error("Failed to delete user refresh token", zap.Error(err), zap.String("user_id", userID), zap.String("id", id))
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// CreateUserToken saves a single-use token. Unused tokens of the same user and
+// purpose are marked as used, so only the token from the latest email works.
+func (r *userRepository) CreateUserToken(ctx context.Context, token *models.UserToken) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE user_tokens
+		SET used_at = $3
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+	`, token.UserID, token.Purpose, now)
+	if err != nil {
+		logger.Logger.Error("Failed to replace user tokens", zap.Error(err), zap.String("user_id", token.UserID))
+		return err
+	}
+
+	query := `
+		INSERT INTO user_tokens (id, user_id, purpose, token_hash, expires_at, created_at)
+		VALUES (uuid_generate_v4(), $1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	token.CreatedAt = now
+	err = tx.QueryRowContext(ctx, query,
+		token.UserID,
+		token.Purpose,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	).Scan(&token.ID)
+	if err != nil {
+		logger.Logger.Error("Failed to create user token", zap.Error(err), zap.String("user_id", token.UserID))
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CountUserTokensSince counts the tokens created for a user and purpose since the given time
+func (r *userRepository) CountUserTokensSince(ctx context.Context, userID, purpose string, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM user_tokens
+		WHERE user_id = $1 AND purpose = $2 AND created_at > $3
+	`
+
+	var count int
+	err := r.db.GetContext(ctx, &count, query, userID, purpose, since)
+	if err != nil {
+		logger.Logger.Error("Failed to count user tokens", zap.Error(err), zap.String("user_id", userID))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// VerifyEmail uses an email verification token and marks the email of its user as verified.
+// It returns the user ID, or an empty string if the token is unknown, used or expired.
+func (r *userRepository) VerifyEmail(ctx context.Context, tokenHash string) (string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	userID, err := useUserToken(ctx, tx, tokenHash, models.TokenPurposeVerifyEmail, now)
+	if err != nil || userID == "" {
+		return "", err
+	}
+
+	// Verifying again keeps the time of the first verification
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, $2), updated_at = $2
+		WHERE id = $1
+	`, userID, now)
+	if err != nil {
+		logger.Logger.Error("Failed to verify email", zap.Error(err), zap.String("user_id", userID))
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return userID, nil
+}
+
+// ResetPassword uses a password reset token and sets the new password hash of its user.
+// It returns the user ID, or an empty string if the token is unknown, used or expired.
+func (r *userRepository) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	userID, err := useUserToken(ctx, tx, tokenHash, models.TokenPurposeResetPassword, now)
+	if err != nil || userID == "" {
+		return "", err
+	}
+
+	// The reset link was delivered to the email, so it is verified as well
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users
+		SET password_hash = $2, email_verified_at = COALESCE(email_verified_at, $3), updated_at = $3
+		WHERE id = $1
+	`, userID, passwordHash, now)
+	if err != nil {
+		logger.Logger.Error("Failed to reset password", zap.Error(err), zap.String("user_id", userID))
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return userID, nil
+}
+
+// useUserToken marks a token as used and returns its user ID. The update only
+// matches an unused, unexpired token, so concurrent requests cannot both use it.
+func useUserToken(ctx context.Context, tx *sqlx.Tx, tokenHash, purpose string, now time.Time) (string, error) {
+	query := `
+		UPDATE user_tokens
+		SET used_at = $3
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > $3
+		RETURNING user_id
+	`
+
+	var userID string
+	err := tx.QueryRowContext(ctx, query, tokenHash, purpose, now).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		logger.Logger.Error("Failed to use user token", zap.Error(err), zap.String("purpose", purpose))
+		return "", err
+	}
+
+	return userID, nil
+}
@@ -2,14 +2,19 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"net/url"
 	"time"
 
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
+	"github.com/sdshorin/generia/pkg/auth"
 	"github.com/sdshorin/generia/pkg/logger"
+	"github.com/sdshorin/generia/pkg/mailer"
 	"github.com/sdshorin/generia/services/auth-service/internal/models"
 	"github.com/sdshorin/generia/services/auth-service/internal/repository"
 	"go.uber.org/zap"
@@ -19,20 +24,45 @@ import (
 	authpb "github.com/sdshorin/generia/api/grpc/auth"
 )
 
+// maxUserAgentLength bounds the user agent stored with a session
+const maxUserAgentLength = 512
+
+// Lifetime of the links sent by email
+const (
+	verifyEmailTokenTTL   = 24 * time.Hour
+	resetPasswordTokenTTL = time.Hour
+)
+
+// maxEmailsPerHour bounds the emails of one kind sent to a user, so the
+// endpoints sending them cannot be used to flood a mailbox
+const maxEmailsPerHour = 5
+
+// minPasswordLength is the minimal length of a new password
+const minPasswordLength = 8
+
 // AuthService implements the auth gRPC service
 type AuthService struct {
 	authpb.UnimplementedAuthServiceServer
 	userRepo       repository.UserRepository
-	jwtSecret      string
+	tokens         *auth.TokenIssuer
+	verifier       *auth.TokenVerifier
 	jwtExpiration  time.Duration
+	denyList       *auth.DenyList
+	mailer         mailer.Mailer
+	appURL         string
 }
 
-// NewAuthService creates a new AuthService
-func NewAuthService(userRepo repository.UserRepository, jwtSecret string, jwtExpiration time.Duration) authpb.AuthServiceServer {
+// NewAuthService creates a new AuthService. Access tokens are issued by tokens
+// and checked by verifier; links in emails point to appURL.
+func NewAuthService(userRepo repository.UserRepository, tokens *auth.TokenIssuer, verifier *auth.TokenVerifier, jwtExpiration time.Duration, denyList *auth.DenyList, mailer mailer.Mailer, appURL string) authpb.AuthServiceServer {
 	return &AuthService{
 		userRepo:      userRepo,
-		jwtSecret:     jwtSecret,
+		tokens:        tokens,
+		verifier:      verifier,
 		jwtExpiration: jwtExpiration,
+		denyList:      denyList,
+		mailer:        mailer,
+		appURL:        appURL,
 	}
 }
 
@@ -83,8 +113,9 @@ func (s *AuthService) Register(ctx context.Context, req *authpb.RegisterRequest)
 		return nil, status.Errorf(codes.Internal, "failed to create user")
 	}
 
-	// Generate access token
-	accessToken, err := s.generateAccessToken(user.ID)
+	// Generate access token for a new session
+	sessionID := uuid.New().String()
+	accessToken, err := s.generateAccessToken(user, sessionID)
 	if err != nil {
 		logger.Logger.Error("Failed to generate access token", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "failed to generate access token")
@@ -101,8 +132,11 @@ func (s *AuthService) Register(ctx context.Context, req *authpb.RegisterRequest)
 	var saveErr error
 	for attempts := 0; attempts < 3; attempts++ {
 		saveErr = s.userRepo.SaveRefreshToken(ctx, &models.RefreshToken{
+			ID:        sessionID,
 			UserID:    user.ID,
 			TokenHash: refreshTokenHash,
+			UserAgent: userAgent(req.GetClient()),
+			IPAddress: req.GetClient().GetIpAddress(),
 			ExpiresAt: time.Now().Add(s.jwtExpiration * 30), // Refresh token lasts 30 times longer than access token
 		})
 		if saveErr == nil {
@@ -131,6 +165,11 @@ func (s *AuthService) Register(ctx context.Context, req *authpb.RegisterRequest)
 		return nil, status.Errorf(codes.Internal, "failed to save refresh token after multiple attempts")
 	}
 
+	// Send verification email, the user can request another one if it fails
+	if err := s.sendUserToken(ctx, user, models.TokenPurposeVerifyEmail); err != nil {
+		logger.Logger.Warn("Failed to send verification email", zap.Error(err), zap.String("user_id", user.ID))
+	}
+
 	return &authpb.RegisterResponse{
 		UserId:       user.ID,
 		AccessToken:  accessToken,
@@ -171,8 +210,9 @@ func (s *AuthService) Login(ctx context.Context, req *authpb.LoginRequest) (*aut
 		return nil, status.Errorf(codes.Unauthenticated, "invalid credentials")
 	}
 
-	// Generate access token
-	accessToken, err := s.generateAccessToken(user.ID)
+	// Generate access token for a new session
+	sessionID := uuid.New().String()
+	accessToken, err := s.generateAccessToken(user, sessionID)
 	if err != nil {
 		logger.Logger.Error("Failed to generate access token", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "failed to generate access token")
@@ -189,8 +229,11 @@ func (s *AuthService) Login(ctx context.Context, req *authpb.LoginRequest) (*aut
 	var saveErr error
 	for attempts := 0; attempts < 3; attempts++ {
 		saveErr = s.userRepo.SaveRefreshToken(ctx, &models.RefreshToken{
+			ID:        sessionID,
 			UserID:    user.ID,
 			TokenHash: refreshTokenHash,
+			UserAgent: userAgent(req.GetClient()),
+			IPAddress: req.GetClient().GetIpAddress(),
 			ExpiresAt: time.Now().Add(s.jwtExpiration * 30), // Refresh token lasts 30 times longer than access token
 		})
 		if saveErr == nil {
@@ -234,42 +277,12 @@ func (s *AuthService) ValidateToken(ctx context.Context, req *authpb.ValidateTok
 		return nil, status.Errorf(codes.InvalidArgument, "token is required")
 	}
 
-	// Parse token
-	token, err := jwt.Parse(req.Token, func(token *jwt.Token) (interface{}, error) {
-		// Validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.jwtSecret), nil
-	})
-
-	if err != nil {
-		return &authpb.ValidateTokenResponse{
-			Valid:  false,
-			UserId: "",
-		}, nil
-	}
-
-	// Check if token is valid
-	if !token.Valid {
-		return &authpb.ValidateTokenResponse{
-			Valid:  false,
-			UserId: "",
-		}, nil
+	// Verify token, this also checks that it was not revoked by logout
+	claims, err := s.verifier.Verify(ctx, req.Token)
+	if errors.Is(err, auth.ErrRevocationUnknown) {
+		return nil, status.Errorf(codes.Unavailable, "token revocation can't be checked")
 	}
-
-	// Extract claims
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return &authpb.ValidateTokenResponse{
-			Valid:  false,
-			UserId: "",
-		}, nil
-	}
-
-	// Get user ID
-	userID, ok := claims["user_id"].(string)
-	if !ok {
+	if err != nil {
 		return &authpb.ValidateTokenResponse{
 			Valid:  false,
 			UserId: "",
@@ -277,7 +290,7 @@ func (s *AuthService) ValidateToken(ctx context.Context, req *authpb.ValidateTok
 	}
 
 	// Check if user exists
-	user, err := s.userRepo.GetByID(ctx, userID)
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
 	if err != nil {
 		logger.Logger.Error("Failed to get user", zap.Error(err))
 		return &authpb.ValidateTokenResponse{
@@ -295,7 +308,7 @@ func (s *AuthService) ValidateToken(ctx context.Context, req *authpb.ValidateTok
 
 	return &authpb.ValidateTokenResponse{
 		Valid:  true,
-		UserId: userID,
+		UserId: claims.UserID,
 	}, nil
 }
 
@@ -318,10 +331,11 @@ func (s *AuthService) GetUserInfo(ctx context.Context, req *authpb.GetUserInfoRe
 	}
 
 	return &authpb.UserInfo{
-		UserId:    user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt.Format(time.RFC3339),
+		UserId:        user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		CreatedAt:     user.CreatedAt.Format(time.RFC3339),
+		EmailVerified: user.EmailVerified(),
 	}, nil
 }
 
@@ -353,8 +367,18 @@ func (s *AuthService) RefreshToken(ctx context.Context, req *authpb.RefreshToken
 		return nil, status.Errorf(codes.Unauthenticated, "refresh token expired")
 	}
 
-	// Generate new access token
-	accessToken, err := s.generateAccessToken(token.UserID)
+	// Get user, the access token carries the current email verification status
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		logger.Logger.Error("Failed to get user", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to get user")
+	}
+	if user == nil {
+		return nil, status.Errorf(codes.NotFound, "user not found")
+	}
+
+	// Generate new access token for the same session
+	accessToken, err := s.generateAccessToken(user, token.ID)
 	if err != nil {
 		logger.Logger.Error("Failed to generate access token", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "failed to generate access token")
@@ -367,45 +391,23 @@ func (s *AuthService) RefreshToken(ctx context.Context, req *authpb.RefreshToken
 		return nil, status.Errorf(codes.Internal, "failed to generate refresh token")
 	}
 
-	// Delete old refresh token
-	err = s.userRepo.DeleteRefreshToken(ctx, refreshTokenHash)
+	// Replace the old refresh token, the session keeps its ID and creation time
+	rotated, err := s.userRepo.RotateRefreshToken(ctx, &models.RefreshToken{
+		ID:        token.ID,
+		UserID:    token.UserID,
+		TokenHash: newRefreshTokenHash,
+		UserAgent: userAgent(req.GetClient()),
+		IPAddress: req.GetClient().GetIpAddress(),
+		ExpiresAt: time.Now().Add(s.jwtExpiration * 30), // Refresh token lasts 30 times longer than access token
+	}, refreshTokenHash)
 	if err != nil {
-		logger.Logger.Error("Failed to delete old refresh token", zap.Error(err))
-		// Continue even if deletion fails
+		logger.Logger.Error("Failed to rotate refresh token", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to save refresh token")
 	}
 
-	// Save new refresh token - retry mechanism for potential DB contention
-	var saveErr error
-	for attempts := 0; attempts < 3; attempts++ {
-		saveErr = s.userRepo.SaveRefreshToken(ctx, &models.RefreshToken{
-			UserID:    token.UserID,
-			TokenHash: newRefreshTokenHash,
-			ExpiresAt: time.Now().Add(s.jwtExpiration * 30), // Refresh token lasts 30 times longer than access token
-		})
-		if saveErr == nil {
-			break // Success, exit the retry loop
-		}
-		
-		logger.Logger.Warn("Attempt to save new refresh token failed, retrying...", 
-			zap.Error(saveErr), 
-			zap.Int("attempt", attempts+1))
-		
-		// Generate a new refresh token to avoid unique constraint violation on retry
-		if attempts < 2 { // Only regenerate if we're going to retry
-			newRefreshToken, newRefreshTokenHash, err = s.generateRefreshToken()
-			if err != nil {
-				logger.Logger.Error("Failed to regenerate refresh token during retry", zap.Error(err))
-				return nil, status.Errorf(codes.Internal, "failed to generate refresh token")
-			}
-		}
-		
-		// Short wait before retry to allow potential DB contention to resolve
-		time.Sleep(time.Millisecond * 200 * time.Duration(attempts+1))
-	}
-	
-	if saveErr != nil {
-		logger.Logger.Error("All attempts to save refresh token failed", zap.Error(saveErr))
-		return nil, status.Errorf(codes.Internal, "failed to save refresh token after multiple attempts")
+	// A concurrent refresh with the same token has already replaced it
+	if !rotated {
+		return nil, status.Errorf(codes.NotFound, "refresh token not found")
 	}
 
 	return &authpb.RefreshTokenResponse{
@@ -415,36 +417,318 @@ func (s *AuthService) RefreshToken(ctx context.Context, req *authpb.RefreshToken
 	}, nil
 }
 
-// HealthCheck implements health check
-func (s *AuthService) HealthCheck(ctx context.Context, req *authpb.HealthCheckRequest) (*authpb.HealthCheckResponse, error) {
-	return &authpb.HealthCheckResponse{
-		Status: authpb.HealthCheckResponse_SERVING,
+// Logout revokes the access token of the request and, when given, its refresh token.
+// Expired access tokens are accepted, so a client can always end its session.
+func (s *AuthService) Logout(ctx context.Context, req *authpb.LogoutRequest) (*authpb.LogoutResponse, error) {
+	// Validate input
+	if req.AccessToken == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "access_token is required")
+	}
+
+	// Parse token, its lifetime is not checked
+	claims, err := s.verifier.VerifySignature(ctx, req.AccessToken)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid access token")
+	}
+	userID := claims.UserID
+
+	// Revoke refresh token
+	if req.RefreshToken != "" {
+		refreshTokenHash := hashToken(req.RefreshToken)
+		refreshToken, err := s.userRepo.GetRefreshToken(ctx, refreshTokenHash)
+		if err != nil {
+			logger.Logger.Error("Failed to get refresh token", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "failed to get refresh token")
+		}
+		if refreshToken != nil {
+			if refreshToken.UserID != userID {
+				return nil, status.Errorf(codes.PermissionDenied, "refresh token belongs to another user")
+			}
+			if err := s.userRepo.DeleteRefreshToken(ctx, refreshTokenHash); err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to revoke refresh token")
+			}
+		}
+	}
+
+	// End the session of the token. Tokens issued before sessions were
+	// introduced have no session ID and are revoked one by one until they expire.
+	if claims.SessionID != "" {
+		// The refresh token may already be gone, e.g. it was passed in the request
+		if _, err := s.userRepo.DeleteUserRefreshToken(ctx, userID, claims.SessionID); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to revoke refresh token")
+		}
+		if err := s.denyList.RevokeSession(ctx, claims.SessionID, s.jwtExpiration); err != nil {
+			logger.Logger.Error("Failed to revoke session", zap.Error(err), zap.String("user_id", userID))
+			return nil, status.Errorf(codes.Internal, "failed to revoke access token")
+		}
+	} else {
+		expiresAt := time.Now().Add(s.jwtExpiration)
+		if claims.ExpiresAt != nil {
+			expiresAt = claims.ExpiresAt.Time
+		}
+		if err := s.denyList.Revoke(ctx, claims.ID, expiresAt); err != nil {
+			logger.Logger.Error("Failed to revoke access token", zap.Error(err), zap.String("user_id", userID))
+			return nil, status.Errorf(codes.Internal, "failed to revoke access token")
+		}
+	}
+
+	return &authpb.LogoutResponse{
+		Success: true,
 	}, nil
 }
 
-// Helper functions
+// LogoutAll revokes every refresh token of a user and every access token issued so far
+func (s *AuthService) LogoutAll(ctx context.Context, req *authpb.LogoutAllRequest) (*authpb.LogoutAllResponse, error) {
+	// Validate input
+	if req.UserId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id is required")
+	}
 
-// generateAccessToken generates a JWT access token
-func (s *AuthService) generateAccessToken(userID string) (string, error) {
-	// Create claims
-	now := time.Now()
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"exp":     now.Add(s.jwtExpiration).Unix(),
-		"iat":     now.Unix(),
-		"iss":     "auth-service",
+	revoked, err := s.userRepo.DeleteUserRefreshTokens(ctx, req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke refresh tokens")
 	}
 
-	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// Access tokens live at most jwtExpiration, the deny-list keeps the entry for the verifiers' leeway on top
+	if err := s.denyList.RevokeUser(ctx, req.UserId, time.Now(), s.jwtExpiration); err != nil {
+		logger.Logger.Error("Failed to revoke access tokens", zap.Error(err), zap.String("user_id", req.UserId))
+		return nil, status.Errorf(codes.Internal, "failed to revoke access tokens")
+	}
 
-	// Sign token
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	logger.Logger.Info("Logged out user on all devices",
+		zap.String("user_id", req.UserId),
+		zap.Int64("revoked_sessions", revoked))
+
+	return &authpb.LogoutAllResponse{
+		RevokedSessions: revoked,
+	}, nil
+}
+
+// ListSessions lists the active sessions of a user
+func (s *AuthService) ListSessions(ctx context.Context, req *authpb.ListSessionsRequest) (*authpb.ListSessionsResponse, error) {
+	// Validate input
+	if req.UserId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id is required")
+	}
+
+	tokens, err := s.userRepo.ListUserRefreshTokens(ctx, req.UserId)
 	if err != nil {
-		return "", err
+		return nil, status.Errorf(codes.Internal, "failed to list sessions")
+	}
+
+	sessions := make([]*authpb.Session, 0, len(tokens))
+	for _, token := range tokens {
+		sessions = append(sessions, &authpb.Session{
+			SessionId:  token.ID,
+			UserAgent:  token.UserAgent,
+			IpAddress:  token.IPAddress,
+			CreatedAt:  token.CreatedAt.Format(time.RFC3339),
+			LastUsedAt: token.LastUsedAt.Format(time.RFC3339),
+			ExpiresAt:  token.ExpiresAt.Format(time.RFC3339),
+		})
 	}
 
-	return tokenString, nil
+	return &authpb.ListSessionsResponse{
+		Sessions: sessions,
+	}, nil
+}
+
+// RevokeSession ends a single session of a user: its refresh token is deleted
+// and the access tokens issued for it are revoked
+func (s *AuthService) RevokeSession(ctx context.Context, req *authpb.RevokeSessionRequest) (*authpb.RevokeSessionResponse, error) {
+	// Validate input
+	if req.UserId == "" || req.SessionId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id and session_id are required")
+	}
+	if _, err := uuid.Parse(req.SessionId); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid session_id")
+	}
+
+	deleted, err := s.userRepo.DeleteUserRefreshToken(ctx, req.UserId, req.SessionId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke refresh token")
+	}
+	if !deleted {
+		return nil, status.Errorf(codes.NotFound, "session not found")
+	}
+
+	// Access tokens live at most jwtExpiration, the deny-list keeps the entry for the verifiers' leeway on top
+	if err := s.denyList.RevokeSession(ctx, req.SessionId, s.jwtExpiration); err != nil {
+		logger.Logger.Error("Failed to revoke session", zap.Error(err), zap.String("session_id", req.SessionId))
+		return nil, status.Errorf(codes.Internal, "failed to revoke access tokens")
+	}
+
+	logger.Logger.Info("Revoked session",
+		zap.String("user_id", req.UserId),
+		zap.String("session_id", req.SessionId))
+
+	return &authpb.RevokeSessionResponse{
+		Success: true,
+	}, nil
+}
+
+// SendVerificationEmail sends another email verification link, e.g. when the first one expired
+func (s *AuthService) SendVerificationEmail(ctx context.Context, req *authpb.SendVerificationEmailRequest) (*authpb.SendVerificationEmailResponse, error) {
+	// Validate input
+	if req.UserId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id is required")
+	}
+
+	// Get user
+	user, err := s.userRepo.GetByID(ctx, req.UserId)
+	if err != nil {
+		logger.Logger.Error("Failed to get user", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to get user")
+	}
+	if user == nil {
+		return nil, status.Errorf(codes.NotFound, "user not found")
+	}
+
+	if user.EmailVerified() {
+		return &authpb.SendVerificationEmailResponse{
+			Sent: false,
+		}, nil
+	}
+
+	if err := s.sendUserToken(ctx, user, models.TokenPurposeVerifyEmail); err != nil {
+		return nil, err
+	}
+
+	return &authpb.SendVerificationEmailResponse{
+		Sent: true,
+	}, nil
+}
+
+// VerifyEmail confirms the email of a user with the token from the verification email
+func (s *AuthService) VerifyEmail(ctx context.Context, req *authpb.VerifyEmailRequest) (*authpb.VerifyEmailResponse, error) {
+	// Validate input
+	if req.Token == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+
+	userID, err := s.userRepo.VerifyEmail(ctx, hashToken(req.Token))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to verify email")
+	}
+	if userID == "" {
+		return nil, status.Errorf(codes.NotFound, "token is invalid or expired")
+	}
+
+	logger.Logger.Info("Verified email", zap.String("user_id", userID))
+
+	return &authpb.VerifyEmailResponse{
+		UserId: userID,
+	}, nil
+}
+
+// RequestPasswordReset emails a password reset link. The response is the same
+// whether the email is registered or not, so it cannot be used to look up users.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, req *authpb.RequestPasswordResetRequest) (*authpb.RequestPasswordResetResponse, error) {
+	// Validate input
+	if req.Email == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "email is required")
+	}
+
+	// Get user
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Logger.Error("Failed to get user by email", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to get user")
+	}
+	if user == nil {
+		return &authpb.RequestPasswordResetResponse{}, nil
+	}
+
+	// A limited or undelivered email must not be told apart from an unknown
+	// address, sendUserToken has already logged the failure
+	if err := s.sendUserToken(ctx, user, models.TokenPurposeResetPassword); err != nil {
+		switch status.Code(err) {
+		case codes.ResourceExhausted:
+			logger.Logger.Warn("Too many password reset requests", zap.String("user_id", user.ID))
+			return &authpb.RequestPasswordResetResponse{}, nil
+		case codes.Unavailable:
+			return &authpb.RequestPasswordResetResponse{}, nil
+		}
+		return nil, err
+	}
+
+	return &authpb.RequestPasswordResetResponse{}, nil
+}
+
+// ResetPassword sets a new password with the token from the password reset email.
+// All sessions of the user are ended, whoever knew the old password is logged out.
+func (s *AuthService) ResetPassword(ctx context.Context, req *authpb.ResetPasswordRequest) (*authpb.ResetPasswordResponse, error) {
+	// Validate input
+	if req.Token == "" || req.NewPassword == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token and new_password are required")
+	}
+	if len(req.NewPassword) < minPasswordLength {
+		return nil, status.Errorf(codes.InvalidArgument, "new_password must be at least %d characters", minPasswordLength)
+	}
+
+	// Hash password
+	passwordHash, err := models.HashPassword(req.NewPassword)
+	if err != nil {
+		logger.Logger.Error("Failed to hash password", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to hash password")
+	}
+
+	userID, err := s.userRepo.ResetPassword(ctx, hashToken(req.Token), passwordHash)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to reset password")
+	}
+	if userID == "" {
+		return nil, status.Errorf(codes.NotFound, "token is invalid or expired")
+	}
+
+	// End all sessions, the password is already changed, so failures are only logged
+	revoked, err := s.userRepo.DeleteUserRefreshTokens(ctx, userID)
+	if err != nil {
+		logger.Logger.Error("Failed to revoke refresh tokens after password reset", zap.Error(err), zap.String("user_id", userID))
+	}
+	if err := s.denyList.RevokeUser(ctx, userID, time.Now(), s.jwtExpiration); err != nil {
+		logger.Logger.Error("Failed to revoke access tokens after password reset", zap.Error(err), zap.String("user_id", userID))
+	}
+
+	logger.Logger.Info("Reset password",
+		zap.String("user_id", userID),
+		zap.Int64("revoked_sessions", revoked))
+
+	return &authpb.ResetPasswordResponse{
+		UserId: userID,
+	}, nil
+}
+
+// GetJWKS returns the public keys access tokens are verified with
+func (s *AuthService) GetJWKS(ctx context.Context, req *authpb.GetJWKSRequest) (*authpb.GetJWKSResponse, error) {
+	jwks, err := s.tokens.JWKS()
+	if err != nil {
+		logger.Logger.Error("Failed to encode JWKS", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to encode JWKS")
+	}
+
+	return &authpb.GetJWKSResponse{
+		Jwks: jwks,
+	}, nil
+}
+
+// HealthCheck implements health check
+func (s *AuthService) HealthCheck(ctx context.Context, req *authpb.HealthCheckRequest) (*authpb.HealthCheckResponse, error) {
+	return &authpb.HealthCheckResponse{
+		Status: authpb.HealthCheckResponse_SERVING,
+	}, nil
+}
+
+// Helper functions
+
+// generateAccessToken generates a JWT access token for a session
+func (s *AuthService) generateAccessToken(user *models.User, sessionID string) (string, error) {
+	return s.tokens.Issue(auth.Claims{
+		UserID:        user.ID,
+		Roles:         []string{user.Role},
+		SessionID:     sessionID,
+		EmailVerified: user.EmailVerified(), // Unverified accounts are limited by the gateway
+	})
 }
 
 // generateRefreshToken generates a refresh token
@@ -458,6 +742,83 @@ func (s *AuthService) generateRefreshToken() (string, string, error) {
 	return token, tokenHash, nil
 }
 
+// sendUserToken creates a single-use token for the purpose and emails its link to the user
+func (s *AuthService) sendUserToken(ctx context.Context, user *models.User, purpose string) error {
+	sent, err := s.userRepo.CountUserTokensSince(ctx, user.ID, purpose, time.Now().Add(-time.Hour))
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to count sent emails")
+	}
+	if sent >= maxEmailsPerHour {
+		return status.Errorf(codes.ResourceExhausted, "too many emails sent, try again later")
+	}
+
+	token, tokenHash, err := generateUserToken()
+	if err != nil {
+		logger.Logger.Error("Failed to generate user token", zap.Error(err))
+		return status.Errorf(codes.Internal, "failed to generate token")
+	}
+
+	var ttl time.Duration
+	msg := mailer.Message{To: user.Email}
+	switch purpose {
+	case models.TokenPurposeVerifyEmail:
+		ttl = verifyEmailTokenTTL
+		msg.Subject = "Confirm your email"
+		msg.Body = fmt.Sprintf("Hi %s,\n\nConfirm your email by opening the link below:\n\n%s/verify-email?token=%s\n\nThe link is valid for %s.\n",
+			user.Username, s.appURL, url.QueryEscape(token), hours(ttl))
+	case models.TokenPurposeResetPassword:
+		ttl = resetPasswordTokenTTL
+		msg.Subject = "Reset your password"
+		msg.Body = fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your account. To choose a new password, open the link below:\n\n%s/reset-password?token=%s\n\nThe link is valid for %s. If it was not you, ignore this email.\n",
+			user.Username, s.appURL, url.QueryEscape(token), hours(ttl))
+	default:
+		return status.Errorf(codes.Internal, "unknown token purpose %q", purpose)
+	}
+
+	err = s.userRepo.CreateUserToken(ctx, &models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to save token")
+	}
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		logger.Logger.Error("Failed to send email", zap.Error(err), zap.String("user_id", user.ID), zap.String("purpose", purpose))
+		return status.Errorf(codes.Unavailable, "failed to send email")
+	}
+	return nil
+}
+
+// hours formats a whole number of hours for emails
+func hours(d time.Duration) string {
+	if h := int(d.Hours()); h != 1 {
+		return fmt.Sprintf("%d hours", h)
+	}
+	return "1 hour"
+}
+
+// generateUserToken generates a token sent by email, it is stored only as a hash
+func generateUserToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+// userAgent returns the user agent of a client, cut to fit the sessions list
+func userAgent(client *authpb.ClientInfo) string {
+	ua := client.GetUserAgent()
+	if len(ua) > maxUserAgentLength {
+		ua = ua[:maxUserAgentLength]
+	}
+	return ua
+}
+
 // hashToken hashes a token
 func hashToken(token string) string {
 	hash := sha256.Sum256([]byte(token))
@@ -0,0 +1,396 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sdshorin/generia/pkg/auth"
+	"github.com/sdshorin/generia/pkg/config"
+	"github.com/sdshorin/generia/pkg/logger"
+	"github.com/sdshorin/generia/pkg/mailer"
+	"github.com/sdshorin/generia/services/auth-service/internal/models"
+	"github.com/sdshorin/generia/services/auth-service/internal/repository"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	authpb "github.com/sdshorin/generia/api/grpc/auth"
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
+)
+
+func TestMain(m *testing.M) {
+	logger.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// fakeRepo keeps users and email tokens in memory with the semantics of the SQL repository
+type fakeRepo struct {
+	repository.UserRepository
+
+	mu              sync.Mutex
+	users           map[string]*models.User
+	tokens          []*models.UserToken
+	revokedSessions int
+}
+
+func newFakeRepo(users ...*models.User) *fakeRepo {
+	r := &fakeRepo{users: make(map[string]*models.User)}
+	for _, user := range users {
+		r.users[user.ID] = user
+	}
+	return r
+}
+
+func (r *fakeRepo) GetByID(ctx context.Context, id string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.users[id], nil
+}
+
+func (r *fakeRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRepo) DeleteUserRefreshTokens(ctx context.Context, userID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revokedSessions++
+	return 1, nil
+}
+
+// CreateUserToken replaces the unused tokens of the user and purpose
+func (r *fakeRepo) CreateUserToken(ctx context.Context, token *models.UserToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, t := range r.tokens {
+		if t.UserID == token.UserID && t.Purpose == token.Purpose && t.UsedAt == nil {
+			t.UsedAt = &now
+		}
+	}
+	token.CreatedAt = now
+	stored := *token
+	r.tokens = append(r.tokens, &stored)
+	return nil
+}
+
+func (r *fakeRepo) CountUserTokensSince(ctx context.Context, userID, purpose string, since time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.Purpose == purpose && t.CreatedAt.After(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeRepo) VerifyEmail(ctx context.Context, tokenHash string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	userID := r.useToken(tokenHash, models.TokenPurposeVerifyEmail, now)
+	if userID != "" && r.users[userID].EmailVerifiedAt == nil {
+		r.users[userID].EmailVerifiedAt = &now
+	}
+	return userID, nil
+}
+
+func (r *fakeRepo) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	userID := r.useToken(tokenHash, models.TokenPurposeResetPassword, now)
+	if userID != "" {
+		r.users[userID].PasswordHash = passwordHash
+		if r.users[userID].EmailVerifiedAt == nil {
+			r.users[userID].EmailVerifiedAt = &now
+		}
+	}
+	return userID, nil
+}
+
+// useToken marks an unused, unexpired token as used and returns its user ID
+func (r *fakeRepo) useToken(tokenHash, purpose string, now time.Time) string {
+	for _, t := range r.tokens {
+		if t.TokenHash == tokenHash && t.Purpose == purpose && t.UsedAt == nil && t.ExpiresAt.After(now) {
+			t.UsedAt = &now
+			return t.UserID
+		}
+	}
+	return ""
+}
+
+// shiftTokens moves the creation and expiry of all tokens by d
+func (r *fakeRepo) shiftTokens(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		t.CreatedAt = t.CreatedAt.Add(d)
+		t.ExpiresAt = t.ExpiresAt.Add(d)
+	}
+}
+
+// fakeCache accepts deny-list writes
+type fakeCache struct {
+	cachepb.CacheServiceClient
+}
+
+func (fakeCache) Set(ctx context.Context, req *cachepb.SetRequest, opts ...grpc.CallOption) (*cachepb.SetResponse, error) {
+	return &cachepb.SetResponse{Success: true}, nil
+}
+
+// failingMailer fails every send, like an unreachable SMTP server
+type failingMailer struct{}
+
+func (failingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	return errors.New("connection refused")
+}
+
+func newTestUser() *models.User {
+	return &models.User{ID: "user-1", Username: "alice", Email: "alice@example.com", PasswordHash: "old", Role: auth.RoleUser}
+}
+
+func newTestService(t *testing.T, repo *fakeRepo, m mailer.Mailer) *AuthService {
+	t.Helper()
+	cfg := config.JWTConfig{Expiration: 15 * time.Minute, Issuer: "generia-auth", Audience: "generia", AllowEphemeralKey: true}
+	keyring, err := auth.NewKeyring(cfg)
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	denyList := auth.NewDenyList(fakeCache{}, cfg.Leeway)
+	verifier := auth.NewTokenVerifier(keyring.Keyfunc, denyList, cfg)
+	return NewAuthService(repo, auth.NewTokenIssuer(keyring, cfg), verifier, cfg.Expiration, denyList, m, "https://generia.test").(*AuthService)
+}
+
+var linkToken = regexp.MustCompile(`\?token=(\S+)`)
+
+// lastToken returns the token of the link in the last sent email
+func lastToken(t *testing.T, m *mailer.MemoryMailer) string {
+	t.Helper()
+	messages := m.Messages()
+	if len(messages) == 0 {
+		t.Fatal("no email sent")
+	}
+	match := linkToken.FindStringSubmatch(messages[len(messages)-1].Body)
+	if match == nil {
+		t.Fatalf("no link in email:\n%s", messages[len(messages)-1].Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatalf("invalid token in link: %v", err)
+	}
+	return token
+}
+
+func assertCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Fatalf("error = %v, want code %s", err, want)
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepo(newTestUser())
+	m := mailer.NewMemoryMailer()
+	s := newTestService(t, repo, m)
+
+	if _, err := s.SendVerificationEmail(ctx, &authpb.SendVerificationEmailRequest{UserId: "user-1"}); err != nil {
+		t.Fatalf("SendVerificationEmail: %v", err)
+	}
+	if to := m.Messages()[0].To; to != "alice@example.com" {
+		t.Errorf("email sent to %q", to)
+	}
+	token := lastToken(t, m)
+
+	resp, err := s.VerifyEmail(ctx, &authpb.VerifyEmailRequest{Token: token})
+	if err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+	if resp.UserId != "user-1" || !repo.users["user-1"].EmailVerified() {
+		t.Errorf("VerifyEmail = %+v, email verified %v", resp, repo.users["user-1"].EmailVerified())
+	}
+
+	// The token works once
+	_, err = s.VerifyEmail(ctx, &authpb.VerifyEmailRequest{Token: token})
+	assertCode(t, err, codes.NotFound)
+
+	// A verified user gets no more emails
+	sent, err := s.SendVerificationEmail(ctx, &authpb.SendVerificationEmailRequest{UserId: "user-1"})
+	if err != nil || sent.Sent {
+		t.Errorf("SendVerificationEmail after verification = %+v, %v", sent, err)
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepo(newTestUser())
+	m := mailer.NewMemoryMailer()
+	s := newTestService(t, repo, m)
+
+	if _, err := s.RequestPasswordReset(ctx, &authpb.RequestPasswordResetRequest{Email: "alice@example.com"}); err != nil {
+		t.Fatalf("RequestPasswordReset: %v", err)
+	}
+	token := lastToken(t, m)
+
+	_, err := s.ResetPassword(ctx, &authpb.ResetPasswordRequest{Token: token, NewPassword: "short"})
+	assertCode(t, err, codes.InvalidArgument)
+
+	resp, err := s.ResetPassword(ctx, &authpb.ResetPasswordRequest{Token: token, NewPassword: "new password"})
+	if err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	user := repo.users["user-1"]
+	if resp.UserId != "user-1" || !models.CheckPassword("new password", user.PasswordHash) {
+		t.Errorf("password was not changed")
+	}
+	if !user.EmailVerified() {
+		t.Error("email is not verified after a password reset")
+	}
+	if repo.revokedSessions != 1 {
+		t.Errorf("sessions revoked %d times, want 1", repo.revokedSessions)
+	}
+
+	// The token works once
+	_, err = s.ResetPassword(ctx, &authpb.ResetPasswordRequest{Token: token, NewPassword: "another password"})
+	assertCode(t, err, codes.NotFound)
+}
+
+func TestExpiredTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		send func(ctx context.Context, s *AuthService) error
+		use  func(ctx context.Context, s *AuthService, token string) error
+	}{
+		{
+			name: "verify email",
+			ttl:  verifyEmailTokenTTL,
+			send: func(ctx context.Context, s *AuthService) error {
+				_, err := s.SendVerificationEmail(ctx, &authpb.SendVerificationEmailRequest{UserId: "user-1"})
+				return err
+			},
+			use: func(ctx context.Context, s *AuthService, token string) error {
+				_, err := s.VerifyEmail(ctx, &authpb.VerifyEmailRequest{Token: token})
+				return err
+			},
+		},
+		{
+			name: "reset password",
+			ttl:  resetPasswordTokenTTL,
+			send: func(ctx context.Context, s *AuthService) error {
+				_, err := s.RequestPasswordReset(ctx, &authpb.RequestPasswordResetRequest{Email: "alice@example.com"})
+				return err
+			},
+			use: func(ctx context.Context, s *AuthService, token string) error {
+				_, err := s.ResetPassword(ctx, &authpb.ResetPasswordRequest{Token: token, NewPassword: "new password"})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newFakeRepo(newTestUser())
+			m := mailer.NewMemoryMailer()
+			s := newTestService(t, repo, m)
+
+			if err := tt.send(ctx, s); err != nil {
+				t.Fatalf("send: %v", err)
+			}
+			repo.shiftTokens(-tt.ttl - time.Second)
+			assertCode(t, tt.use(ctx, s, lastToken(t, m)), codes.NotFound)
+		})
+	}
+}
+
+func TestNewerTokenReplacesOlder(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepo(newTestUser())
+	m := mailer.NewMemoryMailer()
+	s := newTestService(t, repo, m)
+
+	var tokens []string
+	for i := 0; i < 2; i++ {
+		if _, err := s.RequestPasswordReset(ctx, &authpb.RequestPasswordResetRequest{Email: "alice@example.com"}); err != nil {
+			t.Fatalf("RequestPasswordReset: %v", err)
+		}
+		tokens = append(tokens, lastToken(t, m))
+	}
+
+	_, err := s.ResetPassword(ctx, &authpb.ResetPasswordRequest{Token: tokens[0], NewPassword: "new password"})
+	assertCode(t, err, codes.NotFound)
+	if _, err := s.ResetPassword(ctx, &authpb.ResetPasswordRequest{Token: tokens[1], NewPassword: "new password"}); err != nil {
+		t.Fatalf("ResetPassword with the newer token: %v", err)
+	}
+}
+
+func TestEmailLimit(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepo(newTestUser())
+	m := mailer.NewMemoryMailer()
+	s := newTestService(t, repo, m)
+
+	for i := 0; i < maxEmailsPerHour; i++ {
+		if _, err := s.SendVerificationEmail(ctx, &authpb.SendVerificationEmailRequest{UserId: "user-1"}); err != nil {
+			t.Fatalf("email %d: %v", i+1, err)
+		}
+	}
+	_, err := s.SendVerificationEmail(ctx, &authpb.SendVerificationEmailRequest{UserId: "user-1"})
+	assertCode(t, err, codes.ResourceExhausted)
+
+	// Password reset emails are counted separately and hit the limit silently
+	for i := 0; i <= maxEmailsPerHour; i++ {
+		if _, err := s.RequestPasswordReset(ctx, &authpb.RequestPasswordResetRequest{Email: "alice@example.com"}); err != nil {
+			t.Fatalf("password reset %d: %v", i+1, err)
+		}
+	}
+	if sent := len(m.Messages()); sent != 2*maxEmailsPerHour {
+		t.Errorf("%d emails sent, want %d", sent, 2*maxEmailsPerHour)
+	}
+
+	// Emails older than an hour are not counted
+	repo.shiftTokens(-time.Hour)
+	if _, err := s.SendVerificationEmail(ctx, &authpb.SendVerificationEmailRequest{UserId: "user-1"}); err != nil {
+		t.Errorf("SendVerificationEmail an hour later: %v", err)
+	}
+}
+
+func TestRequestPasswordResetDoesNotRevealUsers(t *testing.T) {
+	tests := []struct {
+		name   string
+		email  string
+		mailer mailer.Mailer
+	}{
+		{"registered", "alice@example.com", mailer.NewMemoryMailer()},
+		{"unknown", "bob@example.com", mailer.NewMemoryMailer()},
+		{"mailer fails", "alice@example.com", failingMailer{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, newFakeRepo(newTestUser()), tt.mailer)
+			resp, err := s.RequestPasswordReset(context.Background(), &authpb.RequestPasswordResetRequest{Email: tt.email})
+			if err != nil || resp == nil {
+				t.Errorf("RequestPasswordReset = %v, %v, want an empty response", resp, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
+)
+
+// Batch limits
+const (
+	maxBatchKeys = 1000
+	scanCount    = 500 // Keys per SCAN step of DeleteByPattern
+)
+
+// tagKey is the set of keys stored with a tag
+func tagKey(tag string) string { return "cache:tag:" + tag }
+
+// tagScript adds a key to a tag set. The set lives as long as its longest-lived key:
+// a new set takes the key's TTL, an existing one is only extended, keys without TTL make it persistent.
+var tagScript = redis.NewScript(`
+local existed = redis.call('EXISTS', KEYS[1])
+redis.call('SADD', KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl <= 0 then
+	redis.call('PERSIST', KEYS[1])
+elseif existed == 0 or (redis.call('TTL', KEYS[1]) >= 0 and redis.call('TTL', KEYS[1]) < ttl) then
+	redis.call('EXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+// storeItems writes values and their tags in one pipeline
+func (s *CacheService) storeItems(ctx context.Context, items []*cachepb.SetRequest) error {
+	for _, item := range items {
+		if err := validateKey(item.Key); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, item := range items {
+			ttl := time.Duration(max(item.Ttl, 0)) * time.Second
+			pipe.Set(ctx, item.Key, item.Value, ttl)
+			for _, tag := range item.Tags {
+				// Eval, not Run: a pipeline cannot fall back from EVALSHA
+				tagScript.Eval(ctx, pipe, []string{tagKey(tag)}, item.Key, item.Ttl)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		s.stats.RecordWrite(item.Key, len(item.Value))
+	}
+	return nil
+}
+
+// MGet implements the MGet method with a single MGET
+func (s *CacheService) MGet(ctx context.Context, req *cachepb.MGetRequest) (*cachepb.MGetResponse, error) {
+	if err := validateKeys(req.Keys); err != nil {
+		return nil, err
+	}
+
+	values, err := s.redisClient.MGet(ctx, req.Keys...).Result()
+	if err != nil {
+		s.logger.Error("Failed to get keys from Redis", zap.Int("keys", len(req.Keys)), zap.Error(err))
+		return nil, fmt.Errorf("failed to get keys: %w", err)
+	}
+
+	resp := &cachepb.MGetResponse{Results: make([]*cachepb.MGetResult, 0, len(req.Keys))}
+	for i, key := range req.Keys {
+		result := &cachepb.MGetResult{Key: key}
+		if value, ok := values[i].(string); ok {
+			result.Exists = true
+			result.Value = []byte(value)
+			resp.Hits++
+		} else {
+			resp.Misses++
+		}
+		s.stats.RecordRead(key, result.Exists)
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
+// MSet implements the MSet method
+func (s *CacheService) MSet(ctx context.Context, req *cachepb.MSetRequest) (*cachepb.MSetResponse, error) {
+	if len(req.Items) == 0 || len(req.Items) > maxBatchKeys {
+		return nil, status.Errorf(codes.InvalidArgument, "between 1 and %d items are required", maxBatchKeys)
+	}
+
+	if err := s.storeItems(ctx, req.Items); err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		s.logger.Error("Failed to set keys in Redis", zap.Int("keys", len(req.Items)), zap.Error(err))
+		return nil, fmt.Errorf("failed to set keys: %w", err)
+	}
+
+	return &cachepb.MSetResponse{
+		Success: true,
+		Stored:  int32(len(req.Items)),
+	}, nil
+}
+
+// MDelete implements the MDelete method, one DEL per key in a pipeline to report each key
+func (s *CacheService) MDelete(ctx context.Context, req *cachepb.MDeleteRequest) (*cachepb.MDeleteResponse, error) {
+	if err := validateKeys(req.Keys); err != nil {
+		return nil, err
+	}
+
+	cmds := make([]*redis.IntCmd, len(req.Keys))
+	_, err := s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range req.Keys {
+			cmds[i] = pipe.Del(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to delete keys from Redis", zap.Int("keys", len(req.Keys)), zap.Error(err))
+		return nil, fmt.Errorf("failed to delete keys: %w", err)
+	}
+
+	resp := &cachepb.MDeleteResponse{Results: make([]*cachepb.MDeleteResult, 0, len(req.Keys))}
+	for i, key := range req.Keys {
+		deleted := cmds[i].Val() > 0
+		if deleted {
+			resp.Deleted++
+		}
+		resp.Results = append(resp.Results, &cachepb.MDeleteResult{Key: key, Deleted: deleted})
+	}
+	return resp, nil
+}
+
+// DeleteByPattern implements the DeleteByPattern method. Keys are found with
+// SCAN, which does not block Redis like KEYS, and unlinked batch by batch.
+func (s *CacheService) DeleteByPattern(ctx context.Context, req *cachepb.DeleteByPatternRequest) (*cachepb.DeleteByPatternResponse, error) {
+	if req.Pattern == "" || strings.ContainsAny(req.Pattern[:1], "*?[") {
+		return nil, status.Errorf(codes.InvalidArgument, "pattern must start with a literal prefix")
+	}
+
+	var deleted int64
+	iter := s.redisClient.Scan(ctx, 0, req.Pattern, scanCount).Iterator()
+	batch := make([]string, 0, scanCount)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := s.redisClient.Unlink(ctx, batch...).Result()
+		deleted += n
+		batch = batch[:0]
+		return err
+	}
+	var err error
+	for err == nil && iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == scanCount {
+			err = flush()
+		}
+	}
+	if err == nil {
+		err = iter.Err()
+	}
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		s.logger.Error("Failed to delete keys by pattern", zap.String("pattern", req.Pattern), zap.Error(err))
+		return nil, fmt.Errorf("failed to delete keys by pattern: %w", err)
+	}
+
+	s.logger.Info("Deleted keys by pattern", zap.String("pattern", req.Pattern), zap.Int64("count", deleted))
+	return &cachepb.DeleteByPatternResponse{Deleted: deleted}, nil
+}
+
+// DeleteByTag implements the DeleteByTag method
+func (s *CacheService) DeleteByTag(ctx context.Context, req *cachepb.DeleteByTagRequest) (*cachepb.DeleteByTagResponse, error) {
+	if len(req.Tags) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "tags are required")
+	}
+
+	// Read all tag sets in one round trip, then drop their keys and the sets in another
+	members := make([]*redis.StringSliceCmd, len(req.Tags))
+	_, err := s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, tag := range req.Tags {
+			members[i] = pipe.SMembers(ctx, tagKey(tag))
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to read tags from Redis", zap.Strings("tags", req.Tags), zap.Error(err))
+		return nil, fmt.Errorf("failed to delete keys by tag: %w", err)
+	}
+
+	var unlinks []*redis.IntCmd
+	_, err = s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, tag := range req.Tags {
+			keys := members[i].Val()
+			for start := 0; start < len(keys); start += maxBatchKeys {
+				unlinks = append(unlinks, pipe.Unlink(ctx, keys[start:min(start+maxBatchKeys, len(keys))]...))
+			}
+			pipe.Unlink(ctx, tagKey(tag))
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to delete keys by tag", zap.Strings("tags", req.Tags), zap.Error(err))
+		return nil, fmt.Errorf("failed to delete keys by tag: %w", err)
+	}
+
+	var deleted int64
+	for _, cmd := range unlinks {
+		deleted += cmd.Val()
+	}
+	s.logger.Info("Deleted keys by tag", zap.Strings("tags", req.Tags), zap.Int64("count", deleted))
+	return &cachepb.DeleteByTagResponse{Deleted: deleted}, nil
+}
+
+// validateKeys checks the size of a batch and its keys
+func validateKeys(keys []string) error {
+	if len(keys) == 0 || len(keys) > maxBatchKeys {
+		return status.Errorf(codes.InvalidArgument, "between 1 and %d keys are required", maxBatchKeys)
+	}
+	for _, key := range keys {
+		if err := validateKey(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
+)
+
+func TestMGet(t *testing.T) {
+	s, mr := newTestService(t)
+	mr.Set("a", "1")
+	mr.Set("c", "3")
+
+	resp, err := s.MGet(context.Background(), &cachepb.MGetRequest{Keys: []string{"a", "b", "c", "a"}})
+	if err != nil {
+		t.Fatalf("MGet: %v", err)
+	}
+	if resp.Hits != 3 || resp.Misses != 1 {
+		t.Errorf("hits = %d, misses = %d, want 3 and 1", resp.Hits, resp.Misses)
+	}
+
+	want := []struct {
+		key    string
+		exists bool
+		value  string
+	}{{"a", true, "1"}, {"b", false, ""}, {"c", true, "3"}, {"a", true, "1"}}
+	if len(resp.Results) != len(want) {
+		t.Fatalf("%d results, want %d", len(resp.Results), len(want))
+	}
+	for i, w := range want {
+		got := resp.Results[i]
+		if got.Key != w.key || got.Exists != w.exists || string(got.Value) != w.value {
+			t.Errorf("result %d = %+v, want %+v", i, got, w)
+		}
+	}
+}
+
+func TestMSetAndMDelete(t *testing.T) {
+	s, mr := newTestService(t)
+	ctx := context.Background()
+
+	set, err := s.MSet(ctx, &cachepb.MSetRequest{Items: []*cachepb.SetRequest{
+		{Key: "a", Value: []byte("1"), Ttl: 60},
+		{Key: "b", Value: []byte("2")},
+	}})
+	if err != nil {
+		t.Fatalf("MSet: %v", err)
+	}
+	if !set.Success || set.Stored != 2 {
+		t.Fatalf("MSet = %+v, want 2 stored", set)
+	}
+	if got, _ := mr.Get("a"); got != "1" {
+		t.Errorf("a = %q, want 1", got)
+	}
+	if ttl := mr.TTL("a"); ttl != time.Minute {
+		t.Errorf("TTL of a = %v, want 1m", ttl)
+	}
+	if ttl := mr.TTL("b"); ttl != 0 {
+		t.Errorf("TTL of b = %v, want none", ttl)
+	}
+
+	del, err := s.MDelete(ctx, &cachepb.MDeleteRequest{Keys: []string{"a", "missing", "b"}})
+	if err != nil {
+		t.Fatalf("MDelete: %v", err)
+	}
+	if del.Deleted != 2 {
+		t.Errorf("deleted = %d, want 2", del.Deleted)
+	}
+	for i, want := range []bool{true, false, true} {
+		if del.Results[i].Deleted != want {
+			t.Errorf("result %d = %+v, want deleted %v", i, del.Results[i], want)
+		}
+	}
+	if mr.Exists("a") || mr.Exists("b") {
+		t.Error("keys remain after MDelete")
+	}
+}
+
+func TestBatchValidation(t *testing.T) {
+	s, _ := newTestService(t)
+	ctx := context.Background()
+
+	tooMany := make([]string, maxBatchKeys+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("k%d", i)
+	}
+	tooManyItems := make([]*cachepb.SetRequest, maxBatchKeys+1)
+	for i := range tooManyItems {
+		tooManyItems[i] = &cachepb.SetRequest{Key: tooMany[i]}
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"MGet without keys", func() error {
+			_, err := s.MGet(ctx, &cachepb.MGetRequest{})
+			return err
+		}},
+		{"MGet over the limit", func() error {
+			_, err := s.MGet(ctx, &cachepb.MGetRequest{Keys: tooMany})
+			return err
+		}},
+		{"MGet with an empty key", func() error {
+			_, err := s.MGet(ctx, &cachepb.MGetRequest{Keys: []string{"a", ""}})
+			return err
+		}},
+		{"MSet without items", func() error {
+			_, err := s.MSet(ctx, &cachepb.MSetRequest{})
+			return err
+		}},
+		{"MSet over the limit", func() error {
+			_, err := s.MSet(ctx, &cachepb.MSetRequest{Items: tooManyItems})
+			return err
+		}},
+		{"MSet with an empty key", func() error {
+			_, err := s.MSet(ctx, &cachepb.MSetRequest{Items: []*cachepb.SetRequest{{Key: ""}}})
+			return err
+		}},
+		{"MDelete over the limit", func() error {
+			_, err := s.MDelete(ctx, &cachepb.MDeleteRequest{Keys: tooMany})
+			return err
+		}},
+		{"DeleteByTag without tags", func() error {
+			_, err := s.DeleteByTag(ctx, &cachepb.DeleteByTagRequest{})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertCode(t, tt.call(), codes.InvalidArgument)
+		})
+	}
+
+	// Exactly the limit is accepted
+	if _, err := s.MGet(ctx, &cachepb.MGetRequest{Keys: tooMany[:maxBatchKeys]}); err != nil {
+		t.Errorf("MGet of %d keys: %v", maxBatchKeys, err)
+	}
+}
+
+func TestDeleteByPattern(t *testing.T) {
+	for _, pattern := range []string{"", "*", "?ost:*", "[pq]ost:*"} {
+		t.Run("rejects "+pattern, func(t *testing.T) {
+			s, _ := newTestService(t)
+			_, err := s.DeleteByPattern(context.Background(), &cachepb.DeleteByPatternRequest{Pattern: pattern})
+			assertCode(t, err, codes.InvalidArgument)
+		})
+	}
+
+	s, mr := newTestService(t)
+	const matching = 2*scanCount + 7 // Spans several SCAN steps and UNLINK batches
+	for i := 0; i < matching; i++ {
+		mr.Set(fmt.Sprintf("post:%d", i), "v")
+	}
+	mr.Set("postal:1", "kept")
+	mr.Set("character:1", "kept")
+
+	resp, err := s.DeleteByPattern(context.Background(), &cachepb.DeleteByPatternRequest{Pattern: "post:*"})
+	if err != nil {
+		t.Fatalf("DeleteByPattern: %v", err)
+	}
+	if resp.Deleted != matching {
+		t.Errorf("deleted = %d, want %d", resp.Deleted, matching)
+	}
+	for _, key := range mr.Keys() {
+		if strings.HasPrefix(key, "post:") {
+			t.Fatalf("%s remains after DeleteByPattern", key)
+		}
+	}
+	if !mr.Exists("postal:1") || !mr.Exists("character:1") {
+		t.Error("keys outside the pattern were deleted")
+	}
+}
+
+func TestTagSetTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttls    []int32 // TTLs of the keys stored with the tag, in order
+		wantTTL time.Duration
+	}{
+		{"takes the TTL of the first key", []int32{60}, time.Minute},
+		{"extended by a longer-lived key", []int32{60, 120}, 2 * time.Minute},
+		{"never shortened", []int32{120, 60}, 2 * time.Minute},
+		{"persistent with a key without TTL", []int32{60, 0}, 0},
+		{"stays persistent", []int32{0, 60}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mr := newTestService(t)
+			for i, ttl := range tt.ttls {
+				_, err := s.MSet(context.Background(), &cachepb.MSetRequest{Items: []*cachepb.SetRequest{
+					{Key: fmt.Sprintf("k%d", i), Value: []byte("v"), Ttl: ttl, Tags: []string{"world"}},
+				}})
+				if err != nil {
+					t.Fatalf("MSet: %v", err)
+				}
+			}
+
+			if ttl := mr.TTL(tagKey("world")); ttl != tt.wantTTL {
+				t.Errorf("tag set TTL = %v, want %v", ttl, tt.wantTTL)
+			}
+			members, err := mr.Members(tagKey("world"))
+			if err != nil || len(members) != len(tt.ttls) {
+				t.Errorf("tag set members = %q, %v", members, err)
+			}
+		})
+	}
+}
+
+func TestDeleteByTag(t *testing.T) {
+	s, mr := newTestService(t)
+	ctx := context.Background()
+
+	_, err := s.MSet(ctx, &cachepb.MSetRequest{Items: []*cachepb.SetRequest{
+		{Key: "post:1", Value: []byte("v"), Tags: []string{"world:1"}},
+		{Key: "post:2", Value: []byte("v"), Tags: []string{"world:1", "character:1"}},
+		{Key: "post:3", Value: []byte("v"), Tags: []string{"character:1"}},
+		{Key: "post:4", Value: []byte("v"), Tags: []string{"world:2"}},
+	}})
+	if err != nil {
+		t.Fatalf("MSet: %v", err)
+	}
+	mr.Del("post:1") // Expired keys stay in the tag set
+
+	resp, err := s.DeleteByTag(ctx, &cachepb.DeleteByTagRequest{Tags: []string{"world:1", "character:1"}})
+	if err != nil {
+		t.Fatalf("DeleteByTag: %v", err)
+	}
+	if resp.Deleted != 2 {
+		t.Errorf("deleted = %d, want 2", resp.Deleted)
+	}
+	for _, key := range []string{"post:2", "post:3", tagKey("world:1"), tagKey("character:1")} {
+		if mr.Exists(key) {
+			t.Errorf("%s remains after DeleteByTag", key)
+		}
+	}
+	if !mr.Exists("post:4") || !mr.Exists(tagKey("world:2")) {
+		t.Error("keys of another tag were deleted")
+	}
+}
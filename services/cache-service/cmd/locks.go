@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
+)
+
+// maxLockTTL bounds lock lifetime, a crashed owner blocks others at most this long
+const maxLockTTL = 24 * time.Hour
+
+// lockKeys returns the lock hash and its fencing counter. The hash tag keeps
+// both in one slot, so the scripts stay valid on Redis Cluster.
+func lockKeys(name string) []string {
+	return []string{"lock:{" + name + "}", "lock:{" + name + "}:fence"}
+}
+
+// tryLockScript takes a free lock with the next fencing token. The counter
+// never expires, so tokens keep growing across expired and released locks.
+// The owner retaking its own lock keeps the token and gets a fresh TTL.
+var tryLockScript = redis.NewScript(`
+local owner = redis.call('HGET', KEYS[1], 'owner')
+if owner == false then
+	local token = redis.call('INCR', KEYS[2])
+	redis.call('HSET', KEYS[1], 'owner', ARGV[1], 'token', token)
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return {1, token, ARGV[1], tonumber(ARGV[2])}
+end
+local token = tonumber(redis.call('HGET', KEYS[1], 'token'))
+if owner == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return {1, token, owner, tonumber(ARGV[2])}
+end
+return {0, token, owner, redis.call('PTTL', KEYS[1])}
+`)
+
+// unlockScript deletes the lock if it is still held with the given owner and token
+var unlockScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'owner') == ARGV[1] and redis.call('HGET', KEYS[1], 'token') == ARGV[2] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// extendScript sets a new TTL if the lock is still held with the given owner and token
+var extendScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'owner') == ARGV[1] and redis.call('HGET', KEYS[1], 'token') == ARGV[2] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+	return 1
+end
+return 0
+`)
+
+// TryLock implements the TryLock method. It never waits: callers that did not
+// get the lock retry or give up. The fencing token is passed along with writes
+// made under the lock, so storage can reject writes of an owner whose lock expired.
+func (s *CacheService) TryLock(ctx context.Context, req *cachepb.TryLockRequest) (*cachepb.TryLockResponse, error) {
+	if err := validateLock(req.Name, req.Owner); err != nil {
+		return nil, err
+	}
+	if err := validateLockTTL(req.TtlMs); err != nil {
+		return nil, err
+	}
+
+	result, err := tryLockScript.Run(ctx, s.redisClient, lockKeys(req.Name), req.Owner, req.TtlMs).Slice()
+	if err != nil {
+		s.logger.Error("Failed to acquire lock in Redis", zap.String("name", req.Name), zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+
+	acquired, _ := result[0].(int64)
+	token, _ := result[1].(int64)
+	owner, _ := result[2].(string)
+	ttl, _ := result[3].(int64)
+	return &cachepb.TryLockResponse{
+		Acquired: acquired == 1,
+		Token:    token,
+		Owner:    owner,
+		TtlMs:    ttl,
+	}, nil
+}
+
+// Unlock implements the Unlock method
+func (s *CacheService) Unlock(ctx context.Context, req *cachepb.UnlockRequest) (*cachepb.UnlockResponse, error) {
+	if err := validateLock(req.Name, req.Owner); err != nil {
+		return nil, err
+	}
+	if req.Token <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+
+	released, err := unlockScript.Run(ctx, s.redisClient, lockKeys(req.Name)[:1], req.Owner, req.Token).Int64()
+	if err != nil {
+		s.logger.Error("Failed to release lock in Redis", zap.String("name", req.Name), zap.Error(err))
+		return nil, fmt.Errorf("failed to release lock: %w", err)
+	}
+
+	return &cachepb.UnlockResponse{Released: released == 1}, nil
+}
+
+// Extend implements the Extend method, owners of long operations call it before the lock expires
+func (s *CacheService) Extend(ctx context.Context, req *cachepb.ExtendRequest) (*cachepb.ExtendResponse, error) {
+	if err := validateLock(req.Name, req.Owner); err != nil {
+		return nil, err
+	}
+	if req.Token <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+	if err := validateLockTTL(req.TtlMs); err != nil {
+		return nil, err
+	}
+
+	extended, err := extendScript.Run(ctx, s.redisClient, lockKeys(req.Name)[:1], req.Owner, req.Token, req.TtlMs).Int64()
+	if err != nil {
+		s.logger.Error("Failed to extend lock in Redis", zap.String("name", req.Name), zap.Error(err))
+		return nil, fmt.Errorf("failed to extend lock: %w", err)
+	}
+
+	resp := &cachepb.ExtendResponse{Extended: extended == 1}
+	if resp.Extended {
+		resp.TtlMs = req.TtlMs
+	}
+	return resp, nil
+}
+
+func validateLock(name, owner string) error {
+	if name == "" {
+		return status.Errorf(codes.InvalidArgument, "name is required")
+	}
+	if owner == "" {
+		return status.Errorf(codes.InvalidArgument, "owner is required")
+	}
+	return nil
+}
+
+func validateLockTTL(ttlMs int64) error {
+	if ttlMs <= 0 || ttlMs > maxLockTTL.Milliseconds() {
+		return status.Errorf(codes.InvalidArgument, "ttl_ms must be between 1 and %d", maxLockTTL.Milliseconds())
+	}
+	return nil
+}
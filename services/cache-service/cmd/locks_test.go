@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
+)
+
+func TestLocks(t *testing.T) {
+	s, mr := newTestService(t)
+	ctx := context.Background()
+
+	tryLock := func(owner string, ttlMs int64) *cachepb.TryLockResponse {
+		t.Helper()
+		resp, err := s.TryLock(ctx, &cachepb.TryLockRequest{Name: "job", Owner: owner, TtlMs: ttlMs})
+		if err != nil {
+			t.Fatalf("TryLock: %v", err)
+		}
+		return resp
+	}
+
+	first := tryLock("a", 10000)
+	if !first.Acquired || first.Token != 1 || first.Owner != "a" || first.TtlMs != 10000 {
+		t.Fatalf("first TryLock = %+v, want acquired with token 1", first)
+	}
+
+	// Another owner sees the holder and the remaining TTL
+	busy := tryLock("b", 10000)
+	if busy.Acquired || busy.Token != 1 || busy.Owner != "a" || busy.TtlMs <= 0 {
+		t.Fatalf("TryLock of a held lock = %+v, want held by a", busy)
+	}
+
+	// The owner retaking its lock keeps the token and gets a fresh TTL
+	again := tryLock("a", 20000)
+	if !again.Acquired || again.Token != 1 {
+		t.Fatalf("TryLock by the owner = %+v, want acquired with token 1", again)
+	}
+	if ttl := mr.TTL("lock:{job}"); ttl != 20*time.Second {
+		t.Fatalf("lock TTL = %v, want %v", ttl, 20*time.Second)
+	}
+
+	extended, err := s.Extend(ctx, &cachepb.ExtendRequest{Name: "job", Owner: "a", Token: 1, TtlMs: 30000})
+	if err != nil {
+		t.Fatalf("Extend: %v", err)
+	}
+	if !extended.Extended || extended.TtlMs != 30000 {
+		t.Fatalf("Extend = %+v, want extended", extended)
+	}
+	stale, err := s.Extend(ctx, &cachepb.ExtendRequest{Name: "job", Owner: "a", Token: 2, TtlMs: 30000})
+	if err != nil {
+		t.Fatalf("Extend: %v", err)
+	}
+	if stale.Extended {
+		t.Fatal("Extend with a wrong token succeeded")
+	}
+
+	// Only the holder with its token can release the lock
+	for _, req := range []*cachepb.UnlockRequest{
+		{Name: "job", Owner: "b", Token: 1},
+		{Name: "job", Owner: "a", Token: 2},
+	} {
+		resp, err := s.Unlock(ctx, req)
+		if err != nil {
+			t.Fatalf("Unlock: %v", err)
+		}
+		if resp.Released {
+			t.Fatalf("Unlock by owner %q with token %d released the lock", req.Owner, req.Token)
+		}
+	}
+	released, err := s.Unlock(ctx, &cachepb.UnlockRequest{Name: "job", Owner: "a", Token: 1})
+	if err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if !released.Released {
+		t.Fatal("Unlock by the holder did not release the lock")
+	}
+
+	// Fencing tokens keep growing across released and expired locks
+	next := tryLock("b", 1000)
+	if !next.Acquired || next.Token != 2 {
+		t.Fatalf("TryLock after release = %+v, want acquired with token 2", next)
+	}
+	mr.FastForward(2 * time.Second)
+	last := tryLock("c", 1000)
+	if !last.Acquired || last.Token != 3 {
+		t.Fatalf("TryLock after expiry = %+v, want acquired with token 3", last)
+	}
+}
+
+func TestLockValidation(t *testing.T) {
+	s, _ := newTestService(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"TryLock without name", func() error {
+			_, err := s.TryLock(ctx, &cachepb.TryLockRequest{Owner: "a", TtlMs: 1000})
+			return err
+		}},
+		{"TryLock without owner", func() error {
+			_, err := s.TryLock(ctx, &cachepb.TryLockRequest{Name: "job", TtlMs: 1000})
+			return err
+		}},
+		{"TryLock without TTL", func() error {
+			_, err := s.TryLock(ctx, &cachepb.TryLockRequest{Name: "job", Owner: "a"})
+			return err
+		}},
+		{"TryLock with too long TTL", func() error {
+			_, err := s.TryLock(ctx, &cachepb.TryLockRequest{Name: "job", Owner: "a", TtlMs: maxLockTTL.Milliseconds() + 1})
+			return err
+		}},
+		{"Unlock without token", func() error {
+			_, err := s.Unlock(ctx, &cachepb.UnlockRequest{Name: "job", Owner: "a"})
+			return err
+		}},
+		{"Extend without token", func() error {
+			_, err := s.Extend(ctx, &cachepb.ExtendRequest{Name: "job", Owner: "a", TtlMs: 1000})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertCode(t, tt.call(), codes.InvalidArgument)
+		})
+	}
+}
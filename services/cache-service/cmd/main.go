@@ -7,7 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	// "strconv"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -23,9 +23,11 @@ import (
 	"github.com/sdshorin/generia/pkg/telemetry"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 
 	cachepb "github.com/sdshorin/generia/api/grpc/cache"
 )
@@ -35,6 +37,7 @@ type CacheService struct {
 	cachepb.UnimplementedCacheServiceServer
 	logger      *zap.Logger
 	redisClient *redis.Client
+	stats       *Stats
 }
 
 // Get implements the Get method
@@ -42,6 +45,7 @@ func (s *CacheService) Get(ctx context.Context, req *cachepb.GetRequest) (*cache
 	// Basic implementation
 	val, err := s.redisClient.Get(ctx, req.Key).Result()
 	if err == redis.Nil {
+		s.stats.RecordRead(req.Key, false)
 		return &cachepb.GetResponse{
 			Exists: false,
 			Value:  []byte(""),
@@ -50,6 +54,7 @@ func (s *CacheService) Get(ctx context.Context, req *cachepb.GetRequest) (*cache
 		s.logger.Error("Failed to get key from Redis", zap.String("key", req.Key), zap.Error(err))
 		return nil, fmt.Errorf("failed to get key: %w", err)
 	}
+	s.stats.RecordRead(req.Key, true)
 
 	return &cachepb.GetResponse{
 		Exists: true,
@@ -59,15 +64,10 @@ func (s *CacheService) Get(ctx context.Context, req *cachepb.GetRequest) (*cache
 
 // Set implements the Set method
 func (s *CacheService) Set(ctx context.Context, req *cachepb.SetRequest) (*cachepb.SetResponse, error) {
-	// Basic implementation
-	var err error
-	if req.Ttl > 0 {
-		err = s.redisClient.Set(ctx, req.Key, req.Value, time.Duration(req.Ttl)*time.Second).Err()
-	} else {
-		err = s.redisClient.Set(ctx, req.Key, req.Value, 0).Err()
-	}
-
-	if err != nil {
+	if err := s.storeItems(ctx, []*cachepb.SetRequest{req}); err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
 		s.logger.Error("Failed to set key in Redis", zap.String("key", req.Key), zap.Error(err))
 		return nil, fmt.Errorf("failed to set key: %w", err)
 	}
@@ -91,6 +91,248 @@ func (s *CacheService) Delete(ctx context.Context, req *cachepb.DeleteRequest) (
 	}, nil
 }
 
+// List limits
+const (
+	defaultMaxListLength = 1000
+	defaultListLimit     = 20
+	maxListLimit         = 1000
+)
+
+// incrementScript adds to a counter and sets the TTL when the counter has none,
+// i.e. when it was just created, so hot counters are not kept alive forever
+var incrementScript = redis.NewScript(`
+local value = redis.call('INCRBY', KEYS[1], ARGV[1])
+if tonumber(ARGV[2]) > 0 and redis.call('TTL', KEYS[1]) == -1 then
+	redis.call('EXPIRE', KEYS[1], ARGV[2])
+end
+return value
+`)
+
+// Increment implements the Increment method
+func (s *CacheService) Increment(ctx context.Context, req *cachepb.IncrementRequest) (*cachepb.IncrementResponse, error) {
+	value, err := s.incrementBy(ctx, req.Key, counterDelta(req.Value), req.Ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &cachepb.IncrementResponse{NewValue: value}, nil
+}
+
+// Decrement implements the Decrement method
+func (s *CacheService) Decrement(ctx context.Context, req *cachepb.DecrementRequest) (*cachepb.DecrementResponse, error) {
+	value, err := s.incrementBy(ctx, req.Key, -counterDelta(req.Value), req.Ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &cachepb.DecrementResponse{NewValue: value}, nil
+}
+
+func (s *CacheService) incrementBy(ctx context.Context, key string, delta int64, ttl int32) (int64, error) {
+	if err := validateKey(key); err != nil {
+		return 0, err
+	}
+
+	value, err := incrementScript.Run(ctx, s.redisClient, []string{key}, delta, ttl).Int64()
+	if err != nil {
+		s.logger.Error("Failed to increment counter in Redis", zap.String("key", key), zap.Error(err))
+		return 0, fmt.Errorf("failed to increment counter: %w", err)
+	}
+	s.stats.Touch(key)
+	return value, nil
+}
+
+// counterDelta defaults a missing increment to 1
+func counterDelta(value int32) int64 {
+	if value == 0 {
+		return 1
+	}
+	return int64(value)
+}
+
+// AddToList implements the AddToList method. Lists keep the newest values
+// first, sorted sets the highest scores; both are trimmed to max_length.
+func (s *CacheService) AddToList(ctx context.Context, req *cachepb.AddToListRequest) (*cachepb.AddToListResponse, error) {
+	if err := validateKey(req.Key); err != nil {
+		return nil, err
+	}
+	maxLength := int64(req.MaxLength)
+	if maxLength <= 0 {
+		maxLength = defaultMaxListLength
+	}
+
+	var size *redis.IntCmd
+	_, err := s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if req.Sorted {
+			pipe.ZAdd(ctx, req.Key, &redis.Z{Score: req.Score, Member: req.Value})
+			pipe.ZRemRangeByRank(ctx, req.Key, 0, -maxLength-1)
+			size = pipe.ZCard(ctx, req.Key)
+		} else {
+			pipe.LPush(ctx, req.Key, req.Value)
+			pipe.LTrim(ctx, req.Key, 0, maxLength-1)
+			size = pipe.LLen(ctx, req.Key)
+		}
+		if req.Ttl > 0 {
+			pipe.Expire(ctx, req.Key, time.Duration(req.Ttl)*time.Second)
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to add to list in Redis", zap.String("key", req.Key), zap.Error(err))
+		return nil, fmt.Errorf("failed to add to list: %w", err)
+	}
+	s.stats.RecordWrite(req.Key, len(req.Value))
+
+	return &cachepb.AddToListResponse{
+		Success:  true,
+		ListSize: size.Val(),
+	}, nil
+}
+
+// GetList implements the GetList method for both lists and sorted sets
+func (s *CacheService) GetList(ctx context.Context, req *cachepb.GetListRequest) (*cachepb.GetListResponse, error) {
+	if err := validateKey(req.Key); err != nil {
+		return nil, err
+	}
+	if req.Offset < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "offset must not be negative")
+	}
+	limit := int64(req.Limit)
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	limit = min(limit, maxListLimit)
+	start, stop := int64(req.Offset), int64(req.Offset)+limit-1
+
+	keyType, err := s.redisClient.Type(ctx, req.Key).Result()
+	if err != nil {
+		s.logger.Error("Failed to get key type from Redis", zap.String("key", req.Key), zap.Error(err))
+		return nil, fmt.Errorf("failed to get list: %w", err)
+	}
+
+	var values *redis.StringSliceCmd
+	var total *redis.IntCmd
+	switch keyType {
+	case "none":
+		return &cachepb.GetListResponse{}, nil
+	case "list":
+		_, err = s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			values = pipe.LRange(ctx, req.Key, start, stop)
+			total = pipe.LLen(ctx, req.Key)
+			return nil
+		})
+	case "zset":
+		_, err = s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			values = pipe.ZRevRange(ctx, req.Key, start, stop)
+			total = pipe.ZCard(ctx, req.Key)
+			return nil
+		})
+	default:
+		return nil, status.Errorf(codes.FailedPrecondition, "key holds a %s, not a list", keyType)
+	}
+	if err != nil {
+		s.logger.Error("Failed to get list from Redis", zap.String("key", req.Key), zap.Error(err))
+		return nil, fmt.Errorf("failed to get list: %w", err)
+	}
+
+	result := make([][]byte, 0, len(values.Val()))
+	for _, v := range values.Val() {
+		result = append(result, []byte(v))
+	}
+	return &cachepb.GetListResponse{
+		Values: result,
+		Total:  total.Val(),
+	}, nil
+}
+
+// HSet implements the HSet method
+func (s *CacheService) HSet(ctx context.Context, req *cachepb.HSetRequest) (*cachepb.HSetResponse, error) {
+	if err := validateKey(req.Key); err != nil {
+		return nil, err
+	}
+	if len(req.Fields) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "fields are required")
+	}
+
+	values := make(map[string]interface{}, len(req.Fields))
+	size := 0
+	for field, value := range req.Fields {
+		values[field] = value
+		size += len(field) + len(value)
+	}
+
+	_, err := s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, req.Key, values)
+		if req.Ttl > 0 {
+			pipe.Expire(ctx, req.Key, time.Duration(req.Ttl)*time.Second)
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to set hash in Redis", zap.String("key", req.Key), zap.Error(err))
+		return nil, fmt.Errorf("failed to set hash: %w", err)
+	}
+	s.stats.RecordWrite(req.Key, size)
+
+	return &cachepb.HSetResponse{
+		Success: true,
+	}, nil
+}
+
+// HGet implements the HGet method, all fields are returned when none are requested
+func (s *CacheService) HGet(ctx context.Context, req *cachepb.HGetRequest) (*cachepb.HGetResponse, error) {
+	if err := validateKey(req.Key); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string][]byte)
+	if len(req.Fields) == 0 {
+		values, err := s.redisClient.HGetAll(ctx, req.Key).Result()
+		if err != nil {
+			s.logger.Error("Failed to get hash from Redis", zap.String("key", req.Key), zap.Error(err))
+			return nil, fmt.Errorf("failed to get hash: %w", err)
+		}
+		for field, value := range values {
+			fields[field] = []byte(value)
+		}
+	} else {
+		values, err := s.redisClient.HMGet(ctx, req.Key, req.Fields...).Result()
+		if err != nil {
+			s.logger.Error("Failed to get hash from Redis", zap.String("key", req.Key), zap.Error(err))
+			return nil, fmt.Errorf("failed to get hash: %w", err)
+		}
+		for i, value := range values {
+			if str, ok := value.(string); ok {
+				fields[req.Fields[i]] = []byte(str)
+			}
+		}
+	}
+
+	s.stats.RecordRead(req.Key, len(fields) > 0)
+	return &cachepb.HGetResponse{
+		Exists: len(fields) > 0,
+		Fields: fields,
+	}, nil
+}
+
+// HealthCheck implements the HealthCheck method
+func (s *CacheService) HealthCheck(ctx context.Context, req *cachepb.HealthCheckRequest) (*cachepb.HealthCheckResponse, error) {
+	if err := s.redisClient.Ping(ctx).Err(); err != nil {
+		return &cachepb.HealthCheckResponse{
+			Status: cachepb.HealthCheckResponse_NOT_SERVING,
+		}, nil
+	}
+	return &cachepb.HealthCheckResponse{
+		Status: cachepb.HealthCheckResponse_SERVING,
+	}, nil
+}
+
+// validateKey rejects empty keys
+func validateKey(key string) error {
+	if key == "" {
+		return status.Errorf(codes.InvalidArgument, "key is required")
+	}
+	return nil
+}
+
 func main() {
 	// Initialize logger
 	if err := logger.InitProduction(); err != nil {
@@ -148,10 +390,19 @@ func main() {
 	}
 	defer discoveryClient.Deregister(serviceID)
 
+	// Initialize key statistics
+	statsConfig, err := statsConfigFromEnv()
+	if err != nil {
+		logger.Logger.Fatal("Invalid stats configuration", zap.Error(err))
+	}
+	stats := NewStats(redisClient, statsConfig, logger.Logger)
+	stats.Start()
+
 	// Initialize cache service
 	cacheService := &CacheService{
 		logger:      logger.Logger,
 		redisClient: redisClient,
+		stats:       stats,
 	}
 
 	// Create gRPC server with middleware
@@ -207,6 +458,41 @@ func main() {
 
 	logger.Logger.Info("Shutting down cache service...")
 	grpcServer.GracefulStop()
+	stats.Close()
 	logger.Logger.Info("Cache service stopped")
 }
 
+func statsConfigFromEnv() (StatsConfig, error) {
+	var config StatsConfig
+
+	if v := os.Getenv("CACHE_HOT_KEY_SAMPLE_RATE"); v != "" {
+		rate, err := strconv.Atoi(v)
+		if err != nil || rate <= 0 {
+			return config, fmt.Errorf("invalid CACHE_HOT_KEY_SAMPLE_RATE: %s", v)
+		}
+		config.SampleRate = rate
+	}
+	if v := os.Getenv("CACHE_HOT_KEY_CAPACITY"); v != "" {
+		capacity, err := strconv.Atoi(v)
+		if err != nil || capacity <= 0 {
+			return config, fmt.Errorf("invalid CACHE_HOT_KEY_CAPACITY: %s", v)
+		}
+		config.HotKeyCapacity = capacity
+	}
+	if v := os.Getenv("CACHE_HOT_KEY_DECAY_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil {
+			return config, fmt.Errorf("invalid CACHE_HOT_KEY_DECAY_INTERVAL: %w", err)
+		}
+		config.DecayInterval = interval
+	}
+	if v := os.Getenv("CACHE_TRACK_KEY_EVENTS"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return config, fmt.Errorf("invalid CACHE_TRACK_KEY_EVENTS: %s", v)
+		}
+		config.KeyEvents = enabled
+	}
+	return config, nil
+}
+
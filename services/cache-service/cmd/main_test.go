@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
+)
+
+// newTestService returns a CacheService backed by an in-memory Redis
+func newTestService(t *testing.T) (*CacheService, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return &CacheService{
+		logger:      zap.NewNop(),
+		redisClient: client,
+		stats:       NewStats(client, StatsConfig{}, zap.NewNop()),
+	}, mr
+}
+
+// assertCode fails the test unless err is a gRPC status with the given code
+func assertCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != code {
+		t.Fatalf("error = %v, want code %v", err, code)
+	}
+}
+
+func TestCounters(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial string        // Value stored before the call, empty for none
+		ttl     time.Duration // TTL of the initial value
+		incr    bool
+		value   int32
+		reqTTL  int32
+		want    int64
+		wantTTL time.Duration
+	}{
+		{name: "increment defaults to one", incr: true, want: 1},
+		{name: "increment by value", incr: true, value: 5, want: 5},
+		{name: "decrement below zero", value: 3, want: -3},
+		{name: "increment existing", initial: "10", incr: true, value: 2, want: 12},
+		{name: "decrement existing", initial: "10", value: 4, want: 6},
+		{name: "new counter gets the TTL", incr: true, reqTTL: 60, want: 1, wantTTL: time.Minute},
+		{name: "counter without TTL gets one", initial: "1", incr: true, reqTTL: 60, want: 2, wantTTL: time.Minute},
+		{name: "existing TTL is kept", initial: "1", ttl: 30 * time.Second, incr: true, reqTTL: 60, want: 2, wantTTL: 30 * time.Second},
+		{name: "zero TTL never expires", incr: true, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mr := newTestService(t)
+			ctx := context.Background()
+			if tt.initial != "" {
+				mr.Set("counter", tt.initial)
+				if tt.ttl > 0 {
+					mr.SetTTL("counter", tt.ttl)
+				}
+			}
+
+			var got int64
+			if tt.incr {
+				resp, err := s.Increment(ctx, &cachepb.IncrementRequest{Key: "counter", Value: tt.value, Ttl: tt.reqTTL})
+				if err != nil {
+					t.Fatalf("Increment: %v", err)
+				}
+				got = resp.NewValue
+			} else {
+				resp, err := s.Decrement(ctx, &cachepb.DecrementRequest{Key: "counter", Value: tt.value, Ttl: tt.reqTTL})
+				if err != nil {
+					t.Fatalf("Decrement: %v", err)
+				}
+				got = resp.NewValue
+			}
+
+			if got != tt.want {
+				t.Errorf("value = %d, want %d", got, tt.want)
+			}
+			if ttl := mr.TTL("counter"); ttl != tt.wantTTL {
+				t.Errorf("TTL = %v, want %v", ttl, tt.wantTTL)
+			}
+		})
+	}
+}
+
+func TestCountersRejectEmptyKey(t *testing.T) {
+	s, _ := newTestService(t)
+	_, err := s.Increment(context.Background(), &cachepb.IncrementRequest{})
+	assertCode(t, err, codes.InvalidArgument)
+	_, err = s.Decrement(context.Background(), &cachepb.DecrementRequest{})
+	assertCode(t, err, codes.InvalidArgument)
+}
+
+func TestLists(t *testing.T) {
+	tests := []struct {
+		name      string
+		sorted    bool
+		values    []string
+		scores    []float64
+		maxLength int32
+		offset    int32
+		limit     int32
+		want      []string
+		wantSize  int64
+	}{
+		{
+			name:     "newest first",
+			values:   []string{"a", "b", "c"},
+			want:     []string{"c", "b", "a"},
+			wantSize: 3,
+		},
+		{
+			name:      "trimmed to max length",
+			values:    []string{"a", "b", "c", "d"},
+			maxLength: 2,
+			want:      []string{"d", "c"},
+			wantSize:  2,
+		},
+		{
+			name:     "offset and limit",
+			values:   []string{"a", "b", "c", "d", "e"},
+			offset:   1,
+			limit:    2,
+			want:     []string{"d", "c"},
+			wantSize: 5,
+		},
+		{
+			name:     "offset past the end",
+			values:   []string{"a", "b"},
+			offset:   5,
+			want:     []string{},
+			wantSize: 2,
+		},
+		{
+			name:     "sorted by score",
+			sorted:   true,
+			values:   []string{"low", "high", "mid"},
+			scores:   []float64{1, 3, 2},
+			want:     []string{"high", "mid", "low"},
+			wantSize: 3,
+		},
+		{
+			name:      "sorted keeps the highest scores",
+			sorted:    true,
+			values:    []string{"low", "high", "mid"},
+			scores:    []float64{1, 3, 2},
+			maxLength: 2,
+			want:      []string{"high", "mid"},
+			wantSize:  2,
+		},
+		{
+			name:     "sorted re-adding a member updates its score",
+			sorted:   true,
+			values:   []string{"a", "b", "a"},
+			scores:   []float64{1, 2, 3},
+			want:     []string{"a", "b"},
+			wantSize: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t)
+			ctx := context.Background()
+
+			var size int64
+			for i, value := range tt.values {
+				req := &cachepb.AddToListRequest{Key: "list", Value: []byte(value), Sorted: tt.sorted, MaxLength: tt.maxLength}
+				if tt.sorted {
+					req.Score = tt.scores[i]
+				}
+				resp, err := s.AddToList(ctx, req)
+				if err != nil {
+					t.Fatalf("AddToList: %v", err)
+				}
+				size = resp.ListSize
+			}
+			if size != tt.wantSize {
+				t.Errorf("list size = %d, want %d", size, tt.wantSize)
+			}
+
+			resp, err := s.GetList(ctx, &cachepb.GetListRequest{Key: "list", Offset: tt.offset, Limit: tt.limit})
+			if err != nil {
+				t.Fatalf("GetList: %v", err)
+			}
+			if resp.Total != tt.wantSize {
+				t.Errorf("total = %d, want %d", resp.Total, tt.wantSize)
+			}
+			got := make([]string, 0, len(resp.Values))
+			for _, v := range resp.Values {
+				got = append(got, string(v))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("values = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListTTL(t *testing.T) {
+	s, mr := newTestService(t)
+	_, err := s.AddToList(context.Background(), &cachepb.AddToListRequest{Key: "list", Value: []byte("a"), Ttl: 60})
+	if err != nil {
+		t.Fatalf("AddToList: %v", err)
+	}
+	if ttl := mr.TTL("list"); ttl != time.Minute {
+		t.Errorf("TTL = %v, want %v", ttl, time.Minute)
+	}
+}
+
+func TestGetListErrors(t *testing.T) {
+	s, mr := newTestService(t)
+	ctx := context.Background()
+	mr.Set("string", "value")
+
+	resp, err := s.GetList(ctx, &cachepb.GetListRequest{Key: "missing"})
+	if err != nil {
+		t.Fatalf("GetList of a missing key: %v", err)
+	}
+	if len(resp.Values) != 0 || resp.Total != 0 {
+		t.Errorf("missing key returned %d values, total %d", len(resp.Values), resp.Total)
+	}
+
+	_, err = s.GetList(ctx, &cachepb.GetListRequest{Key: "string"})
+	assertCode(t, err, codes.FailedPrecondition)
+	_, err = s.GetList(ctx, &cachepb.GetListRequest{Key: "list", Offset: -1})
+	assertCode(t, err, codes.InvalidArgument)
+	_, err = s.GetList(ctx, &cachepb.GetListRequest{})
+	assertCode(t, err, codes.InvalidArgument)
+	_, err = s.AddToList(ctx, &cachepb.AddToListRequest{Value: []byte("a")})
+	assertCode(t, err, codes.InvalidArgument)
+}
+
+func TestHashes(t *testing.T) {
+	tests := []struct {
+		name       string
+		set        map[string][]byte
+		fields     []string
+		want       map[string]string
+		wantExists bool
+	}{
+		{
+			name:       "all fields",
+			set:        map[string][]byte{"name": []byte("Ann"), "age": []byte("30")},
+			want:       map[string]string{"name": "Ann", "age": "30"},
+			wantExists: true,
+		},
+		{
+			name:       "requested fields",
+			set:        map[string][]byte{"name": []byte("Ann"), "age": []byte("30")},
+			fields:     []string{"name"},
+			want:       map[string]string{"name": "Ann"},
+			wantExists: true,
+		},
+		{
+			name:       "missing fields are left out",
+			set:        map[string][]byte{"name": []byte("Ann")},
+			fields:     []string{"name", "age"},
+			want:       map[string]string{"name": "Ann"},
+			wantExists: true,
+		},
+		{
+			name:   "only missing fields",
+			set:    map[string][]byte{"name": []byte("Ann")},
+			fields: []string{"age"},
+			want:   map[string]string{},
+		},
+		{
+			name: "missing key",
+			want: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t)
+			ctx := context.Background()
+
+			if tt.set != nil {
+				if _, err := s.HSet(ctx, &cachepb.HSetRequest{Key: "hash", Fields: tt.set}); err != nil {
+					t.Fatalf("HSet: %v", err)
+				}
+			}
+
+			resp, err := s.HGet(ctx, &cachepb.HGetRequest{Key: "hash", Fields: tt.fields})
+			if err != nil {
+				t.Fatalf("HGet: %v", err)
+			}
+			if resp.Exists != tt.wantExists {
+				t.Errorf("exists = %v, want %v", resp.Exists, tt.wantExists)
+			}
+			got := make(map[string]string, len(resp.Fields))
+			for field, value := range resp.Fields {
+				got[field] = string(value)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("fields = %v, want %v", got, tt.want)
+			}
+			for field, value := range tt.want {
+				if got[field] != value {
+					t.Errorf("field %q = %q, want %q", field, got[field], value)
+				}
+			}
+		})
+	}
+}
+
+func TestHSetUpdatesFieldsAndTTL(t *testing.T) {
+	s, mr := newTestService(t)
+	ctx := context.Background()
+
+	if _, err := s.HSet(ctx, &cachepb.HSetRequest{Key: "hash", Fields: map[string][]byte{"a": []byte("1"), "b": []byte("2")}}); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+	if _, err := s.HSet(ctx, &cachepb.HSetRequest{Key: "hash", Fields: map[string][]byte{"b": []byte("3")}, Ttl: 60}); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+
+	if got := mr.HGet("hash", "a"); got != "1" {
+		t.Errorf("field a = %q, want %q", got, "1")
+	}
+	if got := mr.HGet("hash", "b"); got != "3" {
+		t.Errorf("field b = %q, want %q", got, "3")
+	}
+	if ttl := mr.TTL("hash"); ttl != time.Minute {
+		t.Errorf("TTL = %v, want %v", ttl, time.Minute)
+	}
+
+	_, err := s.HSet(ctx, &cachepb.HSetRequest{Key: "hash"})
+	assertCode(t, err, codes.InvalidArgument)
+	_, err = s.HGet(ctx, &cachepb.HGetRequest{})
+	assertCode(t, err, codes.InvalidArgument)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
+)
+
+// maxRateLimit bounds limits, a sliding window keeps one entry per counted request
+const maxRateLimit = 10000
+
+// Both scripts read the clock with TIME, so instances with skewed clocks share one limit.
+// They return {allowed, remaining, retry after ms, reset after ms}.
+
+// tokenBucketScript refills limit tokens per window continuously, a request takes cost tokens
+var tokenBucketScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local rate = limit / window
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or limit
+local ts = tonumber(state[2]) or now
+tokens = math.min(limit, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= cost then
+	tokens = tokens - cost
+	allowed = 1
+else
+	retry = math.ceil((cost - tokens) / rate)
+end
+
+local reset = math.ceil((limit - tokens) / rate)
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], reset + 1000)
+return {allowed, math.floor(tokens), retry, reset}
+`)
+
+// slidingWindowScript keeps a sorted set of the requests of the last window, scored by time
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+
+if count + cost <= limit then
+	for i = 1, cost do
+		redis.call('ZADD', KEYS[1], now, ARGV[4] .. ':' .. i)
+	end
+	redis.call('PEXPIRE', KEYS[1], window)
+	return {1, limit - count - cost, 0, window}
+end
+
+-- The request fits once enough of the oldest requests leave the window
+local oldest = redis.call('ZRANGE', KEYS[1], count + cost - limit - 1, count + cost - limit - 1, 'WITHSCORES')
+local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+local retry = tonumber(oldest[2]) + window - now
+local reset = tonumber(newest[2]) + window - now
+return {0, limit - count, retry, reset}
+`)
+
+// RateLimit implements the RateLimit method. The check and the count are one
+// script, so concurrent requests from any number of instances never exceed the limit.
+func (s *CacheService) RateLimit(ctx context.Context, req *cachepb.RateLimitRequest) (*cachepb.RateLimitResponse, error) {
+	if err := validateKey(req.Key); err != nil {
+		return nil, err
+	}
+	if req.Limit <= 0 || req.Limit > maxRateLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxRateLimit)
+	}
+	if req.WindowMs <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "window_ms must be positive")
+	}
+	cost := req.Cost
+	if cost == 0 {
+		cost = 1
+	}
+	if cost < 0 || cost > req.Limit {
+		return nil, status.Errorf(codes.InvalidArgument, "cost must be between 1 and limit")
+	}
+
+	var cmd *redis.Cmd
+	switch req.Algorithm {
+	case cachepb.RateLimitAlgorithm_TOKEN_BUCKET:
+		cmd = tokenBucketScript.Run(ctx, s.redisClient, []string{"ratelimit:tb:" + req.Key}, req.Limit, req.WindowMs, cost)
+	case cachepb.RateLimitAlgorithm_SLIDING_WINDOW:
+		// Members must be unique, requests of the same millisecond would overwrite each other
+		cmd = slidingWindowScript.Run(ctx, s.redisClient, []string{"ratelimit:sw:" + req.Key}, req.Limit, req.WindowMs, cost, uuid.NewString())
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown algorithm %v", req.Algorithm)
+	}
+
+	result, err := cmd.Slice()
+	if err != nil {
+		s.logger.Error("Failed to check rate limit in Redis", zap.String("key", req.Key), zap.Error(err))
+		return nil, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+
+	allowed, _ := result[0].(int64)
+	remaining, _ := result[1].(int64)
+	retryAfter, _ := result[2].(int64)
+	resetAfter, _ := result[3].(int64)
+	return &cachepb.RateLimitResponse{
+		Allowed:      allowed == 1,
+		Remaining:    int32(remaining),
+		RetryAfterMs: retryAfter,
+		ResetAfterMs: resetAfter,
+	}, nil
+}
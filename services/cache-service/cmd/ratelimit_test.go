@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
+)
+
+func TestRateLimit(t *testing.T) {
+	algorithms := []cachepb.RateLimitAlgorithm{
+		cachepb.RateLimitAlgorithm_TOKEN_BUCKET,
+		cachepb.RateLimitAlgorithm_SLIDING_WINDOW,
+	}
+
+	for _, algorithm := range algorithms {
+		t.Run(algorithm.String(), func(t *testing.T) {
+			s, mr := newTestService(t)
+			ctx := context.Background()
+			now := time.Now()
+			mr.SetTime(now)
+
+			check := func(cost int32) *cachepb.RateLimitResponse {
+				t.Helper()
+				resp, err := s.RateLimit(ctx, &cachepb.RateLimitRequest{
+					Key:       "user",
+					Limit:     3,
+					WindowMs:  1000,
+					Cost:      cost,
+					Algorithm: algorithm,
+				})
+				if err != nil {
+					t.Fatalf("RateLimit: %v", err)
+				}
+				return resp
+			}
+
+			for i := int32(0); i < 3; i++ {
+				resp := check(0)
+				if !resp.Allowed || resp.Remaining != 2-i {
+					t.Fatalf("request %d = %+v, want allowed with %d remaining", i, resp, 2-i)
+				}
+			}
+
+			denied := check(0)
+			if denied.Allowed || denied.Remaining != 0 {
+				t.Fatalf("request over the limit = %+v, want denied", denied)
+			}
+			if denied.RetryAfterMs <= 0 || denied.RetryAfterMs > 1000 {
+				t.Fatalf("retry after = %dms, want within the window", denied.RetryAfterMs)
+			}
+
+			// Once the window passes the full limit is available again
+			mr.SetTime(now.Add(time.Second + time.Millisecond))
+			mr.FastForward(time.Second + time.Millisecond)
+			if resp := check(3); !resp.Allowed || resp.Remaining != 0 {
+				t.Fatalf("request after the window = %+v, want allowed", resp)
+			}
+		})
+	}
+}
+
+func TestRateLimitValidation(t *testing.T) {
+	s, _ := newTestService(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		req  *cachepb.RateLimitRequest
+	}{
+		{"no key", &cachepb.RateLimitRequest{Limit: 1, WindowMs: 1000}},
+		{"no limit", &cachepb.RateLimitRequest{Key: "k", WindowMs: 1000}},
+		{"limit too high", &cachepb.RateLimitRequest{Key: "k", Limit: maxRateLimit + 1, WindowMs: 1000}},
+		{"no window", &cachepb.RateLimitRequest{Key: "k", Limit: 1}},
+		{"cost above limit", &cachepb.RateLimitRequest{Key: "k", Limit: 1, WindowMs: 1000, Cost: 2}},
+		{"negative cost", &cachepb.RateLimitRequest{Key: "k", Limit: 1, WindowMs: 1000, Cost: -1}},
+		{"unknown algorithm", &cachepb.RateLimitRequest{Key: "k", Limit: 1, WindowMs: 1000, Algorithm: 99}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.RateLimit(ctx, tt.req)
+			assertCode(t, err, codes.InvalidArgument)
+		})
+	}
+}
@@ -0,0 +1,526 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
+)
+
+// StatsConfig holds configuration of key statistics
+type StatsConfig struct {
+	SampleRate     int           // One of SampleRate accesses is passed to the hot-key tracker, defaults to 100
+	HotKeyCapacity int           // Keys the hot-key tracker keeps, defaults to 1000
+	DecayInterval  time.Duration // Tracked counts are halved this often so keys that cooled down drop out, defaults to 1m
+	KeyEvents      bool          // Count evictions and expirations from keyevent notifications, which must be enabled on Redis
+}
+
+// Namespaces are the part of a key before the first ":". Clients choose keys,
+// so the number of namespaces is capped to keep metric cardinality bounded.
+const (
+	maxNamespaces  = 100
+	noNamespace    = "none"
+	otherNamespace = "other"
+
+	defaultTopKeys = 20
+)
+
+// Redis publishes a keyevent to every subscriber, so evictions and expirations
+// are counted only by the instance holding this lease. It is a lock renewed
+// every third of its TTL, another instance takes over within a TTL after a crash.
+const (
+	keyEventsLease    = "cache-service:key-events"
+	keyEventsLeaseTTL = 30 * time.Second
+)
+
+// valueSizeBuckets are upper bounds of value sizes in bytes, shared by the
+// Prometheus histogram and GetStats
+var valueSizeBuckets = []float64{64, 256, 1024, 4096, 16384, 65536, 262144, 1048576}
+
+var (
+	keyRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_key_requests_total",
+		Help: "Number of key reads by namespace and result (hit, miss)",
+	}, []string{"namespace", "result"})
+
+	keyWritesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_key_writes_total",
+		Help: "Number of key writes by namespace",
+	}, []string{"namespace"})
+
+	keyRemovalsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_key_removals_total",
+		Help: "Number of keys removed by Redis by namespace and reason (evicted, expired)",
+	}, []string{"namespace", "reason"})
+
+	valueSizeBytes = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cache_value_size_bytes",
+		Help:    "Size of written values by namespace",
+		Buckets: valueSizeBuckets,
+	}, []string{"namespace"})
+)
+
+// namespaceStats are the counters of one namespace since the instance started
+type namespaceStats struct {
+	hits, misses, writes   int64
+	evictions, expirations int64
+	valueBytes, maxValue   int64
+	sizes                  []int64 // Per valueSizeBuckets, the last one counts larger values
+}
+
+// Stats collects per-namespace counters and samples hot keys. The counters
+// are kept both as Prometheus metrics and in memory for GetStats; every
+// instance reports its own traffic, Prometheus sums them up. Removals are
+// reported by the instance holding the key events lease only.
+type Stats struct {
+	config StatsConfig
+	since  time.Time
+	redis  *redis.Client
+	logger *zap.Logger
+	owner  string // Owner of the key events lease
+
+	mu         sync.Mutex
+	namespaces map[string]*namespaceStats
+
+	hotKeys          *hotKeyTracker
+	evictionsTracked atomic.Bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewStats creates a new statistics collector
+func NewStats(redisClient *redis.Client, config StatsConfig, logger *zap.Logger) *Stats {
+	if config.SampleRate <= 0 {
+		config.SampleRate = 100
+	}
+	if config.HotKeyCapacity <= 0 {
+		config.HotKeyCapacity = 1000
+	}
+	if config.DecayInterval <= 0 {
+		config.DecayInterval = time.Minute
+	}
+
+	return &Stats{
+		config:     config,
+		since:      time.Now(),
+		redis:      redisClient,
+		logger:     logger,
+		namespaces: make(map[string]*namespaceStats),
+		owner:      uuid.NewString(),
+		hotKeys:    newHotKeyTracker(config.HotKeyCapacity),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start decays hot keys and, with KeyEvents, competes for the key events lease
+// and counts removals while holding it, in the background until Close is called
+func (st *Stats) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	st.cancel = cancel
+
+	go func() {
+		defer close(st.done)
+		st.run(ctx)
+	}()
+}
+
+// Close stops the background work
+func (st *Stats) Close() {
+	st.once.Do(func() {
+		if st.cancel != nil {
+			st.cancel()
+			<-st.done
+		}
+	})
+}
+
+func (st *Stats) run(ctx context.Context) {
+	ticker := time.NewTicker(st.config.DecayInterval)
+	defer ticker.Stop()
+
+	var renew <-chan time.Time
+	if st.config.KeyEvents {
+		leaseTicker := time.NewTicker(keyEventsLeaseTTL / 3)
+		defer leaseTicker.Stop()
+		renew = leaseTicker.C
+	}
+
+	var pubsub *redis.PubSub
+	var removals <-chan *redis.Message
+	var token int64 // Token of the lease while it is held
+	var lastErr string
+	unsubscribe := func() {
+		if pubsub != nil {
+			pubsub.Close()
+			pubsub, removals = nil, nil
+		}
+		st.evictionsTracked.Store(false)
+	}
+	// update takes or renews the lease and keeps the subscription while it is held
+	update := func() {
+		held, err := st.holdLease(ctx)
+		if err == nil {
+			token = held
+		}
+		if err == nil && held == 0 {
+			unsubscribe()
+			return
+		}
+		if err == nil && pubsub == nil {
+			pubsub, err = st.subscribeRemovals(ctx)
+		}
+		if err != nil {
+			unsubscribe()
+			if err.Error() != lastErr && ctx.Err() == nil {
+				st.logger.Warn("Evictions and expirations are not tracked", zap.Error(err))
+			}
+			lastErr = err.Error()
+			return
+		}
+		lastErr = ""
+		removals = pubsub.Channel()
+		st.evictionsTracked.Store(true)
+	}
+	if st.config.KeyEvents {
+		update()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			unsubscribe()
+			if token != 0 {
+				st.releaseLease(token)
+			}
+			return
+		case <-ticker.C:
+			st.hotKeys.decay()
+		case <-renew:
+			update()
+		case msg, ok := <-removals:
+			if !ok {
+				// Resubscribed on the next renewal
+				unsubscribe()
+				continue
+			}
+			reason := "expired"
+			if strings.HasSuffix(msg.Channel, ":evicted") {
+				reason = "evicted"
+			}
+			st.recordRemoval(msg.Payload, reason)
+		}
+	}
+}
+
+// holdLease takes or renews the key events lease and returns its token, or 0
+// if another instance holds it
+func (st *Stats) holdLease(ctx context.Context) (int64, error) {
+	result, err := tryLockScript.Run(ctx, st.redis, lockKeys(keyEventsLease), st.owner, keyEventsLeaseTTL.Milliseconds()).Slice()
+	if err != nil {
+		return 0, fmt.Errorf("failed to take the key events lease: %w", err)
+	}
+	if acquired, _ := result[0].(int64); acquired != 1 {
+		return 0, nil
+	}
+	token, _ := result[1].(int64)
+	return token, nil
+}
+
+// releaseLease lets another instance take over the key events lease without waiting for its TTL
+func (st *Stats) releaseLease(token int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := unlockScript.Run(ctx, st.redis, lockKeys(keyEventsLease)[:1], st.owner, token).Err(); err != nil {
+		st.logger.Warn("Failed to release the key events lease", zap.Error(err))
+	}
+}
+
+// subscribeRemovals subscribes to evicted and expired keyevents for the
+// database of the client. Notifications are a Redis setting shared by all its
+// clients, so they are never enabled here; if CONFIG is available, flags that
+// leave the events off are reported as an error.
+func (st *Stats) subscribeRemovals(ctx context.Context) (*redis.PubSub, error) {
+	if current, err := st.redis.ConfigGet(ctx, "notify-keyspace-events").Result(); err == nil && len(current) == 2 {
+		flags, _ := current[1].(string)
+		if !strings.Contains(flags, "E") || !strings.Contains(flags, "A") && (!strings.Contains(flags, "x") || !strings.Contains(flags, "e")) {
+			return nil, fmt.Errorf("notify-keyspace-events is %q, keyevents for evicted and expired keys need Exe", flags)
+		}
+	}
+
+	db := st.redis.Options().DB
+	pubsub := st.redis.Subscribe(ctx,
+		fmt.Sprintf("__keyevent@%d__:evicted", db),
+		fmt.Sprintf("__keyevent@%d__:expired", db))
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to key events: %w", err)
+	}
+	return pubsub, nil
+}
+
+// RecordRead counts a read of a key
+func (st *Stats) RecordRead(key string, hit bool) {
+	result := "miss"
+
+	st.mu.Lock()
+	ns, stats := st.namespaceLocked(key)
+	if hit {
+		stats.hits++
+		result = "hit"
+	} else {
+		stats.misses++
+	}
+	st.mu.Unlock()
+
+	keyRequestsTotal.WithLabelValues(ns, result).Inc()
+	st.Touch(key)
+}
+
+// RecordWrite counts a write of a value of the given size
+func (st *Stats) RecordWrite(key string, size int) {
+	bucket := sort.SearchFloat64s(valueSizeBuckets, float64(size))
+
+	st.mu.Lock()
+	ns, stats := st.namespaceLocked(key)
+	stats.writes++
+	stats.valueBytes += int64(size)
+	stats.maxValue = max(stats.maxValue, int64(size))
+	stats.sizes[bucket]++
+	st.mu.Unlock()
+
+	keyWritesTotal.WithLabelValues(ns).Inc()
+	valueSizeBytes.WithLabelValues(ns).Observe(float64(size))
+	st.Touch(key)
+}
+
+// Touch passes a sampled access to the hot-key tracker
+func (st *Stats) Touch(key string) {
+	if rand.IntN(st.config.SampleRate) == 0 {
+		st.hotKeys.add(key)
+	}
+}
+
+func (st *Stats) recordRemoval(key, reason string) {
+	st.mu.Lock()
+	ns, stats := st.namespaceLocked(key)
+	if reason == "evicted" {
+		stats.evictions++
+	} else {
+		stats.expirations++
+	}
+	st.mu.Unlock()
+
+	keyRemovalsTotal.WithLabelValues(ns, reason).Inc()
+}
+
+// namespaceLocked returns the namespace label of a key and its counters, the caller holds the lock
+func (st *Stats) namespaceLocked(key string) (string, *namespaceStats) {
+	ns := namespaceOf(key)
+	stats, ok := st.namespaces[ns]
+	if !ok {
+		if len(st.namespaces) >= maxNamespaces {
+			ns = otherNamespace
+			stats, ok = st.namespaces[ns]
+		}
+		if !ok {
+			stats = &namespaceStats{sizes: make([]int64, len(valueSizeBuckets)+1)}
+			st.namespaces[ns] = stats
+		}
+	}
+	return ns, stats
+}
+
+// namespaceOf returns the part of a key before the first ":"
+func namespaceOf(key string) string {
+	ns, _, ok := strings.Cut(key, ":")
+	if !ok || ns == "" {
+		return noNamespace
+	}
+	return ns
+}
+
+// GetStats implements the GetStats method
+func (s *CacheService) GetStats(ctx context.Context, req *cachepb.GetStatsRequest) (*cachepb.GetStatsResponse, error) {
+	topKeys := int(req.TopKeys)
+	if topKeys <= 0 {
+		topKeys = defaultTopKeys
+	}
+	resp := s.stats.snapshot(req.Namespace, topKeys)
+
+	var dbSize *redis.IntCmd
+	var memory, stats *redis.StringCmd
+	_, err := s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		dbSize = pipe.DBSize(ctx)
+		memory = pipe.Info(ctx, "memory")
+		stats = pipe.Info(ctx, "stats")
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to get stats from Redis", zap.Error(err))
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+	resp.RedisKeys = dbSize.Val()
+	resp.RedisUsedMemoryBytes = infoValue(memory.Val(), "used_memory")
+	resp.RedisEvictedKeys = infoValue(stats.Val(), "evicted_keys")
+
+	return resp, nil
+}
+
+// snapshot copies the counters of one or all namespaces and the hottest keys
+func (st *Stats) snapshot(namespace string, topKeys int) *cachepb.GetStatsResponse {
+	resp := &cachepb.GetStatsResponse{
+		SampleRate:       int32(st.config.SampleRate),
+		Since:            st.since.Format(time.RFC3339),
+		EvictionsTracked: st.evictionsTracked.Load(),
+	}
+
+	st.mu.Lock()
+	for ns, stats := range st.namespaces {
+		if namespace != "" && ns != namespace {
+			continue
+		}
+		item := &cachepb.NamespaceStats{
+			Namespace:       ns,
+			Hits:            stats.hits,
+			Misses:          stats.misses,
+			Writes:          stats.writes,
+			Evictions:       stats.evictions,
+			Expirations:     stats.expirations,
+			ValueBytesTotal: stats.valueBytes,
+			MaxValueBytes:   stats.maxValue,
+		}
+		if reads := stats.hits + stats.misses; reads > 0 {
+			item.HitRatio = float64(stats.hits) / float64(reads)
+		}
+		for i, count := range stats.sizes {
+			bucket := &cachepb.ValueSizeBucket{Count: count}
+			if i < len(valueSizeBuckets) {
+				bucket.LeBytes = int64(valueSizeBuckets[i])
+			}
+			item.ValueSizes = append(item.ValueSizes, bucket)
+		}
+		resp.Namespaces = append(resp.Namespaces, item)
+	}
+	st.mu.Unlock()
+	sort.Slice(resp.Namespaces, func(i, j int) bool {
+		return resp.Namespaces[i].Namespace < resp.Namespaces[j].Namespace
+	})
+
+	rate := int64(st.config.SampleRate)
+	for _, hot := range st.hotKeys.top(namespace, topKeys) {
+		resp.HotKeys = append(resp.HotKeys, &cachepb.HotKey{
+			Key:               hot.key,
+			Namespace:         namespaceOf(hot.key),
+			EstimatedAccesses: hot.count * rate,
+			MaxError:          hot.err * rate,
+		})
+	}
+	return resp
+}
+
+// infoValue returns an integer field of an INFO section
+func infoValue(info, field string) int64 {
+	for _, line := range strings.Split(info, "\r\n") {
+		if value, ok := strings.CutPrefix(line, field+":"); ok {
+			n, _ := strconv.ParseInt(value, 10, 64)
+			return n
+		}
+	}
+	return 0
+}
+
+// hotKeyCount is a tracked key with its sampled count and the overestimation bound
+type hotKeyCount struct {
+	key   string
+	count int64
+	err   int64
+}
+
+// hotKeyTracker finds the most accessed keys in bounded memory with the
+// Space-Saving algorithm: when it is full, a new key replaces the least
+// counted one and inherits its count as the error bound.
+type hotKeyTracker struct {
+	mu       sync.Mutex
+	capacity int
+	keys     map[string]*hotKeyCount
+}
+
+func newHotKeyTracker(capacity int) *hotKeyTracker {
+	return &hotKeyTracker{
+		capacity: capacity,
+		keys:     make(map[string]*hotKeyCount, capacity),
+	}
+}
+
+func (t *hotKeyTracker) add(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if tracked, ok := t.keys[key]; ok {
+		tracked.count++
+		return
+	}
+	if len(t.keys) < t.capacity {
+		t.keys[key] = &hotKeyCount{key: key, count: 1}
+		return
+	}
+
+	// Accesses are sampled, so this scan is rare enough to not need a heap
+	var least *hotKeyCount
+	for _, tracked := range t.keys {
+		if least == nil || tracked.count < least.count {
+			least = tracked
+		}
+	}
+	delete(t.keys, least.key)
+	t.keys[key] = &hotKeyCount{key: key, count: least.count + 1, err: least.count}
+}
+
+// decay halves all counts and forgets keys that reached zero
+func (t *hotKeyTracker) decay() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, tracked := range t.keys {
+		tracked.count /= 2
+		tracked.err /= 2
+		if tracked.count == 0 {
+			delete(t.keys, key)
+		}
+	}
+}
+
+// top returns copies of the n most counted keys of a namespace, or of all keys for an empty namespace
+func (t *hotKeyTracker) top(namespace string, n int) []hotKeyCount {
+	t.mu.Lock()
+	result := make([]hotKeyCount, 0, len(t.keys))
+	for _, tracked := range t.keys {
+		if namespace == "" || namespaceOf(tracked.key) == namespace {
+			result = append(result, *tracked)
+		}
+	}
+	t.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].count != result[j].count {
+			return result[i].count > result[j].count
+		}
+		return result[i].key < result[j].key
+	})
+	return result[:min(n, len(result))]
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+func TestKeyEventsLease(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+
+	a := NewStats(client, StatsConfig{KeyEvents: true}, zap.NewNop())
+	b := NewStats(client, StatsConfig{KeyEvents: true}, zap.NewNop())
+
+	holdLease := func(st *Stats) int64 {
+		t.Helper()
+		token, err := st.holdLease(ctx)
+		if err != nil {
+			t.Fatalf("holdLease: %v", err)
+		}
+		return token
+	}
+
+	first := holdLease(a)
+	if first == 0 {
+		t.Fatal("first instance did not get the lease")
+	}
+	if holdLease(b) != 0 {
+		t.Fatal("second instance got a held lease")
+	}
+	if renewed := holdLease(a); renewed != first {
+		t.Fatalf("renewal token = %d, want %d", renewed, first)
+	}
+
+	// A crashed holder loses the lease after its TTL
+	mr.FastForward(keyEventsLeaseTTL)
+	if holdLease(b) == 0 {
+		t.Fatal("second instance did not take over an expired lease")
+	}
+	if holdLease(a) != 0 {
+		t.Fatal("first instance kept a lease taken over by another")
+	}
+
+	// A released lease is free at once
+	b.releaseLease(holdLease(b))
+	if holdLease(a) == 0 {
+		t.Fatal("first instance did not get a released lease")
+	}
+}
+
+func TestStatsReleasesLeaseOnClose(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	st := NewStats(client, StatsConfig{KeyEvents: true}, zap.NewNop())
+	st.Start()
+	deadline := time.Now().Add(time.Second)
+	for !st.evictionsTracked.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("removals are not tracked by the lease holder")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	st.Close()
+
+	if st.evictionsTracked.Load() {
+		t.Error("removals are tracked after Close")
+	}
+	if mr.Exists(lockKeys(keyEventsLease)[0]) {
+		t.Error("lease is held after Close")
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// CacheConfig holds configuration of the local object cache
+type CacheConfig struct {
+	MaxBytes       int64         // Total size of cached objects, 0 disables the cache
+	MaxObjectBytes int64         // Larger objects are always streamed from MinIO, defaults to 4 MiB
+	TTL            time.Duration // How long an object is served without asking MinIO, defaults to 1h
+}
+
+var (
+	cacheRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cdn_cache_requests_total",
+		Help: "Number of local object cache lookups by result (hit, miss)",
+	}, []string{"result"})
+
+	cacheEvictionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cdn_cache_evictions_total",
+		Help: "Number of objects removed from the local object cache by reason (capacity, expired, invalidated)",
+	}, []string{"reason"})
+
+	cacheBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "cdn_cache_bytes",
+		Help: "Total size of the objects in the local object cache",
+	})
+)
+
+// cachedObject is an object body together with the headers it is served with
+type cachedObject struct {
+	key          string
+	data         []byte
+	contentType  string
+	etag         string
+	cacheControl string // Cache-Control stored with the object
+	lastModified time.Time
+	expiresAt    time.Time
+}
+
+// ObjectCache is an in-memory LRU cache of small objects, keyed by object name
+type ObjectCache struct {
+	config CacheConfig
+
+	mu         sync.Mutex
+	size       int64
+	order      *list.List // Front is the most recently used
+	entries    map[string]*list.Element
+	generation uint64 // Incremented by every invalidation
+}
+
+// NewObjectCache creates a new local object cache
+func NewObjectCache(config CacheConfig) *ObjectCache {
+	if config.MaxObjectBytes <= 0 {
+		config.MaxObjectBytes = 4 << 20
+	}
+	if config.TTL <= 0 {
+		config.TTL = time.Hour
+	}
+
+	return &ObjectCache{
+		config:  config,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Cacheable reports whether an object of the given size may be cached
+func (c *ObjectCache) Cacheable(size int64) bool {
+	return c.config.MaxBytes > 0 && size <= c.config.MaxObjectBytes && size <= c.config.MaxBytes
+}
+
+// Get returns a cached object that has not expired yet
+func (c *ObjectCache) Get(key string) (*cachedObject, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		cacheRequestsTotal.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+	obj := elem.Value.(*cachedObject)
+	if time.Now().After(obj.expiresAt) {
+		c.remove(elem, "expired")
+		cacheRequestsTotal.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	cacheRequestsTotal.WithLabelValues("hit").Inc()
+	return obj, true
+}
+
+// Generation returns the invalidation generation, taken before reading an object from MinIO
+func (c *ObjectCache) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// Put caches an object, evicting the least recently used ones to make room.
+// The object is dropped if an invalidation ran since generation was taken,
+// it may have been read before the invalidated object changed.
+func (c *ObjectCache) Put(obj *cachedObject, generation uint64) {
+	if !c.Cacheable(int64(len(obj.data))) {
+		return
+	}
+	obj.expiresAt = time.Now().Add(c.config.TTL)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if elem, ok := c.entries[obj.key]; ok {
+		c.remove(elem, "replaced")
+	}
+	for c.size+int64(len(obj.data)) > c.config.MaxBytes {
+		c.remove(c.order.Back(), "capacity")
+	}
+
+	c.entries[obj.key] = c.order.PushFront(obj)
+	c.size += int64(len(obj.data))
+	cacheBytes.Set(float64(c.size))
+}
+
+// EvictMatching removes every object whose key matches and returns how many were removed
+func (c *ObjectCache) EvictMatching(match func(key string) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	evicted := 0
+	for key, elem := range c.entries {
+		if match(key) {
+			c.remove(elem, "invalidated")
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// remove drops an entry, the caller holds the lock
+func (c *ObjectCache) remove(elem *list.Element, reason string) {
+	obj := c.order.Remove(elem).(*cachedObject)
+	delete(c.entries, obj.key)
+	c.size -= int64(len(obj.data))
+	cacheBytes.Set(float64(c.size))
+	if reason != "replaced" {
+		cacheEvictionsTotal.WithLabelValues(reason).Inc()
+	}
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+// Invalidation statuses
+const (
+	InvalidationStatusQueued  = "queued"
+	InvalidationStatusRunning = "running"
+	InvalidationStatusDone    = "done"
+	InvalidationStatusFailed  = "failed"
+)
+
+// maxInvalidationPatterns limits the patterns of one operation
+const maxInvalidationPatterns = 100
+
+var (
+	// ErrInvalidationNotFound is returned for unknown operation IDs
+	ErrInvalidationNotFound = errors.New("invalidation not found")
+
+	// ErrInvalidPattern is returned for invalidations with missing or malformed patterns
+	ErrInvalidPattern = errors.New("invalid invalidation pattern")
+)
+
+var invalidationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "cdn_invalidations_total",
+	Help: "Number of cache invalidations finished by this instance by status",
+}, []string{"status"})
+
+// Invalidation is a cache invalidation operation
+type Invalidation struct {
+	ID         string         `db:"id"`
+	WorldID    *string        `db:"world_id"`
+	Patterns   pq.StringArray `db:"patterns"`
+	Status     string         `db:"status"`
+	Evicted    int            `db:"evicted"`
+	Attempts   int            `db:"attempts"`
+	LastError  *string        `db:"last_error"`
+	CreatedAt  time.Time      `db:"created_at"`
+	StartedAt  *time.Time     `db:"started_at"`
+	FinishedAt *time.Time     `db:"finished_at"`
+}
+
+// Matcher compiles the patterns of an invalidation. Patterns are object paths
+// where '*' matches any sequence of characters, '/' included, and a trailing
+// '/' matches everything below a directory. Patterns of a world invalidation
+// are relative to the world's objects ("<world_id>/...").
+func (inv *Invalidation) Matcher() (func(key string) bool, error) {
+	prefix := ""
+	if inv.WorldID != nil {
+		prefix = *inv.WorldID + "/"
+	}
+
+	exprs := make([]string, 0, len(inv.Patterns))
+	for _, pattern := range inv.Patterns {
+		pattern, err := cleanPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		expr := strings.ReplaceAll(regexp.QuoteMeta(prefix+pattern), `\*`, `.*`)
+		if strings.HasSuffix(pattern, "/") {
+			expr += ".*"
+		}
+		exprs = append(exprs, expr)
+	}
+
+	re, err := regexp.Compile(`^(?:` + strings.Join(exprs, "|") + `)$`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPattern, err)
+	}
+	return re.MatchString, nil
+}
+
+// cleanPattern validates an invalidation pattern and strips its leading slashes
+func cleanPattern(pattern string) (string, error) {
+	pattern = strings.TrimLeft(strings.TrimSpace(pattern), "/")
+	if pattern == "" {
+		return "", fmt.Errorf("%w: empty pattern", ErrInvalidPattern)
+	}
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == "." || segment == ".." {
+			return "", fmt.Errorf("%w: %q leaves the bucket root", ErrInvalidPattern, pattern)
+		}
+	}
+	return pattern, nil
+}
+
+// InvalidationRepository stores invalidation operations, the queue shared by all CDN instances
+type InvalidationRepository struct {
+	db *sqlx.DB
+}
+
+// NewInvalidationRepository creates a new invalidation repository
+func NewInvalidationRepository(db *sqlx.DB) *InvalidationRepository {
+	return &InvalidationRepository{db: db}
+}
+
+const invalidationColumns = `id, world_id, patterns, status, evicted, attempts, last_error, created_at, started_at, finished_at`
+
+// Create queues a new invalidation
+func (r *InvalidationRepository) Create(ctx context.Context, inv *Invalidation) error {
+	query := `
+		INSERT INTO cdn_invalidations (world_id, patterns, status)
+		VALUES ($1, $2, $3)
+		RETURNING ` + invalidationColumns
+
+	return r.db.GetContext(ctx, inv, query, inv.WorldID, inv.Patterns, InvalidationStatusQueued)
+}
+
+// Get returns an invalidation by ID
+func (r *InvalidationRepository) Get(ctx context.Context, id string) (*Invalidation, error) {
+	var inv Invalidation
+	query := `SELECT ` + invalidationColumns + ` FROM cdn_invalidations WHERE id = $1`
+	if err := r.db.GetContext(ctx, &inv, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidationNotFound
+		}
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// Claim marks up to limit queued invalidations running and returns them.
+// Operations left running by a crashed instance are claimed again after staleAfter.
+func (r *InvalidationRepository) Claim(ctx context.Context, staleAfter time.Duration, limit int) ([]*Invalidation, error) {
+	query := `
+		UPDATE cdn_invalidations
+		SET status = $1, started_at = NOW(), attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM cdn_invalidations
+			WHERE status = $2 OR (status = $1 AND started_at < $3)
+			ORDER BY created_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + invalidationColumns
+
+	var invs []*Invalidation
+	err := r.db.SelectContext(ctx, &invs, query,
+		InvalidationStatusRunning, InvalidationStatusQueued, time.Now().Add(-staleAfter), limit)
+	return invs, err
+}
+
+// Finish records the outcome of a running invalidation and returns when it finished
+func (r *InvalidationRepository) Finish(ctx context.Context, id, status string, evicted int, lastError *string) (time.Time, error) {
+	query := `
+		UPDATE cdn_invalidations
+		SET status = $2, evicted = $3, last_error = $4, finished_at = NOW()
+		WHERE id = $1
+		RETURNING finished_at`
+
+	var finishedAt time.Time
+	err := r.db.GetContext(ctx, &finishedAt, query, id, status, evicted, lastError)
+	return finishedAt, err
+}
+
+// ListDoneSince returns invalidations finished after the given time, oldest first
+func (r *InvalidationRepository) ListDoneSince(ctx context.Context, since time.Time, limit int) ([]*Invalidation, error) {
+	query := `
+		SELECT ` + invalidationColumns + `
+		FROM cdn_invalidations
+		WHERE status = $1 AND finished_at > $2
+		ORDER BY finished_at
+		LIMIT $3`
+
+	var invs []*Invalidation
+	err := r.db.SelectContext(ctx, &invs, query, InvalidationStatusDone, since, limit)
+	return invs, err
+}
+
+// InvalidatorConfig holds invalidation worker configuration
+type InvalidatorConfig struct {
+	Interval   time.Duration // How often the queue is polled, defaults to 1s
+	StaleAfter time.Duration // When a running operation is considered abandoned, defaults to 1m
+	BatchSize  int           // Defaults to 50
+}
+
+// syncOverlap is how far back invalidations finished by other instances are
+// replayed, rows commit a little after their finished_at
+const syncOverlap = 10 * time.Second
+
+// Invalidator works through the invalidation queue. An instance claims an
+// operation, evicts the matching objects from its cache and marks it done;
+// every other instance replays done operations against its own cache.
+// Evicting is idempotent, so replaying an operation twice is harmless.
+type Invalidator struct {
+	repo   *InvalidationRepository
+	cache  *ObjectCache
+	config InvalidatorConfig
+	logger *zap.Logger
+
+	applied map[string]time.Time // Operations already evicted, by finish time
+	wake    chan struct{}
+	cancel  context.CancelFunc
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewInvalidator creates a new invalidation worker
+func NewInvalidator(repo *InvalidationRepository, cache *ObjectCache, config InvalidatorConfig, logger *zap.Logger) *Invalidator {
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	if config.StaleAfter <= 0 {
+		config.StaleAfter = time.Minute
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 50
+	}
+
+	return &Invalidator{
+		repo:    repo,
+		cache:   cache,
+		config:  config,
+		logger:  logger,
+		applied: make(map[string]time.Time),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+// Enqueue validates and queues an invalidation, an empty worldID makes the patterns absolute
+func (i *Invalidator) Enqueue(ctx context.Context, worldID string, patterns []string) (*Invalidation, error) {
+	if len(patterns) == 0 || len(patterns) > maxInvalidationPatterns {
+		return nil, fmt.Errorf("%w: between 1 and %d patterns are required", ErrInvalidPattern, maxInvalidationPatterns)
+	}
+
+	inv := &Invalidation{Patterns: make(pq.StringArray, 0, len(patterns))}
+	if worldID != "" {
+		inv.WorldID = &worldID
+	}
+	for _, pattern := range patterns {
+		cleaned, err := cleanPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		inv.Patterns = append(inv.Patterns, cleaned)
+	}
+	if _, err := inv.Matcher(); err != nil {
+		return nil, err
+	}
+
+	if err := i.repo.Create(ctx, inv); err != nil {
+		return nil, fmt.Errorf("failed to queue invalidation: %w", err)
+	}
+
+	// Run right away instead of waiting for the next tick
+	select {
+	case i.wake <- struct{}{}:
+	default:
+	}
+	return inv, nil
+}
+
+// Start runs the worker loop in the background until Close is called
+func (i *Invalidator) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	i.cancel = cancel
+
+	go func() {
+		defer close(i.done)
+		i.run(ctx)
+	}()
+
+	i.logger.Info("Cache invalidator started", zap.Duration("interval", i.config.Interval))
+}
+
+// Close stops the worker after the current run
+func (i *Invalidator) Close() {
+	i.once.Do(func() {
+		if i.cancel != nil {
+			i.cancel()
+			<-i.done
+		}
+		i.logger.Info("Cache invalidator stopped")
+	})
+}
+
+func (i *Invalidator) run(ctx context.Context) {
+	ticker := time.NewTicker(i.config.Interval)
+	defer ticker.Stop()
+
+	// The cache starts empty, earlier invalidations do not matter
+	synced := time.Now()
+	for {
+		if err := i.process(ctx); err != nil && ctx.Err() == nil {
+			i.logger.Error("Failed to process invalidations", zap.Error(err))
+		}
+		next, err := i.replay(ctx, synced)
+		if err != nil && ctx.Err() == nil {
+			i.logger.Error("Failed to replay invalidations", zap.Error(err))
+		}
+		synced = next
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-i.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// process claims queued invalidations and runs them against the local cache
+func (i *Invalidator) process(ctx context.Context) error {
+	invs, err := i.repo.Claim(ctx, i.config.StaleAfter, i.config.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to claim invalidations: %w", err)
+	}
+
+	for _, inv := range invs {
+		status, evicted := InvalidationStatusDone, 0
+		var lastError *string
+		if match, err := inv.Matcher(); err != nil {
+			status = InvalidationStatusFailed
+			msg := err.Error()
+			lastError = &msg
+		} else {
+			evicted = i.cache.EvictMatching(match)
+		}
+
+		finishedAt, err := i.repo.Finish(ctx, inv.ID, status, evicted, lastError)
+		if err != nil {
+			return fmt.Errorf("failed to finish invalidation %s: %w", inv.ID, err)
+		}
+		i.applied[inv.ID] = finishedAt
+		invalidationsTotal.WithLabelValues(status).Inc()
+		i.logger.Info("Cache invalidated",
+			zap.String("operation_id", inv.ID),
+			zap.Strings("patterns", inv.Patterns),
+			zap.String("status", status),
+			zap.Int("evicted", evicted))
+	}
+	return nil
+}
+
+// replay evicts the objects of invalidations other instances finished since
+// the given time and returns the time to continue from
+func (i *Invalidator) replay(ctx context.Context, since time.Time) (time.Time, error) {
+	from := since.Add(-syncOverlap)
+	for ctx.Err() == nil {
+		invs, err := i.repo.ListDoneSince(ctx, from, i.config.BatchSize)
+		if err != nil {
+			return since, err
+		}
+
+		for _, inv := range invs {
+			if _, ok := i.applied[inv.ID]; !ok {
+				if match, err := inv.Matcher(); err == nil {
+					i.cache.EvictMatching(match)
+				}
+				i.applied[inv.ID] = *inv.FinishedAt
+			}
+			from = *inv.FinishedAt
+			if from.After(since) {
+				since = from
+			}
+		}
+		if len(invs) < i.config.BatchSize {
+			break
+		}
+	}
+
+	// Operations older than the overlap are never listed again
+	for id, finishedAt := range i.applied {
+		if finishedAt.Before(since.Add(-2 * syncOverlap)) {
+			delete(i.applied, id)
+		}
+	}
+	return since, ctx.Err()
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sdshorin/generia/pkg/config"
+)
+
+// legacyKeyID identifies CDN_SIGNING_KEY and URLs signed before key IDs were added
+const legacyKeyID = "default"
+
+// placeholderSigningKey is the example CDN_SIGNING_KEY shipped in .env files,
+// anyone could sign URLs with it
+const placeholderSigningKey = "your_cdn_signing_key"
+
+// signingKey is an HMAC key signed URLs refer to by ID
+type signingKey struct {
+	id        string
+	secret    []byte
+	retiredAt time.Time // Zero for active keys
+}
+
+// Keyring holds the URL signing keys. New URLs are signed with the primary key;
+// URLs carry the ID of their key, so rotating one key leaves URLs signed with
+// the others valid. Retired keys keep verifying URLs for the grace period, a
+// leaked key is removed from the configuration to reject its URLs at once.
+type Keyring struct {
+	keys    map[string]*signingKey
+	primary *signingKey
+	grace   time.Duration
+}
+
+// NewKeyring builds the keyring from the CDN configuration
+func NewKeyring(cfg config.CDNConfig) (*Keyring, error) {
+	kr := &Keyring{
+		keys:  make(map[string]*signingKey),
+		grace: cfg.KeyGracePeriod,
+	}
+
+	active, err := parseKeys(cfg.SigningKeys, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing keys: %w", err)
+	}
+	if len(active) == 0 {
+		if cfg.SigningKey == "" || cfg.SigningKey == placeholderSigningKey {
+			return nil, errors.New("no signing key configured: set CDN_SIGNING_KEYS or a secret CDN_SIGNING_KEY")
+		}
+		active = []*signingKey{{id: legacyKeyID, secret: []byte(cfg.SigningKey)}}
+	}
+	retired, err := parseKeys(cfg.RetiredSigningKeys, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retired signing keys: %w", err)
+	}
+
+	for _, key := range append(active, retired...) {
+		if _, ok := kr.keys[key.id]; ok {
+			return nil, fmt.Errorf("duplicate signing key ID %q", key.id)
+		}
+		kr.keys[key.id] = key
+	}
+
+	kr.primary = active[0]
+	if cfg.PrimaryKeyID != "" {
+		primary, ok := kr.keys[cfg.PrimaryKeyID]
+		if !ok || !primary.retiredAt.IsZero() {
+			return nil, fmt.Errorf("primary key %q is not an active signing key", cfg.PrimaryKeyID)
+		}
+		kr.primary = primary
+	}
+	return kr, nil
+}
+
+// parseKeys parses comma-separated "id:secret" pairs, retired keys also need "@<RFC 3339 time>"
+func parseKeys(value string, retired bool) ([]*signingKey, error) {
+	var keys []*signingKey
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key := &signingKey{}
+		if retired {
+			at := strings.LastIndex(entry, "@")
+			if at < 0 {
+				return nil, fmt.Errorf("retired key %q has no retirement time", keyIDOf(entry))
+			}
+			retiredAt, err := time.Parse(time.RFC3339, entry[at+1:])
+			if err != nil {
+				return nil, fmt.Errorf("retired key %q: %w", keyIDOf(entry), err)
+			}
+			key.retiredAt = retiredAt
+			entry = entry[:at]
+		}
+
+		id, secret, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("key %q is not in the id:secret format", keyIDOf(entry))
+		}
+		key.id, key.secret = id, []byte(secret)
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// keyIDOf returns the ID part of a key entry, so errors never include secrets
+func keyIDOf(entry string) string {
+	id, _, _ := strings.Cut(entry, ":")
+	return id
+}
+
+// Sign signs a path and expiry with the primary key and returns the key ID and signature
+func (kr *Keyring) Sign(path string, expiry int64) (string, string) {
+	return kr.primary.id, kr.primary.sign(path, expiry)
+}
+
+// Verify checks a signature made with the given key in constant time.
+// An empty key ID refers to the legacy key.
+func (kr *Keyring) Verify(keyID, path string, expiry int64, signature string) bool {
+	if keyID == "" {
+		keyID = legacyKeyID
+	}
+	key, ok := kr.keys[keyID]
+	if !ok || !kr.usable(key, time.Now()) {
+		return false
+	}
+	return hmac.Equal([]byte(key.sign(path, expiry)), []byte(strings.ToLower(signature)))
+}
+
+// usable reports whether a key still verifies URLs
+func (kr *Keyring) usable(key *signingKey, now time.Time) bool {
+	return key.retiredAt.IsZero() || now.Before(key.retiredAt.Add(kr.grace))
+}
+
+// KeyInfo describes a signing key without its secret
+type KeyInfo struct {
+	ID         string
+	Primary    bool
+	RetiredAt  time.Time // Zero for active keys
+	ValidUntil time.Time // End of the grace period of retired keys
+}
+
+// Keys lists the keys that verify URLs, active keys first
+func (kr *Keyring) Keys() []KeyInfo {
+	now := time.Now()
+	infos := make([]KeyInfo, 0, len(kr.keys))
+	for _, key := range kr.keys {
+		if !kr.usable(key, now) {
+			continue
+		}
+		info := KeyInfo{ID: key.id, Primary: key == kr.primary, RetiredAt: key.retiredAt}
+		if !key.retiredAt.IsZero() {
+			info.ValidUntil = key.retiredAt.Add(kr.grace)
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].RetiredAt.IsZero() != infos[j].RetiredAt.IsZero() {
+			return infos[i].RetiredAt.IsZero()
+		}
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// sign returns the hex HMAC-SHA256 of "<path>/<expiry>"
+func (k *signingKey) sign(path string, expiry int64) string {
+	h := hmac.New(sha256.New, k.secret)
+	fmt.Fprintf(h, "%s/%d", path, expiry)
+	return hex.EncodeToString(h.Sum(nil))
+}
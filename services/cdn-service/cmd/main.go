@@ -2,23 +2,28 @@ package main
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
-	// "strconv"
+	"path"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sdshorin/generia/pkg/config"
+	"github.com/sdshorin/generia/pkg/database"
 	"github.com/sdshorin/generia/pkg/discovery"
 	"github.com/sdshorin/generia/pkg/logger"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
@@ -29,9 +34,11 @@ import (
 	// semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 
 	cdnpb "github.com/sdshorin/generia/api/grpc/cdn"
 )
@@ -41,47 +48,124 @@ type CDNService struct {
 	cdnpb.UnimplementedCDNServiceServer
 	logger     *zap.Logger
 	domain     string
+	publicURL  string
 	defaultTTL int
-	signingKey string
+	keyring    *Keyring
+
+	invalidator   *Invalidator
+	invalidations *InvalidationRepository
 }
 
+// expiryGranularity rounds expiry times up, so every request for a path within
+// the same window gets the same URL and browsers can cache it
+const expiryGranularity = 15 * time.Minute
+
 // GetSignedURL implements the GetSignedURL method
 func (s *CDNService) GetSignedURL(ctx context.Context, req *cdnpb.GetSignedURLRequest) (*cdnpb.GetSignedURLResponse, error) {
-	// Basic implementation
+	path, ok := cleanPath(req.Path)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid path")
+	}
+
 	ttl := s.defaultTTL
 	if req.ExpiresIn > 0 {
 		ttl = int(req.ExpiresIn)
 	}
 
-	expiry := time.Now().Add(time.Duration(ttl) * time.Second).Unix()
-	path := req.Path
+	expiresAt := time.Now().Add(time.Duration(ttl) * time.Second)
+	if rounded := expiresAt.Truncate(expiryGranularity); rounded.Before(expiresAt) {
+		expiresAt = rounded.Add(expiryGranularity)
+	}
+	expiry := expiresAt.Unix()
 
 	// Generate signature
-	signature := s.generateSignature(path, expiry)
+	keyID, signature := s.keyring.Sign(path, expiry)
 
 	// Build URL
-	url := fmt.Sprintf("https://%s/%s?expires=%d&signature=%s", s.domain, path, expiry, signature)
+	escaped := (&url.URL{Path: path}).EscapedPath()
+	signedURL := fmt.Sprintf("%s/%s?expires=%d&kid=%s&signature=%s", s.publicURL, escaped, expiry, url.QueryEscape(keyID), signature)
 
 	return &cdnpb.GetSignedURLResponse{
-		Url:       url,
+		Url:       signedURL,
 		ExpiresAt: expiry,
+		KeyId:     keyID,
 	}, nil
 }
 
 // InvalidateCache implements the InvalidateCache method
 func (s *CDNService) InvalidateCache(ctx context.Context, req *cdnpb.InvalidateCacheRequest) (*cdnpb.InvalidateCacheResponse, error) {
-	// Placeholder implementation
-	s.logger.Info("InvalidateCache called", zap.Strings("paths", req.Paths))
+	if req.WorldId != "" {
+		if _, err := uuid.Parse(req.WorldId); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid world id")
+		}
+	}
 
-	// Generate unique operation ID
-	operationID := fmt.Sprintf("op-%d", time.Now().UnixNano())
+	inv, err := s.invalidator.Enqueue(ctx, req.WorldId, req.Paths)
+	if err != nil {
+		if errors.Is(err, ErrInvalidPattern) {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		s.logger.Error("Failed to queue invalidation", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to queue invalidation")
+	}
+
+	s.logger.Info("Invalidation queued",
+		zap.String("operation_id", inv.ID),
+		zap.String("world_id", req.WorldId),
+		zap.Strings("paths", inv.Patterns))
 
 	return &cdnpb.InvalidateCacheResponse{
 		Success:     true,
-		OperationId: operationID,
+		OperationId: inv.ID,
+		Status:      invalidationStatuses[inv.Status],
 	}, nil
 }
 
+// GetInvalidationStatus implements the GetInvalidationStatus method
+func (s *CDNService) GetInvalidationStatus(ctx context.Context, req *cdnpb.GetInvalidationStatusRequest) (*cdnpb.GetInvalidationStatusResponse, error) {
+	if _, err := uuid.Parse(req.OperationId); err != nil {
+		return nil, status.Errorf(codes.NotFound, "%v", ErrInvalidationNotFound)
+	}
+
+	inv, err := s.invalidations.Get(ctx, req.OperationId)
+	if err != nil {
+		if errors.Is(err, ErrInvalidationNotFound) {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
+		s.logger.Error("Failed to get invalidation", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to get invalidation")
+	}
+
+	resp := &cdnpb.GetInvalidationStatusResponse{
+		OperationId:    inv.ID,
+		Status:         invalidationStatuses[inv.Status],
+		Paths:          inv.Patterns,
+		EvictedObjects: int32(inv.Evicted),
+		CreatedAt:      inv.CreatedAt.Unix(),
+	}
+	if inv.WorldID != nil {
+		resp.WorldId = *inv.WorldID
+	}
+	if inv.LastError != nil {
+		resp.Error = *inv.LastError
+	}
+	if inv.StartedAt != nil {
+		resp.StartedAt = inv.StartedAt.Unix()
+	}
+	if inv.FinishedAt != nil {
+		resp.FinishedAt = inv.FinishedAt.Unix()
+	}
+	return resp, nil
+}
+
+// invalidationStatuses maps stored statuses to the proto enum
+var invalidationStatuses = map[string]cdnpb.InvalidationStatus{
+	InvalidationStatusQueued:  cdnpb.InvalidationStatus_INVALIDATION_STATUS_QUEUED,
+	InvalidationStatusRunning: cdnpb.InvalidationStatus_INVALIDATION_STATUS_RUNNING,
+	InvalidationStatusDone:    cdnpb.InvalidationStatus_INVALIDATION_STATUS_DONE,
+	InvalidationStatusFailed:  cdnpb.InvalidationStatus_INVALIDATION_STATUS_FAILED,
+}
+
 // GetCDNConfig implements the GetCDNConfig method
 func (s *CDNService) GetCDNConfig(ctx context.Context, req *cdnpb.GetCDNConfigRequest) (*cdnpb.GetCDNConfigResponse, error) {
 	// Placeholder implementation
@@ -93,6 +177,25 @@ func (s *CDNService) GetCDNConfig(ctx context.Context, req *cdnpb.GetCDNConfigRe
 	}, nil
 }
 
+// ListSigningKeys implements the ListSigningKeys method
+func (s *CDNService) ListSigningKeys(ctx context.Context, req *cdnpb.ListSigningKeysRequest) (*cdnpb.ListSigningKeysResponse, error) {
+	infos := s.keyring.Keys()
+	keys := make([]*cdnpb.SigningKey, 0, len(infos))
+	for _, info := range infos {
+		key := &cdnpb.SigningKey{
+			KeyId:   info.ID,
+			Primary: info.Primary,
+		}
+		if !info.RetiredAt.IsZero() {
+			key.RetiredAt = info.RetiredAt.Unix()
+			key.ValidUntil = info.ValidUntil.Unix()
+		}
+		keys = append(keys, key)
+	}
+
+	return &cdnpb.ListSigningKeysResponse{Keys: keys}, nil
+}
+
 // HealthCheck implements the HealthCheck method
 func (s *CDNService) HealthCheck(ctx context.Context, req *cdnpb.HealthCheckRequest) (*cdnpb.HealthCheckResponse, error) {
 	// Basic implementation
@@ -101,19 +204,13 @@ func (s *CDNService) HealthCheck(ctx context.Context, req *cdnpb.HealthCheckRequ
 	}, nil
 }
 
-// Helper function to generate signature
-func (s *CDNService) generateSignature(path string, expiry int64) string {
-	// Create HMAC
-	h := hmac.New(sha256.New, []byte(s.signingKey))
-
-	// Create string to sign
-	stringToSign := fmt.Sprintf("%s/%d", path, expiry)
-
-	// Write to HMAC
-	h.Write([]byte(stringToSign))
-
-	// Get signature
-	return hex.EncodeToString(h.Sum(nil))
+// cleanPath normalizes an object path, rejecting empty paths and paths leaving the bucket root
+func cleanPath(p string) (string, bool) {
+	p = strings.TrimLeft(p, "/")
+	if p == "" || p != path.Clean(p) || p == ".." || strings.HasPrefix(p, "../") {
+		return "", false
+	}
+	return p, true
 }
 
 func main() {
@@ -156,12 +253,56 @@ func main() {
 	}
 	defer discoveryClient.Deregister(serviceID)
 
+	// Initialize MinIO client, the origin of everything the CDN serves
+	minioClient, err := minio.New(cfg.Minio.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.Minio.AccessKey, cfg.Minio.SecretKey, ""),
+		Secure: cfg.Minio.UseSSL,
+	})
+	if err != nil {
+		logger.Logger.Fatal("Failed to create MinIO client", zap.Error(err))
+	}
+
+	// Initialize database connection, it holds the invalidation queue
+	db, err := database.NewPostgresDB(database.PostgresConfig{
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		Username: cfg.Database.User,
+		Password: cfg.Database.Password,
+		DBName:   cfg.Database.Name,
+		SSLMode:  cfg.Database.SSLMode,
+	})
+	if err != nil {
+		logger.Logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer db.Close()
+
+	// Local cache of small objects, emptied by invalidations
+	cacheConfig, err := cacheConfigFromEnv()
+	if err != nil {
+		logger.Logger.Fatal("Failed to parse CDN cache config", zap.Error(err))
+	}
+	objectCache := NewObjectCache(cacheConfig)
+
+	invalidationRepo := NewInvalidationRepository(db)
+	invalidator := NewInvalidator(invalidationRepo, objectCache, InvalidatorConfig{}, logger.Logger)
+	invalidator.Start()
+
+	// Initialize URL signing keys
+	keyring, err := NewKeyring(cfg.CDN)
+	if err != nil {
+		logger.Logger.Fatal("Failed to load signing keys", zap.Error(err))
+	}
+
 	// Initialize CDN service
 	cdnService := &CDNService{
 		logger:     logger.Logger,
 		domain:     cfg.CDN.Domain,
+		publicURL:  cfg.CDN.PublicURL,
 		defaultTTL: cfg.CDN.DefaultTTL,
-		signingKey: cfg.CDN.SigningKey,
+		keyring:    keyring,
+
+		invalidator:   invalidator,
+		invalidations: invalidationRepo,
 	}
 
 	// Create gRPC server with middleware
@@ -194,6 +335,19 @@ func main() {
 		}
 	}()
 
+	// Start HTTP server serving signed URLs
+	originServer := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.CDN.HTTPPort),
+		Handler:           NewOriginHandler(cdnService, minioClient, cfg.Minio.Bucket, objectCache, logger.Logger),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		logger.Logger.Info("Starting CDN HTTP server", zap.Int("port", cfg.CDN.HTTPPort))
+		if err := originServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Logger.Fatal("CDN HTTP server error", zap.Error(err))
+		}
+	}()
+
 	// Start gRPC server
 	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", cfg.Service.Host, cfg.Service.Port))
 	if err != nil {
@@ -216,6 +370,40 @@ func main() {
 	<-quit
 
 	logger.Logger.Info("Shutting down CDN service...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := originServer.Shutdown(shutdownCtx); err != nil {
+		logger.Logger.Error("Failed to shut down CDN HTTP server", zap.Error(err))
+	}
 	grpcServer.GracefulStop()
+	invalidator.Close()
 	logger.Logger.Info("CDN service stopped")
 }
+
+// cacheConfigFromEnv reads the local object cache configuration, unset values keep the defaults
+func cacheConfigFromEnv() (CacheConfig, error) {
+	config := CacheConfig{MaxBytes: 256 << 20}
+
+	if v := os.Getenv("CDN_CACHE_MAX_BYTES"); v != "" {
+		maxBytes, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || maxBytes < 0 {
+			return config, fmt.Errorf("invalid CDN_CACHE_MAX_BYTES: %s", v)
+		}
+		config.MaxBytes = maxBytes
+	}
+	if v := os.Getenv("CDN_CACHE_MAX_OBJECT_BYTES"); v != "" {
+		maxObjectBytes, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || maxObjectBytes <= 0 {
+			return config, fmt.Errorf("invalid CDN_CACHE_MAX_OBJECT_BYTES: %s", v)
+		}
+		config.MaxObjectBytes = maxObjectBytes
+	}
+	if v := os.Getenv("CDN_CACHE_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return config, fmt.Errorf("invalid CDN_CACHE_TTL: %w", err)
+		}
+		config.TTL = ttl
+	}
+	return config, nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+// maxCacheAge caps Cache-Control max-age, a response may not outlive its URL either way
+const maxCacheAge = 24 * time.Hour
+
+var (
+	originRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cdn_origin_requests_total",
+		Help: "Number of requests served by the CDN HTTP listener by result",
+	}, []string{"result"})
+
+	originBytesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "cdn_origin_bytes_total",
+		Help: "Number of body bytes served by the CDN HTTP listener",
+	})
+)
+
+// OriginHandler serves objects from MinIO behind URLs signed by GetSignedURL:
+//
+//	GET /<path>?expires=<unix>&kid=<key ID>&signature=<hex hmac>
+//
+// Expired or tampered URLs and URLs signed with unknown or retired keys past
+// their grace period are rejected with 403. Range requests, ETag
+// revalidation and HEAD are handled by http.ServeContent. Small objects are
+// kept in the local object cache until they expire or are invalidated.
+type OriginHandler struct {
+	cdn         *CDNService
+	minioClient *minio.Client
+	bucket      string
+	cache       *ObjectCache
+	logger      *zap.Logger
+}
+
+// NewOriginHandler creates a new HTTP handler serving signed URLs
+func NewOriginHandler(cdn *CDNService, minioClient *minio.Client, bucket string, cache *ObjectCache, logger *zap.Logger) *OriginHandler {
+	return &OriginHandler{
+		cdn:         cdn,
+		minioClient: minioClient,
+		bucket:      bucket,
+		cache:       cache,
+		logger:      logger,
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (h *OriginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodOptions:
+		w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Range, If-None-Match, If-Modified-Since")
+		w.Header().Set("Access-Control-Max-Age", "86400")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+		h.fail(w, http.StatusMethodNotAllowed, "method_not_allowed")
+		return
+	}
+
+	objectName, ok := cleanPath(r.URL.Path)
+	if !ok {
+		h.fail(w, http.StatusNotFound, "not_found")
+		return
+	}
+
+	query := r.URL.Query()
+	expiry, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || !h.cdn.keyring.Verify(query.Get("kid"), objectName, expiry, query.Get("signature")) {
+		h.fail(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	remaining := time.Until(time.Unix(expiry, 0))
+	if remaining <= 0 {
+		h.fail(w, http.StatusForbidden, "expired")
+		return
+	}
+
+	if cached, ok := h.cache.Get(objectName); ok {
+		h.serve(w, r, cached, remaining, bytes.NewReader(cached.data))
+		return
+	}
+	generation := h.cache.Generation()
+
+	object, err := h.minioClient.GetObject(r.Context(), h.bucket, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		h.logger.Error("Failed to get object", zap.String("object_name", objectName), zap.Error(err))
+		h.fail(w, http.StatusBadGateway, "error")
+		return
+	}
+	defer object.Close()
+
+	info, err := object.Stat()
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			h.fail(w, http.StatusNotFound, "not_found")
+			return
+		}
+		h.logger.Error("Failed to stat object", zap.String("object_name", objectName), zap.Error(err))
+		h.fail(w, http.StatusBadGateway, "error")
+		return
+	}
+
+	contentType := info.ContentType
+	if contentType == "" || contentType == "application/octet-stream" {
+		if byExt := mime.TypeByExtension(path.Ext(objectName)); byExt != "" {
+			contentType = byExt
+		}
+	}
+	meta := &cachedObject{
+		key:          objectName,
+		contentType:  contentType,
+		etag:         info.ETag,
+		cacheControl: info.Metadata.Get("Cache-Control"),
+		lastModified: info.LastModified,
+	}
+
+	if !h.cache.Cacheable(info.Size) {
+		h.serve(w, r, meta, remaining, object)
+		return
+	}
+
+	// Small objects are read whole and kept in the local cache
+	data, err := io.ReadAll(object)
+	if err != nil {
+		h.logger.Error("Failed to read object", zap.String("object_name", objectName), zap.Error(err))
+		h.fail(w, http.StatusBadGateway, "error")
+		return
+	}
+	meta.data = data
+	h.cache.Put(meta, generation)
+	h.serve(w, r, meta, remaining, bytes.NewReader(data))
+}
+
+// serve writes an object with its headers, ranges and conditional requests are handled by http.ServeContent
+func (h *OriginHandler) serve(w http.ResponseWriter, r *http.Request, obj *cachedObject, remaining time.Duration, content io.ReadSeeker) {
+	header := w.Header()
+	header.Set("Content-Type", obj.contentType)
+	header.Set("Cache-Control", cacheControl(obj.cacheControl, remaining))
+	if obj.etag != "" {
+		header.Set("ETag", fmt.Sprintf("%q", obj.etag))
+	}
+
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	http.ServeContent(sw, r, "", obj.lastModified, content)
+
+	originBytesTotal.Add(float64(sw.written))
+	originRequestsTotal.WithLabelValues(resultLabel(sw.status)).Inc()
+}
+
+// fail writes an uncacheable error response
+func (h *OriginHandler) fail(w http.ResponseWriter, code int, result string) {
+	w.Header().Set("Cache-Control", "no-store")
+	http.Error(w, http.StatusText(code), code)
+	originRequestsTotal.WithLabelValues(result).Inc()
+}
+
+// cacheControl lets caches keep a response no longer than its URL is valid.
+// Objects stored as immutable stay immutable.
+func cacheControl(stored string, remaining time.Duration) string {
+	maxAge := int64(min(remaining, maxCacheAge) / time.Second)
+	value := fmt.Sprintf("public, max-age=%d", maxAge)
+	if strings.Contains(stored, "immutable") {
+		value += ", immutable"
+	}
+	return value
+}
+
+func resultLabel(code int) string {
+	switch code {
+	case http.StatusOK:
+		return "ok"
+	case http.StatusPartialContent:
+		return "partial"
+	case http.StatusNotModified:
+		return "not_modified"
+	case http.StatusRequestedRangeNotSatisfiable:
+		return "range_not_satisfiable"
+	default:
+		return "error"
+	}
+}
+
+// statusWriter records the status code and body size of a response
+type statusWriter struct {
+	http.ResponseWriter
+	status  int
+	written int64
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
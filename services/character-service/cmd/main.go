@@ -16,11 +16,15 @@ import (
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
 	pb "github.com/sdshorin/generia/api/grpc/character"
 	mediapb "github.com/sdshorin/generia/api/grpc/media"
+	"github.com/sdshorin/generia/pkg/cache"
 	"github.com/sdshorin/generia/pkg/config"
 	"github.com/sdshorin/generia/pkg/database"
 	"github.com/sdshorin/generia/pkg/discovery"
+	"github.com/sdshorin/generia/pkg/events"
+	"github.com/sdshorin/generia/pkg/kafka"
 	"github.com/sdshorin/generia/pkg/logger"
 	"github.com/sdshorin/generia/services/character-service/internal/repository"
 	"github.com/sdshorin/generia/services/character-service/internal/service"
@@ -53,6 +57,33 @@ func createMediaClient(discoveryClient discovery.ServiceDiscovery) (*grpc.Client
 	return conn, client, nil
 }
 
+func createCacheClient(discoveryClient discovery.ServiceDiscovery) (*grpc.ClientConn, cachepb.CacheServiceClient, error) {
+	// Get service address from Consul
+	serviceAddress, err := discoveryClient.ResolveService("cache-service")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve cache service: %w", err)
+	}
+
+	// Create gRPC connection
+	conn, err := grpc.Dial(
+		serviceAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                10 * time.Second,
+			Timeout:             time.Second,
+			PermitWithoutStream: true,
+		}),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to cache service: %w", err)
+	}
+
+	// Create client
+	client := cachepb.NewCacheServiceClient(conn)
+
+	return conn, client, nil
+}
+
 func main() {
 	// Initialize logger
 	if err := logger.InitDevelopment(); err != nil {
@@ -97,8 +128,21 @@ func main() {
 	}
 	defer mediaConn.Close()
 
+	// Initialize event publisher
+	eventPublisher := events.NewKafkaPublisher(kafka.NewProducer(cfg.Kafka.Brokers))
+	defer eventPublisher.Close()
+
+	// The cache is optional: without cache-service characters are loaded on every request
+	cacheConn, cacheClient, err := createCacheClient(discoveryClient)
+	if err != nil {
+		logger.Logger.Warn("Cache service is unavailable, caching is disabled", zap.Error(err))
+	} else {
+		defer cacheConn.Close()
+	}
+	characterCache := cache.New(cacheClient, "character-service", cache.Config{})
+
 	// Initialize service
-	characterService := service.NewCharacterService(characterRepo, mediaClient)
+	characterService := service.NewCharacterService(characterRepo, mediaClient, eventPublisher, characterCache)
 
 	// Get port from config or environment
 	// Using port 8089 as specified in docker-compose.yml
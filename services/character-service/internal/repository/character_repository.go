@@ -12,6 +12,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrCharacterNotFound is returned when no character has the requested ID
+var ErrCharacterNotFound = errors.New("character not found")
+
 type CharacterRepository interface {
 	CreateCharacter(ctx context.Context, params models.CreateCharacterParams) (*models.Character, error)
 	UpdateCharacter(ctx context.Context, params models.UpdateCharacterParams) (*models.Character, error)
@@ -85,7 +88,7 @@ func (r *characterRepository) GetCharacter(ctx context.Context, id string) (*mod
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("character not found")
+			return nil, ErrCharacterNotFound
 		}
 		logger.Logger.Error("Failed to get character", zap.Error(err))
 		return nil, err
@@ -146,7 +149,7 @@ func (r *characterRepository) UpdateCharacter(ctx context.Context, params models
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("character not found")
+			return nil, ErrCharacterNotFound
 		}
 		logger.Logger.Error("Failed to update character", zap.Error(err))
 		return nil, err
@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"time"
 
 	pb "github.com/sdshorin/generia/api/grpc/character"
 	mediapb "github.com/sdshorin/generia/api/grpc/media"
+	"github.com/sdshorin/generia/pkg/cache"
+	"github.com/sdshorin/generia/pkg/events"
 	"github.com/sdshorin/generia/pkg/logger"
 	"github.com/sdshorin/generia/services/character-service/internal/models"
 	"github.com/sdshorin/generia/services/character-service/internal/repository"
@@ -19,14 +22,18 @@ import (
 
 type CharacterService struct {
 	pb.UnimplementedCharacterServiceServer
-	repo        repository.CharacterRepository
-	mediaClient mediapb.MediaServiceClient
+	repo           repository.CharacterRepository
+	mediaClient    mediapb.MediaServiceClient
+	eventPublisher events.Publisher
+	cache          *cache.Cache
 }
 
-func NewCharacterService(repo repository.CharacterRepository, mediaClient mediapb.MediaServiceClient) *CharacterService {
+func NewCharacterService(repo repository.CharacterRepository, mediaClient mediapb.MediaServiceClient, eventPublisher events.Publisher, characterCache *cache.Cache) *CharacterService {
 	return &CharacterService{
-		repo:        repo,
-		mediaClient: mediaClient,
+		repo:           repo,
+		mediaClient:    mediaClient,
+		eventPublisher: eventPublisher,
+		cache:          characterCache,
 	}
 }
 
@@ -64,18 +71,44 @@ func (s *CharacterService) CreateCharacter(ctx context.Context, req *pb.CreateCh
 		return nil, status.Error(codes.Internal, "Failed to create character")
 	}
 
+	events.Emit(ctx, s.eventPublisher, events.CharacterCreated, character.WorldID,
+		events.Actor{UserID: character.RealUserID.String, CharacterID: character.ID},
+		events.CharacterCreatedPayload{
+			CharacterID:   character.ID,
+			RealUserID:    character.RealUserID.String,
+			IsAI:          character.IsAI,
+			DisplayName:   character.DisplayName,
+			AvatarMediaID: character.AvatarMediaID.String,
+			CreatedAt:     character.CreatedAt,
+		})
+
 	return characterModelToProto(character), nil
 }
 
+// GetCharacter returns a character with its avatar URL, cached since every post render needs it
 func (s *CharacterService) GetCharacter(ctx context.Context, req *pb.GetCharacterRequest) (*pb.Character, error) {
 	logger.Logger.Info("Getting character", zap.String("id", req.CharacterId))
 
-	character, err := s.repo.GetCharacter(ctx, req.CharacterId)
+	protoCharacter, err := cache.GetOrLoad(ctx, s.cache, characterCacheKey(req.CharacterId), cache.Proto[*pb.Character](), func(ctx context.Context) (*pb.Character, error) {
+		character, err := s.repo.GetCharacter(ctx, req.CharacterId)
+		if errors.Is(err, repository.ErrCharacterNotFound) {
+			return nil, cache.ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		return s.characterWithAvatar(ctx, character), nil
+	})
 	if err != nil {
 		logger.Logger.Error("Failed to get character", zap.Error(err))
 		return nil, status.Error(codes.NotFound, "Character not found")
 	}
 
+	return protoCharacter, nil
+}
+
+// characterWithAvatar converts a character to proto and fills in its avatar URL
+func (s *CharacterService) characterWithAvatar(ctx context.Context, character *models.Character) *pb.Character {
 	// Convert character model to proto
 	protoCharacter := characterModelToProto(character)
 
@@ -100,7 +133,7 @@ func (s *CharacterService) GetCharacter(ctx context.Context, req *pb.GetCharacte
 		}
 	}
 
-	return protoCharacter, nil
+	return protoCharacter
 }
 
 func (s *CharacterService) UpdateCharacter(ctx context.Context, req *pb.UpdateCharacterRequest) (*pb.Character, error) {
@@ -137,6 +170,7 @@ func (s *CharacterService) UpdateCharacter(ctx context.Context, req *pb.UpdateCh
 		logger.Logger.Error("Failed to update character", zap.Error(err))
 		return nil, status.Error(codes.Internal, "Failed to update character")
 	}
+	s.cache.Delete(ctx, characterCacheKey(character.ID))
 
 	// Convert character model to proto
 	protoCharacter := characterModelToProto(character)
@@ -223,6 +257,10 @@ func (s *CharacterService) HealthCheck(ctx context.Context, req *pb.HealthCheckR
 }
 
 // Helper functions
+func characterCacheKey(characterID string) string {
+	return "character:" + characterID
+}
+
 func characterModelToProto(character *models.Character) *pb.Character {
 	protoCharacter := &pb.Character{
 		Id:          character.ID,
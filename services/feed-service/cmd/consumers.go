@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+
+	"github.com/sdshorin/generia/pkg/events"
+	"github.com/sdshorin/generia/pkg/kafka"
+)
+
+// consumerGroup is the Kafka consumer group of the feed service
+const consumerGroup = "feed-service"
+
+// Affinity a user gains to a character per interaction with its posts
+const (
+	likeAffinity    = 1.0
+	commentAffinity = 2.0
+)
+
+// startConsumers subscribes the materialized timelines to domain events
+func startConsumers(brokers []string, store *TimelineStore) []*kafka.Consumer {
+	consumers := []*kafka.Consumer{
+		events.NewConsumer(brokers, consumerGroup, events.PostCreated,
+			events.Handler(func(ctx context.Context, event *events.Event, payload events.PostCreatedPayload) error {
+				keys := []string{worldTimelineKey(event.WorldID), characterTimelineKey(payload.CharacterID)}
+				if event.Actor.UserID != "" {
+					keys = append(keys, userTimelineKey(event.Actor.UserID))
+				}
+				err := store.SetPostMeta(ctx, payload.PostID, PostMeta{
+					WorldID:     event.WorldID,
+					UserID:      event.Actor.UserID,
+					CharacterID: payload.CharacterID,
+				})
+				if err != nil {
+					return err
+				}
+				return store.Add(ctx, payload.PostID, payload.CreatedAt, keys...)
+			})),
+		events.NewConsumer(brokers, consumerGroup, events.PostDeleted,
+			events.Handler(func(ctx context.Context, event *events.Event, payload events.PostDeletedPayload) error {
+				_, err := store.RemovePost(ctx, payload.PostID)
+				return err
+			})),
+		events.NewConsumer(brokers, consumerGroup, events.LikeAdded,
+			events.Handler(func(ctx context.Context, event *events.Event, payload events.LikeAddedPayload) error {
+				_, err := store.ApplyInteraction(ctx, Interaction{
+					EventID: event.ID,
+					UserID:  payload.UserID,
+					PostID:  payload.PostID,
+					Counter: "likes_count",
+					Value:   int64(payload.LikesCount),
+					Weight:  likeAffinity,
+				})
+				return err
+			})),
+		events.NewConsumer(brokers, consumerGroup, events.CommentAdded,
+			events.Handler(func(ctx context.Context, event *events.Event, payload events.CommentAddedPayload) error {
+				_, err := store.ApplyInteraction(ctx, Interaction{
+					EventID: event.ID,
+					UserID:  payload.UserID,
+					PostID:  payload.PostID,
+					Counter: "comments_count",
+					Incr:    true,
+					Value:   1,
+					Weight:  commentAffinity,
+				})
+				return err
+			})),
+	}
+
+	for _, consumer := range consumers {
+		consumer.Start()
+	}
+	return consumers
+}
@@ -10,6 +10,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/go-redis/redis/v8"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
@@ -26,15 +27,18 @@ import (
 	// semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 
 	authpb "github.com/sdshorin/generia/api/grpc/auth"
 	characterpb "github.com/sdshorin/generia/api/grpc/character"
 	feedpb "github.com/sdshorin/generia/api/grpc/feed"
+	interactionpb "github.com/sdshorin/generia/api/grpc/interaction"
 	mediapb "github.com/sdshorin/generia/api/grpc/media"
 	postpb "github.com/sdshorin/generia/api/grpc/post"
 )
@@ -42,115 +46,528 @@ import (
 // FeedService implements the feed service
 type FeedService struct {
 	feedpb.UnimplementedFeedServiceServer
-	logger          *zap.Logger
-	authClient      authpb.AuthServiceClient
-	postClient      postpb.PostServiceClient
-	mediaClient     mediapb.MediaServiceClient
-	characterClient characterpb.CharacterServiceClient
+	logger            *zap.Logger
+	authClient        authpb.AuthServiceClient
+	postClient        postpb.PostServiceClient
+	mediaClient       mediapb.MediaServiceClient
+	characterClient   characterpb.CharacterServiceClient
+	interactionClient interactionpb.InteractionServiceClient
+	timelines         *TimelineStore
+	rankers           *RankerRegistry
 }
 
-// GetUserFeed implements the GetUserFeed method
+const (
+	// backfillPageSize is the page size used to load posts from post-service
+	backfillPageSize = 100
+	// rankCandidates is the number of newest posts considered by the ranked feed
+	rankCandidates = 200
+	// backfillWait bounds how long a read waits for a backfill started by another request
+	backfillWait = 5 * time.Second
+	// backfillPollInterval is how often a waiting read checks whether the backfill is done
+	backfillPollInterval = 100 * time.Millisecond
+)
+
+// GetUserFeed implements the GetUserFeed method.
+// Posts are served from a materialized timeline: a world timeline if world_id
+// is set, otherwise the timeline of posts made by user_id.
 func (s *FeedService) GetUserFeed(ctx context.Context, req *feedpb.GetUserFeedRequest) (*feedpb.GetUserFeedResponse, error) {
 	s.logger.Info("GetUserFeed called",
 		zap.String("user_id", req.UserId),
+		zap.String("world_id", req.WorldId),
 		zap.String("requesting_user_id", req.RequestingUserId),
 		zap.Int32("limit", req.Limit),
 		zap.String("cursor", req.Cursor))
 
 	// Default limit if not provided
-	limit := req.Limit
+	limit := int(req.Limit)
 	if limit <= 0 {
 		limit = 10
 	}
+	if limit > 100 {
+		limit = 100
+	}
 
-	// Get user posts from post service
-	userPostsResp, err := s.postClient.GetUserPosts(ctx, &postpb.GetUserPostsRequest{
-		UserId: req.UserId,
-		Limit:  limit,
-		Offset: 0, // We'll need to implement cursor-based pagination
-	})
+	var key string
+	var backfill func(ctx context.Context) ([]*postpb.Post, error)
+	switch {
+	case req.WorldId != "":
+		key = worldTimelineKey(req.WorldId)
+		backfill = func(ctx context.Context) ([]*postpb.Post, error) { return s.loadWorldPosts(ctx, req.WorldId) }
+	case req.UserId != "":
+		key = userTimelineKey(req.UserId)
+		backfill = func(ctx context.Context) ([]*postpb.Post, error) { return s.loadUserPosts(ctx, req.UserId) }
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "world_id or user_id is required")
+	}
+
+	var cursor *feedCursor
+	if req.Cursor != "" {
+		var err error
+		cursor, err = decodeCursor(req.Cursor)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid cursor")
+		}
+	}
+
+	if err := s.ensureTimeline(ctx, key, backfill); err != nil {
+		s.logger.Error("Failed to build timeline", zap.Error(err), zap.String("key", key))
+		return nil, status.Errorf(codes.Internal, "failed to build feed")
+	}
+
+	if req.Mode == feedpb.GetUserFeedRequest_FOR_YOU {
+		return s.getRankedFeed(ctx, req, key, cursor, limit)
+	}
+	if cursor != nil && cursor.PostID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "cursor belongs to a ranked feed")
+	}
+
+	postIDs, next, err := s.timelines.Page(ctx, key, cursor, limit)
+	if err != nil {
+		s.logger.Error("Failed to read timeline", zap.Error(err), zap.String("key", key))
+		return nil, status.Errorf(codes.Internal, "failed to read feed")
+	}
+
+	entries, err := s.getEntries(ctx, key, postIDs)
 	if err != nil {
-		s.logger.Error("Failed to get user posts", zap.Error(err))
-		return nil, fmt.Errorf("failed to get user posts: %w", err)
+		s.logger.Error("Failed to load feed posts", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to load feed posts")
+	}
+
+	nextCursor := ""
+	if next != nil {
+		nextCursor = encodeCursor(*next)
 	}
 
-	// Transform posts into feed posts
-	feedPosts := make([]*feedpb.PostInfo, 0, len(userPostsResp.Posts))
-	for _, post := range userPostsResp.Posts {
-		// Parse time string to Unix timestamp
-		createdTime, err := time.Parse(time.RFC3339, post.CreatedAt)
+	return &feedpb.GetUserFeedResponse{
+		Posts:      toPostInfos(postIDs, entries, s.likedPosts(ctx, req.RequestingUserId, postIDs)),
+		NextCursor: nextCursor,
+		HasMore:    next != nil,
+	}, nil
+}
+
+// getRankedFeed serves the "For You" feed. The newest rankCandidates posts of
+// the timeline are ranked once per first page; later pages are read from a
+// snapshot of that ranking, so posts do not repeat or move between pages.
+func (s *FeedService) getRankedFeed(ctx context.Context, req *feedpb.GetUserFeedRequest, key string, cursor *feedCursor, limit int) (*feedpb.GetUserFeedResponse, error) {
+	var snapshotID string
+	var offset, total int
+	var postIDs []string
+	var entries map[string]*FeedEntry
+
+	if cursor != nil {
+		if cursor.Snapshot == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "cursor belongs to a chronological feed")
+		}
+
+		var err error
+		snapshotID, offset = cursor.Snapshot, cursor.Offset
+		postIDs, total, err = s.timelines.GetSnapshot(ctx, snapshotID, offset, limit)
 		if err != nil {
-			s.logger.Warn("Failed to parse created time",
-				zap.Error(err),
-				zap.String("time_str", post.CreatedAt))
-			createdTime = time.Now() // Fallback to current time
+			s.logger.Error("Failed to read ranked feed", zap.Error(err), zap.String("snapshot", snapshotID))
+			return nil, status.Errorf(codes.Internal, "failed to read feed")
+		}
+		if total == 0 {
+			return nil, status.Errorf(codes.FailedPrecondition, "feed cursor expired")
 		}
 
-		// Get character info
-		var profilePictureURL string
-		characterResp, err := s.characterClient.GetCharacter(ctx, &characterpb.GetCharacterRequest{
-			CharacterId: post.CharacterId,
-		})
-		if err == nil && characterResp.AvatarMediaId != nil && *characterResp.AvatarMediaId != "" {
-			// Get media URL for avatar
-			mediaResp, err := s.mediaClient.GetMediaURL(ctx, &mediapb.GetMediaURLRequest{
-				MediaId:   *characterResp.AvatarMediaId,
-				Variant:   "small", // Use small variant for avatars
-				ExpiresIn: 3600,    // 1 hour
-			})
-			if err == nil {
-				profilePictureURL = mediaResp.Url
+		entries, err = s.getEntries(ctx, key, postIDs)
+		if err != nil {
+			s.logger.Error("Failed to load feed posts", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "failed to load feed posts")
+		}
+	} else {
+		candidateIDs, _, err := s.timelines.Page(ctx, key, nil, rankCandidates)
+		if err != nil {
+			s.logger.Error("Failed to read timeline", zap.Error(err), zap.String("key", key))
+			return nil, status.Errorf(codes.Internal, "failed to read feed")
+		}
+
+		entries, err = s.getEntries(ctx, key, candidateIDs)
+		if err != nil {
+			s.logger.Error("Failed to load feed posts", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "failed to load feed posts")
+		}
+		s.refreshStats(ctx, entries)
+
+		viewer := Viewer{UserID: req.RequestingUserId}
+		if viewer.UserID != "" {
+			viewer.Affinity, err = s.timelines.GetAffinity(ctx, viewer.UserID, 100)
+			if err != nil {
+				s.logger.Warn("Failed to get user affinity", zap.Error(err), zap.String("user_id", viewer.UserID))
 			}
 		}
 
-		// Add post to feed
-		feedPost := &feedpb.PostInfo{
-			Id:        post.PostId,
-			Caption:   post.Caption,
-			MediaUrl:  post.MediaUrl, // MediaURL from post service already contains the fully formed URL
-			CreatedAt: createdTime.Unix(),
-			Character: &feedpb.CharacterInfo{
-				Id:                post.CharacterId,
-				DisplayName:       post.DisplayName,
-				ProfilePictureUrl: profilePictureURL,
-			},
-			Stats: &feedpb.PostStats{
-				LikesCount:    post.LikesCount,
-				CommentsCount: post.CommentsCount,
-				// Check if the requesting user liked this post (not implemented yet)
-				UserLiked: false,
-			},
+		candidates := make([]*Candidate, 0, len(candidateIDs))
+		for _, postID := range candidateIDs {
+			if entry, ok := entries[postID]; ok {
+				candidates = append(candidates, &Candidate{Entry: entry})
+			}
 		}
 
-		feedPosts = append(feedPosts, feedPost)
-	}
+		rankerName, ranker := s.rankers.ForWorld(req.WorldId)
+		ranked := ranker.Rank(ctx, viewer, candidates, time.Now())
+		rankedRequestsTotal.WithLabelValues(rankerName).Inc()
 
-	// Determine if there are more posts
-	hasMore := len(feedPosts) > 0 && int32(len(feedPosts)) >= limit
+		rankedIDs := make([]string, len(ranked))
+		for i, c := range ranked {
+			rankedIDs[i] = c.Entry.PostID
+		}
+
+		total = len(rankedIDs)
+		postIDs = rankedIDs[:min(limit, total)]
+		if total > limit {
+			snapshotID, err = s.timelines.SaveSnapshot(ctx, rankedIDs)
+			if err != nil {
+				s.logger.Error("Failed to save ranked feed", zap.Error(err))
+				return nil, status.Errorf(codes.Internal, "failed to save feed")
+			}
+		}
+	}
 
-	// In a real implementation, we'd use the cursor for pagination
-	// For now, just return an empty cursor
+	nextOffset := offset + len(postIDs)
+	hasMore := nextOffset < total
 	nextCursor := ""
-	if hasMore && len(feedPosts) > 0 {
-		// Use the last post's ID as the cursor
-		nextCursor = feedPosts[len(feedPosts)-1].Id
+	if hasMore {
+		nextCursor = encodeCursor(feedCursor{Snapshot: snapshotID, Offset: nextOffset})
 	}
 
 	return &feedpb.GetUserFeedResponse{
-		Posts:      feedPosts,
+		Posts:      toPostInfos(postIDs, entries, s.likedPosts(ctx, req.RequestingUserId, postIDs)),
 		NextCursor: nextCursor,
 		HasMore:    hasMore,
 	}, nil
 }
 
-// InvalidateFeedCache implements the InvalidateFeedCache method
+// refreshStats replaces cached counters with the current ones from the
+// interaction stats collection; cached counters are kept if it is unavailable
+func (s *FeedService) refreshStats(ctx context.Context, entries map[string]*FeedEntry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	postIDs := make([]string, 0, len(entries))
+	for postID := range entries {
+		postIDs = append(postIDs, postID)
+	}
+
+	statsResp, err := s.interactionClient.GetPostsStats(ctx, &interactionpb.GetPostsStatsRequest{
+		PostIds: postIDs,
+	})
+	if err != nil {
+		s.logger.Warn("Failed to get posts stats", zap.Error(err))
+		return
+	}
+
+	for postID, stats := range statsResp.Stats {
+		if entry, ok := entries[postID]; ok {
+			entry.LikesCount = stats.LikesCount
+			entry.CommentsCount = stats.CommentsCount
+		}
+	}
+}
+
+// likedPosts returns which of the posts the requesting user has liked, using a
+// single batched call. Errors are logged and treated as "not liked".
+func (s *FeedService) likedPosts(ctx context.Context, userID string, postIDs []string) map[string]bool {
+	liked := make(map[string]bool)
+	if userID == "" || len(postIDs) == 0 {
+		return liked
+	}
+
+	resp, err := s.interactionClient.CheckUserLikedPosts(ctx, &interactionpb.CheckUserLikedPostsRequest{
+		UserId:  userID,
+		PostIds: postIDs,
+	})
+	if err != nil {
+		s.logger.Warn("Failed to check liked posts", zap.Error(err), zap.String("user_id", userID))
+		return liked
+	}
+
+	for _, postID := range resp.LikedPostIds {
+		liked[postID] = true
+	}
+	return liked
+}
+
+// toPostInfos transforms entries into feed posts, keeping the order of postIDs
+func toPostInfos(postIDs []string, entries map[string]*FeedEntry, liked map[string]bool) []*feedpb.PostInfo {
+	feedPosts := make([]*feedpb.PostInfo, 0, len(postIDs))
+	for _, postID := range postIDs {
+		entry, ok := entries[postID]
+		if !ok {
+			continue
+		}
+
+		feedPosts = append(feedPosts, &feedpb.PostInfo{
+			Id:                 entry.PostID,
+			Caption:            entry.Caption,
+			MediaUrl:           entry.MediaURL,
+			CreatedAt:          time.UnixMilli(entry.CreatedAt).Unix(),
+			MediaWidth:         entry.MediaWidth,
+			MediaHeight:        entry.MediaHeight,
+			MediaBlurhash:      entry.MediaBlurhash,
+			MediaDominantColor: entry.MediaDominantColor,
+			IsAi:               entry.IsAI,
+			Character: &feedpb.CharacterInfo{
+				Id:                entry.CharacterID,
+				DisplayName:       entry.DisplayName,
+				ProfilePictureUrl: entry.AvatarURL,
+			},
+			Stats: &feedpb.PostStats{
+				LikesCount:    entry.LikesCount,
+				CommentsCount: entry.CommentsCount,
+				UserLiked:     liked[entry.PostID],
+			},
+		})
+	}
+
+	return feedPosts
+}
+
+// ensureTimeline backfills a timeline from post-service the first time it is read,
+// e.g. for worlds created before the feed was materialized or after a Redis flush.
+// Concurrent reads of a timeline that is not ready wait for a single backfill.
+func (s *FeedService) ensureTimeline(ctx context.Context, key string, backfill func(ctx context.Context) ([]*postpb.Post, error)) error {
+	ready, err := s.timelines.IsReady(ctx, key)
+	if err != nil || ready {
+		return err
+	}
+
+	started, err := s.timelines.StartBackfill(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !started {
+		return s.waitTimeline(ctx, key)
+	}
+	defer func() {
+		if err := s.timelines.FinishBackfill(context.WithoutCancel(ctx), key); err != nil {
+			s.logger.Warn("Failed to finish timeline backfill", zap.Error(err), zap.String("key", key))
+		}
+	}()
+
+	posts, err := backfill(ctx)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]*FeedEntry, len(posts))
+	for i, post := range posts {
+		entries[i] = postToEntry(post)
+	}
+	if err := s.timelines.Backfill(ctx, key, entries); err != nil {
+		return err
+	}
+
+	s.logger.Info("Timeline backfilled", zap.String("key", key), zap.Int("posts", len(posts)))
+	return nil
+}
+
+// waitTimeline waits for a backfill started by another request. If it takes
+// longer than backfillWait, the timeline is served as far as it is filled.
+func (s *FeedService) waitTimeline(ctx context.Context, key string) error {
+	deadline := time.Now().Add(backfillWait)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backfillPollInterval):
+		}
+
+		ready, err := s.timelines.IsReady(ctx, key)
+		if err != nil || ready {
+			return err
+		}
+	}
+	s.logger.Warn("Timeline backfill is taking long, serving it partially", zap.String("key", key))
+	return nil
+}
+
+// loadWorldPosts loads the newest posts of a world for a backfill
+func (s *FeedService) loadWorldPosts(ctx context.Context, worldID string) ([]*postpb.Post, error) {
+	var posts []*postpb.Post
+	cursor := ""
+	for len(posts) < timelineMaxLen {
+		resp, err := s.postClient.GetGlobalFeed(ctx, &postpb.GetGlobalFeedRequest{
+			Limit:   backfillPageSize,
+			Cursor:  cursor,
+			WorldId: worldID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get world posts: %w", err)
+		}
+		posts = append(posts, resp.Posts...)
+		if len(resp.Posts) < backfillPageSize || resp.NextCursor == "" {
+			break
+		}
+		cursor = resp.NextCursor
+	}
+	return posts, nil
+}
+
+// loadUserPosts loads the newest posts of a user for a backfill
+func (s *FeedService) loadUserPosts(ctx context.Context, userID string) ([]*postpb.Post, error) {
+	var posts []*postpb.Post
+	for len(posts) < timelineMaxLen {
+		resp, err := s.postClient.GetUserPosts(ctx, &postpb.GetUserPostsRequest{
+			UserId: userID,
+			Limit:  backfillPageSize,
+			Offset: int32(len(posts)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user posts: %w", err)
+		}
+		posts = append(posts, resp.Posts...)
+		if len(resp.Posts) < backfillPageSize {
+			break
+		}
+	}
+	return posts, nil
+}
+
+// getEntries returns hydrated posts from Redis. Only posts missing from the
+// cache are loaded from post-service, in a single batch; posts that no longer
+// exist are dropped from the timeline.
+func (s *FeedService) getEntries(ctx context.Context, key string, postIDs []string) (map[string]*FeedEntry, error) {
+	if len(postIDs) == 0 {
+		return map[string]*FeedEntry{}, nil
+	}
+
+	entries, err := s.timelines.GetEntries(ctx, postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, postID := range postIDs {
+		if _, ok := entries[postID]; !ok {
+			missing = append(missing, postID)
+		}
+	}
+	if len(missing) == 0 {
+		return entries, nil
+	}
+
+	// Removed posts must not be loaded back into the cache
+	removed, err := s.timelines.Removed(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	toLoad := make([]string, 0, len(missing))
+	for _, postID := range missing {
+		if !removed[postID] {
+			toLoad = append(toLoad, postID)
+		}
+	}
+
+	resp := &postpb.PostList{}
+	if len(toLoad) > 0 {
+		resp, err = s.postClient.GetPostsByIds(ctx, &postpb.GetPostsByIdsRequest{
+			PostIds: toLoad,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get posts by IDs: %w", err)
+		}
+	}
+
+	loaded := make([]*FeedEntry, 0, len(resp.Posts))
+	for _, post := range resp.Posts {
+		entry := postToEntry(post)
+		entries[entry.PostID] = entry
+		loaded = append(loaded, entry)
+	}
+	if err := s.timelines.SaveEntries(ctx, loaded); err != nil {
+		s.logger.Warn("Failed to cache feed posts", zap.Error(err))
+	}
+
+	var gone []string
+	for _, postID := range missing {
+		if _, ok := entries[postID]; !ok {
+			gone = append(gone, postID)
+		}
+	}
+	if err := s.timelines.Remove(ctx, key, gone...); err != nil {
+		s.logger.Warn("Failed to remove deleted posts from timeline", zap.Error(err))
+	}
+
+	return entries, nil
+}
+
+// postToEntry converts a post-service post into a feed entry
+func postToEntry(post *postpb.Post) *FeedEntry {
+	// Parse time string to Unix timestamp
+	createdTime, err := time.Parse(time.RFC3339, post.CreatedAt)
+	if err != nil {
+		logger.Logger.Warn("Failed to parse created time",
+			zap.Error(err),
+			zap.String("time_str", post.CreatedAt))
+		createdTime = time.Now() // Fallback to current time
+	}
+
+	return &FeedEntry{
+		PostID:             post.PostId,
+		WorldID:            post.WorldId,
+		CharacterID:        post.CharacterId,
+		DisplayName:        post.DisplayName,
+		AvatarURL:          post.AvatarUrl,
+		Caption:            post.Caption,
+		MediaURL:           post.MediaUrl, // MediaURL from post service already contains the fully formed URL
+		MediaWidth:         post.MediaWidth,
+		MediaHeight:        post.MediaHeight,
+		MediaBlurhash:      post.MediaBlurhash,
+		MediaDominantColor: post.MediaDominantColor,
+		IsAI:               post.IsAi,
+		CreatedAt:          createdTime.UnixMilli(),
+		LikesCount:         post.LikesCount,
+		CommentsCount:      post.CommentsCount,
+	}
+}
+
+// InvalidateFeedCache implements the InvalidateFeedCache method.
+// POST removes a deleted or moderated post from every feed; USER and WORLD drop
+// the timeline and its cached posts, so it is rebuilt on the next read;
+// CHARACTER evicts the cached posts of a character; ALL drops every timeline and cached post.
 func (s *FeedService) InvalidateFeedCache(ctx context.Context, req *feedpb.InvalidateFeedCacheRequest) (*feedpb.InvalidateFeedCacheResponse, error) {
-	// Placeholder implementation
 	s.logger.Info("InvalidateFeedCache called",
 		zap.String("type", req.Type.String()),
 		zap.String("id", req.Id))
+
+	if req.Type != feedpb.InvalidateFeedCacheRequest_ALL && req.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "id is required")
+	}
+
+	var removed int
+	var err error
+	switch req.Type {
+	case feedpb.InvalidateFeedCacheRequest_ALL:
+		removed, err = s.timelines.EvictAll(ctx)
+	case feedpb.InvalidateFeedCacheRequest_POST:
+		removed, err = s.timelines.RemovePost(ctx, req.Id)
+	case feedpb.InvalidateFeedCacheRequest_USER:
+		removed, err = s.timelines.EvictTimeline(ctx, userTimelineKey(req.Id), true)
+	case feedpb.InvalidateFeedCacheRequest_CHARACTER:
+		removed, err = s.timelines.EvictTimeline(ctx, characterTimelineKey(req.Id), false)
+	case feedpb.InvalidateFeedCacheRequest_WORLD:
+		removed, err = s.timelines.EvictTimeline(ctx, worldTimelineKey(req.Id), true)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown invalidation type")
+	}
+	if err != nil {
+		s.logger.Error("Failed to invalidate feed cache",
+			zap.Error(err),
+			zap.String("type", req.Type.String()),
+			zap.String("id", req.Id))
+		return nil, status.Errorf(codes.Internal, "failed to invalidate feed cache")
+	}
+
+	s.logger.Info("Feed cache invalidated",
+		zap.String("type", req.Type.String()),
+		zap.String("id", req.Id),
+		zap.Int("removed", removed))
+
 	return &feedpb.InvalidateFeedCacheResponse{
-		Success: true,
+		Success:      true,
+		RemovedCount: int32(removed),
 	}, nil
 }
 
@@ -227,13 +644,44 @@ func main() {
 	}
 	defer characterConn.Close()
 
+	interactionConn, interactionClient, err := createInteractionClient(discoveryClient)
+	if err != nil {
+		logger.Logger.Fatal("Failed to create interaction client", zap.Error(err))
+	}
+	defer interactionConn.Close()
+
+	// Initialize Redis client
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Address,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	defer redisClient.Close()
+
+	// Ping Redis to verify connection
+	pingCtx, pingCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer pingCancel()
+
+	if err := redisClient.Ping(pingCtx).Err(); err != nil {
+		logger.Logger.Fatal("Failed to connect to Redis", zap.Error(err))
+	}
+	logger.Logger.Info("Connected to Redis", zap.String("address", cfg.Redis.Address))
+
+	timelines := NewTimelineStore(redisClient)
+
+	// Keep timelines up to date from domain events
+	consumers := startConsumers(cfg.Kafka.Brokers, timelines)
+
 	// Initialize feed service
 	feedService := &FeedService{
-		logger:          logger.Logger,
-		authClient:      authClient,
-		postClient:      postClient,
-		mediaClient:     mediaClient,
-		characterClient: characterClient,
+		logger:            logger.Logger,
+		authClient:        authClient,
+		postClient:        postClient,
+		mediaClient:       mediaClient,
+		characterClient:   characterClient,
+		interactionClient: interactionClient,
+		timelines:         timelines,
+		rankers:           NewRankerRegistry(os.Getenv("FEED_RANKERS")),
 	}
 
 	// Create gRPC server with middleware
@@ -289,6 +737,11 @@ func main() {
 
 	logger.Logger.Info("Shutting down feed service...")
 	grpcServer.GracefulStop()
+	for _, consumer := range consumers {
+		if err := consumer.Close(); err != nil {
+			logger.Logger.Error("Failed to close consumer", zap.Error(err))
+		}
+	}
 	logger.Logger.Info("Feed service stopped")
 }
 
@@ -403,3 +856,31 @@ func createCharacterClient(discoveryClient discovery.ServiceDiscovery) (*grpc.Cl
 
 	return conn, client, nil
 }
+
+func createInteractionClient(discoveryClient discovery.ServiceDiscovery) (*grpc.ClientConn, interactionpb.InteractionServiceClient, error) {
+	// Get service address from Consul
+	serviceAddress, err := discoveryClient.ResolveService("interaction-service")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve interaction service: %w", err)
+	}
+
+	// Create gRPC connection
+	conn, err := grpc.Dial(
+		serviceAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                10 * time.Second,
+			Timeout:             time.Second,
+			PermitWithoutStream: true,
+		}),
+		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to interaction service: %w", err)
+	}
+
+	// Create client
+	client := interactionpb.NewInteractionServiceClient(conn)
+
+	return conn, client, nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var rankedRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "feed_ranked_requests_total",
+	Help: "Number of ranked feeds computed by ranker",
+}, []string{"ranker"})
+
+// Candidate is a post considered for the ranked feed
+type Candidate struct {
+	Entry *FeedEntry
+	Score float64
+}
+
+// Viewer describes the user the feed is ranked for
+type Viewer struct {
+	UserID string
+	// Affinity maps character IDs to how much the user interacted with them
+	Affinity map[string]float64
+}
+
+// Ranker orders candidate posts for the "For You" feed.
+// Implementations must not drop candidates.
+type Ranker interface {
+	Rank(ctx context.Context, viewer Viewer, candidates []*Candidate, now time.Time) []*Candidate
+}
+
+// RankerWeights configures a WeightedRanker
+type RankerWeights struct {
+	Recency         float64       // Weight of the exponential recency decay
+	RecencyHalfLife time.Duration // Age at which the recency component halves
+	Velocity        float64       // Weight of likes and comments per hour since creation
+	LikeWeight      float64       // Engagement contributed by a like
+	CommentWeight   float64       // Engagement contributed by a comment
+	Affinity        float64       // Weight of the viewer's affinity to the post's character
+	DiversityWindow int           // A character appears at most once in this many consecutive posts
+}
+
+// WeightedRanker scores posts by a weighted sum of recency decay, engagement
+// velocity and character affinity, then reorders them for diversity
+type WeightedRanker struct {
+	weights RankerWeights
+}
+
+// NewWeightedRanker creates a new WeightedRanker
+func NewWeightedRanker(weights RankerWeights) *WeightedRanker {
+	if weights.RecencyHalfLife <= 0 {
+		weights.RecencyHalfLife = 12 * time.Hour
+	}
+	return &WeightedRanker{weights: weights}
+}
+
+// Rank implements Ranker
+func (r *WeightedRanker) Rank(ctx context.Context, viewer Viewer, candidates []*Candidate, now time.Time) []*Candidate {
+	var maxAffinity float64
+	for _, v := range viewer.Affinity {
+		maxAffinity = math.Max(maxAffinity, v)
+	}
+
+	for _, c := range candidates {
+		ageHours := math.Max(now.Sub(time.UnixMilli(c.Entry.CreatedAt)).Hours(), 0)
+		recency := math.Pow(0.5, ageHours/r.weights.RecencyHalfLife.Hours())
+
+		engagement := float64(c.Entry.LikesCount)*r.weights.LikeWeight + float64(c.Entry.CommentsCount)*r.weights.CommentWeight
+		velocity := math.Log1p(engagement / math.Max(ageHours, 1))
+
+		var affinity float64
+		if maxAffinity > 0 {
+			affinity = viewer.Affinity[c.Entry.CharacterID] / maxAffinity
+		}
+
+		c.Score = r.weights.Recency*recency + r.weights.Velocity*velocity + r.weights.Affinity*affinity
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	return diversify(candidates, r.weights.DiversityWindow)
+}
+
+// diversify greedily picks the best candidate whose character did not appear
+// in the previous window-1 picks; if all remaining candidates are blocked,
+// the best one is taken anyway
+func diversify(sorted []*Candidate, window int) []*Candidate {
+	if window <= 1 {
+		return sorted
+	}
+
+	result := make([]*Candidate, 0, len(sorted))
+	remaining := append([]*Candidate(nil), sorted...)
+	for len(remaining) > 0 {
+		pick := 0
+		for i, c := range remaining {
+			if !recentlyPicked(result, c.Entry.CharacterID, window-1) {
+				pick = i
+				break
+			}
+		}
+		result = append(result, remaining[pick])
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+	}
+	return result
+}
+
+func recentlyPicked(picked []*Candidate, characterID string, n int) bool {
+	for i := len(picked) - 1; i >= 0 && i >= len(picked)-n; i-- {
+		if picked[i].Entry.CharacterID == characterID {
+			return true
+		}
+	}
+	return false
+}
+
+// Built-in rankers
+const (
+	defaultRanker = "weighted"
+	recencyRanker = "recency"
+)
+
+// RankerRegistry selects the ranker of a world, so rankers can be A/B tested per world
+type RankerRegistry struct {
+	rankers     map[string]Ranker
+	assignments map[string]string
+}
+
+// NewRankerRegistry creates a registry with the built-in rankers.
+// Assignments have the form "<world_id>=<ranker>,<world_id>=<ranker>".
+func NewRankerRegistry(assignments string) *RankerRegistry {
+	registry := &RankerRegistry{
+		rankers: map[string]Ranker{
+			defaultRanker: NewWeightedRanker(RankerWeights{
+				Recency:         1.0,
+				RecencyHalfLife: 12 * time.Hour,
+				Velocity:        0.5,
+				LikeWeight:      1.0,
+				CommentWeight:   2.0,
+				Affinity:        0.3,
+				DiversityWindow: 3,
+			}),
+			recencyRanker: NewWeightedRanker(RankerWeights{
+				Recency:         1.0,
+				RecencyHalfLife: 12 * time.Hour,
+				DiversityWindow: 3,
+			}),
+		},
+		assignments: make(map[string]string),
+	}
+
+	for _, pair := range strings.Split(assignments, ",") {
+		worldID, name, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		registry.assignments[worldID] = name
+	}
+
+	return registry
+}
+
+// Register adds or replaces a ranker
+func (r *RankerRegistry) Register(name string, ranker Ranker) {
+	r.rankers[name] = ranker
+}
+
+// ForWorld returns the ranker assigned to a world and its name
+func (r *RankerRegistry) ForWorld(worldID string) (string, Ranker) {
+	if name, ok := r.assignments[worldID]; ok {
+		if ranker, ok := r.rankers[name]; ok {
+			return name, ranker
+		}
+	}
+	return defaultRanker, r.rankers[defaultRanker]
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+// candidate builds a candidate of a post created age before now
+func candidate(postID, characterID string, now time.Time, age time.Duration, likes, comments int32) *Candidate {
+	return &Candidate{Entry: &FeedEntry{
+		PostID:        postID,
+		CharacterID:   characterID,
+		CreatedAt:     now.Add(-age).UnixMilli(),
+		LikesCount:    likes,
+		CommentsCount: comments,
+	}}
+}
+
+func postIDs(candidates []*Candidate) []string {
+	ids := make([]string, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.Entry.PostID
+	}
+	return ids
+}
+
+func TestWeightedRankerOrder(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		weights    RankerWeights
+		affinity   map[string]float64
+		candidates []*Candidate
+		want       []string
+	}{
+		{
+			name:    "newer posts first",
+			weights: RankerWeights{Recency: 1},
+			candidates: []*Candidate{
+				candidate("old", "a", now, 30*time.Hour, 0, 0),
+				candidate("new", "b", now, time.Hour, 0, 0),
+				candidate("mid", "c", now, 10*time.Hour, 0, 0),
+			},
+			want: []string{"new", "mid", "old"},
+		},
+		{
+			name:    "posts from the future count as new",
+			weights: RankerWeights{Recency: 1},
+			candidates: []*Candidate{
+				candidate("old", "a", now, time.Hour, 0, 0),
+				candidate("skewed", "b", now, -time.Minute, 0, 0),
+			},
+			want: []string{"skewed", "old"},
+		},
+		{
+			name:    "comments weigh more than likes",
+			weights: RankerWeights{Velocity: 1, LikeWeight: 1, CommentWeight: 3},
+			candidates: []*Candidate{
+				candidate("liked", "a", now, time.Hour, 5, 0),
+				candidate("quiet", "b", now, time.Hour, 0, 0),
+				candidate("discussed", "c", now, time.Hour, 0, 2),
+			},
+			want: []string{"discussed", "liked", "quiet"},
+		},
+		{
+			name:    "engagement per hour favors fresh posts",
+			weights: RankerWeights{Velocity: 1, LikeWeight: 1},
+			candidates: []*Candidate{
+				candidate("slow", "a", now, 20*time.Hour, 40, 0),
+				candidate("fast", "b", now, 2*time.Hour, 10, 0),
+			},
+			want: []string{"fast", "slow"},
+		},
+		{
+			name:     "affinity to the character",
+			weights:  RankerWeights{Affinity: 1},
+			affinity: map[string]float64{"a": 1, "b": 10},
+			candidates: []*Candidate{
+				candidate("unknown", "c", now, time.Hour, 0, 0),
+				candidate("liked", "a", now, time.Hour, 0, 0),
+				candidate("favorite", "b", now, time.Hour, 0, 0),
+			},
+			want: []string{"favorite", "liked", "unknown"},
+		},
+		{
+			name:    "equal scores keep the candidate order",
+			weights: RankerWeights{Recency: 1},
+			candidates: []*Candidate{
+				candidate("first", "a", now, time.Hour, 0, 0),
+				candidate("second", "b", now, time.Hour, 0, 0),
+			},
+			want: []string{"first", "second"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranker := NewWeightedRanker(tt.weights)
+			ranked := ranker.Rank(context.Background(), Viewer{UserID: "user", Affinity: tt.affinity}, tt.candidates, now)
+			if got := postIDs(ranked); !slices.Equal(got, tt.want) {
+				t.Errorf("order = %q, want %q", got, tt.want)
+			}
+			for i := 1; i < len(ranked); i++ {
+				if ranked[i].Score > ranked[i-1].Score {
+					t.Errorf("score of %s (%v) exceeds score of %s (%v)", ranked[i].Entry.PostID, ranked[i].Score, ranked[i-1].Entry.PostID, ranked[i-1].Score)
+				}
+			}
+		})
+	}
+}
+
+func TestDiversify(t *testing.T) {
+	// sorted builds candidates in score order from "<post>:<character>" pairs
+	sorted := func(pairs ...string) []*Candidate {
+		candidates := make([]*Candidate, len(pairs))
+		for i, pair := range pairs {
+			candidates[i] = &Candidate{Entry: &FeedEntry{PostID: pair[:2], CharacterID: pair[3:]}}
+		}
+		return candidates
+	}
+
+	tests := []struct {
+		name       string
+		candidates []*Candidate
+		window     int
+		want       []string
+	}{
+		{
+			name:       "window of one keeps the order",
+			candidates: sorted("p1:a", "p2:a", "p3:b"),
+			window:     1,
+			want:       []string{"p1", "p2", "p3"},
+		},
+		{
+			name:       "a character is spaced out",
+			candidates: sorted("p1:a", "p2:a", "p3:b", "p4:c"),
+			window:     2,
+			want:       []string{"p1", "p3", "p2", "p4"},
+		},
+		{
+			name:       "at most once per window",
+			candidates: sorted("p1:a", "p2:a", "p3:a", "p4:b", "p5:c", "p6:d"),
+			window:     3,
+			want:       []string{"p1", "p4", "p5", "p2", "p6", "p3"},
+		},
+		{
+			name:       "blocked candidates are taken when nothing else is left",
+			candidates: sorted("p1:a", "p2:a", "p3:a", "p4:b"),
+			window:     3,
+			want:       []string{"p1", "p4", "p2", "p3"},
+		},
+		{
+			name:   "empty",
+			window: 3,
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := postIDs(diversify(tt.candidates, tt.window)); !slices.Equal(got, tt.want) {
+				t.Errorf("order = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fixedRanker returns candidates unchanged
+type fixedRanker struct{}
+
+func (fixedRanker) Rank(ctx context.Context, viewer Viewer, candidates []*Candidate, now time.Time) []*Candidate {
+	return candidates
+}
+
+func TestRankerRegistry(t *testing.T) {
+	registry := NewRankerRegistry("w1=recency, w2=missing,malformed,w3=custom")
+	registry.Register("custom", fixedRanker{})
+
+	tests := []struct {
+		worldID string
+		want    string
+	}{
+		{"w1", recencyRanker},
+		{"w2", defaultRanker}, // Unknown ranker falls back to the default
+		{"w3", "custom"},
+		{"unassigned", defaultRanker},
+		{"malformed", defaultRanker},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.worldID, func(t *testing.T) {
+			name, ranker := registry.ForWorld(tt.worldID)
+			if name != tt.want {
+				t.Errorf("ranker = %q, want %q", name, tt.want)
+			}
+			if ranker == nil {
+				t.Error("ranker is nil")
+			}
+		})
+	}
+
+	if _, ranker := registry.ForWorld("w3"); ranker != (fixedRanker{}) {
+		t.Errorf("registered ranker = %T, want fixedRanker", ranker)
+	}
+}
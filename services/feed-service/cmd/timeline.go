@@ -0,0 +1,586 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+const (
+	// timelineMaxLen is the number of newest posts kept in a materialized timeline
+	timelineMaxLen = 1000
+	// entryTTL must stay below the lifetime of the presigned media URLs stored in an entry
+	entryTTL = 50 * time.Minute
+	// affinityTTL is how long interactions of a user influence the ranked feed
+	affinityTTL = 30 * 24 * time.Hour
+	// snapshotTTL is how long a ranked feed can be paged through
+	snapshotTTL = 10 * time.Minute
+	// removedTTL is how long a removed post is kept out of feeds that still reference it
+	removedTTL = 24 * time.Hour
+	// processedTTL is how long redeliveries of an applied event are recognized
+	processedTTL = 24 * time.Hour
+	// backfillTTL bounds how long a crashed backfill keeps others from starting one
+	backfillTTL = 30 * time.Second
+)
+
+// Timeline keys
+func worldTimelineKey(worldID string) string         { return "feed:world:" + worldID }
+func userTimelineKey(userID string) string           { return "feed:user:" + userID }
+func characterTimelineKey(characterID string) string { return "feed:character:" + characterID }
+func readyKey(timelineKey string) string             { return timelineKey + ":ready" }
+func backfillKey(timelineKey string) string          { return timelineKey + ":backfill" }
+func entryKey(postID string) string                  { return "feed:post:" + postID }
+func postMetaKey(postID string) string               { return "feed:post_meta:" + postID }
+func removedKey(postID string) string                { return "feed:removed:" + postID }
+func affinityKey(userID string) string               { return "feed:affinity:" + userID }
+func snapshotKey(snapshotID string) string           { return "feed:ranked:" + snapshotID }
+func processedKey(eventID string) string             { return "feed:event:" + eventID }
+
+// applyInteractionScript applies an interaction and marks its event processed in one step,
+// so a failed attempt leaves nothing behind and the retry applies it in full.
+// The counter is updated only if the entry is cached, so counters never create partial entries.
+// KEYS: processed marker, entry, post meta, affinity
+// ARGV: marker TTL, counter op ("incr", "set" or ""), counter field, counter value, affinity weight, affinity TTL
+var applyInteractionScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+if ARGV[2] ~= '' and redis.call('EXISTS', KEYS[2]) == 1 then
+	if ARGV[2] == 'incr' then
+		redis.call('HINCRBY', KEYS[2], ARGV[3], ARGV[4])
+	else
+		redis.call('HSET', KEYS[2], ARGV[3], ARGV[4])
+	end
+end
+local character = redis.call('HGET', KEYS[3], 'character_id')
+if not character then
+	character = redis.call('HGET', KEYS[2], 'character_id')
+end
+if character then
+	redis.call('ZINCRBY', KEYS[4], ARGV[5], character)
+	redis.call('EXPIRE', KEYS[4], ARGV[6])
+end
+redis.call('SET', KEYS[1], 1, 'EX', ARGV[1])
+return 1
+`)
+
+// FeedEntry is a hydrated post cached for feed reads
+type FeedEntry struct {
+	PostID             string
+	WorldID            string
+	CharacterID        string
+	DisplayName        string
+	AvatarURL          string
+	Caption            string
+	MediaURL           string
+	MediaWidth         int32
+	MediaHeight        int32
+	MediaBlurhash      string
+	MediaDominantColor string // Dominant color of the image, #rrggbb
+	IsAI               bool
+	CreatedAt          int64 // Unix milliseconds, the score of the post in timelines
+	LikesCount         int32
+	CommentsCount      int32
+}
+
+// feedCursor points at the last post of a page: its score and ID break ties
+// between posts created in the same millisecond. Ranked feeds are paged
+// through a snapshot of the ranking instead.
+type feedCursor struct {
+	Score    int64  `json:"s,omitempty"`
+	PostID   string `json:"p,omitempty"`
+	Snapshot string `json:"r,omitempty"`
+	Offset   int    `json:"o,omitempty"`
+}
+
+// encodeCursor returns an opaque cursor string
+func encodeCursor(c feedCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a cursor produced by encodeCursor
+func decodeCursor(s string) (*feedCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor: %w", err)
+	}
+	var c feedCursor
+	if err := json.Unmarshal(data, &c); err != nil || (c.PostID == "" && c.Snapshot == "") {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	return &c, nil
+}
+
+// TimelineStore keeps materialized timelines in Redis sorted sets
+// (post ID scored by creation time in milliseconds) and hydrated posts in hashes
+type TimelineStore struct {
+	redis *redis.Client
+}
+
+// NewTimelineStore creates a new TimelineStore
+func NewTimelineStore(redisClient *redis.Client) *TimelineStore {
+	return &TimelineStore{redis: redisClient}
+}
+
+// Add inserts a post into the given timelines and trims them to timelineMaxLen.
+// Adding the same post twice is a no-op, so redelivered events are harmless.
+func (t *TimelineStore) Add(ctx context.Context, postID string, createdAt time.Time, keys ...string) error {
+	pipe := t.redis.TxPipeline()
+	for _, key := range keys {
+		pipe.ZAdd(ctx, key, &redis.Z{Score: float64(createdAt.UnixMilli()), Member: postID})
+		pipe.ZRemRangeByRank(ctx, key, 0, -timelineMaxLen-1)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Remove deletes posts from a timeline
+func (t *TimelineStore) Remove(ctx context.Context, key string, postIDs ...string) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+	members := make([]interface{}, len(postIDs))
+	for i, id := range postIDs {
+		members[i] = id
+	}
+	return t.redis.ZRem(ctx, key, members...).Err()
+}
+
+// IsReady reports whether a timeline was backfilled
+func (t *TimelineStore) IsReady(ctx context.Context, key string) (bool, error) {
+	n, err := t.redis.Exists(ctx, readyKey(key)).Result()
+	return n > 0, err
+}
+
+// StartBackfill reports whether the caller may backfill a timeline,
+// only one backfill of a timeline runs at a time
+func (t *TimelineStore) StartBackfill(ctx context.Context, key string) (bool, error) {
+	return t.redis.SetNX(ctx, backfillKey(key), 1, backfillTTL).Result()
+}
+
+// FinishBackfill lets the next backfill of a timeline start
+func (t *TimelineStore) FinishBackfill(ctx context.Context, key string) error {
+	return t.redis.Del(ctx, backfillKey(key)).Err()
+}
+
+// Backfill adds posts to a timeline and to the timelines of their characters,
+// caches them and marks the timeline ready, all in one round trip
+func (t *TimelineStore) Backfill(ctx context.Context, key string, entries []*FeedEntry) error {
+	pipe := t.redis.Pipeline()
+	timelines := map[string]bool{key: true}
+	for _, entry := range entries {
+		meta := PostMeta{WorldID: entry.WorldID, CharacterID: entry.CharacterID}
+		if fields := meta.fields(); len(fields) > 0 {
+			pipe.HSet(ctx, postMetaKey(entry.PostID), fields)
+			pipe.Expire(ctx, postMetaKey(entry.PostID), affinityTTL)
+		}
+
+		z := &redis.Z{Score: float64(entry.CreatedAt), Member: entry.PostID}
+		characterKey := characterTimelineKey(entry.CharacterID)
+		pipe.ZAdd(ctx, key, z)
+		pipe.ZAdd(ctx, characterKey, z)
+		timelines[characterKey] = true
+
+		pipe.HSet(ctx, entryKey(entry.PostID), entryToHash(entry))
+		pipe.Expire(ctx, entryKey(entry.PostID), entryTTL)
+	}
+	for timeline := range timelines {
+		pipe.ZRemRangeByRank(ctx, timeline, 0, -timelineMaxLen-1)
+	}
+	pipe.Set(ctx, readyKey(key), time.Now().Unix(), 0)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Page returns up to limit post IDs older than the cursor, newest first,
+// together with the cursor of the next page (nil if there are no more posts)
+func (t *TimelineStore) Page(ctx context.Context, key string, cursor *feedCursor, limit int) ([]string, *feedCursor, error) {
+	max := "+inf"
+	if cursor != nil {
+		max = strconv.FormatInt(cursor.Score, 10)
+	}
+
+	ids := make([]string, 0, limit+1)
+	scores := make([]int64, 0, limit+1)
+	var offset int64
+	for len(ids) <= limit {
+		batch, err := t.redis.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+			Max:    max,
+			Min:    "-inf",
+			Offset: offset,
+			Count:  int64(limit + 1),
+		}).Result()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, z := range batch {
+			id, _ := z.Member.(string)
+			score := int64(z.Score)
+			// Equal scores are returned in reverse lexicographic order,
+			// so everything up to the cursor's post was already served
+			if cursor != nil && score == cursor.Score && id >= cursor.PostID {
+				continue
+			}
+			ids = append(ids, id)
+			scores = append(scores, score)
+			if len(ids) > limit {
+				break
+			}
+		}
+
+		if len(batch) < limit+1 {
+			break
+		}
+		offset += int64(len(batch))
+	}
+
+	if len(ids) <= limit {
+		return ids, nil, nil
+	}
+
+	ids = ids[:limit]
+	return ids, &feedCursor{Score: scores[limit-1], PostID: ids[limit-1]}, nil
+}
+
+// GetEntries returns cached entries by post ID; missing posts are absent from the map
+func (t *TimelineStore) GetEntries(ctx context.Context, postIDs []string) (map[string]*FeedEntry, error) {
+	pipe := t.redis.Pipeline()
+	cmds := make([]*redis.StringStringMapCmd, len(postIDs))
+	for i, id := range postIDs {
+		cmds[i] = pipe.HGetAll(ctx, entryKey(id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	entries := make(map[string]*FeedEntry, len(postIDs))
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		if len(fields) == 0 {
+			continue
+		}
+		entries[postIDs[i]] = entryFromHash(postIDs[i], fields)
+	}
+	return entries, nil
+}
+
+// SaveEntries caches hydrated entries for entryTTL
+func (t *TimelineStore) SaveEntries(ctx context.Context, entries []*FeedEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	pipe := t.redis.Pipeline()
+	for _, entry := range entries {
+		key := entryKey(entry.PostID)
+		pipe.HSet(ctx, key, entryToHash(entry))
+		pipe.Expire(ctx, key, entryTTL)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Interaction is a like or comment event applied to the cached entry of a post
+// and to the affinity of the user to the post's author
+type Interaction struct {
+	EventID string
+	UserID  string
+	PostID  string
+	Counter string // Entry field holding the counter, empty if the event carries none
+	Incr    bool   // Add Value to the counter instead of setting it
+	Value   int64
+	Weight  float64 // Affinity the user gains to the author
+}
+
+// ApplyInteraction applies an interaction once per event ID and reports whether
+// it was applied now; redeliveries of an applied event are skipped
+func (t *TimelineStore) ApplyInteraction(ctx context.Context, in Interaction) (bool, error) {
+	op := ""
+	if in.Counter != "" {
+		op = "set"
+		if in.Incr {
+			op = "incr"
+		}
+	}
+	keys := []string{processedKey(in.EventID), entryKey(in.PostID), postMetaKey(in.PostID), affinityKey(in.UserID)}
+	applied, err := applyInteractionScript.Run(ctx, t.redis, keys,
+		int64(processedTTL/time.Second), op, in.Counter, in.Value, in.Weight, int64(affinityTTL/time.Second)).Int()
+	return applied == 1, err
+}
+
+// PostMeta records which timelines a post was added to
+type PostMeta struct {
+	WorldID     string
+	UserID      string
+	CharacterID string
+}
+
+// fields returns the known fields of the metadata
+func (m PostMeta) fields() map[string]interface{} {
+	fields := map[string]interface{}{}
+	for name, value := range map[string]string{
+		"world_id":     m.WorldID,
+		"user_id":      m.UserID,
+		"character_id": m.CharacterID,
+	} {
+		if value != "" {
+			fields[name] = value
+		}
+	}
+	return fields
+}
+
+// SetPostMeta remembers the world, user and character of a post, so interactions
+// can be attributed to characters and the post can be removed from its timelines
+func (t *TimelineStore) SetPostMeta(ctx context.Context, postID string, meta PostMeta) error {
+	fields := meta.fields()
+	if len(fields) == 0 {
+		return nil
+	}
+
+	pipe := t.redis.TxPipeline()
+	pipe.HSet(ctx, postMetaKey(postID), fields)
+	pipe.Expire(ctx, postMetaKey(postID), affinityTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RemovePost drops a deleted or moderated post from every timeline it was added to
+// and from the cache. Ranked snapshots still referencing it skip it via a tombstone.
+// It returns the number of removed entries.
+func (t *TimelineStore) RemovePost(ctx context.Context, postID string) (int, error) {
+	meta, err := t.redis.HGetAll(ctx, postMetaKey(postID)).Result()
+	if err != nil {
+		return 0, err
+	}
+	entry, err := t.redis.HMGet(ctx, entryKey(postID), "world_id", "character_id").Result()
+	if err != nil {
+		return 0, err
+	}
+	if meta["world_id"] == "" {
+		meta["world_id"], _ = entry[0].(string)
+	}
+	if meta["character_id"] == "" {
+		meta["character_id"], _ = entry[1].(string)
+	}
+
+	var keys []string
+	if meta["world_id"] != "" {
+		keys = append(keys, worldTimelineKey(meta["world_id"]))
+	}
+	if meta["user_id"] != "" {
+		keys = append(keys, userTimelineKey(meta["user_id"]))
+	}
+	if meta["character_id"] != "" {
+		keys = append(keys, characterTimelineKey(meta["character_id"]))
+	}
+
+	pipe := t.redis.TxPipeline()
+	cmds := make([]*redis.IntCmd, 0, len(keys)+1)
+	for _, key := range keys {
+		cmds = append(cmds, pipe.ZRem(ctx, key, postID))
+	}
+	cmds = append(cmds, pipe.Del(ctx, entryKey(postID), postMetaKey(postID)))
+	pipe.Set(ctx, removedKey(postID), 1, removedTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, cmd := range cmds {
+		removed += int(cmd.Val())
+	}
+	return removed, nil
+}
+
+// Removed returns which of the given posts were removed by RemovePost
+func (t *TimelineStore) Removed(ctx context.Context, postIDs []string) (map[string]bool, error) {
+	pipe := t.redis.Pipeline()
+	cmds := make([]*redis.IntCmd, len(postIDs))
+	for i, id := range postIDs {
+		cmds[i] = pipe.Exists(ctx, removedKey(id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	removed := make(map[string]bool)
+	for i, cmd := range cmds {
+		if cmd.Val() > 0 {
+			removed[postIDs[i]] = true
+		}
+	}
+	return removed, nil
+}
+
+// EvictTimeline deletes the cached entries of every post of a timeline and,
+// if dropTimeline is set, the timeline itself, so it is backfilled on the next read.
+// It returns the number of removed keys.
+func (t *TimelineStore) EvictTimeline(ctx context.Context, key string, dropTimeline bool) (int, error) {
+	postIDs, err := t.redis.ZRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	keys := make([]string, 0, len(postIDs)+2)
+	for _, postID := range postIDs {
+		keys = append(keys, entryKey(postID))
+	}
+	if dropTimeline {
+		keys = append(keys, key, readyKey(key))
+	}
+	return t.deleteKeys(ctx, keys)
+}
+
+// evictPatterns match the timelines with their ready markers and the cached entries.
+// Affinity, post metadata, processed event markers and tombstones are state rather
+// than cache and are never evicted.
+var evictPatterns = []string{
+	worldTimelineKey("*"),
+	userTimelineKey("*"),
+	characterTimelineKey("*"),
+	entryKey("*"),
+}
+
+// EvictAll deletes every timeline and cached entry, timelines are backfilled on the next read
+func (t *TimelineStore) EvictAll(ctx context.Context) (int, error) {
+	removed := 0
+	for _, pattern := range evictPatterns {
+		iter := t.redis.Scan(ctx, 0, pattern, 500).Iterator()
+		batch := make([]string, 0, 500)
+		for iter.Next(ctx) {
+			// A running backfill keeps its marker, so it is not started twice
+			if strings.HasSuffix(iter.Val(), ":backfill") {
+				continue
+			}
+			batch = append(batch, iter.Val())
+			if len(batch) == cap(batch) {
+				n, err := t.deleteKeys(ctx, batch)
+				if err != nil {
+					return removed, err
+				}
+				removed += n
+				batch = batch[:0]
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return removed, err
+		}
+
+		n, err := t.deleteKeys(ctx, batch)
+		if err != nil {
+			return removed, err
+		}
+		removed += n
+	}
+	return removed, nil
+}
+
+// deleteKeys unlinks keys in chunks and returns how many existed
+func (t *TimelineStore) deleteKeys(ctx context.Context, keys []string) (int, error) {
+	removed := 0
+	for start := 0; start < len(keys); start += 500 {
+		end := min(start+500, len(keys))
+		n, err := t.redis.Unlink(ctx, keys[start:end]...).Result()
+		if err != nil {
+			return removed, err
+		}
+		removed += int(n)
+	}
+	return removed, nil
+}
+
+// GetAffinity returns the characters a user interacted with the most
+func (t *TimelineStore) GetAffinity(ctx context.Context, userID string, limit int) (map[string]float64, error) {
+	items, err := t.redis.ZRevRangeWithScores(ctx, affinityKey(userID), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	affinity := make(map[string]float64, len(items))
+	for _, z := range items {
+		characterID, _ := z.Member.(string)
+		affinity[characterID] = z.Score
+	}
+	return affinity, nil
+}
+
+// SaveSnapshot stores a ranked list of post IDs and returns its ID
+func (t *TimelineStore) SaveSnapshot(ctx context.Context, postIDs []string) (string, error) {
+	snapshotID := uuid.New().String()
+	if len(postIDs) == 0 {
+		return snapshotID, nil
+	}
+	members := make([]interface{}, len(postIDs))
+	for i, id := range postIDs {
+		members[i] = id
+	}
+	pipe := t.redis.TxPipeline()
+	pipe.RPush(ctx, snapshotKey(snapshotID), members...)
+	pipe.Expire(ctx, snapshotKey(snapshotID), snapshotTTL)
+	_, err := pipe.Exec(ctx)
+	return snapshotID, err
+}
+
+// GetSnapshot returns a page of a ranked list and its total length.
+// A zero length means the snapshot expired.
+func (t *TimelineStore) GetSnapshot(ctx context.Context, snapshotID string, offset, limit int) ([]string, int, error) {
+	pipe := t.redis.Pipeline()
+	page := pipe.LRange(ctx, snapshotKey(snapshotID), int64(offset), int64(offset+limit-1))
+	total := pipe.LLen(ctx, snapshotKey(snapshotID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, 0, err
+	}
+	return page.Val(), int(total.Val()), nil
+}
+
+func entryToHash(e *FeedEntry) map[string]interface{} {
+	return map[string]interface{}{
+		"world_id":             e.WorldID,
+		"character_id":         e.CharacterID,
+		"display_name":         e.DisplayName,
+		"avatar_url":           e.AvatarURL,
+		"caption":              e.Caption,
+		"media_url":            e.MediaURL,
+		"media_width":          e.MediaWidth,
+		"media_height":         e.MediaHeight,
+		"media_blurhash":       e.MediaBlurhash,
+		"media_dominant_color": e.MediaDominantColor,
+		"is_ai":                e.IsAI,
+		"created_at_ms":        e.CreatedAt,
+		"likes_count":          e.LikesCount,
+		"comments_count":       e.CommentsCount,
+	}
+}
+
+func entryFromHash(postID string, h map[string]string) *FeedEntry {
+	createdAt, _ := strconv.ParseInt(h["created_at_ms"], 10, 64)
+	likes, _ := strconv.ParseInt(h["likes_count"], 10, 32)
+	comments, _ := strconv.ParseInt(h["comments_count"], 10, 32)
+	mediaWidth, _ := strconv.ParseInt(h["media_width"], 10, 32)
+	mediaHeight, _ := strconv.ParseInt(h["media_height"], 10, 32)
+	return &FeedEntry{
+		PostID:             postID,
+		WorldID:            h["world_id"],
+		CharacterID:        h["character_id"],
+		DisplayName:        h["display_name"],
+		AvatarURL:          h["avatar_url"],
+		Caption:            h["caption"],
+		MediaURL:           h["media_url"],
+		MediaWidth:         int32(mediaWidth),
+		MediaHeight:        int32(mediaHeight),
+		MediaBlurhash:      h["media_blurhash"],
+		MediaDominantColor: h["media_dominant_color"],
+		IsAI:               h["is_ai"] == "1",
+		CreatedAt:          createdAt,
+		LikesCount:         int32(likes),
+		CommentsCount:      int32(comments),
+	}
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+
+	postpb "github.com/sdshorin/generia/api/grpc/post"
+)
+
+// newTestStore returns a TimelineStore backed by an in-memory Redis
+func newTestStore(t *testing.T) (*TimelineStore, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewTimelineStore(client), mr
+}
+
+func TestPage(t *testing.T) {
+	type post struct {
+		id    string
+		score int64
+	}
+
+	tests := []struct {
+		name  string
+		posts []post
+		limit int
+		want  [][]string // Pages in the order they are served
+	}{
+		{
+			name:  "empty timeline",
+			limit: 2,
+			want:  [][]string{{}},
+		},
+		{
+			name:  "single page",
+			posts: []post{{"a", 1}, {"b", 2}},
+			limit: 5,
+			want:  [][]string{{"b", "a"}},
+		},
+		{
+			name:  "exact pages",
+			posts: []post{{"a", 1}, {"b", 2}, {"c", 3}, {"d", 4}},
+			limit: 2,
+			want:  [][]string{{"d", "c"}, {"b", "a"}},
+		},
+		{
+			name:  "last page is short",
+			posts: []post{{"a", 1}, {"b", 2}, {"c", 3}},
+			limit: 2,
+			want:  [][]string{{"c", "b"}, {"a"}},
+		},
+		{
+			name:  "ties broken by post ID",
+			posts: []post{{"a", 5}, {"b", 5}, {"c", 5}, {"d", 5}, {"e", 1}},
+			limit: 2,
+			want:  [][]string{{"d", "c"}, {"b", "a"}, {"e"}},
+		},
+		{
+			name:  "ties span more than a batch",
+			posts: []post{{"a", 7}, {"b", 7}, {"c", 7}, {"d", 7}, {"e", 7}, {"f", 7}, {"g", 3}},
+			limit: 1,
+			want:  [][]string{{"f"}, {"e"}, {"d"}, {"c"}, {"b"}, {"a"}, {"g"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, _ := newTestStore(t)
+			ctx := context.Background()
+			for _, p := range tt.posts {
+				store.redis.ZAdd(ctx, "timeline", &redis.Z{Score: float64(p.score), Member: p.id})
+			}
+
+			var cursor *feedCursor
+			for i, want := range tt.want {
+				ids, next, err := store.Page(ctx, "timeline", cursor, tt.limit)
+				if err != nil {
+					t.Fatalf("page %d: %v", i, err)
+				}
+				if !slices.Equal(ids, want) {
+					t.Fatalf("page %d = %q, want %q", i, ids, want)
+				}
+				if last := i == len(tt.want)-1; last != (next == nil) {
+					t.Fatalf("page %d: next cursor = %+v, last page = %v", i, next, last)
+				}
+
+				// Cursors travel through clients, the decoded one must work the same
+				if next != nil {
+					cursor, err = decodeCursor(encodeCursor(*next))
+					if err != nil {
+						t.Fatalf("page %d: decodeCursor: %v", i, err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejectsMalformed(t *testing.T) {
+	for _, s := range []string{"!!!", encodeCursor(feedCursor{}), "e30"} {
+		if _, err := decodeCursor(s); err == nil {
+			t.Errorf("decodeCursor(%q) succeeded", s)
+		}
+	}
+}
+
+func TestApplyInteraction(t *testing.T) {
+	tests := []struct {
+		name         string
+		entry        map[string]string // Cached entry of the post, nil if not cached
+		meta         map[string]string
+		in           Interaction
+		wantCounter  string // Expected counter field of the entry, empty if the entry is absent
+		wantAffinity float64
+	}{
+		{
+			name:         "like sets the count",
+			entry:        map[string]string{"likes_count": "3", "character_id": "char"},
+			in:           Interaction{Counter: "likes_count", Value: 7, Weight: 1},
+			wantCounter:  "7",
+			wantAffinity: 1,
+		},
+		{
+			name:         "comment increments the count",
+			entry:        map[string]string{"comments_count": "3", "character_id": "char"},
+			in:           Interaction{Counter: "comments_count", Incr: true, Value: 1, Weight: 2},
+			wantCounter:  "4",
+			wantAffinity: 2,
+		},
+		{
+			name:         "missing entry is not created",
+			meta:         map[string]string{"character_id": "char"},
+			in:           Interaction{Counter: "likes_count", Value: 7, Weight: 1},
+			wantAffinity: 1,
+		},
+		{
+			name:         "character comes from the post meta first",
+			entry:        map[string]string{"likes_count": "0", "character_id": "stale"},
+			meta:         map[string]string{"character_id": "char"},
+			in:           Interaction{Counter: "likes_count", Value: 1, Weight: 1},
+			wantCounter:  "1",
+			wantAffinity: 1,
+		},
+		{
+			name: "unknown post changes nothing",
+			in:   Interaction{Counter: "likes_count", Value: 1, Weight: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, mr := newTestStore(t)
+			ctx := context.Background()
+			for field, value := range tt.entry {
+				mr.HSet(entryKey("post"), field, value)
+			}
+			for field, value := range tt.meta {
+				mr.HSet(postMetaKey("post"), field, value)
+			}
+			tt.in.EventID, tt.in.UserID, tt.in.PostID = "event", "user", "post"
+
+			// A redelivered event is skipped
+			for i, want := range []bool{true, false} {
+				applied, err := store.ApplyInteraction(ctx, tt.in)
+				if err != nil {
+					t.Fatalf("ApplyInteraction: %v", err)
+				}
+				if applied != want {
+					t.Fatalf("attempt %d applied = %v, want %v", i, applied, want)
+				}
+			}
+
+			if got := mr.HGet(entryKey("post"), tt.in.Counter); got != tt.wantCounter {
+				t.Errorf("counter = %q, want %q", got, tt.wantCounter)
+			}
+			if tt.wantAffinity == 0 {
+				if mr.Exists(affinityKey("user")) {
+					t.Error("affinity recorded for a post without a character")
+				}
+				return
+			}
+			affinity, err := mr.ZScore(affinityKey("user"), "char")
+			if err != nil || affinity != tt.wantAffinity {
+				t.Errorf("affinity = %v (%v), want %v", affinity, err, tt.wantAffinity)
+			}
+			if ttl := mr.TTL(affinityKey("user")); ttl != affinityTTL {
+				t.Errorf("affinity TTL = %v, want %v", ttl, affinityTTL)
+			}
+		})
+	}
+}
+
+func TestBackfillAndRemovePost(t *testing.T) {
+	store, mr := newTestStore(t)
+	ctx := context.Background()
+	key := worldTimelineKey("world")
+
+	started, err := store.StartBackfill(ctx, key)
+	if err != nil || !started {
+		t.Fatalf("StartBackfill = %v, %v", started, err)
+	}
+	if again, _ := store.StartBackfill(ctx, key); again {
+		t.Fatal("second StartBackfill succeeded while the first is running")
+	}
+
+	entries := make([]*FeedEntry, 0, 3)
+	for i := 0; i < 3; i++ {
+		entries = append(entries, &FeedEntry{
+			PostID:      fmt.Sprintf("post%d", i),
+			WorldID:     "world",
+			CharacterID: "char",
+			CreatedAt:   int64(i + 1),
+		})
+	}
+	if err := store.Backfill(ctx, key, entries); err != nil {
+		t.Fatalf("Backfill: %v", err)
+	}
+	if err := store.FinishBackfill(ctx, key); err != nil {
+		t.Fatalf("FinishBackfill: %v", err)
+	}
+
+	ready, err := store.IsReady(ctx, key)
+	if err != nil || !ready {
+		t.Fatalf("IsReady = %v, %v", ready, err)
+	}
+	ids, _, err := store.Page(ctx, key, nil, 10)
+	if err != nil || !slices.Equal(ids, []string{"post2", "post1", "post0"}) {
+		t.Fatalf("Page = %q, %v", ids, err)
+	}
+	if ttl := mr.TTL(entryKey("post1")); ttl != entryTTL {
+		t.Errorf("entry TTL = %v, want %v", ttl, entryTTL)
+	}
+
+	if _, err := store.RemovePost(ctx, "post1"); err != nil {
+		t.Fatalf("RemovePost: %v", err)
+	}
+	ids, _, _ = store.Page(ctx, key, nil, 10)
+	if !slices.Equal(ids, []string{"post2", "post0"}) {
+		t.Errorf("Page after RemovePost = %q", ids)
+	}
+	charIDs, _, _ := store.Page(ctx, characterTimelineKey("char"), nil, 10)
+	if !slices.Equal(charIDs, []string{"post2", "post0"}) {
+		t.Errorf("character timeline after RemovePost = %q", charIDs)
+	}
+	removed, err := store.Removed(ctx, []string{"post0", "post1"})
+	if err != nil || !removed["post1"] || removed["post0"] {
+		t.Errorf("Removed = %v, %v", removed, err)
+	}
+}
+
+func TestBackfillScoresLikeEvents(t *testing.T) {
+	store, mr := newTestStore(t)
+	ctx := context.Background()
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 678901000, time.UTC)
+
+	// The same post reaches one timeline from post.created and another from post-service
+	if err := store.Add(ctx, "post", createdAt, "live"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	entry := postToEntry(&postpb.Post{PostId: "post", CharacterId: "char", CreatedAt: createdAt.Format(time.RFC3339Nano)})
+	if err := store.Backfill(ctx, "backfilled", []*FeedEntry{entry}); err != nil {
+		t.Fatalf("Backfill: %v", err)
+	}
+
+	live, _ := mr.ZScore("live", "post")
+	backfilled, _ := mr.ZScore("backfilled", "post")
+	if live != backfilled || live != float64(createdAt.UnixMilli()) {
+		t.Errorf("scores = %v live, %v backfilled, want %d", live, backfilled, createdAt.UnixMilli())
+	}
+
+	cached, err := store.redis.HGetAll(ctx, entryKey("post")).Result()
+	if err != nil {
+		t.Fatalf("HGetAll: %v", err)
+	}
+	if got := entryFromHash("post", cached).CreatedAt; got != createdAt.UnixMilli() {
+		t.Errorf("cached created at = %d, want %d", got, createdAt.UnixMilli())
+	}
+}
+
+func TestEvictAll(t *testing.T) {
+	store, mr := newTestStore(t)
+	ctx := context.Background()
+
+	evicted := []string{
+		worldTimelineKey("w"),
+		userTimelineKey("u"),
+		characterTimelineKey("c"),
+		entryKey("p"),
+	}
+	kept := []string{
+		backfillKey(worldTimelineKey("w")),
+		postMetaKey("p"),
+		removedKey("p"),
+		affinityKey("u"),
+		snapshotKey("s"),
+		processedKey("e"),
+	}
+	for _, key := range append(evicted, kept...) {
+		mr.Set(key, "1")
+	}
+	mr.Set(readyKey(worldTimelineKey("w")), "1")
+
+	if _, err := store.EvictAll(ctx); err != nil {
+		t.Fatalf("EvictAll: %v", err)
+	}
+
+	for _, key := range append(evicted, readyKey(worldTimelineKey("w"))) {
+		if mr.Exists(key) {
+			t.Errorf("%s was not evicted", key)
+		}
+	}
+	for _, key := range kept {
+		if !mr.Exists(key) {
+			t.Errorf("%s was evicted", key)
+		}
+	}
+}
@@ -16,6 +16,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sdshorin/generia/pkg/config"
 	"github.com/sdshorin/generia/pkg/discovery"
+	"github.com/sdshorin/generia/pkg/events"
+	"github.com/sdshorin/generia/pkg/kafka"
 	"github.com/sdshorin/generia/pkg/logger"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -104,11 +106,15 @@ func main() {
 	}
 	defer authConn.Close()
 
+	// Initialize event publisher
+	eventPublisher := events.NewKafkaPublisher(kafka.NewProducer(cfg.Kafka.Brokers))
+	defer eventPublisher.Close()
+
 	// Initialize repositories
 	interactionRepo := repository.NewInteractionRepository(mongoDb)
 
 	// Initialize services
-	interactionService := service.NewInteractionService(interactionRepo, authClient)
+	interactionService := service.NewInteractionService(interactionRepo, authClient, eventPublisher)
 
 	// Create gRPC server with middleware
 	grpcServer := grpc.NewServer(
@@ -16,10 +16,11 @@ import (
 // InteractionRepository handles database operations for interactions
 type InteractionRepository interface {
 	// Likes
-	AddLike(ctx context.Context, like *models.Like) error
+	AddLike(ctx context.Context, like *models.Like) (bool, error)
 	RemoveLike(ctx context.Context, postID, userID string) error
 	GetPostLikes(ctx context.Context, postID string, limit, offset int) ([]*models.Like, int, error)
 	CheckUserLiked(ctx context.Context, postID, userID string) (bool, error)
+	GetLikedPostIDs(ctx context.Context, userID string, postIDs []string) ([]string, error)
 	
 	// Comments
 	AddComment(ctx context.Context, comment *models.Comment) error
@@ -48,8 +49,8 @@ func NewInteractionRepository(db *mongo.Database) InteractionRepository {
 	}
 }
 
-// AddLike adds a like to a post
-func (r *interactionRepository) AddLike(ctx context.Context, like *models.Like) error {
+// AddLike adds a like to a post and reports whether a new like was stored
+func (r *interactionRepository) AddLike(ctx context.Context, like *models.Like) (bool, error) {
 	like.CreatedAt = time.Now()
 	
 	// Check if like already exists
@@ -57,19 +58,19 @@ func (r *interactionRepository) AddLike(ctx context.Context, like *models.Like)
 	count, err := r.likesCol.CountDocuments(ctx, filter)
 	if err != nil {
 		logger.Logger.Error("Failed to check if like exists", zap.Error(err))
-		return err
+		return false, err
 	}
 	
 	if count > 0 {
 		// Like already exists, nothing to do
-		return nil
+		return false, nil
 	}
 	
 	// Insert like
 	_, err = r.likesCol.InsertOne(ctx, like)
 	if err != nil {
 		logger.Logger.Error("Failed to add like", zap.Error(err))
-		return err
+		return false, err
 	}
 	
 	// Update post stats
@@ -79,7 +80,7 @@ func (r *interactionRepository) AddLike(ctx context.Context, like *models.Like)
 		// Continue even if stats update fails
 	}
 	
-	return nil
+	return true, nil
 }
 
 // RemoveLike removes a like from a post
@@ -153,6 +154,36 @@ func (r *interactionRepository) CheckUserLiked(ctx context.Context, postID, user
 	return count > 0, nil
 }
 
+// GetLikedPostIDs returns which of the given posts a user has liked, using a single query
+func (r *interactionRepository) GetLikedPostIDs(ctx context.Context, userID string, postIDs []string) ([]string, error) {
+	if len(postIDs) == 0 {
+		return []string{}, nil
+	}
+
+	filter := bson.M{"user_id": userID, "post_id": bson.M{"$in": postIDs}}
+	opts := options.Find().SetProjection(bson.M{"post_id": 1})
+
+	cursor, err := r.likesCol.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Logger.Error("Failed to get liked posts", zap.Error(err))
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	likes := []*models.Like{}
+	if err := cursor.All(ctx, &likes); err != nil {
+		logger.Logger.Error("Failed to decode liked posts", zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]string, 0, len(likes))
+	for _, like := range likes {
+		result = append(result, like.PostID)
+	}
+
+	return result, nil
+}
+
 // AddComment adds a comment to a post
 func (r *interactionRepository) AddComment(ctx context.Context, comment *models.Comment) error {
 	comment.CreatedAt = time.Now()
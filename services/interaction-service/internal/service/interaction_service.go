@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/sdshorin/generia/pkg/events"
 	"github.com/sdshorin/generia/pkg/logger"
 	"github.com/sdshorin/generia/services/interaction-service/internal/models"
 	"github.com/sdshorin/generia/services/interaction-service/internal/repository"
@@ -20,16 +21,19 @@ type InteractionService struct {
 	interactionpb.UnimplementedInteractionServiceServer
 	interactionRepo repository.InteractionRepository
 	authClient      authpb.AuthServiceClient
+	eventPublisher  events.Publisher
 }
 
 // NewInteractionService creates a new InteractionService
 func NewInteractionService(
 	interactionRepo repository.InteractionRepository,
 	authClient authpb.AuthServiceClient,
+	eventPublisher events.Publisher,
 ) interactionpb.InteractionServiceServer {
 	return &InteractionService{
 		interactionRepo: interactionRepo,
 		authClient:      authClient,
+		eventPublisher:  eventPublisher,
 	}
 }
 
@@ -57,7 +61,7 @@ func (s *InteractionService) LikePost(ctx context.Context, req *interactionpb.Li
 		PostID: req.PostId,
 		UserID: req.UserId,
 	}
-	err = s.interactionRepo.AddLike(ctx, like)
+	added, err := s.interactionRepo.AddLike(ctx, like)
 	if err != nil {
 		logger.Logger.Error("Failed to add like", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "failed to add like")
@@ -74,6 +78,18 @@ func (s *InteractionService) LikePost(ctx context.Context, req *interactionpb.Li
 		}
 	}
 
+	// Repeated likes are no-ops and must not be announced again
+	if added {
+		events.Emit(ctx, s.eventPublisher, events.LikeAdded, req.WorldId,
+			events.Actor{UserID: req.UserId},
+			events.LikeAddedPayload{
+				PostID:     req.PostId,
+				UserID:     req.UserId,
+				LikesCount: stats.LikesCount,
+				CreatedAt:  like.CreatedAt,
+			})
+	}
+
 	return &interactionpb.LikePostResponse{
 		Success:    true,
 		LikesCount: stats.LikesCount,
@@ -195,6 +211,30 @@ func (s *InteractionService) CheckUserLiked(ctx context.Context, req *interactio
 	}, nil
 }
 
+// maxBatchSize limits the number of posts in batched requests
+const maxBatchSize = 500
+
+// CheckUserLikedPosts handles checking which of the given posts a user has liked
+func (s *InteractionService) CheckUserLikedPosts(ctx context.Context, req *interactionpb.CheckUserLikedPostsRequest) (*interactionpb.CheckUserLikedPostsResponse, error) {
+	// Validate input
+	if req.UserId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id is required")
+	}
+	if len(req.PostIds) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d post_ids are allowed", maxBatchSize)
+	}
+
+	likedPostIDs, err := s.interactionRepo.GetLikedPostIDs(ctx, req.UserId, req.PostIds)
+	if err != nil {
+		logger.Logger.Error("Failed to check liked posts", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to check liked posts")
+	}
+
+	return &interactionpb.CheckUserLikedPostsResponse{
+		LikedPostIds: likedPostIDs,
+	}, nil
+}
+
 // AddComment handles adding a comment to a post
 func (s *InteractionService) AddComment(ctx context.Context, req *interactionpb.AddCommentRequest) (*interactionpb.AddCommentResponse, error) {
 	// Validate input
@@ -226,6 +266,16 @@ func (s *InteractionService) AddComment(ctx context.Context, req *interactionpb.
 		return nil, status.Errorf(codes.Internal, "failed to add comment")
 	}
 
+	events.Emit(ctx, s.eventPublisher, events.CommentAdded, req.WorldId,
+		events.Actor{UserID: req.UserId},
+		events.CommentAddedPayload{
+			CommentID: comment.ID,
+			PostID:    comment.PostID,
+			UserID:    comment.UserID,
+			Text:      comment.Text,
+			CreatedAt: comment.CreatedAt,
+		})
+
 	return &interactionpb.AddCommentResponse{
 		CommentId: comment.ID,
 		CreatedAt: comment.CreatedAt.Format(time.RFC3339),
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+
+	"github.com/sdshorin/generia/pkg/events"
+	"github.com/sdshorin/generia/pkg/kafka"
+	"github.com/sdshorin/generia/services/media-service/internal/repository"
+	"github.com/sdshorin/generia/services/media-service/internal/service"
+)
+
+// consumerGroup is the Kafka consumer group of the media service
+const consumerGroup = "media-service"
+
+// startConsumers runs the post-upload processing jobs enqueued by ConfirmUpload
+// and evicts the media of deleted and moderated posts from the CDN cache
+func startConsumers(brokers []string, s *MediaService) []*kafka.Consumer {
+	consumers := []*kafka.Consumer{
+		events.NewConsumer(brokers, consumerGroup, events.MediaUploaded,
+			events.Handler(func(ctx context.Context, event *events.Event, payload events.MediaUploadedPayload) error {
+				mediaRepo := repository.NewPostgresMediaRepository(s.db, s.minioClient)
+				mediaService := service.NewMediaService(mediaRepo, s.minioClient, s.bucket, s.signer, s.invalidator, s.variants, s.quotas, s.logger)
+				return mediaService.ProcessMedia(ctx, payload.MediaID)
+			})),
+		events.NewConsumer(brokers, consumerGroup, events.PostDeleted,
+			events.Handler(func(ctx context.Context, event *events.Event, payload events.PostDeletedPayload) error {
+				// Events written before media_id was added carry no media
+				if payload.MediaID == "" {
+					return nil
+				}
+				mediaRepo := repository.NewPostgresMediaRepository(s.db, s.minioClient)
+				mediaService := service.NewMediaService(mediaRepo, s.minioClient, s.bucket, s.signer, s.invalidator, s.variants, s.quotas, s.logger)
+				return mediaService.InvalidateMedia(ctx, payload.MediaID)
+			})),
+	}
+
+	for _, consumer := range consumers {
+		consumer.Start()
+	}
+	return consumers
+}
@@ -2,11 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -20,21 +22,28 @@ import (
 	"github.com/sdshorin/generia/pkg/config"
 	"github.com/sdshorin/generia/pkg/database"
 	"github.com/sdshorin/generia/pkg/discovery"
+	"github.com/sdshorin/generia/pkg/events"
+	"github.com/sdshorin/generia/pkg/kafka"
 	"github.com/sdshorin/generia/pkg/logger"
+	"github.com/sdshorin/generia/pkg/outbox"
 	"github.com/sdshorin/generia/pkg/telemetry"
+	"github.com/sdshorin/generia/services/media-service/internal/imaging"
 	"github.com/sdshorin/generia/services/media-service/internal/models"
 	"github.com/sdshorin/generia/services/media-service/internal/repository"
 	"github.com/sdshorin/generia/services/media-service/internal/service"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 
 	authpb "github.com/sdshorin/generia/api/grpc/auth"
+	cdnpb "github.com/sdshorin/generia/api/grpc/cdn"
 	mediapb "github.com/sdshorin/generia/api/grpc/media"
 )
 
@@ -46,25 +55,35 @@ type MediaService struct {
 	minioClient *minio.Client
 	db          *sqlx.DB
 	bucket      string
+	signer      service.URLSigner
+	invalidator service.CacheInvalidator
+	variants    []imaging.VariantSpec
+	quotas      service.Quotas
 }
 
 // GetPresignedUploadURL generates a presigned URL for direct upload to storage
 func (s *MediaService) GetPresignedUploadURL(ctx context.Context, req *mediapb.GetPresignedUploadURLRequest) (*mediapb.GetPresignedUploadURLResponse, error) {
 	s.logger.Info("GetPresignedUploadURL called",
 		zap.String("character_id", req.CharacterId),
+		zap.String("user_id", req.UserId),
 		zap.String("filename", req.Filename),
 		zap.String("content_type", req.ContentType),
 		zap.Int64("size", req.Size))
 
+	if req.Size < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "size must not be negative")
+	}
+
 	// Create media service instance
 	mediaRepo := repository.NewPostgresMediaRepository(s.db, s.minioClient)
-	mediaService := service.NewMediaService(mediaRepo, s.minioClient, s.bucket, s.logger)
+	mediaService := service.NewMediaService(mediaRepo, s.minioClient, s.bucket, s.signer, s.invalidator, s.variants, s.quotas, s.logger)
 
 	// Generate presigned URL
 	media, presignedURL, expiresAt, err := mediaService.GeneratePresignedPutURL(
 		ctx,
 		req.WorldId,
 		req.CharacterId,
+		req.UserId,
 		req.Filename,
 		req.ContentType,
 		req.Size,
@@ -72,6 +91,9 @@ func (s *MediaService) GetPresignedUploadURL(ctx context.Context, req *mediapb.G
 	)
 	if err != nil {
 		s.logger.Error("Failed to generate presigned URL", zap.Error(err))
+		if errors.Is(err, service.ErrQuotaExceeded) {
+			return nil, status.Errorf(codes.ResourceExhausted, "%v", err)
+		}
 		return nil, err
 	}
 
@@ -89,42 +111,23 @@ func (s *MediaService) ConfirmUpload(ctx context.Context, req *mediapb.ConfirmUp
 
 	// Create media service instance
 	mediaRepo := repository.NewPostgresMediaRepository(s.db, s.minioClient)
-	mediaService := service.NewMediaService(mediaRepo, s.minioClient, s.bucket, s.logger)
-
-	// Check if media exists and belongs to the character
-	media, err := mediaRepo.GetMediaByID(ctx, req.MediaId)
-	if err != nil {
-		s.logger.Error("Failed to get media from database", zap.Error(err))
-		return nil, fmt.Errorf("failed to get media from database: %w", err)
-	}
+	mediaService := service.NewMediaService(mediaRepo, s.minioClient, s.bucket, s.signer, s.invalidator, s.variants, s.quotas, s.logger)
 
-	// Confirm upload
-	err = mediaService.ConfirmMediaUpload(ctx, req.MediaId)
+	// Confirm upload; variants are generated asynchronously by the processing job
+	media, err := mediaService.ConfirmMediaUpload(ctx, req.MediaId)
 	if err != nil {
 		s.logger.Error("Failed to confirm upload", zap.Error(err))
+		if errors.Is(err, service.ErrMediaDeleted) {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
+		if errors.Is(err, service.ErrQuotaExceeded) {
+			return nil, status.Errorf(codes.ResourceExhausted, "%v", err)
+		}
 		return nil, err
 	}
 
-	// Generate variants asynchronously (in a real application, this could be done via Kafka)
-	// For now, we'll just generate them synchronously
-	variants, err := mediaService.GenerateVariants(ctx, req.MediaId, []string{"thumbnail", "medium"})
-	if err != nil {
-		s.logger.Error("Failed to generate variants", zap.Error(err))
-		// Continue even if variant generation fails
-	}
-
-	// Return response with variants (if any)
-	variantsProto := make([]*mediapb.MediaVariant, 0, len(variants))
-	for _, v := range variants {
-		variantsProto = append(variantsProto, &mediapb.MediaVariant{
-			Name:   v.Name,
-			Url:    v.URL,
-			Width:  v.Width,
-			Height: v.Height,
-		})
-	}
-
-	// Add the original as a variant
+	// Return the original until processing finishes, clients poll GetMedia for the status
+	variantsProto := []*mediapb.MediaVariant{}
 	urlStr, _, err := mediaService.GetPresignedURL(ctx, media, "original", time.Hour)
 	if err == nil {
 		variantsProto = append(variantsProto, &mediapb.MediaVariant{
@@ -136,6 +139,7 @@ func (s *MediaService) ConfirmUpload(ctx context.Context, req *mediapb.ConfirmUp
 	return &mediapb.ConfirmUploadResponse{
 		Success:  true,
 		Variants: variantsProto,
+		Status:   mediaStatusToProto(media.Status),
 	}, nil
 }
 
@@ -145,25 +149,20 @@ func (s *MediaService) GetMedia(ctx context.Context, req *mediapb.GetMediaReques
 
 	// Create media service instance
 	mediaRepo := repository.NewPostgresMediaRepository(s.db, s.minioClient)
-	mediaService := service.NewMediaService(mediaRepo, s.minioClient, s.bucket, s.logger)
+	mediaService := service.NewMediaService(mediaRepo, s.minioClient, s.bucket, s.signer, s.invalidator, s.variants, s.quotas, s.logger)
 
 	// Get media from database
 	media, variants, err := mediaService.GetMedia(ctx, req.MediaId)
 	if err != nil {
 		s.logger.Error("Failed to get media", zap.Error(err))
+		if errors.Is(err, service.ErrMediaDeleted) {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
 		return nil, fmt.Errorf("failed to get media: %w", err)
 	}
 
 	// Convert variants to proto format
-	variantsProto := make([]*mediapb.MediaVariant, 0, len(variants))
-	for _, v := range variants {
-		variantsProto = append(variantsProto, &mediapb.MediaVariant{
-			Name:   v.Name,
-			Url:    v.URL,
-			Width:  v.Width,
-			Height: v.Height,
-		})
-	}
+	variantsProto := variantsToProto(ctx, mediaService, media, variants)
 
 	// Add the original as a variant if not already included
 	originalExists := false
@@ -186,15 +185,21 @@ func (s *MediaService) GetMedia(ctx context.Context, req *mediapb.GetMediaReques
 	}
 
 	return &mediapb.Media{
-		MediaId:     media.ID,
-		CharacterId: models.StringValue(media.CharacterId),
-		WorldId:     media.WorldId,
-		Filename:    media.Filename,
-		ContentType: media.ContentType,
-		Size:        media.Size,
-		Variants:    variantsProto,
-		CreatedAt:   media.CreatedAt.Format(time.RFC3339),
-		MediaType:   mediapb.MediaType(media.MediaType),
+		MediaId:         media.ID,
+		CharacterId:     models.StringValue(media.CharacterId),
+		WorldId:         media.WorldId,
+		Filename:        media.Filename,
+		ContentType:     media.ContentType,
+		Size:            media.Size,
+		Variants:        variantsProto,
+		CreatedAt:       media.CreatedAt.Format(time.RFC3339),
+		MediaType:       mediapb.MediaType(media.MediaType),
+		Status:          mediaStatusToProto(media.Status),
+		Width:           media.Width,
+		Height:          media.Height,
+		ProcessingError: models.StringValue(media.ProcessingError),
+		Blurhash:        media.Blurhash,
+		DominantColor:   media.DominantColor,
 	}, nil
 }
 
@@ -207,7 +212,7 @@ func (s *MediaService) GetMediaURL(ctx context.Context, req *mediapb.GetMediaURL
 
 	// Create media service instance
 	mediaRepo := repository.NewPostgresMediaRepository(s.db, s.minioClient)
-	mediaService := service.NewMediaService(mediaRepo, s.minioClient, s.bucket, s.logger)
+	mediaService := service.NewMediaService(mediaRepo, s.minioClient, s.bucket, s.signer, s.invalidator, s.variants, s.quotas, s.logger)
 
 	// Get media from database
 	media, err := mediaRepo.GetMediaByID(ctx, req.MediaId)
@@ -215,8 +220,11 @@ func (s *MediaService) GetMediaURL(ctx context.Context, req *mediapb.GetMediaURL
 		s.logger.Error("Failed to get media from database", zap.Error(err))
 		return nil, fmt.Errorf("failed to get media from database: %w", err)
 	}
+	if media.Status == models.MediaStatusDeleted {
+		return nil, status.Errorf(codes.NotFound, "%v", service.ErrMediaDeleted)
+	}
 
-	// Generate presigned URL
+	// Generate signed URL
 	expiresIn := time.Duration(req.ExpiresIn) * time.Second
 	if expiresIn <= 0 {
 		expiresIn = time.Hour // Default expiry
@@ -224,13 +232,17 @@ func (s *MediaService) GetMediaURL(ctx context.Context, req *mediapb.GetMediaURL
 
 	urlStr, expiresAt, err := mediaService.GetPresignedURL(ctx, media, req.Variant, expiresIn)
 	if err != nil {
-		s.logger.Error("Failed to generate presigned URL", zap.Error(err))
-		return nil, fmt.Errorf("failed to generate presigned URL: %w", err)
+		s.logger.Error("Failed to generate media URL", zap.Error(err))
+		return nil, fmt.Errorf("failed to generate media URL: %w", err)
 	}
 
 	return &mediapb.GetMediaURLResponse{
-		Url:       urlStr,
-		ExpiresAt: expiresAt.Unix(),
+		Url:           urlStr,
+		ExpiresAt:     expiresAt.Unix(),
+		Width:         media.Width,
+		Height:        media.Height,
+		Blurhash:      media.Blurhash,
+		DominantColor: media.DominantColor,
 	}, nil
 }
 
@@ -240,31 +252,69 @@ func (s *MediaService) OptimizeImage(ctx context.Context, req *mediapb.OptimizeI
 
 	// Create media service instance
 	mediaRepo := repository.NewPostgresMediaRepository(s.db, s.minioClient)
-	mediaService := service.NewMediaService(mediaRepo, s.minioClient, s.bucket, s.logger)
+	mediaService := service.NewMediaService(mediaRepo, s.minioClient, s.bucket, s.signer, s.invalidator, s.variants, s.quotas, s.logger)
+
+	// Get media from database
+	media, err := mediaRepo.GetMediaByID(ctx, req.MediaId)
+	if err != nil {
+		s.logger.Error("Failed to get media from database", zap.Error(err))
+		return nil, fmt.Errorf("failed to get media from database: %w", err)
+	}
 
 	// Generate variants
 	variants, err := mediaService.GenerateVariants(ctx, req.MediaId, req.VariantsToCreate)
 	if err != nil {
 		s.logger.Error("Failed to generate variants", zap.Error(err))
+		switch {
+		case errors.Is(err, service.ErrUnknownVariant):
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		case errors.Is(err, imaging.ErrUnsupportedImage):
+			return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+		}
 		return nil, fmt.Errorf("failed to generate variants: %w", err)
 	}
 
 	// Convert variants to proto format
-	variantsProto := make([]*mediapb.MediaVariant, 0, len(variants))
-	for _, v := range variants {
-		variantsProto = append(variantsProto, &mediapb.MediaVariant{
-			Name:   v.Name,
-			Url:    v.URL,
-			Width:  v.Width,
-			Height: v.Height,
-		})
-	}
+	variantsProto := variantsToProto(ctx, mediaService, media, variants)
 
 	return &mediapb.OptimizeImageResponse{
 		Variants: variantsProto,
 	}, nil
 }
 
+// GetStorageUsage reports the storage used by a user and a world against their quotas
+func (s *MediaService) GetStorageUsage(ctx context.Context, req *mediapb.GetStorageUsageRequest) (*mediapb.GetStorageUsageResponse, error) {
+	if req.UserId == "" && req.WorldId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id or world_id is required")
+	}
+
+	mediaRepo := repository.NewPostgresMediaRepository(s.db, s.minioClient)
+	mediaService := service.NewMediaService(mediaRepo, s.minioClient, s.bucket, s.signer, s.invalidator, s.variants, s.quotas, s.logger)
+
+	user, world, quotas, err := mediaService.GetStorageUsage(ctx, req.UserId, req.WorldId)
+	if err != nil {
+		s.logger.Error("Failed to get storage usage", zap.Error(err))
+		return nil, fmt.Errorf("failed to get storage usage: %w", err)
+	}
+
+	resp := &mediapb.GetStorageUsageResponse{}
+	if user != nil {
+		resp.User = &mediapb.StorageUsage{
+			UsedBytes:  user.UsedBytes,
+			QuotaBytes: quotas.UserBytes,
+			MediaCount: user.MediaCount,
+		}
+	}
+	if world != nil {
+		resp.World = &mediapb.StorageUsage{
+			UsedBytes:  world.UsedBytes,
+			QuotaBytes: quotas.WorldBytes,
+			MediaCount: world.MediaCount,
+		}
+	}
+	return resp, nil
+}
+
 // HealthCheck implements the HealthCheck method
 func (s *MediaService) HealthCheck(ctx context.Context, req *mediapb.HealthCheckRequest) (*mediapb.HealthCheckResponse, error) {
 	// Placeholder implementation
@@ -360,6 +410,40 @@ func main() {
 	}
 	defer authConn.Close()
 
+	// Media URLs are signed by the CDN, which serves the objects from MinIO
+	cdnConn, cdnClient, err := createCDNClient(discoveryClient)
+	if err != nil {
+		logger.Logger.Fatal("Failed to create CDN client", zap.Error(err))
+	}
+	defer cdnConn.Close()
+	cdn := service.NewCDN(cdnClient)
+
+	// Variants generated for uploaded images, e.g. "thumb=150x150,small=320x320"
+	variants, err := imaging.ParseVariants(os.Getenv("MEDIA_VARIANTS"))
+	if err != nil {
+		logger.Logger.Fatal("Failed to parse MEDIA_VARIANTS", zap.Error(err))
+	}
+
+	// Initialize outbox relay, which publishes processing jobs stored together with media status changes
+	eventPublisher := events.NewKafkaPublisher(kafka.NewProducer(cfg.Kafka.Brokers))
+	defer eventPublisher.Close()
+	outboxRelay := outbox.NewRelay(db, eventPublisher, outbox.RelayConfig{})
+	outboxRelay.Start()
+
+	// Storage quotas in bytes, 0 disables a quota
+	quotas, err := quotasFromEnv()
+	if err != nil {
+		logger.Logger.Fatal("Failed to parse storage quotas", zap.Error(err))
+	}
+
+	// Expire abandoned uploads and reclaim storage of unreferenced media
+	sweeperConfig, err := sweeperConfigFromEnv()
+	if err != nil {
+		logger.Logger.Fatal("Failed to parse media sweeper config", zap.Error(err))
+	}
+	sweeper := service.NewSweeper(repository.NewPostgresMediaRepository(db, minioClient), minioClient, cdn, sweeperConfig, logger.Logger)
+	sweeper.Start()
+
 	// Initialize media service
 	mediaService := &MediaService{
 		logger:      logger.Logger,
@@ -367,6 +451,10 @@ func main() {
 		minioClient: minioClient,
 		db:          db,
 		bucket:      cfg.Minio.Bucket,
+		signer:      cdn,
+		invalidator: cdn,
+		variants:    variants,
+		quotas:      quotas,
 	}
 
 	// Create gRPC server with middleware
@@ -381,6 +469,9 @@ func main() {
 	// Register services
 	mediapb.RegisterMediaServiceServer(grpcServer, mediaService)
 
+	// Start processing uploads
+	consumers := startConsumers(cfg.Kafka.Brokers, mediaService)
+
 	// Register health check service
 	healthServer := health.NewServer()
 	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
@@ -422,6 +513,13 @@ func main() {
 
 	logger.Logger.Info("Shutting down media service...")
 	grpcServer.GracefulStop()
+	for _, consumer := range consumers {
+		if err := consumer.Close(); err != nil {
+			logger.Logger.Error("Failed to close consumer", zap.Error(err))
+		}
+	}
+	sweeper.Close()
+	outboxRelay.Close()
 	logger.Logger.Info("Media service stopped")
 }
 
@@ -452,3 +550,120 @@ func createAuthClient(discoveryClient discovery.ServiceDiscovery) (*grpc.ClientC
 
 	return conn, client, nil
 }
+
+func createCDNClient(discoveryClient discovery.ServiceDiscovery) (*grpc.ClientConn, cdnpb.CDNServiceClient, error) {
+	// Get service address from Consul
+	serviceAddress, err := discoveryClient.ResolveService("cdn-service")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve CDN service: %w", err)
+	}
+
+	// Create gRPC connection
+	conn, err := grpc.Dial(
+		serviceAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                10 * time.Second,
+			Timeout:             time.Second,
+			PermitWithoutStream: true,
+		}),
+		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to CDN service: %w", err)
+	}
+
+	// Create client
+	client := cdnpb.NewCDNServiceClient(conn)
+
+	return conn, client, nil
+}
+
+// variantsToProto converts stored variants to proto format with signed CDN URLs
+func variantsToProto(ctx context.Context, mediaService *service.MediaService, media *models.Media, variants []*models.MediaVariant) []*mediapb.MediaVariant {
+	variantsProto := make([]*mediapb.MediaVariant, 0, len(variants)+1)
+	for _, v := range variants {
+		urlStr, err := mediaService.GetVariantURL(ctx, media, v, time.Hour)
+		if err != nil {
+			logger.Logger.Error("Failed to generate variant URL", zap.Error(err), zap.String("variant", v.Name))
+			continue
+		}
+		variantsProto = append(variantsProto, &mediapb.MediaVariant{
+			Name:   v.Name,
+			Url:    urlStr,
+			Width:  v.Width,
+			Height: v.Height,
+		})
+	}
+	return variantsProto
+}
+
+// mediaStatuses maps stored statuses to the proto enum
+var mediaStatuses = map[string]mediapb.MediaStatus{
+	models.MediaStatusPending:           mediapb.MediaStatus_MEDIA_STATUS_PENDING,
+	models.MediaStatusPendingProcessing: mediapb.MediaStatus_MEDIA_STATUS_PENDING_PROCESSING,
+	models.MediaStatusReady:             mediapb.MediaStatus_MEDIA_STATUS_READY,
+	models.MediaStatusFailed:            mediapb.MediaStatus_MEDIA_STATUS_FAILED,
+	models.MediaStatusUploaded:          mediapb.MediaStatus_MEDIA_STATUS_UPLOADED,
+	models.MediaStatusOrphaned:          mediapb.MediaStatus_MEDIA_STATUS_ORPHANED,
+	models.MediaStatusDeleted:           mediapb.MediaStatus_MEDIA_STATUS_DELETED,
+}
+
+func mediaStatusToProto(status string) mediapb.MediaStatus {
+	return mediaStatuses[status]
+}
+
+// sweeperConfigFromEnv reads optional sweeper durations, e.g. MEDIA_SWEEPER_INTERVAL=5m.
+// Unset values fall back to the sweeper defaults.
+func sweeperConfigFromEnv() (service.SweeperConfig, error) {
+	var config service.SweeperConfig
+	durations := map[string]*time.Duration{
+		"MEDIA_SWEEPER_INTERVAL":     &config.Interval,
+		"MEDIA_SWEEPER_UPLOAD_GRACE": &config.UploadGrace,
+		"MEDIA_SWEEPER_ORPHAN_AFTER": &config.OrphanAfter,
+		"MEDIA_SWEEPER_DELETE_AFTER": &config.DeleteAfter,
+		"MEDIA_SWEEPER_BLOB_GRACE":   &config.BlobGrace,
+	}
+	for name, target := range durations {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*target = d
+	}
+	return config, nil
+}
+
+// Default storage quotas
+const (
+	defaultUserQuotaBytes  = 1 << 30  // 1 GiB
+	defaultWorldQuotaBytes = 10 << 30 // 10 GiB
+)
+
+// quotasFromEnv reads MEDIA_USER_QUOTA_BYTES and MEDIA_WORLD_QUOTA_BYTES
+func quotasFromEnv() (service.Quotas, error) {
+	quotas := service.Quotas{
+		UserBytes:  defaultUserQuotaBytes,
+		WorldBytes: defaultWorldQuotaBytes,
+	}
+	limits := map[string]*int64{
+		"MEDIA_USER_QUOTA_BYTES":  &quotas.UserBytes,
+		"MEDIA_WORLD_QUOTA_BYTES": &quotas.WorldBytes,
+	}
+	for name, target := range limits {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 0 {
+			return quotas, fmt.Errorf("invalid %s: %q", name, value)
+		}
+		*target = n
+	}
+	return quotas, nil
+}
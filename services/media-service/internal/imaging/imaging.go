@@ -0,0 +1,199 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register the GIF decoder, variants use the first frame
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register the WebP decoder
+)
+
+// Output formats
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatGIF  = "gif"
+	FormatWebP = "webp"
+)
+
+// WebPSuffix is appended to a variant name for its WebP rendition (e.g. "medium_webp")
+const WebPSuffix = "_webp"
+
+// MaxPixels limits the decoded size of an original to protect against decompression bombs
+const MaxPixels = 50_000_000
+
+// DefaultJPEGQuality is used for JPEG variants
+const DefaultJPEGQuality = 85
+
+// ErrUnsupportedImage is returned when the data is not an image the pipeline can decode
+var ErrUnsupportedImage = errors.New("unsupported image")
+
+// VariantSpec describes a named image variant. The image is scaled down to fit
+// into MaxWidth x MaxHeight keeping the aspect ratio; it is never scaled up.
+type VariantSpec struct {
+	Name      string
+	MaxWidth  int
+	MaxHeight int
+}
+
+// DefaultVariants returns the variants generated when none are configured
+func DefaultVariants() []VariantSpec {
+	return []VariantSpec{
+		{Name: "thumb", MaxWidth: 150, MaxHeight: 150},
+		{Name: "small", MaxWidth: 320, MaxHeight: 320},
+		{Name: "medium", MaxWidth: 720, MaxHeight: 720},
+		{Name: "large", MaxWidth: 1280, MaxHeight: 1280},
+	}
+}
+
+// ParseVariants parses a list like "thumb=150x150,small=320x320".
+// An empty string yields DefaultVariants.
+func ParseVariants(value string) ([]VariantSpec, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultVariants(), nil
+	}
+
+	var specs []VariantSpec
+	for _, item := range strings.Split(value, ",") {
+		name, size, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid variant %q", item)
+		}
+		if name == "original" || strings.HasSuffix(name, WebPSuffix) {
+			return nil, fmt.Errorf("reserved variant name %q", name)
+		}
+		w, h, ok := strings.Cut(size, "x")
+		if !ok {
+			return nil, fmt.Errorf("invalid variant size %q", size)
+		}
+		width, err := strconv.Atoi(w)
+		if err != nil || width <= 0 {
+			return nil, fmt.Errorf("invalid variant width %q", w)
+		}
+		height, err := strconv.Atoi(h)
+		if err != nil || height <= 0 {
+			return nil, fmt.Errorf("invalid variant height %q", h)
+		}
+		specs = append(specs, VariantSpec{Name: name, MaxWidth: width, MaxHeight: height})
+	}
+	return specs, nil
+}
+
+// Decode decodes a JPEG, PNG, GIF or WebP image and returns it with its format name
+func Decode(data []byte) (image.Image, string, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, "", fmt.Errorf("%w: invalid dimensions %dx%d", ErrUnsupportedImage, cfg.Width, cfg.Height)
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	return img, format, nil
+}
+
+// Fit scales img down to fit into maxWidth x maxHeight keeping the aspect ratio.
+// Images that already fit are returned unchanged.
+func Fit(img image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxWidth && height <= maxHeight {
+		return img
+	}
+
+	scale := min(float64(maxWidth)/float64(width), float64(maxHeight)/float64(height))
+	newWidth := max(1, int(float64(width)*scale+0.5))
+	newHeight := max(1, int(float64(height)*scale+0.5))
+
+	dst := image.NewNRGBA(image.Rect(0, 0, newWidth, newHeight))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// HasAlpha reports whether any pixel of img is not fully opaque
+func HasAlpha(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return !o.Opaque()
+	}
+	return true
+}
+
+// IsPaletted reports whether img uses a color palette (GIF, indexed PNG)
+func IsPaletted(img image.Image) bool {
+	_, ok := img.(*image.Paletted)
+	return ok
+}
+
+// OutputFormat picks the format a variant of a source image is stored in:
+// PNG for images with transparency or a palette, JPEG for photos
+func OutputFormat(img image.Image) string {
+	if HasAlpha(img) || IsPaletted(img) {
+		return FormatPNG
+	}
+	return FormatJPEG
+}
+
+// HasWebP reports whether variants in the given format get a WebP rendition.
+// The WebP encoder is lossless, so it only beats PNG; for photos it produces
+// files larger than the JPEG and they are stored as JPEG only. Lossy WebP for
+// photos would need an external encoder, which is not part of the build.
+func HasWebP(format string) bool {
+	return format == FormatPNG
+}
+
+// Encode writes img in the given format
+func Encode(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case FormatJPEG:
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: DefaultJPEGQuality})
+	case FormatPNG:
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		return encoder.Encode(w, img)
+	case FormatWebP:
+		return EncodeWebP(w, img)
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// Extension returns the file extension for a format
+func Extension(format string) string {
+	switch format {
+	case FormatJPEG:
+		return ".jpg"
+	case FormatPNG:
+		return ".png"
+	case FormatGIF:
+		return ".gif"
+	case FormatWebP:
+		return ".webp"
+	}
+	return ""
+}
+
+// ContentType returns the MIME type for a format
+func ContentType(format string) string {
+	switch format {
+	case FormatJPEG:
+		return "image/jpeg"
+	case FormatPNG:
+		return "image/png"
+	case FormatGIF:
+		return "image/gif"
+	case FormatWebP:
+		return "image/webp"
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,197 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// Placeholder settings: the image is shrunk first, placeholders need no detail
+const (
+	placeholderSize        = 64
+	blurhashXComponents    = 4
+	blurhashYComponents    = 3
+	dominantColorBits      = 4 // Bits kept per channel when bucketing colors
+	dominantColorMinAlpha  = 128
+	blurhashMaxComponents  = 9
+	blurhashAlphabet       = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+	blurhashAlphabetLength = len(blurhashAlphabet)
+)
+
+// Placeholder describes an image before it is loaded: clients paint the
+// dominant color or the decoded blurhash in a box of the intrinsic size
+type Placeholder struct {
+	Blurhash      string
+	DominantColor string // "#rrggbb", empty for fully transparent images
+	Width         int
+	Height        int
+}
+
+// NewPlaceholder computes the placeholder of a decoded image
+func NewPlaceholder(img image.Image) Placeholder {
+	bounds := img.Bounds()
+	small := toNRGBA(Fit(img, placeholderSize, placeholderSize))
+	return Placeholder{
+		Blurhash:      Blurhash(small, blurhashXComponents, blurhashYComponents),
+		DominantColor: DominantColor(small),
+		Width:         bounds.Dx(),
+		Height:        bounds.Dy(),
+	}
+}
+
+// Blurhash encodes an image as a blurhash (https://blurha.sh) with the given
+// number of horizontal and vertical components (1-9). Large images should be
+// shrunk first, the result barely depends on the resolution.
+func Blurhash(img image.Image, xComponents, yComponents int) string {
+	xComponents = min(max(xComponents, 1), blurhashMaxComponents)
+	yComponents = min(max(yComponents, 1), blurhashMaxComponents)
+
+	src := toNRGBA(img)
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	if width == 0 || height == 0 {
+		return ""
+	}
+
+	// Linear RGB of every pixel, computed once for all components
+	linear := make([][3]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := src.NRGBAAt(src.Rect.Min.X+x, src.Rect.Min.Y+y)
+			linear[y*width+x] = [3]float64{sRGBToLinear(c.R), sRGBToLinear(c.G), sRGBToLinear(c.B)}
+		}
+	}
+
+	factors := make([][3]float64, 0, xComponents*yComponents)
+	for j := 0; j < yComponents; j++ {
+		for i := 0; i < xComponents; i++ {
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1
+			}
+			var factor [3]float64
+			for y := 0; y < height; y++ {
+				basisY := math.Cos(math.Pi * float64(j) * float64(y) / float64(height))
+				for x := 0; x < width; x++ {
+					basis := basisY * math.Cos(math.Pi*float64(i)*float64(x)/float64(width))
+					pixel := linear[y*width+x]
+					factor[0] += basis * pixel[0]
+					factor[1] += basis * pixel[1]
+					factor[2] += basis * pixel[2]
+				}
+			}
+			scale := normalisation / float64(width*height)
+			factors = append(factors, [3]float64{factor[0] * scale, factor[1] * scale, factor[2] * scale})
+		}
+	}
+
+	var hash strings.Builder
+	encodeBase83(&hash, (xComponents-1)+(yComponents-1)*9, 1)
+
+	dc, ac := factors[0], factors[1:]
+	maximumValue := 1.0
+	if len(ac) > 0 {
+		actualMaximum := 0.0
+		for _, f := range ac {
+			actualMaximum = max(actualMaximum, math.Abs(f[0]), math.Abs(f[1]), math.Abs(f[2]))
+		}
+		quantisedMaximum := int(max(0, min(82, math.Floor(actualMaximum*166-0.5))))
+		maximumValue = float64(quantisedMaximum+1) / 166
+		encodeBase83(&hash, quantisedMaximum, 1)
+	} else {
+		encodeBase83(&hash, 0, 1)
+	}
+
+	encodeBase83(&hash, linearToSRGB(dc[0])<<16+linearToSRGB(dc[1])<<8+linearToSRGB(dc[2]), 4)
+	for _, f := range ac {
+		quantise := func(v float64) int {
+			return int(max(0, min(18, math.Floor(signPow(v/maximumValue, 0.5)*9+9.5))))
+		}
+		encodeBase83(&hash, quantise(f[0])*19*19+quantise(f[1])*19+quantise(f[2]), 2)
+	}
+	return hash.String()
+}
+
+// DominantColor returns the most common color of an image as "#rrggbb".
+// Colors are bucketed coarsely and the pixels of the largest bucket averaged;
+// mostly transparent pixels are ignored.
+func DominantColor(img image.Image) string {
+	src := toNRGBA(img)
+	bounds := src.Bounds()
+
+	const shift = 8 - dominantColorBits
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+	buckets := make(map[int]*bucket)
+	var best *bucket
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := src.NRGBAAt(x, y)
+			if c.A < dominantColorMinAlpha {
+				continue
+			}
+			key := int(c.R>>shift)<<(2*dominantColorBits) | int(c.G>>shift)<<dominantColorBits | int(c.B>>shift)
+			bk := buckets[key]
+			if bk == nil {
+				bk = &bucket{}
+				buckets[key] = bk
+			}
+			bk.count++
+			bk.r += int(c.R)
+			bk.g += int(c.G)
+			bk.b += int(c.B)
+			if best == nil || bk.count > best.count {
+				best = bk
+			}
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.count, best.g/best.count, best.b/best.count)
+}
+
+// toNRGBA converts an image to non-premultiplied RGBA unless it already is
+func toNRGBA(img image.Image) *image.NRGBA {
+	if nrgba, ok := img.(*image.NRGBA); ok {
+		return nrgba
+	}
+	bounds := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			dst.SetNRGBA(x-bounds.Min.X, y-bounds.Min.Y, color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA))
+		}
+	}
+	return dst
+}
+
+func encodeBase83(sb *strings.Builder, value, length int) {
+	for i := 1; i <= length; i++ {
+		digit := value / int(math.Pow(float64(blurhashAlphabetLength), float64(length-i))) % blurhashAlphabetLength
+		sb.WriteByte(blurhashAlphabet[digit])
+	}
+}
+
+func sRGBToLinear(value uint8) float64 {
+	v := float64(value) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(value float64) int {
+	v := max(0, min(1, value))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(value, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(value), exp), value)
+}
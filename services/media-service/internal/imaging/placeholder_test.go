@@ -0,0 +1,125 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// decodeBase83 is the inverse of encodeBase83
+func decodeBase83(s string) int {
+	value := 0
+	for _, c := range s {
+		value = value*blurhashAlphabetLength + strings.IndexRune(blurhashAlphabet, c)
+	}
+	return value
+}
+
+func TestBlurhash(t *testing.T) {
+	tests := []struct {
+		name        string
+		img         image.Image
+		xComponents int
+		yComponents int
+		wantLength  int
+		wantSize    int // Size flag: (x - 1) + (y - 1) * 9
+	}{
+		{"default components", gradient(32, 24, false), 4, 3, 4 + 2*4*3, 3 + 2*9},
+		{"single component", gradient(8, 8, false), 1, 1, 6, 0},
+		{"components are clamped", gradient(8, 8, false), 12, 0, 4 + 2*9*1, 8},
+		{"transparent pixels", gradient(16, 16, true), 3, 3, 4 + 2*3*3, 2 + 2*9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash := Blurhash(tt.img, tt.xComponents, tt.yComponents)
+			if len(hash) != tt.wantLength {
+				t.Fatalf("length of %q = %d, want %d", hash, len(hash), tt.wantLength)
+			}
+			for _, c := range hash {
+				if !strings.ContainsRune(blurhashAlphabet, c) {
+					t.Fatalf("%q contains %q outside the base83 alphabet", hash, c)
+				}
+			}
+			if size := decodeBase83(hash[:1]); size != tt.wantSize {
+				t.Errorf("size flag = %d, want %d", size, tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestBlurhashSolidColor(t *testing.T) {
+	for _, c := range []color.NRGBA{
+		{R: 255, G: 255, B: 255, A: 255},
+		{R: 0, G: 0, B: 0, A: 255},
+		{R: 200, G: 100, B: 50, A: 255},
+	} {
+		hash := Blurhash(solid(16, 16, c), 4, 3)
+
+		// The DC component is the average color
+		dc := decodeBase83(hash[2:6])
+		r, g, b := dc>>16, dc>>8&0xff, dc&0xff
+		if abs(r-int(c.R)) > 1 || abs(g-int(c.G)) > 1 || abs(b-int(c.B)) > 1 {
+			t.Errorf("DC of %v = (%d, %d, %d)", c, r, g, b)
+		}
+	}
+}
+
+func TestBlurhashEmptyImage(t *testing.T) {
+	if hash := Blurhash(image.NewNRGBA(image.Rect(0, 0, 0, 0)), 4, 3); hash != "" {
+		t.Errorf("Blurhash of an empty image = %q, want empty", hash)
+	}
+}
+
+func TestDominantColor(t *testing.T) {
+	mostlyRed := solid(10, 10, color.NRGBA{R: 250, A: 255})
+	for x := 0; x < 10; x++ {
+		mostlyRed.SetNRGBA(x, 0, color.NRGBA{B: 250, A: 255})
+	}
+	transparentBlue := solid(10, 10, color.NRGBA{R: 250, A: 255})
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 8; x++ {
+			transparentBlue.SetNRGBA(x, y, color.NRGBA{B: 250, A: 10})
+		}
+	}
+
+	tests := []struct {
+		name string
+		img  image.Image
+		want string
+	}{
+		{"solid", solid(4, 4, color.NRGBA{R: 0x12, G: 0x34, B: 0x56, A: 255}), "#123456"},
+		{"largest bucket wins", mostlyRed, "#fa0000"},
+		{"transparent pixels are ignored", transparentBlue, "#fa0000"},
+		{"fully transparent", solid(4, 4, color.NRGBA{R: 255}), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DominantColor(tt.img); got != tt.want {
+				t.Errorf("DominantColor = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPlaceholder(t *testing.T) {
+	p := NewPlaceholder(solid(300, 200, color.NRGBA{R: 10, G: 20, B: 30, A: 255}))
+	if p.Width != 300 || p.Height != 200 {
+		t.Errorf("size = %dx%d, want 300x200", p.Width, p.Height)
+	}
+	if p.DominantColor != "#0a141e" {
+		t.Errorf("dominant color = %q, want #0a141e", p.DominantColor)
+	}
+	if len(p.Blurhash) != 4+2*blurhashXComponents*blurhashYComponents {
+		t.Errorf("blurhash = %q", p.Blurhash)
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
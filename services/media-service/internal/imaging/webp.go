@@ -0,0 +1,440 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+	"sort"
+)
+
+// VP8L (lossless WebP) bitstream constants
+const (
+	vp8lSignature     = 0x2f
+	vp8lMaxDimension  = 1 << 14
+	vp8lPredictorBits = 9 // log2 of the predictor tile size
+	vp8lPredictorMode = 7 // Average2(L, T)
+
+	transformPredictor     = 0
+	transformSubtractGreen = 2
+
+	alphabetLiteral = 256
+	alphabetGreen   = 256 + 24 // literals plus LZ77 length prefixes, no color cache
+
+	maxCodeLength           = 15
+	maxCodeLengthCodeLength = 7
+)
+
+// codeLengthCodeOrder is the order in which code length code lengths are stored
+var codeLengthCodeOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// EncodeWebP writes img as a lossless WebP (VP8L) image.
+// The encoder applies the subtract-green and predictor transforms and
+// entropy-codes literals only, which keeps it small while still producing
+// files every WebP decoder accepts.
+func EncodeWebP(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 {
+		return errors.New("webp: empty image")
+	}
+	if width > vp8lMaxDimension || height > vp8lMaxDimension {
+		return errors.New("webp: image is too large")
+	}
+
+	nrgba := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+
+	argb := make([][4]uint8, width*height) // a, r, g, b
+	hasAlpha := false
+	for y := 0; y < height; y++ {
+		row := nrgba.Pix[y*nrgba.Stride:]
+		for x := 0; x < width; x++ {
+			r, g, b, a := row[4*x], row[4*x+1], row[4*x+2], row[4*x+3]
+			if a != 0xff {
+				hasAlpha = true
+			}
+			// Subtract-green transform
+			argb[y*width+x] = [4]uint8{a, r - g, g, b - g}
+		}
+	}
+	residuals := predict(argb, width, height)
+
+	bw := &bitWriter{}
+	bw.write(vp8lSignature, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if hasAlpha {
+		bw.write(1, 1)
+	} else {
+		bw.write(0, 1)
+	}
+	bw.write(0, 3) // version
+
+	// Transforms are undone by the decoder in reverse order
+	bw.write(1, 1)
+	bw.write(transformSubtractGreen, 2)
+	bw.write(1, 1)
+	bw.write(transformPredictor, 2)
+	bw.write(vp8lPredictorBits-2, 3)
+	writePredictorImage(bw)
+	bw.write(0, 1) // no more transforms
+
+	bw.write(0, 1) // no color cache
+	bw.write(0, 1) // no meta prefix codes
+	writeEntropyCodedPixels(bw, residuals)
+
+	return writeRIFF(w, bw.bytes())
+}
+
+// predict replaces every pixel with its residual against the predictor
+func predict(pix [][4]uint8, width, height int) [][4]uint8 {
+	out := make([][4]uint8, len(pix))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			var pred [4]uint8
+			switch {
+			case x == 0 && y == 0:
+				pred = [4]uint8{0xff, 0, 0, 0}
+			case y == 0:
+				pred = pix[i-1]
+			case x == 0:
+				pred = pix[i-width]
+			default:
+				left, top := pix[i-1], pix[i-width]
+				for c := range pred {
+					pred[c] = uint8((uint16(left[c]) + uint16(top[c])) / 2)
+				}
+			}
+			for c := range pred {
+				out[i][c] = pix[i][c] - pred[c]
+			}
+		}
+	}
+	return out
+}
+
+// writePredictorImage writes the sub-resolution image selecting the predictor
+// mode of every tile. All tiles use the same mode, so each prefix code has a
+// single symbol and the tile pixels themselves take no bits.
+func writePredictorImage(bw *bitWriter) {
+	bw.write(0, 1) // no color cache
+	writeSimpleCode(bw, []int{vp8lPredictorMode})
+	for i := 0; i < 4; i++ {
+		writeSimpleCode(bw, []int{0})
+	}
+}
+
+// writeEntropyCodedPixels writes the prefix code group and the literal pixels
+func writeEntropyCodedPixels(bw *bitWriter, pix [][4]uint8) {
+	var histograms [4][]int // green, red, blue, alpha
+	histograms[0] = make([]int, alphabetGreen)
+	for i := 1; i < 4; i++ {
+		histograms[i] = make([]int, alphabetLiteral)
+	}
+	for _, p := range pix {
+		histograms[0][p[2]]++
+		histograms[1][p[1]]++
+		histograms[2][p[3]]++
+		histograms[3][p[0]]++
+	}
+
+	var codes [4]*prefixCode
+	for i, histogram := range histograms {
+		codes[i] = writePrefixCode(bw, histogram)
+	}
+	writeSimpleCode(bw, []int{0}) // distance codes are never used
+
+	for _, p := range pix {
+		codes[0].writeSymbol(bw, int(p[2]))
+		codes[1].writeSymbol(bw, int(p[1]))
+		codes[2].writeSymbol(bw, int(p[3]))
+		codes[3].writeSymbol(bw, int(p[0]))
+	}
+}
+
+// prefixCode is a canonical Huffman code
+type prefixCode struct {
+	lengths []int
+	codes   []uint32 // bit-reversed, ready for an LSB-first writer
+	single  bool     // a single symbol is coded with zero bits
+}
+
+func (c *prefixCode) writeSymbol(bw *bitWriter, symbol int) {
+	if c.single {
+		return
+	}
+	bw.write(c.codes[symbol], uint(c.lengths[symbol]))
+}
+
+// writePrefixCode chooses between the simple and the normal code encoding,
+// writes the code and returns it
+func writePrefixCode(bw *bitWriter, histogram []int) *prefixCode {
+	var used []int
+	for symbol, count := range histogram {
+		if count > 0 {
+			used = append(used, symbol)
+		}
+	}
+	if len(used) == 0 {
+		used = []int{0}
+	}
+	if len(used) <= 2 && used[len(used)-1] < alphabetLiteral {
+		return writeSimpleCode(bw, used)
+	}
+
+	lengths := huffmanLengths(histogram, maxCodeLength)
+	code := newPrefixCode(lengths)
+
+	// Encode the code lengths with runs of zeros collapsed
+	type token struct{ symbol, extra, extraBits int }
+	var tokens []token
+	for i := 0; i < len(lengths); {
+		if lengths[i] != 0 {
+			tokens = append(tokens, token{symbol: lengths[i]})
+			i++
+			continue
+		}
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == 0 {
+			run++
+		}
+		i += run
+		for run > 0 {
+			switch {
+			case run >= 11:
+				n := min(run, 138)
+				tokens = append(tokens, token{symbol: 18, extra: n - 11, extraBits: 7})
+				run -= n
+			case run >= 3:
+				tokens = append(tokens, token{symbol: 17, extra: run - 3, extraBits: 3})
+				run = 0
+			default:
+				tokens = append(tokens, token{symbol: 0})
+				run--
+			}
+		}
+	}
+
+	codeLengthHistogram := make([]int, len(codeLengthCodeOrder))
+	for _, t := range tokens {
+		codeLengthHistogram[t.symbol]++
+	}
+	codeLengthLengths := huffmanLengths(codeLengthHistogram, maxCodeLengthCodeLength)
+	codeLengthCode := newPrefixCode(codeLengthLengths)
+
+	numCodes := 4
+	for i, symbol := range codeLengthCodeOrder {
+		if codeLengthLengths[symbol] != 0 {
+			numCodes = max(numCodes, i+1)
+		}
+	}
+
+	bw.write(0, 1) // normal code
+	bw.write(uint32(numCodes-4), 4)
+	for _, symbol := range codeLengthCodeOrder[:numCodes] {
+		bw.write(uint32(codeLengthLengths[symbol]), 3)
+	}
+	bw.write(0, 1) // code lengths are given for the whole alphabet
+	for _, t := range tokens {
+		codeLengthCode.writeSymbol(bw, t.symbol)
+		if t.extraBits > 0 {
+			bw.write(uint32(t.extra), uint(t.extraBits))
+		}
+	}
+
+	return code
+}
+
+// writeSimpleCode writes a code of one or two 8-bit symbols
+func writeSimpleCode(bw *bitWriter, symbols []int) *prefixCode {
+	bw.write(1, 1) // simple code
+	bw.write(uint32(len(symbols)-1), 1)
+	if symbols[0] < 2 {
+		bw.write(0, 1)
+		bw.write(uint32(symbols[0]), 1)
+	} else {
+		bw.write(1, 1)
+		bw.write(uint32(symbols[0]), 8)
+	}
+
+	code := &prefixCode{
+		lengths: make([]int, alphabetGreen),
+		codes:   make([]uint32, alphabetGreen),
+	}
+	if len(symbols) == 1 {
+		code.single = true
+		return code
+	}
+	bw.write(uint32(symbols[1]), 8)
+	code.lengths[symbols[0]], code.lengths[symbols[1]] = 1, 1
+	code.codes[symbols[1]] = 1
+	return code
+}
+
+// newPrefixCode assigns canonical codes to code lengths
+func newPrefixCode(lengths []int) *prefixCode {
+	code := &prefixCode{lengths: lengths, codes: make([]uint32, len(lengths))}
+
+	used := 0
+	var counts [maxCodeLength + 1]uint32
+	for _, l := range lengths {
+		if l > 0 {
+			counts[l]++
+			used++
+		}
+	}
+	if used <= 1 {
+		code.single = true
+		return code
+	}
+
+	var next [maxCodeLength + 1]uint32
+	var c uint32
+	for bits := 1; bits <= maxCodeLength; bits++ {
+		c = (c + counts[bits-1]) << 1
+		next[bits] = c
+	}
+	for symbol, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		code.codes[symbol] = reverse(next[l], l)
+		next[l]++
+	}
+	return code
+}
+
+func reverse(v uint32, n int) uint32 {
+	var r uint32
+	for i := 0; i < n; i++ {
+		r = r<<1 | v&1
+		v >>= 1
+	}
+	return r
+}
+
+// huffmanLengths computes Huffman code lengths limited to maxLength bits.
+// Counts are flattened until the tree fits, which is good enough for
+// alphabets of a few hundred symbols.
+func huffmanLengths(histogram []int, maxLength int) []int {
+	counts := append([]int(nil), histogram...)
+	for {
+		lengths := huffmanTree(counts)
+		longest := 0
+		for _, l := range lengths {
+			longest = max(longest, l)
+		}
+		if longest <= maxLength {
+			return lengths
+		}
+		for i, c := range counts {
+			if c > 0 {
+				counts[i] = (c + 1) / 2
+			}
+		}
+	}
+}
+
+// huffmanTree returns unrestricted Huffman code lengths. A single used symbol gets length 1.
+func huffmanTree(counts []int) []int {
+	type node struct {
+		weight      int
+		symbol      int // -1 for internal nodes
+		left, right int
+	}
+	var nodes []node
+	for symbol, c := range counts {
+		if c > 0 {
+			nodes = append(nodes, node{weight: c, symbol: symbol, left: -1, right: -1})
+		}
+	}
+	lengths := make([]int, len(counts))
+	switch len(nodes) {
+	case 0:
+		return lengths
+	case 1:
+		lengths[nodes[0].symbol] = 1
+		return lengths
+	}
+
+	// Two-queue construction over leaves sorted by weight
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].weight < nodes[j].weight })
+	leaves := len(nodes)
+	nextLeaf, nextInternal := 0, leaves
+	pick := func() int {
+		if nextLeaf < leaves && (nextInternal >= len(nodes) || nodes[nextLeaf].weight <= nodes[nextInternal].weight) {
+			nextLeaf++
+			return nextLeaf - 1
+		}
+		nextInternal++
+		return nextInternal - 1
+	}
+	for i := 0; i < leaves-1; i++ {
+		a, b := pick(), pick()
+		nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight, symbol: -1, left: a, right: b})
+	}
+
+	var walk func(n, depth int)
+	walk = func(n, depth int) {
+		if nodes[n].symbol >= 0 {
+			lengths[nodes[n].symbol] = depth
+			return
+		}
+		walk(nodes[n].left, depth+1)
+		walk(nodes[n].right, depth+1)
+	}
+	walk(len(nodes)-1, 0)
+	return lengths
+}
+
+// bitWriter packs values LSB-first as required by VP8L
+type bitWriter struct {
+	buf   bytes.Buffer
+	acc   uint64
+	nbits uint
+}
+
+func (w *bitWriter) write(v uint32, n uint) {
+	w.acc |= uint64(v&(1<<n-1)) << w.nbits
+	w.nbits += n
+	for w.nbits >= 8 {
+		w.buf.WriteByte(byte(w.acc))
+		w.acc >>= 8
+		w.nbits -= 8
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.nbits > 0 {
+		w.buf.WriteByte(byte(w.acc))
+		w.acc, w.nbits = 0, 0
+	}
+	return w.buf.Bytes()
+}
+
+// writeRIFF wraps a VP8L bitstream into a WebP container
+func writeRIFF(w io.Writer, data []byte) error {
+	padded := len(data) + len(data)&1
+	header := make([]byte, 20)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+8+padded))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(data)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if padded != len(data) {
+		if _, err := w.Write([]byte{0}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+func TestEncodeWebPRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{"single pixel", solid(1, 1, color.NRGBA{R: 10, G: 20, B: 30, A: 255})},
+		{"solid", solid(17, 9, color.NRGBA{R: 200, G: 100, B: 50, A: 255})},
+		{"gradient", gradient(64, 48, false)},
+		{"gradient with alpha", gradient(33, 65, true)},
+		{"larger than a predictor tile", gradient(700, 3, true)},
+		{"paletted", paletted(40, 30)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := EncodeWebP(&buf, tt.img); err != nil {
+				t.Fatalf("EncodeWebP: %v", err)
+			}
+
+			decoded, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("webp.Decode: %v", err)
+			}
+			if decoded.Bounds().Size() != tt.img.Bounds().Size() {
+				t.Fatalf("size = %v, want %v", decoded.Bounds().Size(), tt.img.Bounds().Size())
+			}
+
+			// The encoding is lossless, every pixel must survive
+			bounds := tt.img.Bounds()
+			for y := 0; y < bounds.Dy(); y++ {
+				for x := 0; x < bounds.Dx(); x++ {
+					want := color.NRGBAModel.Convert(tt.img.At(bounds.Min.X+x, bounds.Min.Y+y))
+					got := color.NRGBAModel.Convert(decoded.At(decoded.Bounds().Min.X+x, decoded.Bounds().Min.Y+y))
+					if got != want {
+						t.Fatalf("pixel (%d, %d) = %v, want %v", x, y, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeWebPRejectsEmptyImage(t *testing.T) {
+	if err := EncodeWebP(&bytes.Buffer{}, image.NewNRGBA(image.Rect(0, 0, 0, 0))); err == nil {
+		t.Fatal("EncodeWebP of an empty image succeeded")
+	}
+}
+
+func TestOutputFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		img      image.Image
+		format   string
+		withWebP bool
+	}{
+		{"photo", image.NewYCbCr(image.Rect(0, 0, 4, 4), image.YCbCrSubsampleRatio420), FormatJPEG, false},
+		{"opaque", solid(4, 4, color.NRGBA{R: 1, G: 2, B: 3, A: 255}), FormatJPEG, false},
+		{"alpha", gradient(4, 4, true), FormatPNG, true},
+		{"paletted", paletted(4, 4), FormatPNG, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := OutputFormat(tt.img)
+			if format != tt.format {
+				t.Fatalf("OutputFormat = %q, want %q", format, tt.format)
+			}
+			if got := HasWebP(format); got != tt.withWebP {
+				t.Fatalf("HasWebP(%q) = %v, want %v", format, got, tt.withWebP)
+			}
+		})
+	}
+}
+
+func solid(width, height int, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func gradient(width, height int, alpha bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBA{R: uint8(x * 7), G: uint8(y * 5), B: uint8(x*y + 3), A: 255}
+			if alpha {
+				c.A = uint8(x + y*3)
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func paletted(width, height int) *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, width, height), palette.Plan9)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetColorIndex(x, y, uint8(x+y*width))
+		}
+	}
+	return img
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"path"
 	"path/filepath"
 	"time"
 )
@@ -15,6 +16,17 @@ const (
 	MediaTypePostImage       = 4
 )
 
+// Media lifecycle statuses
+const (
+	MediaStatusPending           = "pending"            // Upload URL issued, upload not confirmed yet
+	MediaStatusUploaded          = "uploaded"           // Object found in storage after the upload URL expired, never confirmed
+	MediaStatusPendingProcessing = "pending_processing" // Upload confirmed, processing job enqueued
+	MediaStatusReady             = "ready"              // Original sanitized and variants generated
+	MediaStatusFailed            = "failed"             // Upload is not a supported image
+	MediaStatusOrphaned          = "orphaned"           // Not referenced by any post, character or world, awaiting deletion
+	MediaStatusDeleted           = "deleted"            // Expired or reclaimed, objects removed from storage
+)
+
 // Helper functions for pointer handling
 func StringPtr(s string) *string {
 	if s == "" {
@@ -49,27 +61,79 @@ func GenerateObjectName(worldId, characterId, mediaId, filename string, mediaTyp
 	}
 }
 
+// GenerateVariantObjectName generates the object name of a variant, stored next to the original
+// under the variant key of the media (see Media.VariantKey)
+func GenerateVariantObjectName(originalObjectName, key, variant, ext string) string {
+	return path.Join(path.Dir(originalObjectName), fmt.Sprintf("%s_%s%s", key, variant, ext))
+}
+
+// GenerateBlobObjectName generates the object name of a content-addressed original
+func GenerateBlobObjectName(contentHash, ext string) string {
+	return BlobPrefix(contentHash) + ext
+}
+
+// BlobPrefix is the object name prefix shared by a blob and its variants
+func BlobPrefix(contentHash string) string {
+	return fmt.Sprintf("blobs/%s/%s", contentHash[:2], contentHash)
+}
+
 // Media represents a media entity in the database
 type Media struct {
-	ID          string    `db:"id" json:"id"`
-	CharacterId *string   `db:"character_id" json:"character_id"` // Nullable for world-level media
-	WorldId     string    `db:"world_id" json:"world_id"`
-	Filename    string    `db:"filename" json:"filename"`
-	ContentType string    `db:"content_type" json:"content_type"`
-	Size        int64     `db:"size" json:"size"`
-	BucketName  string    `db:"bucket" json:"bucket"`
-	ObjectName  string    `db:"object_name" json:"object_name"`
-	MediaType   int32     `db:"media_type" json:"media_type"` // Corresponds to proto MediaType enum
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	ID              string     `db:"id" json:"id"`
+	CharacterId     *string    `db:"character_id" json:"character_id"` // Nullable for world-level media
+	WorldId         string     `db:"world_id" json:"world_id"`
+	UserId          *string    `db:"user_id" json:"user_id"` // Uploader charged for the media, nil for AI-generated media
+	Filename        string     `db:"filename" json:"filename"`
+	ContentType     string     `db:"content_type" json:"content_type"`
+	Size            int64      `db:"size" json:"size"`
+	BucketName      string     `db:"bucket" json:"bucket"`
+	ObjectName      string     `db:"object_name" json:"object_name"`
+	MediaType       int32      `db:"media_type" json:"media_type"` // Corresponds to proto MediaType enum
+	Status          string     `db:"status" json:"status"`
+	Width           int32      `db:"width" json:"width"`
+	Height          int32      `db:"height" json:"height"`
+	Blurhash        string     `db:"blurhash" json:"blurhash"`
+	DominantColor   string     `db:"dominant_color" json:"dominant_color"`               // #rrggbb
+	ProcessingError *string    `db:"processing_error" json:"processing_error,omitempty"` // Why processing failed
+	ContentHash     *string    `db:"content_hash" json:"content_hash,omitempty"`         // SHA-256 of the processed original
+	OrphanedAt      *time.Time `db:"orphaned_at" json:"orphaned_at,omitempty"`
+	DeletedAt       *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// VariantKey is the name variants are stored under: the content hash for
+// deduplicated media, so identical uploads share their variants, the ID otherwise
+func (m *Media) VariantKey() string {
+	if m.ContentHash != nil {
+		return *m.ContentHash
+	}
+	return m.ID
+}
+
+// MediaBlob is a stored original shared by all media with identical content
+type MediaBlob struct {
+	ContentHash string     `db:"content_hash" json:"content_hash"`
+	BucketName  string     `db:"bucket" json:"bucket"`
+	ObjectName  string     `db:"object_name" json:"object_name"`
+	Size        int64      `db:"size" json:"size"`
+	RefCount    int32      `db:"ref_count" json:"ref_count"`
+	ReleasedAt  *time.Time `db:"released_at" json:"released_at,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+}
+
+// StorageUsage is the storage charged to a user or a world
+type StorageUsage struct {
+	UsedBytes  int64 `db:"used_bytes" json:"used_bytes"`
+	MediaCount int64 `db:"media_count" json:"media_count"`
 }
 
-// MediaVariant represents a variant of a media (e.g., thumbnail, medium, original)
+// MediaVariant represents a variant of a media (e.g., thumb, medium, medium_webp)
 type MediaVariant struct {
 	ID        string    `db:"id" json:"id"`
 	MediaID   string    `db:"media_id" json:"media_id"`
 	Name      string    `db:"name" json:"name"`
-	URL       string    `db:"url" json:"url"`
+	URL       string    `db:"url" json:"url"` // Object name of the variant in the media bucket
 	Width     int32     `db:"width" json:"width"`
 	Height    int32     `db:"height" json:"height"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
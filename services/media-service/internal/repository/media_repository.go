@@ -2,21 +2,61 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/minio/minio-go/v7"
+	"github.com/sdshorin/generia/pkg/events"
+	"github.com/sdshorin/generia/pkg/outbox"
 	"github.com/sdshorin/generia/services/media-service/internal/models"
 )
 
+// ErrQuotaExceeded is returned when a write would exceed a storage quota
+var ErrQuotaExceeded = errors.New("storage quota exceeded")
+
+// QuotaLimits limits the storage charged to a user and to a world, 0 means unlimited
+type QuotaLimits struct {
+	UserBytes  int64
+	WorldBytes int64
+}
+
 // MediaRepository defines the interface for media data access
 type MediaRepository interface {
 	CreateMedia(ctx context.Context, media *models.Media) error
+	CreateMediaWithinQuota(ctx context.Context, media *models.Media, limits QuotaLimits) error
 	GetMediaByID(ctx context.Context, id string) (*models.Media, error)
 	GetMediaVariants(ctx context.Context, mediaID string) ([]*models.MediaVariant, error)
 	CreateMediaVariant(ctx context.Context, variant *models.MediaVariant) error
+	MarkPendingProcessing(ctx context.Context, media *models.Media, staleAfter time.Duration, limits QuotaLimits) (bool, error)
+	UpdateProcessingResult(ctx context.Context, media *models.Media) error
+	ListExpiredUploads(ctx context.Context, createdBefore time.Time, limit int) ([]*models.Media, error)
+	UpdateStatus(ctx context.Context, id, from, to string) (bool, error)
+	MarkOrphaned(ctx context.Context, updatedBefore time.Time, limit int) (int64, error)
+	RestoreReferenced(ctx context.Context) (int64, error)
+	MarkDeleted(ctx context.Context, orphanedBefore time.Time, limit int) (int64, error)
+	ListUnpurged(ctx context.Context, limit int) ([]*models.Media, error)
+	MarkPurged(ctx context.Context, media *models.Media) error
+	AttachBlob(ctx context.Context, media *models.Media, blob *models.MediaBlob) (bool, error)
+	CopyVariants(ctx context.Context, media *models.Media) (int64, error)
+	DeleteReleasedBlobs(ctx context.Context, releasedBefore time.Time, limit int) ([]*models.MediaBlob, error)
+	GetUserUsage(ctx context.Context, userID string) (*models.StorageUsage, error)
+	GetWorldUsage(ctx context.Context, worldID string) (*models.StorageUsage, error)
 }
 
+// mediaColumns are the columns selected into models.Media
+const mediaColumns = `id, character_id, world_id, user_id, filename, content_type, size, bucket, object_name, media_type,
+	status, width, height, blurhash, dominant_color, processing_error, content_hash, orphaned_at, deleted_at, created_at, updated_at`
+
+// unreferencedMedia matches media m that no post, character avatar or world image points to
+const unreferencedMedia = `
+	NOT EXISTS (SELECT 1 FROM posts p WHERE p.media_id = m.id)
+	AND NOT EXISTS (SELECT 1 FROM world_user_characters c WHERE c.avatar_media_id = m.id)
+	AND NOT EXISTS (SELECT 1 FROM worlds w WHERE w.image_uuid = m.id OR w.icon_uuid = m.id)`
+
 // PostgresMediaRepository implements MediaRepository interface
 type PostgresMediaRepository struct {
 	db          *sqlx.DB
@@ -37,24 +77,89 @@ func (r *PostgresMediaRepository) CreateMedia(ctx context.Context, media *models
 	now := time.Now()
 	media.CreatedAt = now
 	media.UpdatedAt = now
+	if media.Status == "" {
+		media.Status = models.MediaStatusPending
+	}
 
 	// Insert media record
+	_, err := r.db.NamedExecContext(ctx, insertMediaQuery, media)
+	return err
+}
+
+const insertMediaQuery = `
+	INSERT INTO media (id, character_id, world_id, user_id, filename, content_type, size, bucket, object_name, media_type, status, created_at, updated_at)
+	VALUES (:id, :character_id, :world_id, :user_id, :filename, :content_type, :size, :bucket, :object_name, :media_type, :status, :created_at, :updated_at)
+`
+
+// CreateMediaWithinQuota stores a new media record if its size fits the quotas
+// of its user and world, otherwise it returns ErrQuotaExceeded. The check and
+// the insert run under the quota locks, so concurrent uploads can't overbook a quota.
+func (r *PostgresMediaRepository) CreateMediaWithinQuota(ctx context.Context, media *models.Media, limits QuotaLimits) error {
+	now := time.Now()
+	media.CreatedAt = now
+	media.UpdatedAt = now
+	if media.Status == "" {
+		media.Status = models.MediaStatusPending
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := reserveQuota(ctx, tx, media, media.Size, limits); err != nil {
+		return err
+	}
+	if _, err := tx.NamedExecContext(ctx, insertMediaQuery, media); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// reserveQuota takes the quota locks of the user and the world of a media and
+// fails with ErrQuotaExceeded if size bytes of it don't fit next to their
+// other media. The locks are held until the transaction ends; the user's is
+// always taken first, so concurrent reservations can't deadlock.
+func reserveQuota(ctx context.Context, tx *sqlx.Tx, media *models.Media, size int64, limits QuotaLimits) error {
+	userID := models.StringValue(media.UserId)
+	if userID != "" && limits.UserBytes > 0 {
+		if err := checkQuota(ctx, tx, "user_id", userID, media.ID, size, limits.UserBytes); err != nil {
+			return err
+		}
+	}
+	if limits.WorldBytes > 0 {
+		if err := checkQuota(ctx, tx, "world_id", media.WorldId, media.ID, size, limits.WorldBytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkQuota(ctx context.Context, tx *sqlx.Tx, column, id, mediaID string, size, limit int64) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "media_quota:"+column+":"+id); err != nil {
+		return fmt.Errorf("failed to lock quota: %w", err)
+	}
+
+	var used int64
 	query := `
-		INSERT INTO media (id, character_id, world_id, filename, content_type, size, bucket, object_name, media_type, created_at, updated_at)
-		VALUES (:id, :character_id, :world_id, :filename, :content_type, :size, :bucket, :object_name, :media_type, :created_at, :updated_at)
+		SELECT COALESCE(SUM(size), 0)
+		FROM media
+		WHERE ` + column + ` = $1 AND status <> $2 AND id <> $3
 	`
-	_, err := r.db.NamedExecContext(ctx, query, media)
-	return err
+	if err := tx.GetContext(ctx, &used, query, id, models.MediaStatusDeleted, mediaID); err != nil {
+		return err
+	}
+	if used+size > limit {
+		return fmt.Errorf("%w: %s %s uses %d of %d bytes", ErrQuotaExceeded, strings.TrimSuffix(column, "_id"), id, used, limit)
+	}
+	return nil
 }
 
 // GetMediaByID retrieves a media record by its ID
 func (r *PostgresMediaRepository) GetMediaByID(ctx context.Context, id string) (*models.Media, error) {
 	var media models.Media
-	query := `
-		SELECT id, character_id, world_id, filename, content_type, size, bucket, object_name, media_type, created_at, updated_at
-		FROM media
-		WHERE id = $1
-	`
+	query := `SELECT ` + mediaColumns + ` FROM media WHERE id = $1`
 	err := r.db.GetContext(ctx, &media, query, id)
 	if err != nil {
 		return nil, err
@@ -77,13 +182,389 @@ func (r *PostgresMediaRepository) GetMediaVariants(ctx context.Context, mediaID
 	return variants, nil
 }
 
-// CreateMediaVariant stores a new media variant record
+// CreateMediaVariant stores a media variant record, replacing an existing variant with the same name
 func (r *PostgresMediaRepository) CreateMediaVariant(ctx context.Context, variant *models.MediaVariant) error {
 	variant.CreatedAt = time.Now()
 	query := `
 		INSERT INTO media_variants (id, media_id, name, url, width, height, created_at)
 		VALUES (:id, :media_id, :name, :url, :width, :height, :created_at)
+		ON CONFLICT (media_id, name) DO UPDATE
+		SET url = EXCLUDED.url, width = EXCLUDED.width, height = EXCLUDED.height, created_at = EXCLUDED.created_at
 	`
 	_, err := r.db.NamedExecContext(ctx, query, variant)
 	return err
 }
+
+// MarkPendingProcessing moves a confirmed upload (pending or uploaded) to pending_processing, records
+// its stored size and enqueues the processing job through the outbox in the same transaction.
+// It returns false if the media is already ready or has a job in flight that is not older than staleAfter.
+// A stored size larger than the recorded one must fit the quotas, otherwise ErrQuotaExceeded is returned.
+func (r *PostgresMediaRepository) MarkPendingProcessing(ctx context.Context, media *models.Media, staleAfter time.Duration, limits QuotaLimits) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var recorded int64
+	err = tx.GetContext(ctx, &recorded, `SELECT size FROM media WHERE id = $1 FOR UPDATE`, media.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if media.Size > recorded {
+		if err := reserveQuota(ctx, tx, media, media.Size, limits); err != nil {
+			return false, err
+		}
+	}
+
+	now := time.Now()
+	query := `
+		UPDATE media
+		SET status = $2, size = $8, processing_error = NULL, updated_at = $3
+		WHERE id = $1
+		  AND (status IN ($4, $5, $7) OR (status = $2 AND updated_at < $6))
+		RETURNING updated_at
+	`
+	var updatedAt time.Time
+	err = tx.QueryRowxContext(ctx, query,
+		media.ID,
+		models.MediaStatusPendingProcessing,
+		now,
+		models.MediaStatusPending,
+		models.MediaStatusFailed,
+		now.Add(-staleAfter),
+		models.MediaStatusUploaded,
+		media.Size,
+	).Scan(&updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	actor := events.Actor{CharacterID: models.StringValue(media.CharacterId)}
+	err = outbox.InsertEvent(ctx, tx, events.MediaUploaded, media.WorldId, actor, events.MediaUploadedPayload{
+		MediaID:    media.ID,
+		UploadedAt: now,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	media.Status = models.MediaStatusPendingProcessing
+	media.ProcessingError = nil
+	media.UpdatedAt = updatedAt
+	return true, nil
+}
+
+// UpdateProcessingResult stores the outcome of processing an upload
+func (r *PostgresMediaRepository) UpdateProcessingResult(ctx context.Context, media *models.Media) error {
+	media.UpdatedAt = time.Now()
+	query := `
+		UPDATE media
+		SET status = :status, content_type = :content_type, size = :size, width = :width, height = :height,
+		    blurhash = :blurhash, dominant_color = :dominant_color,
+		    processing_error = :processing_error, updated_at = :updated_at
+		WHERE id = :id
+	`
+	_, err := r.db.NamedExecContext(ctx, query, media)
+	return err
+}
+
+// ListExpiredUploads returns pending media whose upload URL was issued before createdBefore
+func (r *PostgresMediaRepository) ListExpiredUploads(ctx context.Context, createdBefore time.Time, limit int) ([]*models.Media, error) {
+	media := []*models.Media{}
+	query := `SELECT ` + mediaColumns + ` FROM media WHERE status = $1 AND created_at < $2 ORDER BY created_at LIMIT $3`
+	err := r.db.SelectContext(ctx, &media, query, models.MediaStatusPending, createdBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// UpdateStatus moves a media from one status to another and reports whether it
+// was still in the expected status
+func (r *PostgresMediaRepository) UpdateStatus(ctx context.Context, id, from, to string) (bool, error) {
+	query := `
+		UPDATE media
+		SET status = $3::text, updated_at = $4,
+		    orphaned_at = CASE WHEN $3::text = $5 THEN $4 ELSE orphaned_at END,
+		    deleted_at = CASE WHEN $3::text = $6 THEN $4 ELSE deleted_at END
+		WHERE id = $1 AND status = $2
+	`
+	result, err := r.db.ExecContext(ctx, query, id, from, to, time.Now(), models.MediaStatusOrphaned, models.MediaStatusDeleted)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// MarkOrphaned marks up to limit unreferenced media, not changed since updatedBefore, as orphaned.
+// Media still being uploaded or processed is left alone.
+func (r *PostgresMediaRepository) MarkOrphaned(ctx context.Context, updatedBefore time.Time, limit int) (int64, error) {
+	query := `
+		UPDATE media
+		SET status = $1, orphaned_at = $2, updated_at = $2
+		WHERE id IN (
+			SELECT m.id FROM media m
+			WHERE m.status IN ($3, $4, $5) AND m.updated_at < $6 AND ` + unreferencedMedia + `
+			LIMIT $7
+		)
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		models.MediaStatusOrphaned,
+		time.Now(),
+		models.MediaStatusUploaded,
+		models.MediaStatusReady,
+		models.MediaStatusFailed,
+		updatedBefore,
+		limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RestoreReferenced returns orphaned media that got referenced again to the
+// status it would have had: ready if processed, failed if processing failed,
+// uploaded otherwise
+func (r *PostgresMediaRepository) RestoreReferenced(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE media m
+		SET status = CASE
+		        WHEN m.width > 0 THEN $2
+		        WHEN m.processing_error IS NOT NULL THEN $3
+		        ELSE $4
+		    END,
+		    orphaned_at = NULL, updated_at = $5
+		WHERE m.status = $1 AND NOT (` + unreferencedMedia + `)
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		models.MediaStatusOrphaned,
+		models.MediaStatusReady,
+		models.MediaStatusFailed,
+		models.MediaStatusUploaded,
+		time.Now(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// MarkDeleted marks up to limit media orphaned before orphanedBefore, and still
+// unreferenced, as deleted. Their objects are removed by the purge that follows.
+func (r *PostgresMediaRepository) MarkDeleted(ctx context.Context, orphanedBefore time.Time, limit int) (int64, error) {
+	query := `
+		UPDATE media
+		SET status = $1, deleted_at = $2, updated_at = $2
+		WHERE id IN (
+			SELECT m.id FROM media m
+			WHERE m.status = $3 AND m.orphaned_at < $4 AND ` + unreferencedMedia + `
+			LIMIT $5
+		)
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		models.MediaStatusDeleted,
+		time.Now(),
+		models.MediaStatusOrphaned,
+		orphanedBefore,
+		limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ListUnpurged returns deleted media whose objects have not been removed from storage yet
+func (r *PostgresMediaRepository) ListUnpurged(ctx context.Context, limit int) ([]*models.Media, error) {
+	media := []*models.Media{}
+	query := `SELECT ` + mediaColumns + ` FROM media WHERE status = $1 AND purged_at IS NULL ORDER BY deleted_at LIMIT $2`
+	err := r.db.SelectContext(ctx, &media, query, models.MediaStatusDeleted, limit)
+	if err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// MarkPurged records that the objects of a deleted media were removed and drops
+// its variants. A deduplicated media releases its reference on the blob instead;
+// the blob objects are removed once no media references them.
+func (r *PostgresMediaRepository) MarkPurged(ctx context.Context, media *models.Media) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM media_variants WHERE media_id = $1`, media.ID); err != nil {
+		return err
+	}
+	if media.ContentHash != nil {
+		if err := releaseBlob(ctx, tx, *media.ContentHash, now); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE media SET purged_at = $2 WHERE id = $1`, media.ID, now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AttachBlob points a media at the shared original for its content and takes a
+// reference on the blob. It returns true if the blob did not exist before.
+// Attaching the blob a media already points at is a no-op, so a redelivered
+// processing job does not take a second reference.
+func (r *PostgresMediaRepository) AttachBlob(ctx context.Context, media *models.Media, blob *models.MediaBlob) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var current sql.NullString
+	err = tx.GetContext(ctx, &current, `SELECT content_hash FROM media WHERE id = $1 FOR UPDATE`, media.ID)
+	if err != nil {
+		return false, err
+	}
+	if current.Valid && current.String == blob.ContentHash {
+		media.ContentHash = &blob.ContentHash
+		media.ObjectName = blob.ObjectName
+		return false, tx.Commit()
+	}
+
+	now := time.Now()
+	if current.Valid {
+		if err := releaseBlob(ctx, tx, current.String, now); err != nil {
+			return false, err
+		}
+	}
+
+	// xmax is 0 only for a freshly inserted row
+	query := `
+		INSERT INTO media_blobs (content_hash, bucket, object_name, size, ref_count, created_at)
+		VALUES ($1, $2, $3, $4, 1, $5)
+		ON CONFLICT (content_hash) DO UPDATE
+		SET ref_count = media_blobs.ref_count + 1, released_at = NULL
+		RETURNING (xmax = 0) AS inserted
+	`
+	var created bool
+	err = tx.QueryRowxContext(ctx, query, blob.ContentHash, blob.BucketName, blob.ObjectName, blob.Size, now).Scan(&created)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE media SET content_hash = $2, object_name = $3, updated_at = $4 WHERE id = $1`,
+		media.ID, blob.ContentHash, blob.ObjectName, now)
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	media.ContentHash = &blob.ContentHash
+	media.ObjectName = blob.ObjectName
+	media.UpdatedAt = now
+	return created, nil
+}
+
+// releaseBlob drops a reference on a blob, remembering when the last one went away
+func releaseBlob(ctx context.Context, tx *sqlx.Tx, contentHash string, now time.Time) error {
+	query := `
+		UPDATE media_blobs
+		SET ref_count = ref_count - 1,
+		    released_at = CASE WHEN ref_count <= 1 THEN $2 ELSE NULL END
+		WHERE content_hash = $1 AND ref_count > 0
+	`
+	_, err := tx.ExecContext(ctx, query, contentHash, now)
+	return err
+}
+
+// CopyVariants gives a deduplicated media the variants already generated for
+// another ready media with the same content, which share the same objects.
+// It returns how many variants were copied.
+func (r *PostgresMediaRepository) CopyVariants(ctx context.Context, media *models.Media) (int64, error) {
+	if media.ContentHash == nil {
+		return 0, nil
+	}
+	query := `
+		INSERT INTO media_variants (media_id, name, url, width, height, created_at)
+		SELECT $1::uuid, v.name, v.url, v.width, v.height, $2::timestamptz
+		FROM media_variants v
+		WHERE v.media_id = (
+			SELECT m.id FROM media m
+			WHERE m.content_hash = $3 AND m.id <> $1::uuid AND m.status = $4
+			ORDER BY m.created_at
+			LIMIT 1
+		)
+		ON CONFLICT (media_id, name) DO NOTHING
+	`
+	result, err := r.db.ExecContext(ctx, query, media.ID, time.Now(), *media.ContentHash, models.MediaStatusReady)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteReleasedBlobs deletes up to limit blobs nothing has referenced since
+// releasedBefore and returns them, so their objects can be removed
+func (r *PostgresMediaRepository) DeleteReleasedBlobs(ctx context.Context, releasedBefore time.Time, limit int) ([]*models.MediaBlob, error) {
+	blobs := []*models.MediaBlob{}
+	query := `
+		DELETE FROM media_blobs
+		WHERE content_hash IN (
+			SELECT content_hash FROM media_blobs
+			WHERE ref_count = 0 AND released_at < $1
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING content_hash, bucket, object_name, size, ref_count, released_at, created_at
+	`
+	err := r.db.SelectContext(ctx, &blobs, query, releasedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	return blobs, nil
+}
+
+// GetUserUsage returns the storage charged to a user. Every media counts with
+// its own size, deduplicated or not; deleted media is not counted.
+func (r *PostgresMediaRepository) GetUserUsage(ctx context.Context, userID string) (*models.StorageUsage, error) {
+	return r.getUsage(ctx, "user_id", userID)
+}
+
+// GetWorldUsage returns the storage charged to a world
+func (r *PostgresMediaRepository) GetWorldUsage(ctx context.Context, worldID string) (*models.StorageUsage, error) {
+	return r.getUsage(ctx, "world_id", worldID)
+}
+
+func (r *PostgresMediaRepository) getUsage(ctx context.Context, column, id string) (*models.StorageUsage, error) {
+	var usage models.StorageUsage
+	query := `
+		SELECT COALESCE(SUM(size), 0) AS used_bytes, COUNT(*) AS media_count
+		FROM media
+		WHERE ` + column + ` = $1 AND status <> $2
+	`
+	err := r.db.GetContext(ctx, &usage, query, id, models.MediaStatusDeleted)
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cdnpb "github.com/sdshorin/generia/api/grpc/cdn"
+)
+
+// URLSigner signs read URLs for stored objects
+type URLSigner interface {
+	SignURL(ctx context.Context, objectName string, expiresIn time.Duration) (string, time.Time, error)
+}
+
+// CacheInvalidator evicts objects from the CDN cache, e.g. after they were removed
+type CacheInvalidator interface {
+	Invalidate(ctx context.Context, paths []string) (string, error)
+}
+
+// CDN signs URLs served by the CDN service and invalidates its cache. The CDN
+// rounds expiry times up, so URLs stay the same for a while and can be cached by clients.
+type CDN struct {
+	client cdnpb.CDNServiceClient
+}
+
+// NewCDN creates a new CDN
+func NewCDN(client cdnpb.CDNServiceClient) *CDN {
+	return &CDN{client: client}
+}
+
+// SignURL implements URLSigner
+func (c *CDN) SignURL(ctx context.Context, objectName string, expiresIn time.Duration) (string, time.Time, error) {
+	resp, err := c.client.GetSignedURL(ctx, &cdnpb.GetSignedURLRequest{
+		Path:      objectName,
+		ExpiresIn: int32(expiresIn / time.Second),
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign CDN URL: %w", err)
+	}
+	return resp.Url, time.Unix(resp.ExpiresAt, 0), nil
+}
+
+// Invalidate implements CacheInvalidator. Paths are object names, '*' matches
+// any sequence of characters. It returns the ID of the queued operation.
+func (c *CDN) Invalidate(ctx context.Context, paths []string) (string, error) {
+	resp, err := c.client.InvalidateCache(ctx, &cdnpb.InvalidateCacheRequest{Paths: paths})
+	if err != nil {
+		return "", fmt.Errorf("failed to invalidate CDN cache: %w", err)
+	}
+	return resp.OperationId, nil
+}
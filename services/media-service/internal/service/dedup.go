@@ -0,0 +1,95 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sdshorin/generia/services/media-service/internal/imaging"
+	"github.com/sdshorin/generia/services/media-service/internal/models"
+	"go.uber.org/zap"
+)
+
+var (
+	dedupHitsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "media_dedup_hits_total",
+		Help: "Number of uploads whose content was already stored",
+	})
+
+	dedupBytesSavedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "media_dedup_bytes_saved_total",
+		Help: "Number of bytes not stored thanks to content deduplication",
+	})
+)
+
+// storeOriginal stores a processed original under its SHA-256, so identical
+// uploads share one object, and points the media at it. The per-upload object
+// is removed afterwards. It returns true if the content was already stored.
+func (s *MediaService) storeOriginal(ctx context.Context, media *models.Media, data []byte, format, contentType string) (bool, error) {
+	sum := sha256.Sum256(data)
+	contentHash := hex.EncodeToString(sum[:])
+	blob := &models.MediaBlob{
+		ContentHash: contentHash,
+		BucketName:  media.BucketName,
+		ObjectName:  models.GenerateBlobObjectName(contentHash, imaging.Extension(format)),
+		Size:        int64(len(data)),
+	}
+
+	// The object goes first, so a blob row never exists without its object
+	_, err := s.minioClient.StatObject(ctx, blob.BucketName, blob.ObjectName, minio.StatObjectOptions{})
+	uploaded := false
+	if isNoSuchKey(err) {
+		if err := s.putBlob(ctx, blob, data, contentType); err != nil {
+			return false, err
+		}
+		uploaded = true
+	} else if err != nil {
+		return false, fmt.Errorf("failed to check blob in storage: %w", err)
+	}
+
+	uploadObject := media.ObjectName
+	created, err := s.repo.AttachBlob(ctx, media, blob)
+	if err != nil {
+		return false, fmt.Errorf("failed to attach blob: %w", err)
+	}
+	// The sweeper may have removed the objects of a released blob in the meantime
+	if created && !uploaded {
+		if err := s.putBlob(ctx, blob, data, contentType); err != nil {
+			return false, err
+		}
+	}
+
+	if uploadObject != blob.ObjectName {
+		err := s.minioClient.RemoveObject(ctx, media.BucketName, uploadObject, minio.RemoveObjectOptions{})
+		if err != nil && !isNoSuchKey(err) {
+			// Not fatal: the media already points at the blob
+			s.logger.Warn("Failed to remove upload object",
+				zap.String("media_id", media.ID),
+				zap.String("object_name", uploadObject),
+				zap.Error(err))
+		}
+	}
+
+	duplicate := !created && !uploaded
+	if duplicate {
+		dedupHitsTotal.Inc()
+		dedupBytesSavedTotal.Add(float64(blob.Size))
+	}
+	return duplicate, nil
+}
+
+func (s *MediaService) putBlob(ctx context.Context, blob *models.MediaBlob, data []byte, contentType string) error {
+	_, err := s.minioClient.PutObject(ctx, blob.BucketName, blob.ObjectName, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType:  contentType,
+		CacheControl: variantCacheControl,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload original: %w", err)
+	}
+	return nil
+}
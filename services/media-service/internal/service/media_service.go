@@ -5,31 +5,68 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"net/url"
-	"path/filepath"
+	"image"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/minio/minio-go/v7"
+	"github.com/sdshorin/generia/services/media-service/internal/imaging"
 	"github.com/sdshorin/generia/services/media-service/internal/models"
 	"github.com/sdshorin/generia/services/media-service/internal/repository"
 	"go.uber.org/zap"
 )
 
+// maxOriginalSize limits how much of an original is read for variant generation
+const maxOriginalSize = 50 << 20
+
+// variantCacheControl is set on variant objects, which never change once written
+const variantCacheControl = "public, max-age=31536000, immutable"
+
+// uploadURLExpiry is how long a presigned upload URL stays valid
+const uploadURLExpiry = 10 * time.Minute
+
+var (
+	// ErrUnknownVariant is returned when a requested variant is not configured
+	ErrUnknownVariant = errors.New("unknown variant")
+
+	// ErrMediaDeleted is returned for media that expired or was reclaimed by the sweeper
+	ErrMediaDeleted = errors.New("media deleted")
+
+	// ErrQuotaExceeded is returned when an upload would exceed a storage quota
+	ErrQuotaExceeded = repository.ErrQuotaExceeded
+)
+
+// Quotas limits the storage charged to a user and to a world, 0 means unlimited
+type Quotas struct {
+	UserBytes  int64
+	WorldBytes int64
+}
+
 // MediaService provides business logic for media operations
 type MediaService struct {
 	repo        repository.MediaRepository
 	minioClient *minio.Client
 	bucket      string
+	signer      URLSigner
+	invalidator CacheInvalidator
+	variants    []imaging.VariantSpec
+	quotas      Quotas
 	logger      *zap.Logger
 }
 
 // NewMediaService creates a new MediaService
-func NewMediaService(repo repository.MediaRepository, minioClient *minio.Client, bucket string, logger *zap.Logger) *MediaService {
+func NewMediaService(repo repository.MediaRepository, minioClient *minio.Client, bucket string, signer URLSigner, invalidator CacheInvalidator, variants []imaging.VariantSpec, quotas Quotas, logger *zap.Logger) *MediaService {
 	return &MediaService{
 		repo:        repo,
 		minioClient: minioClient,
 		bucket:      bucket,
+		signer:      signer,
+		invalidator: invalidator,
+		variants:    variants,
+		quotas:      quotas,
 		logger:      logger,
 	}
 }
@@ -73,11 +110,19 @@ func (s *MediaService) CreateMedia(ctx context.Context, worldID, characterID, fi
 		return nil, fmt.Errorf("failed to store media in database: %w", err)
 	}
 
+	// The data is already in storage, so processing can start right away
+	if _, err := s.repo.MarkPendingProcessing(ctx, media, staleProcessingAfter, repository.QuotaLimits{}); err != nil {
+		return nil, fmt.Errorf("failed to enqueue media processing: %w", err)
+	}
+
 	return media, nil
 }
 
-// GeneratePresignedPutURL generates a presigned URL for client-side uploading
-func (s *MediaService) GeneratePresignedPutURL(ctx context.Context, worldID, characterID, filename, contentType string, size int64, mediaType int32) (*models.Media, string, time.Time, error) {
+// GeneratePresignedPutURL generates a presigned URL for client-side uploading.
+// The declared size is reserved in the quotas of the uploader and the world,
+// the stored one is checked again in ConfirmMediaUpload; userID is empty for
+// AI-generated media, which only counts towards the world.
+func (s *MediaService) GeneratePresignedPutURL(ctx context.Context, worldID, characterID, userID, filename, contentType string, size int64, mediaType int32) (*models.Media, string, time.Time, error) {
 	// Generate a unique ID
 	id, err := GenerateID()
 	if err != nil {
@@ -88,8 +133,7 @@ func (s *MediaService) GeneratePresignedPutURL(ctx context.Context, worldID, cha
 	objectName := models.GenerateObjectName(worldID, characterID, id, filename, mediaType)
 
 	// Generate presigned PUT URL
-	expiry := time.Minute * 10 // 10 minutes expiry for upload
-	presignedURL, err := s.minioClient.PresignedPutObject(ctx, s.bucket, objectName, expiry)
+	presignedURL, err := s.minioClient.PresignedPutObject(ctx, s.bucket, objectName, uploadURLExpiry)
 	if err != nil {
 		return nil, "", time.Time{}, fmt.Errorf("failed to generate presigned URL: %w", err)
 	}
@@ -99,6 +143,7 @@ func (s *MediaService) GeneratePresignedPutURL(ctx context.Context, worldID, cha
 		ID:          id,
 		CharacterId: models.StringPtr(characterID),
 		WorldId:     worldID,
+		UserId:      models.StringPtr(userID),
 		Filename:    filename,
 		ContentType: contentType,
 		Size:        size,
@@ -107,36 +152,95 @@ func (s *MediaService) GeneratePresignedPutURL(ctx context.Context, worldID, cha
 		MediaType:   mediaType,
 	}
 
-	// Store in database
-	err = s.repo.CreateMedia(ctx, media)
+	// Store in database, the quota check and the insert are atomic
+	err = s.repo.CreateMediaWithinQuota(ctx, media, repository.QuotaLimits(s.quotas))
+	if errors.Is(err, ErrQuotaExceeded) {
+		return nil, "", time.Time{}, err
+	}
 	if err != nil {
 		return nil, "", time.Time{}, fmt.Errorf("failed to store media in database: %w", err)
 	}
 
-	expiresAt := time.Now().Add(expiry)
+	expiresAt := time.Now().Add(uploadURLExpiry)
 	return media, presignedURL.String(), expiresAt, nil
 }
 
-// ConfirmMediaUpload confirms that a media file has been uploaded via presigned URL
-func (s *MediaService) ConfirmMediaUpload(ctx context.Context, mediaID string) error {
+// GetStorageUsage returns the storage charged to a user and to a world together
+// with their quotas. Empty IDs yield nil usage.
+func (s *MediaService) GetStorageUsage(ctx context.Context, userID, worldID string) (user, world *models.StorageUsage, quotas Quotas, err error) {
+	if userID != "" {
+		user, err = s.repo.GetUserUsage(ctx, userID)
+		if err != nil {
+			return nil, nil, quotas, fmt.Errorf("failed to get user storage usage: %w", err)
+		}
+	}
+	if worldID != "" {
+		world, err = s.repo.GetWorldUsage(ctx, worldID)
+		if err != nil {
+			return nil, nil, quotas, fmt.Errorf("failed to get world storage usage: %w", err)
+		}
+	}
+	return user, world, s.quotas, nil
+}
+
+// ConfirmMediaUpload confirms that a media file has been uploaded via presigned URL.
+// The media is marked pending_processing and a processing job is enqueued;
+// confirming again is a no-op until the job fails or goes stale. An upload larger
+// than declared that exceeds a quota is deleted and ErrQuotaExceeded returned.
+func (s *MediaService) ConfirmMediaUpload(ctx context.Context, mediaID string) (*models.Media, error) {
 	// Get media from database
 	media, err := s.repo.GetMediaByID(ctx, mediaID)
 	if err != nil {
-		return fmt.Errorf("failed to get media from database: %w", err)
+		return nil, fmt.Errorf("failed to get media from database: %w", err)
+	}
+	if media.Status == models.MediaStatusDeleted {
+		return nil, ErrMediaDeleted
 	}
 
 	// Character ID check removed as it's no longer required
 
 	// Check if object exists in MinIO
-	_, err = s.minioClient.StatObject(ctx, media.BucketName, media.ObjectName, minio.StatObjectOptions{})
+	info, err := s.minioClient.StatObject(ctx, media.BucketName, media.ObjectName, minio.StatObjectOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to verify media in storage: %w", err)
+		return nil, fmt.Errorf("failed to verify media in storage: %w", err)
 	}
 
-	// Generate variants if needed (thumbnails, etc.)
-	// This could be triggered as an async process via Kafka
+	// The upload URL does not limit the size, so a file larger than declared
+	// is checked against the quotas again when its size is recorded
+	declaredSize := media.Size
+	media.Size = info.Size
 
-	return nil
+	// Enqueue processing (sniffing, EXIF stripping, variants) via the outbox
+	queued, err := s.repo.MarkPendingProcessing(ctx, media, staleProcessingAfter, repository.QuotaLimits(s.quotas))
+	if errors.Is(err, ErrQuotaExceeded) {
+		s.rejectUpload(ctx, media, declaredSize)
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to enqueue media processing: %w", err)
+	}
+	if queued {
+		s.logger.Info("Media processing enqueued", zap.String("media_id", mediaID))
+	}
+
+	return media, nil
+}
+
+// rejectUpload deletes an upload that exceeds a quota. The object is removed
+// right away so it stops taking space; the sweeper purges the rest.
+func (s *MediaService) rejectUpload(ctx context.Context, media *models.Media, declaredSize int64) {
+	s.logger.Warn("Upload exceeds storage quota",
+		zap.String("media_id", media.ID),
+		zap.Int64("declared_size", declaredSize),
+		zap.Int64("size", media.Size))
+
+	if _, err := s.repo.UpdateStatus(ctx, media.ID, media.Status, models.MediaStatusDeleted); err != nil {
+		s.logger.Error("Failed to delete rejected upload", zap.String("media_id", media.ID), zap.Error(err))
+		return
+	}
+	if err := s.minioClient.RemoveObject(ctx, media.BucketName, media.ObjectName, minio.RemoveObjectOptions{}); err != nil {
+		s.logger.Warn("Failed to remove rejected upload from storage", zap.String("media_id", media.ID), zap.Error(err))
+	}
 }
 
 // GetMedia retrieves a media by its ID
@@ -146,6 +250,9 @@ func (s *MediaService) GetMedia(ctx context.Context, id string) (*models.Media,
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get media from database: %w", err)
 	}
+	if media.Status == models.MediaStatusDeleted {
+		return nil, nil, ErrMediaDeleted
+	}
 
 	// Get variants
 	variants, err := s.repo.GetMediaVariants(ctx, id)
@@ -156,57 +263,246 @@ func (s *MediaService) GetMedia(ctx context.Context, id string) (*models.Media,
 	return media, variants, nil
 }
 
-// GetPresignedURL generates a presigned URL for a media object
+// GetPresignedURL generates a signed CDN URL for a media object.
+// A missing WebP rendition falls back to its base variant (photos have none),
+// and a variant that has not been generated yet falls back to the original.
 func (s *MediaService) GetPresignedURL(ctx context.Context, media *models.Media, variant string, expiresIn time.Duration) (string, time.Time, error) {
-	// Determine the object name based on variant
-	var objectName string
-	if variant == "original" {
-		objectName = media.ObjectName
+	objectName := media.ObjectName
+	if variant != "" && variant != "original" {
+		variants, err := s.repo.GetMediaVariants(ctx, media.ID)
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("failed to get media variants: %w", err)
+		}
+		urls := make(map[string]string, len(variants))
+		for _, v := range variants {
+			urls[v.Name] = v.URL
+		}
+		if url, ok := urls[variant]; ok {
+			objectName = url
+		} else if url, ok := urls[strings.TrimSuffix(variant, imaging.WebPSuffix)]; ok {
+			objectName = url
+		}
+	}
+
+	return s.signer.SignURL(ctx, objectName, expiresIn)
+}
+
+// InvalidateMedia evicts the original and the variants of a media from the CDN
+// cache, so an image stops being served once the post showing it is deleted.
+// A deduplicated media evicts every object of its blob, the objects are shared.
+func (s *MediaService) InvalidateMedia(ctx context.Context, mediaID string) error {
+	media, err := s.repo.GetMediaByID(ctx, mediaID)
+	if err != nil {
+		return fmt.Errorf("failed to get media from database: %w", err)
+	}
+
+	paths := []string{media.ObjectName}
+	if media.ContentHash != nil {
+		paths = []string{models.BlobPrefix(*media.ContentHash) + "*"}
 	} else {
-		objectName = fmt.Sprintf("%s/%s_%s%s", models.StringValue(media.CharacterId), media.ID, variant, filepath.Ext(media.Filename))
+		variants, err := s.repo.GetMediaVariants(ctx, mediaID)
+		if err != nil {
+			return fmt.Errorf("failed to get media variants: %w", err)
+		}
+		for _, v := range variants {
+			paths = append(paths, v.URL)
+		}
+	}
+
+	if _, err := s.invalidator.Invalidate(ctx, paths); err != nil {
+		return err
+	}
+	s.logger.Info("Media invalidated in CDN cache", zap.String("media_id", mediaID), zap.Strings("paths", paths))
+	return nil
+}
+
+// GetVariantURL generates a signed CDN URL for a stored variant
+func (s *MediaService) GetVariantURL(ctx context.Context, media *models.Media, variant *models.MediaVariant, expiresIn time.Duration) (string, error) {
+	urlStr, _, err := s.signer.SignURL(ctx, variant.URL, expiresIn)
+	return urlStr, err
+}
+
+// GenerateVariants creates the resized variants of an image together with their
+// WebP renditions ("<name>_webp") for PNG variants, uploads them next to the
+// original and records them in the database. Variants that already exist are
+// returned as is.
+// An empty list generates all configured variants.
+func (s *MediaService) GenerateVariants(ctx context.Context, mediaID string, variantsToCreate []string) ([]*models.MediaVariant, error) {
+	specs, err := s.resolveVariants(variantsToCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	media, err := s.repo.GetMediaByID(ctx, mediaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media from database: %w", err)
+	}
+
+	stored, err := s.repo.GetMediaVariants(ctx, mediaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media variants: %w", err)
+	}
+	existing := make(map[string]*models.MediaVariant, len(stored))
+	for _, v := range stored {
+		existing[v.Name] = v
+	}
+
+	var img image.Image
+	result := make([]*models.MediaVariant, 0, 2*len(specs))
+	for _, spec := range specs {
+		if base := existing[spec.Name]; base != nil {
+			webp := existing[spec.Name+imaging.WebPSuffix]
+			if webp != nil {
+				result = append(result, base, webp)
+				continue
+			}
+			if !strings.HasSuffix(base.URL, imaging.Extension(imaging.FormatPNG)) {
+				result = append(result, base)
+				continue
+			}
+		}
+
+		// Decode the original lazily, only when something has to be generated
+		if img == nil {
+			img, err = s.loadImage(ctx, media)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		variants, err := s.createVariants(ctx, media, spec, img)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, variants...)
+	}
+
+	s.logger.Info("Generated media variants",
+		zap.String("media_id", mediaID),
+		zap.Int("variants", len(result)))
+
+	return result, nil
+}
+
+// resolveVariants maps requested names to configured variants.
+// WebP names ("medium_webp") resolve to their base variant.
+func (s *MediaService) resolveVariants(names []string) ([]imaging.VariantSpec, error) {
+	if len(names) == 0 {
+		return s.variants, nil
+	}
+
+	specs := make([]imaging.VariantSpec, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSuffix(name, imaging.WebPSuffix)
+		if seen[name] {
+			continue
+		}
+		found := false
+		for _, spec := range s.variants {
+			if spec.Name == name {
+				specs = append(specs, spec)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownVariant, name)
+		}
+		seen[name] = true
+	}
+	return specs, nil
+}
+
+// createVariants renders a variant from a decoded original, together with its
+// WebP rendition when the variant is stored as PNG
+func (s *MediaService) createVariants(ctx context.Context, media *models.Media, spec imaging.VariantSpec, img image.Image) ([]*models.MediaVariant, error) {
+	resized := imaging.Fit(img, spec.MaxWidth, spec.MaxHeight)
+
+	format := imaging.OutputFormat(img)
+	outputs := []struct{ name, format string }{{spec.Name, format}}
+	if imaging.HasWebP(format) {
+		outputs = append(outputs, struct{ name, format string }{spec.Name + imaging.WebPSuffix, imaging.FormatWebP})
 	}
 
-	// If no variants exist yet but original is requested, use the object name
-	if variant != "original" {
-		// Check if the variant exists
-		_, err := s.minioClient.StatObject(ctx, media.BucketName, objectName, minio.StatObjectOptions{})
+	variants := make([]*models.MediaVariant, 0, len(outputs))
+	for _, output := range outputs {
+		variant, err := s.storeVariant(ctx, media, output.name, resized, output.format)
 		if err != nil {
-			// If variant doesn't exist, use the original
-			objectName = media.ObjectName
+			return nil, err
 		}
+		variants = append(variants, variant)
 	}
+	return variants, nil
+}
 
-	// Generate presigned URL
-	reqParams := make(url.Values)
-	if media.ContentType != "" {
-		reqParams.Set("response-content-type", media.ContentType)
+// readOriginal downloads the original of a media
+func (s *MediaService) readOriginal(ctx context.Context, media *models.Media) ([]byte, error) {
+	object, err := s.minioClient.GetObject(ctx, media.BucketName, media.ObjectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get original from storage: %w", err)
 	}
+	defer object.Close()
 
-	url, err := s.minioClient.PresignedGetObject(ctx, media.BucketName, objectName, expiresIn, reqParams)
+	data, err := io.ReadAll(io.LimitReader(object, maxOriginalSize+1))
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("failed to generate presigned URL: %w", err)
+		return nil, fmt.Errorf("failed to read original from storage: %w", err)
 	}
+	if len(data) > maxOriginalSize {
+		return nil, fmt.Errorf("%w: original exceeds %d bytes", imaging.ErrUnsupportedImage, maxOriginalSize)
+	}
+	return data, nil
+}
 
-	expiresAt := time.Now().Add(expiresIn)
-	return url.String(), expiresAt, nil
+// loadImage downloads and decodes the original of a media
+func (s *MediaService) loadImage(ctx context.Context, media *models.Media) (image.Image, error) {
+	data, err := s.readOriginal(ctx, media)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
 }
 
-// GenerateVariants creates different size variants of an image
-func (s *MediaService) GenerateVariants(ctx context.Context, mediaID string, variantsToCreate []string) ([]*models.MediaVariant, error) {
-	// In a real implementation, this would:
-	// 1. Get the original media from MinIO
-	// 2. Use an image processing library to create variants
-	// 3. Upload variants to MinIO
-	// 4. Store variant information in the database
-
-	// For now, just return a placeholder implementation
-	s.logger.Info("GenerateVariants called",
-		zap.String("media_id", mediaID),
-		zap.Strings("variants", variantsToCreate))
+// storeVariant encodes a variant, uploads it to MinIO and records it in the database
+func (s *MediaService) storeVariant(ctx context.Context, media *models.Media, name string, img image.Image, format string) (*models.MediaVariant, error) {
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, format); err != nil {
+		return nil, fmt.Errorf("failed to encode variant %s: %w", name, err)
+	}
+
+	objectName := models.GenerateVariantObjectName(media.ObjectName, media.VariantKey(), name, imaging.Extension(format))
+	_, err := s.minioClient.PutObject(ctx, media.BucketName, objectName, &buf, int64(buf.Len()), minio.PutObjectOptions{
+		ContentType:  imaging.ContentType(format),
+		CacheControl: variantCacheControl,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload variant %s: %w", name, err)
+	}
 
-	// This method would typically be called asynchronously after an upload
+	id, err := GenerateID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ID: %w", err)
+	}
+
+	bounds := img.Bounds()
+	variant := &models.MediaVariant{
+		ID:      id,
+		MediaID: media.ID,
+		Name:    name,
+		URL:     objectName,
+		Width:   int32(bounds.Dx()),
+		Height:  int32(bounds.Dy()),
+	}
+	if err := s.repo.CreateMediaVariant(ctx, variant); err != nil {
+		return nil, fmt.Errorf("failed to store variant %s: %w", name, err)
+	}
 
-	return []*models.MediaVariant{}, nil
+	return variant, nil
 }
 
 // GenerateID generates a unique ID for media
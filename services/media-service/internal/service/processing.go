@@ -0,0 +1,134 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sdshorin/generia/services/media-service/internal/imaging"
+	"github.com/sdshorin/generia/services/media-service/internal/models"
+	"go.uber.org/zap"
+)
+
+// staleProcessingAfter is how long a processing job may stay in flight before
+// confirming the upload again enqueues a new one
+const staleProcessingAfter = 15 * time.Minute
+
+// ProcessMedia runs the post-upload pipeline for a confirmed upload: it sniffs
+// the real content type, strips EXIF and other metadata from the original
+// (applying the EXIF orientation first), stores it content-addressed (see
+// storeOriginal), records the dimensions and a placeholder (blurhash and
+// dominant color), generates all configured variants and marks the media ready.
+//
+// Uploads that are not supported images are marked failed and nil is returned,
+// so the job is not retried. Other errors are returned for a retry. Media that
+// is not pending_processing is skipped, which makes redelivered jobs harmless.
+func (s *MediaService) ProcessMedia(ctx context.Context, mediaID string) error {
+	media, err := s.repo.GetMediaByID(ctx, mediaID)
+	if err != nil {
+		return fmt.Errorf("failed to get media from database: %w", err)
+	}
+	if media.Status != models.MediaStatusPendingProcessing {
+		s.logger.Info("Skipping media processing",
+			zap.String("media_id", mediaID),
+			zap.String("status", media.Status))
+		return nil
+	}
+
+	data, err := s.readOriginal(ctx, media)
+	if errors.Is(err, imaging.ErrUnsupportedImage) {
+		return s.failProcessing(ctx, media, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	format, contentType, ok := imaging.SniffFormat(data)
+	if !ok {
+		return s.failProcessing(ctx, media, fmt.Sprintf("unsupported content type %s", contentType))
+	}
+
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		return s.failProcessing(ctx, media, err.Error())
+	}
+
+	// Rotated JPEGs are re-encoded upright, everything else keeps its pixels untouched
+	var sanitized []byte
+	if orientation := imaging.Orientation(data); format == imaging.FormatJPEG && orientation > 1 {
+		img = imaging.Orient(img, orientation)
+		var buf bytes.Buffer
+		if err := imaging.Encode(&buf, img, imaging.FormatJPEG); err != nil {
+			return fmt.Errorf("failed to encode oriented original: %w", err)
+		}
+		sanitized = buf.Bytes()
+	} else {
+		sanitized, err = imaging.StripMetadata(data, format)
+		if err != nil {
+			return s.failProcessing(ctx, media, err.Error())
+		}
+	}
+
+	duplicate, err := s.storeOriginal(ctx, media, sanitized, format, contentType)
+	if err != nil {
+		return err
+	}
+
+	// Identical content already has its variants, otherwise every variant is rendered again
+	variants := 0
+	if duplicate {
+		copied, err := s.repo.CopyVariants(ctx, media)
+		if err != nil {
+			return fmt.Errorf("failed to copy variants: %w", err)
+		}
+		variants = int(copied)
+	}
+	if variants == 0 {
+		for _, spec := range s.variants {
+			created, err := s.createVariants(ctx, media, spec, img)
+			if err != nil {
+				return err
+			}
+			variants += len(created)
+		}
+	}
+
+	placeholder := imaging.NewPlaceholder(img)
+	media.ContentType = contentType
+	media.Size = int64(len(sanitized))
+	media.Width = int32(placeholder.Width)
+	media.Height = int32(placeholder.Height)
+	media.Blurhash = placeholder.Blurhash
+	media.DominantColor = placeholder.DominantColor
+	media.Status = models.MediaStatusReady
+	media.ProcessingError = nil
+	if err := s.repo.UpdateProcessingResult(ctx, media); err != nil {
+		return fmt.Errorf("failed to store processing result: %w", err)
+	}
+
+	s.logger.Info("Media processed",
+		zap.String("media_id", mediaID),
+		zap.String("content_type", contentType),
+		zap.Int32("width", media.Width),
+		zap.Int32("height", media.Height),
+		zap.Int("variants", variants),
+		zap.Bool("duplicate", duplicate))
+
+	return nil
+}
+
+// failProcessing marks a media as failed with a reason clients can show
+func (s *MediaService) failProcessing(ctx context.Context, media *models.Media, reason string) error {
+	s.logger.Warn("Media processing failed",
+		zap.String("media_id", media.ID),
+		zap.String("reason", reason))
+
+	media.Status = models.MediaStatusFailed
+	media.ProcessingError = &reason
+	if err := s.repo.UpdateProcessingResult(ctx, media); err != nil {
+		return fmt.Errorf("failed to store processing result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sdshorin/generia/services/media-service/internal/models"
+	"github.com/sdshorin/generia/services/media-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+// SweeperConfig holds media sweeper configuration
+type SweeperConfig struct {
+	Interval    time.Duration // How often the sweeper runs, defaults to 5m
+	UploadGrace time.Duration // Extra time after the upload URL expired before an upload is expired, defaults to 5m
+	OrphanAfter time.Duration // How long media may stay unreferenced before it is orphaned, defaults to 24h
+	DeleteAfter time.Duration // How long media stays orphaned before its objects are removed, defaults to 24h
+	BlobGrace   time.Duration // How long an unreferenced blob is kept for identical uploads, defaults to 1h
+	BatchSize   int           // Defaults to 100
+}
+
+var (
+	sweeperBytesReclaimedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "media_sweeper_bytes_reclaimed_total",
+		Help: "Number of bytes removed from storage by the media sweeper",
+	})
+
+	sweeperObjectsDeletedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "media_sweeper_objects_deleted_total",
+		Help: "Number of objects (originals and variants) removed from storage by the media sweeper",
+	})
+
+	sweeperTransitionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "media_sweeper_transitions_total",
+		Help: "Number of media moved to a lifecycle status by the media sweeper",
+	}, []string{"status"})
+)
+
+// Sweeper drives the media lifecycle in the background:
+//   - pending uploads never confirmed after the upload URL expired become
+//     uploaded if the object exists, deleted otherwise
+//   - media not referenced by any post, character or world becomes orphaned
+//   - orphaned media referenced again is restored
+//   - media orphaned for long enough is deleted and its objects removed
+//   - deduplicated blobs no media references anymore are removed after BlobGrace
+//
+// Removed objects are invalidated in the CDN cache, so they stop being served at once.
+//
+// Every transition is a conditional update, so several replicas can sweep concurrently.
+type Sweeper struct {
+	repo        repository.MediaRepository
+	minioClient *minio.Client
+	invalidator CacheInvalidator
+	config      SweeperConfig
+	logger      *zap.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewSweeper creates a new media sweeper
+func NewSweeper(repo repository.MediaRepository, minioClient *minio.Client, invalidator CacheInvalidator, config SweeperConfig, logger *zap.Logger) *Sweeper {
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Minute
+	}
+	if config.UploadGrace <= 0 {
+		config.UploadGrace = 5 * time.Minute
+	}
+	if config.OrphanAfter <= 0 {
+		config.OrphanAfter = 24 * time.Hour
+	}
+	if config.DeleteAfter <= 0 {
+		config.DeleteAfter = 24 * time.Hour
+	}
+	if config.BlobGrace <= 0 {
+		config.BlobGrace = time.Hour
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+
+	return &Sweeper{
+		repo:        repo,
+		minioClient: minioClient,
+		invalidator: invalidator,
+		config:      config,
+		logger:      logger,
+		done:        make(chan struct{}),
+	}
+}
+
+// Start runs the sweeper loop in the background until Close is called
+func (s *Sweeper) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	go func() {
+		defer close(s.done)
+		s.run(ctx)
+	}()
+
+	s.logger.Info("Media sweeper started", zap.Duration("interval", s.config.Interval))
+}
+
+// Close stops the sweeper after the current run
+func (s *Sweeper) Close() {
+	s.once.Do(func() {
+		if s.cancel != nil {
+			s.cancel()
+			<-s.done
+		}
+		s.logger.Info("Media sweeper stopped")
+	})
+}
+
+func (s *Sweeper) run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.Sweep(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep runs every lifecycle step once. Errors are logged, a failed step does not stop the others.
+func (s *Sweeper) Sweep(ctx context.Context) {
+	steps := []struct {
+		name string
+		fn   func(context.Context) error
+	}{
+		{"expire uploads", s.expireUploads},
+		{"restore referenced", s.restoreReferenced},
+		{"mark orphaned", s.markOrphaned},
+		{"mark deleted", s.markDeleted},
+		{"purge deleted", s.purgeDeleted},
+		{"purge blobs", s.purgeBlobs},
+	}
+	for _, step := range steps {
+		if err := step.fn(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("Media sweeper step failed", zap.String("step", step.name), zap.Error(err))
+		}
+	}
+}
+
+// expireUploads resolves pending uploads whose upload URL expired without a confirmation
+func (s *Sweeper) expireUploads(ctx context.Context) error {
+	createdBefore := time.Now().Add(-uploadURLExpiry - s.config.UploadGrace)
+	media, err := s.repo.ListExpiredUploads(ctx, createdBefore, s.config.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list expired uploads: %w", err)
+	}
+
+	for _, m := range media {
+		to := models.MediaStatusUploaded
+		_, err := s.minioClient.StatObject(ctx, m.BucketName, m.ObjectName, minio.StatObjectOptions{})
+		if isNoSuchKey(err) {
+			to = models.MediaStatusDeleted
+		} else if err != nil {
+			s.logger.Warn("Failed to check expired upload in storage", zap.String("media_id", m.ID), zap.Error(err))
+			continue
+		}
+
+		// A confirmation may have won the race, then the media is left alone
+		updated, err := s.repo.UpdateStatus(ctx, m.ID, models.MediaStatusPending, to)
+		if err != nil {
+			return fmt.Errorf("failed to expire upload %s: %w", m.ID, err)
+		}
+		if updated {
+			sweeperTransitionsTotal.WithLabelValues(to).Inc()
+		}
+	}
+	return nil
+}
+
+// restoreReferenced brings orphaned media that got referenced again back to life
+func (s *Sweeper) restoreReferenced(ctx context.Context) error {
+	restored, err := s.repo.RestoreReferenced(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to restore referenced media: %w", err)
+	}
+	if restored > 0 {
+		s.logger.Info("Restored referenced media", zap.Int64("count", restored))
+	}
+	return nil
+}
+
+// markOrphaned marks media nothing points to as orphaned
+func (s *Sweeper) markOrphaned(ctx context.Context) error {
+	updatedBefore := time.Now().Add(-s.config.OrphanAfter)
+	return s.drain(ctx, models.MediaStatusOrphaned, func(ctx context.Context) (int64, error) {
+		return s.repo.MarkOrphaned(ctx, updatedBefore, s.config.BatchSize)
+	})
+}
+
+// markDeleted marks media orphaned for longer than DeleteAfter as deleted
+func (s *Sweeper) markDeleted(ctx context.Context) error {
+	orphanedBefore := time.Now().Add(-s.config.DeleteAfter)
+	return s.drain(ctx, models.MediaStatusDeleted, func(ctx context.Context) (int64, error) {
+		return s.repo.MarkDeleted(ctx, orphanedBefore, s.config.BatchSize)
+	})
+}
+
+// drain repeats a batched transition until a batch comes back short
+func (s *Sweeper) drain(ctx context.Context, status string, batch func(context.Context) (int64, error)) error {
+	for ctx.Err() == nil {
+		updated, err := batch(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to mark media %s: %w", status, err)
+		}
+		if updated > 0 {
+			sweeperTransitionsTotal.WithLabelValues(status).Add(float64(updated))
+			s.logger.Info("Media lifecycle updated", zap.String("status", status), zap.Int64("count", updated))
+		}
+		if updated < int64(s.config.BatchSize) {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// purgeDeleted removes the original and variant objects of deleted media from storage.
+// Deduplicated media only drops its blob reference, see purgeBlobs.
+func (s *Sweeper) purgeDeleted(ctx context.Context) error {
+	media, err := s.repo.ListUnpurged(ctx, s.config.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list deleted media: %w", err)
+	}
+
+	for _, m := range media {
+		if m.ContentHash != nil {
+			if err := s.repo.MarkPurged(ctx, m); err != nil {
+				return fmt.Errorf("failed to release blob of %s: %w", m.ID, err)
+			}
+			continue
+		}
+
+		variants, err := s.repo.GetMediaVariants(ctx, m.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get variants of %s: %w", m.ID, err)
+		}
+
+		objects := make([]string, 0, len(variants)+1)
+		objects = append(objects, m.ObjectName)
+		for _, v := range variants {
+			objects = append(objects, v.URL)
+		}
+
+		var reclaimed int64
+		for _, objectName := range objects {
+			size, err := s.removeObject(ctx, m.BucketName, objectName)
+			if err != nil {
+				return fmt.Errorf("failed to remove %s: %w", objectName, err)
+			}
+			reclaimed += size
+		}
+
+		if err := s.repo.MarkPurged(ctx, m); err != nil {
+			return fmt.Errorf("failed to mark %s purged: %w", m.ID, err)
+		}
+		s.invalidate(ctx, objects)
+
+		s.logger.Info("Media purged",
+			zap.String("media_id", m.ID),
+			zap.Int("objects", len(objects)),
+			zap.Int64("bytes", reclaimed))
+	}
+	return nil
+}
+
+// purgeBlobs removes the originals and variants of blobs no media references
+// anymore. The blob row goes first: a failure afterwards leaks objects rather
+// than leaving a blob without them.
+func (s *Sweeper) purgeBlobs(ctx context.Context) error {
+	blobs, err := s.repo.DeleteReleasedBlobs(ctx, time.Now().Add(-s.config.BlobGrace), s.config.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to delete released blobs: %w", err)
+	}
+
+	for _, blob := range blobs {
+		var reclaimed int64
+		objects := 0
+		for object := range s.minioClient.ListObjects(ctx, blob.BucketName, minio.ListObjectsOptions{Prefix: models.BlobPrefix(blob.ContentHash)}) {
+			if object.Err != nil {
+				return fmt.Errorf("failed to list objects of blob %s: %w", blob.ContentHash, object.Err)
+			}
+			size, err := s.removeObject(ctx, blob.BucketName, object.Key)
+			if err != nil {
+				return fmt.Errorf("failed to remove %s: %w", object.Key, err)
+			}
+			reclaimed += size
+			objects++
+		}
+		s.invalidate(ctx, []string{models.BlobPrefix(blob.ContentHash) + "*"})
+
+		s.logger.Info("Blob purged",
+			zap.String("content_hash", blob.ContentHash),
+			zap.Int("objects", objects),
+			zap.Int64("bytes", reclaimed))
+	}
+	return nil
+}
+
+// invalidate evicts removed objects from the CDN cache. Failures are only
+// logged, the cache entries expire on their own.
+func (s *Sweeper) invalidate(ctx context.Context, paths []string) {
+	if s.invalidator == nil {
+		return
+	}
+	if _, err := s.invalidator.Invalidate(ctx, paths); err != nil {
+		s.logger.Warn("Failed to invalidate CDN cache", zap.Strings("paths", paths), zap.Error(err))
+	}
+}
+
+// removeObject deletes an object and returns its size, or 0 if it did not exist
+func (s *Sweeper) removeObject(ctx context.Context, bucket, objectName string) (int64, error) {
+	info, err := s.minioClient.StatObject(ctx, bucket, objectName, minio.StatObjectOptions{})
+	if isNoSuchKey(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.minioClient.RemoveObject(ctx, bucket, objectName, minio.RemoveObjectOptions{}); err != nil {
+		return 0, err
+	}
+
+	sweeperObjectsDeletedTotal.Inc()
+	sweeperBytesReclaimedTotal.Add(float64(info.Size))
+	return info.Size, nil
+}
+
+// isNoSuchKey reports whether a MinIO error means the object does not exist
+func isNoSuchKey(err error) bool {
+	return err != nil && minio.ToErrorResponse(err).Code == "NoSuchKey"
+}
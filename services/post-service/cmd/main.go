@@ -14,10 +14,15 @@ import (
 	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sdshorin/generia/pkg/auth"
+	"github.com/sdshorin/generia/pkg/cache"
 	"github.com/sdshorin/generia/pkg/config"
 	"github.com/sdshorin/generia/pkg/database"
 	"github.com/sdshorin/generia/pkg/discovery"
+	"github.com/sdshorin/generia/pkg/events"
+	"github.com/sdshorin/generia/pkg/kafka"
 	"github.com/sdshorin/generia/pkg/logger"
+	"github.com/sdshorin/generia/pkg/outbox"
 	"github.com/sdshorin/generia/pkg/telemetry"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 
@@ -31,6 +36,7 @@ import (
 	"google.golang.org/grpc/reflection"
 
 	authpb "github.com/sdshorin/generia/api/grpc/auth"
+	cachepb "github.com/sdshorin/generia/api/grpc/cache"
 	characterpb "github.com/sdshorin/generia/api/grpc/character"
 	interactionpb "github.com/sdshorin/generia/api/grpc/interaction"
 	mediapb "github.com/sdshorin/generia/api/grpc/media"
@@ -118,11 +124,41 @@ func main() {
 	}
 	defer characterConn.Close()
 
+	// The cache is optional: without cache-service posts are loaded on every request
+	cacheConn, cacheClient, err := createCacheClient(discoveryClient)
+	if err != nil {
+		logger.Logger.Warn("Cache service is unavailable, caching is disabled", zap.Error(err))
+	} else {
+		defer cacheConn.Close()
+	}
+	postCache := cache.New(cacheClient, cfg.Service.Name, cache.Config{})
+
+	// Moderation checks the access token of the caller, revoked tokens are looked up in cache-service
+	var tokenVerifier *auth.TokenVerifier
+	if cacheClient != nil {
+		jwks := auth.NewJWKSCache(func(ctx context.Context) ([]byte, error) {
+			resp, err := authClient.GetJWKS(ctx, &authpb.GetJWKSRequest{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get JWKS: %w", err)
+			}
+			return resp.Jwks, nil
+		}, cfg.JWT.JWKSRefreshInterval)
+		jwks.Start()
+		defer jwks.Close()
+		tokenVerifier = auth.NewTokenVerifier(jwks.Keyfunc, auth.NewDenyList(cacheClient, cfg.JWT.Leeway), cfg.JWT)
+	}
+
+	// Initialize outbox relay, which publishes events stored together with posts
+	eventPublisher := events.NewKafkaPublisher(kafka.NewProducer(cfg.Kafka.Brokers))
+	defer eventPublisher.Close()
+	outboxRelay := outbox.NewRelay(db, eventPublisher, outbox.RelayConfig{})
+	outboxRelay.Start()
+
 	// Initialize repositories
 	postRepo := repository.NewPostRepository(db)
 
 	// Initialize services
-	postService := service.NewPostService(postRepo, authClient, mediaClient, interactionClient, characterClient)
+	postService := service.NewPostService(postRepo, authClient, mediaClient, interactionClient, characterClient, postCache, tokenVerifier)
 
 	// Create gRPC server with middleware
 	grpcServer := grpc.NewServer(
@@ -177,6 +213,7 @@ func main() {
 
 	logger.Logger.Info("Shutting down post service...")
 	grpcServer.GracefulStop()
+	outboxRelay.Close()
 	logger.Logger.Info("Post service stopped")
 }
 
@@ -291,3 +328,31 @@ func createCharacterClient(discoveryClient discovery.ServiceDiscovery) (*grpc.Cl
 
 	return conn, client, nil
 }
+
+func createCacheClient(discoveryClient discovery.ServiceDiscovery) (*grpc.ClientConn, cachepb.CacheServiceClient, error) {
+	// Get service address from Consul
+	serviceAddress, err := discoveryClient.ResolveService("cache-service")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve cache service: %w", err)
+	}
+
+	// Create gRPC connection
+	conn, err := grpc.Dial(
+		serviceAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                10 * time.Second,
+			Timeout:             time.Second,
+			PermitWithoutStream: true,
+		}),
+		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to cache service: %w", err)
+	}
+
+	// Create client
+	client := cachepb.NewCacheServiceClient(conn)
+
+	return conn, client, nil
+}
@@ -8,19 +8,22 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/sdshorin/generia/pkg/events"
 	"github.com/sdshorin/generia/pkg/logger"
+	"github.com/sdshorin/generia/pkg/outbox"
 	"github.com/sdshorin/generia/services/post-service/internal/models"
 	"go.uber.org/zap"
 )
 
 // PostRepository handles database operations for posts
 type PostRepository interface {
-	Create(ctx context.Context, post *models.Post) error
+	Create(ctx context.Context, post *models.Post, actor events.Actor) error
 	GetByID(ctx context.Context, id string) (*models.Post, error)
 	GetByUserID(ctx context.Context, userID string, limit, offset int) ([]*models.Post, int, error)
 	GetByCharacterID(ctx context.Context, characterID string, limit, offset int) ([]*models.Post, int, error)
 	GetGlobalFeed(ctx context.Context, limit int, cursor string, worldID string) ([]*models.Post, string, error)
 	GetByIDs(ctx context.Context, ids []string) ([]*models.Post, error)
+	Delete(ctx context.Context, post *models.Post, actor events.Actor, reason string) error
 }
 
 type postRepository struct {
@@ -34,16 +37,17 @@ func NewPostRepository(db *sqlx.DB) PostRepository {
 	}
 }
 
-// Create inserts a new post into the database
-func (r *postRepository) Create(ctx context.Context, post *models.Post) error {
+// Create inserts a new post into the database together with its post.created
+// outbox event, so the event is never lost if the process dies after the insert
+func (r *postRepository) Create(ctx context.Context, post *models.Post, actor events.Actor) error {
 	query := `
 		INSERT INTO posts (id, character_id, is_ai, world_id, caption, media_id, created_at, updated_at)
 		VALUES (:id, :character_id, :is_ai, :world_id, :caption, :media_id, :created_at, :updated_at)
 		RETURNING id
 	`
 
-	// Set timestamps
-	now := time.Now()
+	// Set timestamps. PostgreSQL keeps microseconds, so the event carries the stored time.
+	now := time.Now().Truncate(time.Microsecond)
 	post.CreatedAt = now
 	post.UpdatedAt = now
 
@@ -52,23 +56,49 @@ func (r *postRepository) Create(ctx context.Context, post *models.Post) error {
 		post.ID = uuid.New().String()
 	}
 
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.Logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
 	// Use named parameters
 	var id string
-	rows, err := r.db.NamedQueryContext(ctx, query, post)
+	rows, err := sqlx.NamedQueryContext(ctx, tx, query, post)
 	if err != nil {
 		logger.Logger.Error("Failed to create post", zap.Error(err))
 		return err
 	}
-	defer rows.Close()
 
 	if rows.Next() {
 		err = rows.Scan(&id)
 		if err != nil {
+			rows.Close()
 			logger.Logger.Error("Failed to scan post ID", zap.Error(err))
 			return err
 		}
 		post.ID = id
 	}
+	rows.Close()
+
+	err = outbox.InsertEvent(ctx, tx, events.PostCreated, post.WorldID, actor, events.PostCreatedPayload{
+		PostID:      post.ID,
+		CharacterID: post.CharacterID,
+		MediaID:     post.MediaID,
+		Caption:     post.Caption,
+		IsAI:        post.IsAI,
+		CreatedAt:   post.CreatedAt,
+	})
+	if err != nil {
+		logger.Logger.Error("Failed to write post.created to outbox", zap.Error(err))
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Logger.Error("Failed to commit post creation", zap.Error(err))
+		return err
+	}
 
 	return nil
 }
@@ -253,4 +283,39 @@ func (r *postRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.
 	}
 
 	return posts, nil
-}
\ No newline at end of file
+}
+
+// Delete removes a post and writes its post.deleted outbox event
+func (r *postRepository) Delete(ctx context.Context, post *models.Post, actor events.Actor, reason string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.Logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM posts WHERE id = $1`, post.ID)
+	if err != nil {
+		logger.Logger.Error("Failed to delete post", zap.Error(err), zap.String("id", post.ID))
+		return err
+	}
+
+	err = outbox.InsertEvent(ctx, tx, events.PostDeleted, post.WorldID, actor, events.PostDeletedPayload{
+		PostID:      post.ID,
+		CharacterID: post.CharacterID,
+		MediaID:     post.MediaID,
+		Reason:      reason,
+		DeletedAt:   time.Now(),
+	})
+	if err != nil {
+		logger.Logger.Error("Failed to write post.deleted to outbox", zap.Error(err))
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Logger.Error("Failed to commit post deletion", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -2,8 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/sdshorin/generia/pkg/auth"
+	"github.com/sdshorin/generia/pkg/cache"
+	"github.com/sdshorin/generia/pkg/events"
 	"github.com/sdshorin/generia/pkg/logger"
 	"github.com/sdshorin/generia/services/post-service/internal/models"
 	"github.com/sdshorin/generia/services/post-service/internal/repository"
@@ -26,15 +30,20 @@ type PostService struct {
 	mediaClient       mediapb.MediaServiceClient
 	interactionClient interactionpb.InteractionServiceClient
 	characterClient   characterpb.CharacterServiceClient
+	cache             *cache.Cache
+	verifier          *auth.TokenVerifier
 }
 
-// NewPostService creates a new PostService
+// NewPostService creates a new PostService. verifier checks the access tokens
+// of moderators, without it deletion by moderation is unavailable.
 func NewPostService(
 	postRepo repository.PostRepository,
 	authClient authpb.AuthServiceClient,
 	mediaClient mediapb.MediaServiceClient,
 	interactionClient interactionpb.InteractionServiceClient,
 	characterClient characterpb.CharacterServiceClient,
+	postCache *cache.Cache,
+	verifier *auth.TokenVerifier,
 ) postpb.PostServiceServer {
 	return &PostService{
 		postRepo:          postRepo,
@@ -42,6 +51,8 @@ func NewPostService(
 		mediaClient:       mediaClient,
 		interactionClient: interactionClient,
 		characterClient:   characterClient,
+		cache:             postCache,
+		verifier:          verifier,
 	}
 }
 
@@ -91,7 +102,7 @@ func (s *PostService) CreatePost(ctx context.Context, req *postpb.CreatePostRequ
 		MediaID:     req.MediaId,
 	}
 
-	err = s.postRepo.Create(ctx, post)
+	err = s.postRepo.Create(ctx, post, events.Actor{UserID: req.UserId, CharacterID: characterID})
 	if err != nil {
 		logger.Logger.Error("Failed to create post", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "failed to create post")
@@ -99,75 +110,95 @@ func (s *PostService) CreatePost(ctx context.Context, req *postpb.CreatePostRequ
 
 	return &postpb.CreatePostResponse{
 		PostId:    post.ID,
-		CreatedAt: post.CreatedAt.Format(time.RFC3339),
+		CreatedAt: post.CreatedAt.Format(time.RFC3339Nano),
 	}, nil
 }
 
-// GetPost handles post retrieval by ID
+// GetPost handles post retrieval by ID. The post is cached without its
+// interaction stats, which change too often, and without its character's name
+// and avatar, which character-service caches and invalidates on updates; both
+// are fetched on every call.
 func (s *PostService) GetPost(ctx context.Context, req *postpb.GetPostRequest) (*postpb.Post, error) {
 	// Validate input
 	if req.PostId == "" {
 		return nil, status.Errorf(codes.InvalidArgument, "post_id is required")
 	}
 
-	// Get post
-	post, err := s.postRepo.GetByID(ctx, req.PostId)
-	if err != nil {
-		logger.Logger.Error("Failed to get post", zap.Error(err), zap.String("post_id", req.PostId))
-		return nil, status.Errorf(codes.Internal, "failed to get post")
-	}
-
-	if post == nil {
+	result, err := cache.GetOrLoad(ctx, s.cache, postCacheKey(req.PostId), cache.Proto[*postpb.Post](), func(ctx context.Context) (*postpb.Post, error) {
+		return s.loadPost(ctx, req.PostId)
+	})
+	if errors.Is(err, cache.ErrNotFound) {
 		return nil, status.Errorf(codes.NotFound, "post not found")
 	}
+	if err != nil {
+		return nil, err
+	}
 
 	// Get character info
 	characterResp, err := s.characterClient.GetCharacter(ctx, &characterpb.GetCharacterRequest{
-		CharacterId: post.CharacterID,
+		CharacterId: result.CharacterId,
 	})
 	if err != nil {
-		logger.Logger.Error("Failed to get character info", zap.Error(err), zap.String("character_id", post.CharacterID))
+		logger.Logger.Error("Failed to get character info", zap.Error(err), zap.String("character_id", result.CharacterId))
 		return nil, status.Errorf(codes.Internal, "failed to get character info")
 	}
-
-	// Get media URL
-	mediaResp, err := s.mediaClient.GetMediaURL(ctx, &mediapb.GetMediaURLRequest{
-		MediaId:   post.MediaID,
-		Variant:   "medium", // Default to medium size
-		ExpiresIn: 3600,     // 1 hour
-	})
-	if err != nil {
-		logger.Logger.Error("Failed to get media URL", zap.Error(err), zap.String("media_id", post.MediaID))
-		return nil, status.Errorf(codes.Internal, "failed to get media URL")
-	}
+	result.DisplayName = characterResp.DisplayName
+	result.AvatarUrl = characterResp.AvatarUrl
 
 	// Get interaction stats
 	statsResp, err := s.interactionClient.GetPostStats(ctx, &interactionpb.GetPostStatsRequest{
-		PostId: post.ID,
+		PostId: result.PostId,
 	})
 	if err != nil {
-		logger.Logger.Error("Failed to get post stats", zap.Error(err), zap.String("post_id", post.ID))
+		logger.Logger.Error("Failed to get post stats", zap.Error(err), zap.String("post_id", result.PostId))
 		// Continue even if stats can't be retrieved
 		statsResp = &interactionpb.PostStatsResponse{
-			PostId:        post.ID,
+			PostId:        result.PostId,
 			LikesCount:    0,
 			CommentsCount: 0,
 		}
 	}
 
-	return &postpb.Post{
-		PostId:        post.ID,
-		CharacterId:   post.CharacterID,
-		DisplayName:   characterResp.DisplayName,
-		Caption:       post.Caption,
-		MediaUrl:      mediaResp.Url,
-		CreatedAt:     post.CreatedAt.Format(time.RFC3339),
-		LikesCount:    statsResp.LikesCount,
-		CommentsCount: statsResp.CommentsCount,
-		WorldId:       post.WorldID,
-		IsAi:          post.IsAI,
-		AvatarUrl:     characterResp.AvatarUrl,
-	}, nil
+	result.LikesCount = statsResp.LikesCount
+	result.CommentsCount = statsResp.CommentsCount
+	return result, nil
+}
+
+// loadPost builds a post with its media URL, missing posts are reported as cache.ErrNotFound
+func (s *PostService) loadPost(ctx context.Context, postID string) (*postpb.Post, error) {
+	// Get post
+	post, err := s.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		logger.Logger.Error("Failed to get post", zap.Error(err), zap.String("post_id", postID))
+		return nil, status.Errorf(codes.Internal, "failed to get post")
+	}
+
+	if post == nil {
+		return nil, cache.ErrNotFound
+	}
+
+	// Get media URL
+	mediaResp, err := s.mediaClient.GetMediaURL(ctx, &mediapb.GetMediaURLRequest{
+		MediaId:   post.MediaID,
+		Variant:   "medium", // Default to medium size
+		ExpiresIn: 3600,     // 1 hour
+	})
+	if err != nil {
+		logger.Logger.Error("Failed to get media URL", zap.Error(err), zap.String("media_id", post.MediaID))
+		return nil, status.Errorf(codes.Internal, "failed to get media URL")
+	}
+
+	result := &postpb.Post{
+		PostId:      post.ID,
+		CharacterId: post.CharacterID,
+		Caption:     post.Caption,
+		MediaUrl:    mediaResp.Url,
+		CreatedAt:   post.CreatedAt.Format(time.RFC3339Nano),
+		WorldId:     post.WorldID,
+		IsAi:        post.IsAI,
+	}
+	setMediaPlaceholder(result, mediaResp)
+	return result, nil
 }
 
 // GetUserPosts handles retrieval of posts by user ID
@@ -285,13 +316,14 @@ func (s *PostService) GetUserPosts(ctx context.Context, req *postpb.GetUserPosts
 			DisplayName:   displayName,
 			Caption:       post.Caption,
 			MediaUrl:      mediaURL,
-			CreatedAt:     post.CreatedAt.Format(time.RFC3339),
+			CreatedAt:     post.CreatedAt.Format(time.RFC3339Nano),
 			LikesCount:    likesCount,
 			CommentsCount: commentsCount,
 			WorldId:       post.WorldID,
 			IsAi:          post.IsAI,
 			AvatarUrl:     characterInfoMap[post.CharacterID].AvatarUrl,
 		}
+		setMediaPlaceholder(result[i], mediaResp)
 	}
 
 	return &postpb.PostList{
@@ -399,13 +431,14 @@ func (s *PostService) GetCharacterPosts(ctx context.Context, req *postpb.GetChar
 			DisplayName:   characterResp.DisplayName,
 			Caption:       post.Caption,
 			MediaUrl:      mediaURL,
-			CreatedAt:     post.CreatedAt.Format(time.RFC3339),
+			CreatedAt:     post.CreatedAt.Format(time.RFC3339Nano),
 			LikesCount:    likesCount,
 			CommentsCount: commentsCount,
 			WorldId:       post.WorldID,
 			IsAi:          post.IsAI,
 			AvatarUrl:     characterResp.AvatarUrl,
 		}
+		setMediaPlaceholder(result[i], mediaResp)
 	}
 
 	return &postpb.PostList{
@@ -511,13 +544,14 @@ func (s *PostService) GetPostsByIds(ctx context.Context, req *postpb.GetPostsByI
 			DisplayName:   displayName,
 			Caption:       post.Caption,
 			MediaUrl:      mediaURL,
-			CreatedAt:     post.CreatedAt.Format(time.RFC3339),
+			CreatedAt:     post.CreatedAt.Format(time.RFC3339Nano),
 			LikesCount:    likesCount,
 			CommentsCount: commentsCount,
 			WorldId:       post.WorldID,
 			IsAi:          post.IsAI,
 			AvatarUrl:     characterInfoMap[post.CharacterID].AvatarUrl,
 		}
+		setMediaPlaceholder(result[i], mediaResp)
 	}
 
 	return &postpb.PostList{
@@ -632,13 +666,14 @@ func (s *PostService) GetGlobalFeed(ctx context.Context, req *postpb.GetGlobalFe
 			DisplayName:   displayName,
 			Caption:       post.Caption,
 			MediaUrl:      mediaURL,
-			CreatedAt:     post.CreatedAt.Format(time.RFC3339),
+			CreatedAt:     post.CreatedAt.Format(time.RFC3339Nano),
 			LikesCount:    likesCount,
 			CommentsCount: commentsCount,
 			WorldId:       post.WorldID,
 			IsAi:          post.IsAI,
 			AvatarUrl:     avatarURL,
 		}
+		setMediaPlaceholder(result[i], mediaResp)
 	}
 
 	return &postpb.PostList{
@@ -648,6 +683,94 @@ func (s *PostService) GetGlobalFeed(ctx context.Context, req *postpb.GetGlobalFe
 	}, nil
 }
 
+// DeletePost handles post deletion by its author or by moderation.
+// Feeds drop the post when they receive the post.deleted event.
+func (s *PostService) DeletePost(ctx context.Context, req *postpb.DeletePostRequest) (*postpb.DeletePostResponse, error) {
+	// Validate input
+	if req.PostId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "post_id is required")
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = events.PostDeletedByAuthor
+	}
+	if reason != events.PostDeletedByAuthor && reason != events.PostDeletedByModeration {
+		return nil, status.Errorf(codes.InvalidArgument, "reason must be %q or %q", events.PostDeletedByAuthor, events.PostDeletedByModeration)
+	}
+	if reason == events.PostDeletedByAuthor && req.UserId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id is required")
+	}
+
+	// Moderation skips the ownership check, so the caller must prove the role with its token
+	actorID := req.UserId
+	if reason == events.PostDeletedByModeration {
+		moderatorID, err := s.moderator(ctx, req.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+		actorID = moderatorID
+	}
+
+	// Get post
+	post, err := s.postRepo.GetByID(ctx, req.PostId)
+	if err != nil {
+		logger.Logger.Error("Failed to get post", zap.Error(err), zap.String("post_id", req.PostId))
+		return nil, status.Errorf(codes.Internal, "failed to get post")
+	}
+
+	if post == nil {
+		return nil, status.Errorf(codes.NotFound, "post not found")
+	}
+
+	// Only the owner of the character may delete its posts
+	if reason == events.PostDeletedByAuthor {
+		characterResp, err := s.characterClient.GetCharacter(ctx, &characterpb.GetCharacterRequest{
+			CharacterId: post.CharacterID,
+		})
+		if err != nil {
+			logger.Logger.Error("Failed to get character info", zap.Error(err), zap.String("character_id", post.CharacterID))
+			return nil, status.Errorf(codes.Internal, "failed to get character info")
+		}
+		if characterResp.RealUserId == nil || *characterResp.RealUserId != req.UserId {
+			return nil, status.Errorf(codes.PermissionDenied, "post belongs to another user")
+		}
+	}
+
+	err = s.postRepo.Delete(ctx, post, events.Actor{UserID: actorID, CharacterID: post.CharacterID}, reason)
+	if err != nil {
+		logger.Logger.Error("Failed to delete post", zap.Error(err), zap.String("post_id", req.PostId))
+		return nil, status.Errorf(codes.Internal, "failed to delete post")
+	}
+	s.cache.Delete(ctx, postCacheKey(post.ID))
+
+	return &postpb.DeletePostResponse{
+		Success: true,
+	}, nil
+}
+
+// moderator verifies the access token of a moderation call and returns the user ID of the moderator
+func (s *PostService) moderator(ctx context.Context, accessToken string) (string, error) {
+	if s.verifier == nil {
+		return "", status.Errorf(codes.Unavailable, "moderation is unavailable")
+	}
+	if accessToken == "" {
+		return "", status.Errorf(codes.Unauthenticated, "access_token is required for moderation")
+	}
+
+	claims, err := s.verifier.Verify(ctx, accessToken)
+	if errors.Is(err, auth.ErrRevocationUnknown) {
+		return "", status.Errorf(codes.Unavailable, "access token can't be checked")
+	}
+	if err != nil {
+		return "", status.Errorf(codes.Unauthenticated, "invalid access token")
+	}
+	if !claims.HasRole(auth.RoleModerator) && !claims.HasRole(auth.RoleAdmin) {
+		return "", status.Errorf(codes.PermissionDenied, "moderator or admin role is required")
+	}
+	return claims.UserID, nil
+}
+
 // HealthCheck implements health check
 func (s *PostService) HealthCheck(ctx context.Context, req *postpb.HealthCheckRequest) (*postpb.HealthCheckResponse, error) {
 	return &postpb.HealthCheckResponse{
@@ -699,7 +822,7 @@ func (s *PostService) CreateAIPost(ctx context.Context, req *postpb.CreateAIPost
 		MediaID:     req.MediaId,
 	}
 
-	err = s.postRepo.Create(ctx, post)
+	err = s.postRepo.Create(ctx, post, events.Actor{CharacterID: req.CharacterId})
 	if err != nil {
 		logger.Logger.Error("Failed to create AI post", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "failed to create post")
@@ -707,6 +830,24 @@ func (s *PostService) CreateAIPost(ctx context.Context, req *postpb.CreateAIPost
 
 	return &postpb.CreatePostResponse{
 		PostId:    post.ID,
-		CreatedAt: post.CreatedAt.Format(time.RFC3339),
+		CreatedAt: post.CreatedAt.Format(time.RFC3339Nano),
 	}, nil
 }
+
+// postCacheKey is the cache key of a post returned by GetPost
+func postCacheKey(postID string) string {
+	return "post:" + postID
+}
+
+// setMediaPlaceholder copies the image size, blurhash and dominant color returned
+// together with the media URL onto a post, so clients can lay the post out before
+// the image loads. A nil response (media URL lookup failed) leaves them empty.
+func setMediaPlaceholder(post *postpb.Post, media *mediapb.GetMediaURLResponse) {
+	if media == nil {
+		return
+	}
+	post.MediaWidth = media.Width
+	post.MediaHeight = media.Height
+	post.MediaBlurhash = media.Blurhash
+	post.MediaDominantColor = media.DominantColor
+}
@@ -18,7 +18,10 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sdshorin/generia/pkg/config"
 	"github.com/sdshorin/generia/pkg/discovery"
+	"github.com/sdshorin/generia/pkg/events"
+	"github.com/sdshorin/generia/pkg/kafka"
 	"github.com/sdshorin/generia/pkg/logger"
+	"github.com/sdshorin/generia/pkg/outbox"
 	"github.com/sdshorin/generia/pkg/telemetry"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.uber.org/zap"
@@ -112,6 +115,12 @@ func main() {
 		temporalHost = "temporal:7233" // Default to docker-compose service name
 	}
 
+	// Initialize outbox relay, which publishes events stored together with worlds
+	eventPublisher := events.NewKafkaPublisher(kafka.NewProducer(cfg.Kafka.Brokers))
+	defer eventPublisher.Close()
+	outboxRelay := outbox.NewRelay(db, eventPublisher, outbox.RelayConfig{})
+	outboxRelay.Start()
+
 	// Initialize world service
 	worldService := service.NewWorldService(worldRepo, authClient, postClient, mediaClient, temporalHost)
 
@@ -168,6 +177,7 @@ func main() {
 
 	logger.Logger.Info("Shutting down world service...")
 	grpcServer.GracefulStop()
+	outboxRelay.Close()
 	logger.Logger.Info("World service stopped")
 }
 
@@ -3,9 +3,12 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/sdshorin/generia/pkg/events"
 	"github.com/sdshorin/generia/pkg/logger"
+	"github.com/sdshorin/generia/pkg/outbox"
 	"github.com/sdshorin/generia/services/world-service/internal/models"
 	"go.uber.org/zap"
 )
@@ -43,15 +46,22 @@ func NewWorldRepository(db *sqlx.DB) WorldRepository {
 	}
 }
 
-// Create inserts a new world into the database
+// Create inserts a new world into the database together with its world.created outbox event
 func (r *PostgresWorldRepository) Create(ctx context.Context, world *models.World) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.Logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
                INSERT INTO worlds (name, description, prompt, creator_id, generation_status, status, image_uuid, icon_uuid, params, users_count, posts_count)
                VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
                RETURNING id, created_at, updated_at
        `
 
-	row := r.db.QueryRowContext(
+	row := tx.QueryRowContext(
 		ctx,
 		query,
 		world.Name,
@@ -68,12 +78,29 @@ func (r *PostgresWorldRepository) Create(ctx context.Context, world *models.Worl
 		world.PostsCount,
 	)
 
-	err := row.Scan(&world.ID, &world.CreatedAt, &world.UpdatedAt)
+	err = row.Scan(&world.ID, &world.CreatedAt, &world.UpdatedAt)
 	if err != nil {
 		logger.Logger.Error("Failed to create world", zap.Error(err))
 		return err
 	}
 
+	err = outbox.InsertEvent(ctx, tx, events.WorldCreated, world.ID,
+		events.Actor{UserID: world.CreatorID},
+		events.WorldCreatedPayload{
+			Name:      world.Name,
+			CreatorID: world.CreatorID,
+			CreatedAt: world.CreatedAt,
+		})
+	if err != nil {
+		logger.Logger.Error("Failed to write world.created to outbox", zap.Error(err))
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Logger.Error("Failed to commit world creation", zap.Error(err))
+		return err
+	}
+
 	return nil
 }
 
@@ -238,7 +265,7 @@ func (r *PostgresWorldRepository) UpdateStatus(ctx context.Context, id, status s
 	return nil
 }
 
-// AddUserToWorld adds a user to a world
+// AddUserToWorld adds a user to a world and records a world.joined outbox event
 func (r *PostgresWorldRepository) AddUserToWorld(ctx context.Context, userID, worldID string) error {
 	// Check if relationship already exists
 	var exists bool
@@ -261,12 +288,25 @@ func (r *PostgresWorldRepository) AddUserToWorld(ctx context.Context, userID, wo
 		return nil // Relationship already exists
 	}
 
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.Logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO user_worlds (user_id, world_id)
 		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+		RETURNING created_at
 	`
 
-	_, err = r.db.ExecContext(ctx, query, userID, worldID)
+	var joinedAt time.Time
+	err = tx.QueryRowContext(ctx, query, userID, worldID).Scan(&joinedAt)
+	if err == sql.ErrNoRows {
+		return nil // Relationship was created concurrently
+	}
 	if err != nil {
 		logger.Logger.Error("Failed to add user to world",
 			zap.Error(err),
@@ -275,6 +315,22 @@ func (r *PostgresWorldRepository) AddUserToWorld(ctx context.Context, userID, wo
 		return err
 	}
 
+	err = outbox.InsertEvent(ctx, tx, events.WorldJoined, worldID,
+		events.Actor{UserID: userID},
+		events.WorldJoinedPayload{
+			UserID:   userID,
+			JoinedAt: joinedAt,
+		})
+	if err != nil {
+		logger.Logger.Error("Failed to write world.joined to outbox", zap.Error(err))
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Logger.Error("Failed to commit user world join", zap.Error(err))
+		return err
+	}
+
 	return nil
 }
 